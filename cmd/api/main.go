@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -13,10 +14,25 @@ import (
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/navid/blog/internal/apiversion"
+	"github.com/navid/blog/internal/auth"
 	"github.com/navid/blog/internal/config"
+	"github.com/navid/blog/internal/database"
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/gql"
+	"github.com/navid/blog/internal/handlers"
+	"github.com/navid/blog/internal/health"
+	"github.com/navid/blog/internal/logging"
+	"github.com/navid/blog/internal/metrics"
 	"github.com/navid/blog/internal/middleware"
+	"github.com/navid/blog/internal/openapi"
+	"github.com/navid/blog/internal/ratelimit"
 	"github.com/navid/blog/internal/routes"
+	"github.com/navid/blog/internal/rpc"
 	"github.com/navid/blog/internal/services"
+	"github.com/navid/blog/internal/sitemap"
+	"github.com/navid/blog/internal/tracing"
+	"github.com/navid/blog/internal/web"
 )
 
 func main() {
@@ -35,10 +51,29 @@ func run(ctx context.Context) error {
 	}
 
 	// Create a structured logger, which will print logs in json format to the
-	// writer we specify.
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	// writer we specify. Records logged with a traced context carry the trace
+	// and span IDs, and records logged during a request are written by the
+	// request's logger so they carry its request ID, route and user.
+	logger := slog.New(logging.NewHandler(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: cfg.LogLevel,
-	}))
+	}))))
+	slog.SetDefault(logger)
+
+	// Set up tracing and flush any buffered spans on the way out
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.OTLPEndpoint,
+		SampleRatio: cfg.TracingSampleRatio,
+		ServiceName: cfg.ServiceName,
+	})
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to set up tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.ErrorContext(ctx, "Failed to shut down tracing", "err", err)
+		}
+	}()
 
 	// Create a new DB connection using environment config
 	logger.DebugContext(ctx, "Connecting to database")
@@ -69,6 +104,9 @@ func run(ctx context.Context) error {
 
 	logger.InfoContext(ctx, "Connected successfully to the database")
 
+	// Report connection pool statistics with the other metrics
+	metrics.Default.RegisterDBStats(db)
+
 	// Create a new users service
 	usersService := services.NewUsersService(logger, db)
 
@@ -78,22 +116,182 @@ func run(ctx context.Context) error {
 	// Create a new comments service
 	commentsService := services.NewCommentsService(db, logger)
 
+	// Create a new reactions service
+	reactionsService := services.NewReactionsService(db, logger, cfg.ReactionTypes)
+
+	// Create a new follows service
+	followsService := services.NewFollowsService(db, logger)
+
+	// Create new bookmarks and reading lists services
+	bookmarksService := services.NewBookmarksService(db, logger)
+	readingListsService := services.NewReadingListsService(db, logger)
+
+	// Create the bearer token issuer and verifier
+	secret := []byte(cfg.AuthSecret)
+	if len(secret) == 0 {
+		logger.WarnContext(ctx, "AUTH_SECRET is not set, generating a random secret; tokens will not survive a restart")
+		secret = make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			return fmt.Errorf("[in main.run] failed to generate auth secret: %w", err)
+		}
+	}
+	tokens := auth.NewTokens(secret, cfg.TokenTTL)
+
+	// Create the bulk import and export service
+	transferService := services.NewTransferService(db, logger)
+
+	// Create the tracker for background jobs, reported by the readiness check
+	// and waited on at shutdown
+	jobs := health.NewJobs(logger)
+
+	// Create the content filter chain run before comments and blogs are
+	// created, and train the spam scorer from past moderator decisions in the
+	// background. The service isn't ready until training has succeeded, so
+	// no content is screened by an untrained scorer
+	spamScorer := filters.NewBayes(cfg.SpamThreshold)
+	filterChain := filters.Chain{
+		filters.NewBannedWords(cfg.BannedWords),
+		filters.NewRepeatedMessage(cfg.RepeatWindow),
+		filters.NewLinkLimit(cfg.MaxLinks),
+		spamScorer,
+	}
+	moderationService := services.NewModerationService(db, logger, filterChain, spamScorer)
+	jobs.GoRequired(ctx, "train_spam_scorer", moderationService.Train)
+
+	// Build the GraphQL schema, resolved by the same services as the REST API
+	graphQL, err := gql.NewSchema(logger, usersService, blogService, commentsService, moderationService, cfg.AdminUserIDs, gql.Limits{
+		MaxDepth:      cfg.GraphQLMaxDepth,
+		MaxComplexity: cfg.GraphQLMaxComplexity,
+	})
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to build graphql schema: %w", err)
+	}
+
+	// Parse the templates of the server-rendered site
+	renderer, err := web.NewRenderer()
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to load templates: %w", err)
+	}
+
+	baseURL := fmt.Sprintf("http://%s:%s", cfg.Host, cfg.Port)
+
+	// Create the sitemap, rebuilt from the blogs and authors at most once per
+	// SitemapTTL
+	sitemaps := sitemap.NewCache(
+		handlers.SitemapSource(baseURL, blogService, usersService),
+		cfg.SitemapTTL,
+		func(n int) string { return fmt.Sprintf("%s/sitemaps/%d.xml", baseURL, n) },
+	)
+
+	// Create the rate limiter, keeping buckets in memory or in Postgres
+	limits, err := ratelimit.ParseLimits(cfg.RateLimitDefault, cfg.RateLimits)
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to parse rate limits: %w", err)
+	}
+	var rateLimitStore ratelimit.Store
+	switch cfg.RateLimitStore {
+	case "memory":
+		rateLimitStore = ratelimit.NewMemoryStore()
+	case "postgres":
+		rateLimitStore = ratelimit.NewPostgresStore(db)
+	default:
+		return fmt.Errorf("[in main.run] unknown rate limit store %q: must be memory or postgres", cfg.RateLimitStore)
+	}
+	limiter := ratelimit.NewLimiter(rateLimitStore, limits)
+	proxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to parse trusted proxies: %w", err)
+	}
+
+	// Work out which versions of the API are deprecated
+	deprecations, err := apiversion.ParseDeprecations(cfg.APIDeprecations)
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to parse API deprecations: %w", err)
+	}
+
+	// Create the readiness checks: the database must answer a ping and be
+	// migrated to the schema this build expects
+	checker := health.NewChecker(cfg.HealthCheckTimeout)
+	checker.Add("database", database.Ping(db))
+	checker.Add("migrations", database.Migrations(db))
+	checker.Add("jobs", jobs.Check)
+
 	// Create a serve mux to act as our route multiplexer
 	mux := http.NewServeMux()
 
-	// Add our routes to the mux
+	// Add our routes to the mux, along with the OpenAPI document describing
+	// them
+	spec := openapi.API()
+	for version := range deprecations {
+		spec.Deprecate(version)
+	}
+	// Every version of a route shares its rate limit and body size limit
+	unversioned := apiversion.Unversioned(mux)
+	// Each operation of a batch goes through the middleware a request to its
+	// route would, so batches get around neither rate limits nor validation.
+	// That of the batch request as a whole, such as CORS, isn't repeated
+	batchOperations := func(next http.Handler) http.Handler {
+		if cfg.OpenAPIValidate {
+			next = middleware.ValidateOpenAPI(logger, spec, mux, cfg.DevMode)(next)
+		}
+		next = middleware.RateLimit(logger, limiter, unversioned, proxies)(next)
+		return middleware.Logger(logger)(next)
+	}
 	routes.AddRoutes(
 		mux,
 		logger,
 		usersService,
 		blogService,
 		commentsService,
-		fmt.Sprintf("http://%s:%s", cfg.Host, cfg.Port),
+		moderationService,
+		reactionsService,
+		followsService,
+		bookmarksService,
+		readingListsService,
+		transferService,
+		db,
+		graphQL,
+		tokens,
+		renderer,
+		sitemaps,
+		checker,
+		spec,
+		deprecations,
+		proxies,
+		batchOperations,
+		cfg.AdminUserIDs,
+		cfg.DevMode,
+		baseURL,
 	)
 
 	// Wrap the mux with middleware
-	wrappedMux := middleware.Logger(logger)(mux)
+	var wrappedMux http.Handler = mux
+	if cfg.OpenAPIValidate {
+		wrappedMux = middleware.ValidateOpenAPI(logger, spec, mux, cfg.DevMode)(wrappedMux)
+	}
+	wrappedMux = middleware.Compress(cfg.CompressionMinSize)(wrappedMux)
+	wrappedMux = middleware.RateLimit(logger, limiter, unversioned, proxies)(wrappedMux)
+	wrappedMux = middleware.Authenticate(logger, tokens)(wrappedMux)
+	wrappedMux = middleware.MaxBodySize(cfg.MaxBodySize, unversioned, map[string]int64{
+		routes.ImportPattern: cfg.ImportMaxBodySize,
+	})(wrappedMux)
+	wrappedMux = middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins:   cfg.CORSOrigins,
+		AllowedMethods:   cfg.CORSMethods,
+		AllowedHeaders:   cfg.CORSHeaders,
+		ExposedHeaders:   []string{"X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", "ETag", "Location", "Deprecation", "Sunset", "Link"},
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	})(wrappedMux)
+	wrappedMux = middleware.SecurityHeaders(middleware.SecurityHeadersOptions{
+		ContentSecurityPolicy: cfg.ContentSecurityPolicy,
+		HSTSMaxAge:            cfg.HSTSMaxAge,
+	})(wrappedMux)
+	wrappedMux = middleware.Logger(logger)(wrappedMux)
 	wrappedMux = middleware.Recovery(logger)(wrappedMux)
+	wrappedMux = middleware.RequestID(logger, mux)(wrappedMux)
+	wrappedMux = middleware.Tracing(mux)(wrappedMux)
+	wrappedMux = middleware.Metrics(metrics.Default, mux)(wrappedMux)
 
 	// Create a new http server with our mux as the handler
 	httpServer := &http.Server{
@@ -101,6 +299,27 @@ func run(ctx context.Context) error {
 		Handler: wrappedMux,
 	}
 
+	// Create the gRPC server, sharing the service layer with the HTTP API.
+	// Listen before serving, so a port in use stops startup
+	grpcServer := rpc.NewServer(logger, tokens, cfg.AdminUserIDs, usersService, blogService, commentsService, moderationService)
+	grpcListener, err := net.Listen("tcp", net.JoinHostPort(cfg.Host, cfg.GRPCPort))
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to listen for grpc: %w", err)
+	}
+
+	// Serve metrics on their own listener, which can be kept internal
+	var metricsServer *http.Server
+	var metricsListener net.Listener
+	if cfg.MetricsAddr != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("GET /metrics", metrics.Default)
+		metricsServer = &http.Server{Handler: metricsMux}
+		metricsListener, err = net.Listen("tcp", cfg.MetricsAddr)
+		if err != nil {
+			return fmt.Errorf("[in main.run] failed to listen for metrics: %w", err)
+		}
+	}
+
 	errChan := make(chan error)
 
 	// Server run context
@@ -116,6 +335,12 @@ func run(ctx context.Context) error {
 
 		logger.DebugContext(ctx, "Received SIGINT, shutting down server")
 
+		// Report the server as unavailable and give load balancers time to
+		// notice before it stops accepting connections
+		checker.Drain()
+		grpcServer.Drain()
+		time.Sleep(cfg.ShutdownDrainDelay)
+
 		// Create a context with a timeout to allow the server to shut down gracefully
 		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
@@ -126,10 +351,52 @@ func run(ctx context.Context) error {
 			return
 		}
 
+		if metricsServer != nil {
+			if err := metricsServer.Shutdown(ctx); err != nil {
+				logger.WarnContext(ctx, "failed to shutdown metrics server", slog.String("error", err.Error()))
+			}
+		}
+
+		// Stop the gRPC server once in-flight calls finish, or cut them off
+		// at the deadline
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			grpcServer.Stop()
+		}
+
+		// Wait for background jobs to finish
+		if err := jobs.Wait(ctx); err != nil {
+			logger.WarnContext(ctx, "Background jobs did not finish before shutdown", slog.Any("pending", jobs.Pending()))
+		}
+
 		// Close the idle connections channel, unblocking `run()`
 		done()
 	}()
 
+	// Start the gRPC server
+	go func() {
+		logger.InfoContext(ctx, "listening for grpc", slog.String("address", grpcListener.Addr().String()))
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logger.ErrorContext(ctx, "grpc server stopped", slog.String("error", err.Error()))
+		}
+	}()
+
+	// Start the metrics server
+	if metricsServer != nil {
+		go func() {
+			logger.InfoContext(ctx, "serving metrics", slog.String("address", metricsListener.Addr().String()))
+			if err := metricsServer.Serve(metricsListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.ErrorContext(ctx, "metrics server stopped", slog.String("error", err.Error()))
+			}
+		}()
+	}
+
 	// Start the http server
 	//
 	// once httpServer.Shutdown is called, it will always return a
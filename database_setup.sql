@@ -1,6 +1,16 @@
 DROP TABLE IF EXISTS "users";
 DROP TABLE IF EXISTS blogs;
 DROP TABLE IF EXISTS "comments";
+DROP TABLE IF EXISTS "ratings";
+DROP TABLE IF EXISTS "reactions";
+DROP TABLE IF EXISTS "follows";
+DROP TABLE IF EXISTS "moderation_queue";
+DROP TABLE IF EXISTS "bookmarks";
+DROP TABLE IF EXISTS "reading_lists";
+DROP TABLE IF EXISTS "reading_list_items";
+DROP TABLE IF EXISTS "blog_tags";
+DROP TABLE IF EXISTS "rate_limit_buckets";
+DROP TABLE IF EXISTS "schema_migrations";
 
 -- Create user table
 CREATE TABLE "users" (
@@ -15,10 +25,24 @@ CREATE TABLE "blogs" (
     id BIGSERIAL PRIMARY KEY,
     author_id INTEGER NOT NULL,
     title TEXT NOT NULL,
-    score REAL NOT NULL,
-    created_date TIMESTAMP NOT NULL
+    rating_sum INTEGER NOT NULL DEFAULT 0,
+    rating_count INTEGER NOT NULL DEFAULT 0,
+    created_date TIMESTAMP NOT NULL,
+    updated_date TIMESTAMP
+);
+
+-- Feeds read a followed author's blogs newest first
+CREATE INDEX blogs_author_created_idx ON blogs (author_id, created_date DESC, id DESC);
+
+-- Create blog tag table. Tags are lowercase letters, digits and hyphens.
+CREATE TABLE "blog_tags" (
+    blog_id INTEGER NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (blog_id, tag)
 );
 
+CREATE INDEX blog_tags_tag_idx ON blog_tags (tag, blog_id);
+
 -- Create comment table
 CREATE TABLE "comments" (
     user_id BIGSERIAL NOT NULL,
@@ -28,36 +52,166 @@ CREATE TABLE "comments" (
     PRIMARY KEY (user_id, blog_id)
 );
 
--- Insert data into the user table
+-- Create rating table. One rating per user per blog; blogs.rating_sum and
+-- blogs.rating_count are kept in step with it by the API.
+CREATE TABLE "ratings" (
+    user_id INTEGER NOT NULL,
+    blog_id INTEGER NOT NULL,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    created_date TIMESTAMP NOT NULL,
+    updated_date TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, blog_id)
+);
+
+-- Create reaction table. comment_user_id is 0 for reactions on the blog
+-- itself, otherwise it and blog_id identify the comment reacted to.
+CREATE TABLE "reactions" (
+    blog_id INTEGER NOT NULL,
+    comment_user_id INTEGER NOT NULL DEFAULT 0,
+    user_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    created_date TIMESTAMP NOT NULL,
+    PRIMARY KEY (blog_id, comment_user_id, user_id, type)
+);
+
+-- Create follow table. follower_id follows followee_id.
+CREATE TABLE "follows" (
+    follower_id INTEGER NOT NULL,
+    followee_id INTEGER NOT NULL,
+    created_date TIMESTAMP NOT NULL,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX follows_followee_idx ON follows (followee_id, follower_id);
+
+-- Create bookmark table. Blogs a user saved to read later.
+CREATE TABLE "bookmarks" (
+    user_id INTEGER NOT NULL,
+    blog_id INTEGER NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_date TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, blog_id)
+);
+
+-- Create reading list tables. Items are ordered by position, which starts at
+-- 1 and is kept contiguous within a list.
+CREATE TABLE "reading_lists" (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    public BOOLEAN NOT NULL DEFAULT false,
+    created_date TIMESTAMP NOT NULL
+);
+
+CREATE INDEX reading_lists_user_idx ON reading_lists (user_id);
+
+CREATE TABLE "reading_list_items" (
+    list_id BIGINT NOT NULL,
+    blog_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    added_date TIMESTAMP NOT NULL,
+    PRIMARY KEY (list_id, blog_id)
+);
+
+CREATE INDEX reading_list_items_blog_idx ON reading_list_items (blog_id);
+
+-- Create moderation queue table. Holds content flagged or rejected by the
+-- content filters along with the reason and the moderator's decision.
+CREATE TABLE "moderation_queue" (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    verdict TEXT NOT NULL,
+    filter TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL,
+    created_date TIMESTAMP NOT NULL,
+    decided_date TIMESTAMP
+);
+
+CREATE INDEX moderation_queue_status_idx ON moderation_queue (status, created_date);
+
+-- Create rate limit buckets table. Holds the token buckets of the Postgres
+-- rate limit store, keyed by route and client; rows are deleted once their
+-- bucket is full again.
+CREATE TABLE "rate_limit_buckets" (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_date TIMESTAMPTZ NOT NULL,
+    full_date TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX rate_limit_buckets_full_idx ON rate_limit_buckets (full_date);
+
+-- Create schema migrations table. Records the schema version this script
+-- creates; bump it together with database.SchemaVersion when the schema
+-- changes so readiness checks catch a database that hasn't been migrated.
+CREATE TABLE "schema_migrations" (
+    version INTEGER PRIMARY KEY,
+    applied_date TIMESTAMP NOT NULL DEFAULT now()
+);
+
+INSERT INTO "schema_migrations" (version) VALUES (1), (2);
+
+-- Insert data into the user table. Passwords are bcrypt hashes of password1
+-- to password10.
 INSERT INTO "users" (name, email, password) VALUES
-    ('John Doe', 'john@example.com', 'password1'),
-    ('Jane Smith', 'jane@example.com', 'password2'),
-    ('Alice Johnson', 'alice@example.com', 'password3'),
-    ('Bob Brown', 'bob@example.com', 'password4'),
-    ('Emma Davis', 'emma@example.com', 'password5'),
-    ('Michael Wilson', 'michael@example.com', 'password6'),
-    ('Sarah Lee', 'sarah@example.com', 'password7'),
-    ('David Garcia', 'david@example.com', 'password8'),
-    ('Olivia Martinez', 'olivia@example.com', 'password9'),
-    ('William Rodriguez', 'william@example.com', 'password10');
+    ('John Doe', 'john@example.com', '$2a$10$spTsBKHH3kgD5TFN6h8HAO9DDvZcCGDkJJjI1LZTXyoD8mY3ouU2K'),
+    ('Jane Smith', 'jane@example.com', '$2a$10$GJNffd4CGLtlcoNrgVGsmuH2AD7egfQ8IxPj17Ak5eO/WaXtHAHNO'),
+    ('Alice Johnson', 'alice@example.com', '$2a$10$JXqnJW9NudiahreKrAlsH.9bNE7TRow5w/bBr87jAk8XuFsiPdgPS'),
+    ('Bob Brown', 'bob@example.com', '$2a$10$/Oywq2OMf88V9Bu8k6TC7uP3zhrlYeKeQt/JR4iqPwsxAQXTETpvW'),
+    ('Emma Davis', 'emma@example.com', '$2a$10$KvZ1Hp8VGfa3RRN.XPmuNec//Llox4SHrhveg3z/aePZrncBoT37G'),
+    ('Michael Wilson', 'michael@example.com', '$2a$10$kjLxnccUcnB94UtzArRZhOOkM/632x3WcVABMrst5xiMHz04Bc0sy'),
+    ('Sarah Lee', 'sarah@example.com', '$2a$10$UtMtblL0ppZcta8PSeWzuOQrE0YV3PUmSpt.QxxV5aB0Dtjov9AaS'),
+    ('David Garcia', 'david@example.com', '$2a$10$DwRW.c/MFAgOW80emVYw4u5UJUU1tgVsfi5i9vAZo4N5Jyb6j1pTm'),
+    ('Olivia Martinez', 'olivia@example.com', '$2a$10$wkCXlh55NMoEkOWi8kjyGec2aj1xldN3ANk1LXPE73X4MfNRQtWOm'),
+    ('William Rodriguez', 'william@example.com', '$2a$10$Mvmvia.QicfIlTja2GRHUeDxOuOv4930r2J1pKE7roKnDap8j1Kym');
 
 -- Insert data into the blog table
-INSERT INTO blogs (author_id, title, score, created_date) VALUES
-    (1, 'First Blog Post', 8.5, '2024-05-14 09:00:00'),
-    (2, 'Travel Adventures', 7.2, '2024-05-13 14:30:00'),
-    (3, 'Cooking Tips', 9.3, '2024-05-12 11:45:00'),
-    (4, 'Tech Reviews', 6.7, '2024-05-11 16:20:00'),
-    (5, 'Fitness Journey', 8.9, '2024-05-10 08:15:00'),
-    (6, 'Book Recommendations', 7.8, '2024-05-09 10:45:00'),
-    (7, 'Photography Tips', 9.1, '2024-05-08 13:20:00'),
-    (8, 'Financial Advice', 6.4, '2024-05-07 17:30:00'),
-    (9, 'DIY Projects', 8.0, '2024-05-06 09:45:00'),
-    (10, 'Movie Reviews', 7.5, '2024-05-05 14:00:00'),
-    (1, 'Second Blog Post', 8.2, '2024-05-04 11:10:00'),
-    (2, 'Healthy Recipes', 9.0, '2024-05-03 15:25:00'),
-    (3, 'Productivity Hacks', 8.7, '2024-05-02 10:50:00'),
-    (4, 'Gaming News', 7.3, '2024-05-01 12:15:00'),
-    (5, 'Home Decor Ideas', 9.5, '2024-04-30 09:30:00');
+INSERT INTO blogs (author_id, title, created_date) VALUES
+    (1, 'First Blog Post', '2024-05-14 09:00:00'),
+    (2, 'Travel Adventures', '2024-05-13 14:30:00'),
+    (3, 'Cooking Tips', '2024-05-12 11:45:00'),
+    (4, 'Tech Reviews', '2024-05-11 16:20:00'),
+    (5, 'Fitness Journey', '2024-05-10 08:15:00'),
+    (6, 'Book Recommendations', '2024-05-09 10:45:00'),
+    (7, 'Photography Tips', '2024-05-08 13:20:00'),
+    (8, 'Financial Advice', '2024-05-07 17:30:00'),
+    (9, 'DIY Projects', '2024-05-06 09:45:00'),
+    (10, 'Movie Reviews', '2024-05-05 14:00:00'),
+    (1, 'Second Blog Post', '2024-05-04 11:10:00'),
+    (2, 'Healthy Recipes', '2024-05-03 15:25:00'),
+    (3, 'Productivity Hacks', '2024-05-02 10:50:00'),
+    (4, 'Gaming News', '2024-05-01 12:15:00'),
+    (5, 'Home Decor Ideas', '2024-04-30 09:30:00');
+
+-- Insert data into the blog tag table
+INSERT INTO "blog_tags" (blog_id, tag) VALUES
+    (1, 'meta'),
+    (2, 'travel'),
+    (3, 'food'),
+    (4, 'tech'),
+    (4, 'reviews'),
+    (5, 'health'),
+    (6, 'books'),
+    (6, 'reviews'),
+    (7, 'photography'),
+    (8, 'finance'),
+    (9, 'diy'),
+    (9, 'home'),
+    (10, 'movies'),
+    (10, 'reviews'),
+    (11, 'meta'),
+    (12, 'food'),
+    (12, 'health'),
+    (13, 'productivity'),
+    (14, 'gaming'),
+    (14, 'tech'),
+    (15, 'home');
 
 -- Insert data into the comment table
 INSERT INTO "comments" (user_id, blog_id, message, created_date) VALUES
@@ -111,4 +265,37 @@ INSERT INTO "comments" (user_id, blog_id, message, created_date) VALUES
     (2, 12, 'Can''t wait to try this nutritious dish!', '2024-05-15 12:15:00'),
     (10, 10, '10/10 would watch again.', '2024-05-15 14:15:00'),
     (9, 14, 'Ready to level up!', '2024-05-15 14:00:00'),
-    (6, 5, 'No pain, no gain!', '2024-05-15 13:15:00');
\ No newline at end of file
+    (6, 5, 'No pain, no gain!', '2024-05-15 13:15:00');
+
+-- Insert data into the rating table
+INSERT INTO "ratings" (user_id, blog_id, rating, created_date, updated_date) VALUES
+    (2, 1, 5, '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (3, 1, 4, '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (1, 2, 4, '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (1, 3, 5, '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (2, 3, 5, '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (4, 3, 4, '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (1, 4, 3, '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (6, 5, 5, '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (3, 8, 2, '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (5, 10, 4, '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (7, 15, 5, '2024-05-15 12:00:00', '2024-05-15 12:00:00');
+
+-- Insert data into the follow table
+INSERT INTO "follows" (follower_id, followee_id, created_date) VALUES
+    (1, 2, '2024-05-15 12:00:00'),
+    (1, 3, '2024-05-15 12:00:00'),
+    (2, 1, '2024-05-15 12:15:00'),
+    (2, 5, '2024-05-15 12:15:00'),
+    (4, 1, '2024-05-15 12:45:00'),
+    (5, 3, '2024-05-15 13:00:00');
+
+-- Bring the blog rating totals in line with the seeded ratings
+UPDATE blogs
+SET rating_sum = totals.rating_sum, rating_count = totals.rating_count
+FROM (
+    SELECT blog_id, SUM(rating) AS rating_sum, COUNT(*) AS rating_count
+    FROM ratings
+    GROUP BY blog_id
+) AS totals
+WHERE blogs.id = totals.blog_id;
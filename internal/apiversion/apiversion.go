@@ -0,0 +1,169 @@
+// Package apiversion lists the versions of the REST API, maps routes between
+// them and describes the ones that are deprecated.
+//
+// Each version is served under /api/<version>, such as /api/v2/user/{id}.
+// /api itself is an alias of Default, so clients that predate versioning
+// keep working.
+package apiversion
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Versions are the versions of the REST API, oldest first. A version serves
+// the endpoints of the one before it unless it overrides them.
+var Versions = []string{"v1", "v2"}
+
+// Default is the version served under /api without a version.
+const Default = "v1"
+
+// Latest returns the newest version.
+func Latest() string { return Versions[len(Versions)-1] }
+
+// Deprecation describes a deprecated version of the API or endpoint.
+type Deprecation struct {
+	// Since is when it was deprecated.
+	Since time.Time
+	// Sunset is when it will stop being served, or zero if that isn't
+	// planned yet.
+	Sunset time.Time
+	// Successor is the version clients should move to, or "" for none.
+	Successor string
+}
+
+// Endpoints lists the endpoints deprecated on their own, by their pattern in
+// the version they are deprecated in.
+var Endpoints = map[string]Deprecation{
+	"POST /api/v1/user":     passwordField,
+	"GET /api/v1/user":      passwordField,
+	"GET /api/v1/user/{id}": passwordField,
+	"PUT /api/v1/user/{id}": passwordField,
+}
+
+// passwordField deprecates the user endpoints of v1, whose responses carry
+// an always empty password field, in favour of those of v2, which drop it.
+var passwordField = Deprecation{
+	Since:     time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+	Successor: "v2",
+}
+
+// ParseDeprecations parses the deprecations of whole versions, mapping each
+// version to when it was deprecated and, after a slash, when it will stop
+// being served: 2026-10-01 or 2026-10-01/2027-04-01. Deprecated versions
+// point clients at the latest one.
+func ParseDeprecations(specs map[string]string) (map[string]Deprecation, error) {
+	deprecations := make(map[string]Deprecation, len(specs))
+	for version, spec := range specs {
+		if !slices.Contains(Versions, version) {
+			return nil, fmt.Errorf("deprecating unknown API version %q", version)
+		}
+		if version == Latest() {
+			return nil, fmt.Errorf("deprecating API version %s, the latest", version)
+		}
+
+		since, sunset, hasSunset := strings.Cut(spec, "/")
+		d := Deprecation{Successor: Latest()}
+		var err error
+		if d.Since, err = time.Parse(time.DateOnly, strings.TrimSpace(since)); err != nil {
+			return nil, fmt.Errorf("API version %s: invalid deprecation date %q: %w", version, since, err)
+		}
+		if hasSunset {
+			if d.Sunset, err = time.Parse(time.DateOnly, strings.TrimSpace(sunset)); err != nil {
+				return nil, fmt.Errorf("API version %s: invalid sunset date %q: %w", version, sunset, err)
+			}
+			if d.Sunset.Before(d.Since) {
+				return nil, fmt.Errorf("API version %s: sunset %s is before its deprecation", version, sunset)
+			}
+		}
+		deprecations[version] = d
+	}
+	return deprecations, nil
+}
+
+// Prefix returns the path prefix version is served under.
+func Prefix(version string) string {
+	return "/api/" + version
+}
+
+// Split returns the version of the API a pattern or path is in, along with
+// the pattern or path without it: "v2" and "GET /api/user/{id}" for
+// "GET /api/v2/user/{id}". Those under /api without a version are in
+// Default. Those outside /api are returned unchanged, with no version.
+func Split(pattern string) (version, unversioned string) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, path = "", pattern
+	}
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return "", pattern
+	}
+
+	version = Default
+	if v, after, _ := strings.Cut(rest, "/"); slices.Contains(Versions, v) {
+		version, path = v, "/api/"+after
+	}
+	if method != "" {
+		path = method + " " + path
+	}
+	return version, path
+}
+
+// Patterns returns the patterns route, a pattern under /api without a
+// version, is served at in version: under the version's prefix and, for
+// Default, under /api too.
+func Patterns(route, version string) []string {
+	method, path, ok := strings.Cut(route, " ")
+	if !ok {
+		method, path = "", route
+	}
+	rest, ok := strings.CutPrefix(path, "/api")
+	if !ok {
+		panic(fmt.Sprintf("apiversion: %s isn't under /api", route))
+	}
+
+	prefix := ""
+	if method != "" {
+		prefix = method + " "
+	}
+	patterns := []string{prefix + Prefix(version) + rest}
+	if version == Default {
+		patterns = append(patterns, route)
+	}
+	return patterns
+}
+
+// Rewrite returns the path the API path path has in version.
+func Rewrite(path, version string) string {
+	_, unversioned := Split(path)
+	rest, ok := strings.CutPrefix(unversioned, "/api")
+	if !ok {
+		return path
+	}
+	return Prefix(version) + rest
+}
+
+// Router represents a type that can report which pattern a request matches,
+// such as *http.ServeMux.
+type Router interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// Unversioned returns a Router reporting the patterns requests match on
+// router without their version, so every version of a route shares the
+// limits set for it.
+func Unversioned(router Router) Router {
+	return unversioned{router}
+}
+
+type unversioned struct{ router Router }
+
+func (u unversioned) Handler(r *http.Request) (http.Handler, string) {
+	h, pattern := u.router.Handler(r)
+	_, pattern = Split(pattern)
+	return h, pattern
+}
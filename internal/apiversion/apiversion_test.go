@@ -0,0 +1,82 @@
+package apiversion
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplit(t *testing.T) {
+	tests := map[string]struct {
+		version     string
+		unversioned string
+	}{
+		"GET /api/v2/user/{id}": {"v2", "GET /api/user/{id}"},
+		"GET /api/v1/user":      {"v1", "GET /api/user"},
+		"GET /api/user/{id}":    {"v1", "GET /api/user/{id}"},
+		"/api/v2/health":        {"v2", "/api/health"},
+		"/api/v3/health":        {"v1", "/api/v3/health"},
+		"GET /blog/{id}":        {"", "GET /blog/{id}"},
+		"":                      {"", ""},
+	}
+
+	for pattern, want := range tests {
+		version, unversioned := Split(pattern)
+		if version != want.version || unversioned != want.unversioned {
+			t.Errorf("Split(%q): want %q, %q, got %q, %q", pattern, want.version, want.unversioned, version, unversioned)
+		}
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	if got, want := Patterns("GET /api/user/{id}", "v1"), []string{"GET /api/v1/user/{id}", "GET /api/user/{id}"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := Patterns("/api/health", "v2"), []string{"/api/v2/health"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := Rewrite("/api/user/5", "v2"), "/api/v2/user/5"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestParseDeprecations(t *testing.T) {
+	tests := map[string]struct {
+		specs   map[string]string
+		want    map[string]Deprecation
+		wantErr bool
+	}{
+		"deprecated": {
+			specs: map[string]string{"v1": "2026-10-01"},
+			want: map[string]Deprecation{"v1": {
+				Since:     time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+				Successor: "v2",
+			}},
+		},
+		"with sunset": {
+			specs: map[string]string{"v1": "2026-10-01/2027-04-01"},
+			want: map[string]Deprecation{"v1": {
+				Since:     time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+				Sunset:    time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC),
+				Successor: "v2",
+			}},
+		},
+		"none":                     {specs: nil, want: map[string]Deprecation{}},
+		"unknown version":          {specs: map[string]string{"v9": "2026-10-01"}, wantErr: true},
+		"latest version":           {specs: map[string]string{"v2": "2026-10-01"}, wantErr: true},
+		"invalid date":             {specs: map[string]string{"v1": "October"}, wantErr: true},
+		"sunset before deprecated": {specs: map[string]string{"v1": "2026-10-01/2026-01-01"}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseDeprecations(tc.specs)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error %t, got %v", tc.wantErr, err)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokens(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	tokens := NewTokens([]byte("secret"), time.Hour)
+	tokens.now = func() time.Time { return now }
+
+	valid, expires := tokens.Issue(7)
+	_, sig, _ := strings.Cut(valid, ".")
+	tampered := encode([]byte("8:"+strconv.FormatInt(expires.Unix(), 10))) + "." + sig
+
+	testcases := map[string]struct {
+		token       string
+		advance     time.Duration
+		tokens      *Tokens
+		expectedID  int
+		expectedErr error
+	}{
+		"valid token": {
+			token:      valid,
+			tokens:     tokens,
+			expectedID: 7,
+		},
+		"expired token": {
+			token:       valid,
+			advance:     2 * time.Hour,
+			tokens:      tokens,
+			expectedErr: ErrInvalidToken,
+		},
+		"tampered payload": {
+			token:       tampered,
+			tokens:      tokens,
+			expectedErr: ErrInvalidToken,
+		},
+		"signed with another secret": {
+			token:       valid,
+			tokens:      NewTokens([]byte("other"), time.Hour),
+			expectedErr: ErrInvalidToken,
+		},
+		"garbage": {
+			token:       "not-a-token",
+			tokens:      tokens,
+			expectedErr: ErrInvalidToken,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			tc.tokens.now = func() time.Time { return now.Add(tc.advance) }
+			id, err := tc.tokens.Verify(tc.token)
+			if err != tc.expectedErr {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if id != tc.expectedID {
+				t.Errorf("expected user id %d, got %d", tc.expectedID, id)
+			}
+		})
+	}
+}
+
+func TestUserID(t *testing.T) {
+	if _, ok := UserID(context.Background()); ok {
+		t.Error("expected anonymous context to have no user id")
+	}
+	if id, ok := UserID(WithUserID(context.Background(), 3)); !ok || id != 3 {
+		t.Errorf("expected user id 3, got %d (%v)", id, ok)
+	}
+}
+
+func TestActAs(t *testing.T) {
+	admins := []int{1}
+	testcases := map[string]struct {
+		ctx         context.Context
+		expectedErr error
+	}{
+		"the user":     {ctx: WithUserID(context.Background(), 5)},
+		"an admin":     {ctx: WithUserID(context.Background(), 1)},
+		"anonymous":    {ctx: context.Background(), expectedErr: ErrUnauthenticated},
+		"another user": {ctx: WithUserID(context.Background(), 6), expectedErr: ErrForbidden},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if err := ActAs(tc.ctx, 5, admins); err != tc.expectedErr {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
@@ -19,6 +22,105 @@ type Config struct {
 	Host           string     `env:"HOST,required"`
 	Port           string     `env:"PORT,required"`
 	LogLevel       slog.Level `env:"LOG_LEVEL,required"`
+
+	// Content filters run before comments and blogs are created.
+	BannedWords   []string      `env:"BANNED_WORDS" envSeparator:","`
+	MaxLinks      int           `env:"MAX_LINKS" envDefault:"3"`
+	RepeatWindow  time.Duration `env:"REPEAT_MESSAGE_WINDOW" envDefault:"10m"`
+	SpamThreshold float64       `env:"SPAM_THRESHOLD" envDefault:"0.9"`
+
+	// ReactionTypes is the fixed set of emoji users can react with on blogs
+	// and comments.
+	ReactionTypes []string `env:"REACTION_TYPES" envSeparator:"," envDefault:"👍,❤️,😂,😮,😢,🎉"`
+
+	// AuthSecret signs bearer tokens. If it is empty a random secret is
+	// generated at startup, so tokens don't survive a restart.
+	AuthSecret string        `env:"AUTH_SECRET"`
+	TokenTTL   time.Duration `env:"TOKEN_TTL" envDefault:"24h"`
+
+	// SitemapTTL is how long the generated sitemap is cached for.
+	SitemapTTL time.Duration `env:"SITEMAP_TTL" envDefault:"1h"`
+
+	// Tracing selects where OpenTelemetry spans are exported: none, stdout
+	// or otlp. OTLPEndpoint is the OTLP/HTTP traces URL.
+	TracingExporter    string  `env:"TRACING_EXPORTER" envDefault:"none"`
+	OTLPEndpoint       string  `env:"OTLP_ENDPOINT"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+	ServiceName        string  `env:"SERVICE_NAME" envDefault:"blog-api"`
+
+	// RateLimitDefault is the limit applied to each client on every route
+	// without a limit in RateLimits, which maps route patterns to their own
+	// limit. Limits are written as requests/period, e.g. 60/m, or off.
+	// RateLimitStore keeps the buckets: memory or postgres, the latter
+	// sharing limits between instances. X-Forwarded-For is only believed
+	// from TrustedProxies, a list of addresses or CIDR prefixes.
+	RateLimitDefault string            `env:"RATE_LIMIT_DEFAULT" envDefault:"300/m"`
+	RateLimits       map[string]string `env:"RATE_LIMITS" envSeparator:"," envKeyValSeparator:"=" envDefault:"POST /api/auth/token=10/m,POST /api/user=10/h,POST /api/comments=10/m"`
+	RateLimitStore   string            `env:"RATE_LIMIT_STORE" envDefault:"memory"`
+	TrustedProxies   []string          `env:"TRUSTED_PROXIES" envSeparator:","`
+
+	// CORS settings for browser clients on other origins. CORSOrigins lists
+	// the allowed origins, or * for any; none are allowed by default.
+	// Credentials can only be allowed for listed origins, not with *.
+	CORSOrigins          []string      `env:"CORS_ORIGINS" envSeparator:","`
+	CORSMethods          []string      `env:"CORS_METHODS" envSeparator:"," envDefault:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+	CORSHeaders          []string      `env:"CORS_HEADERS" envSeparator:"," envDefault:"Authorization,Content-Type,X-Request-ID"`
+	CORSAllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS" envDefault:"false"`
+	CORSMaxAge           time.Duration `env:"CORS_MAX_AGE" envDefault:"10m"`
+
+	// ContentSecurityPolicy is sent with every response. HSTSMaxAge enables
+	// Strict-Transport-Security; leave it at 0 unless served over HTTPS.
+	ContentSecurityPolicy string        `env:"CONTENT_SECURITY_POLICY" envDefault:"default-src 'self'; img-src 'self' data:; frame-ancestors 'none'; base-uri 'self'; form-action 'self'"`
+	HSTSMaxAge            time.Duration `env:"HSTS_MAX_AGE" envDefault:"0s"`
+
+	// MaxBodySize is the largest request body accepted, in bytes, except by
+	// bulk imports, which accept up to ImportMaxBodySize.
+	MaxBodySize       int64 `env:"MAX_BODY_SIZE" envDefault:"1048576"`
+	ImportMaxBodySize int64 `env:"IMPORT_MAX_BODY_SIZE" envDefault:"67108864"`
+
+	// AdminUserIDs are the users allowed to use the admin endpoints.
+	AdminUserIDs []int `env:"ADMIN_USER_IDS" envSeparator:","`
+
+	// CompressionMinSize is the smallest response body, in bytes, worth
+	// compressing.
+	CompressionMinSize int `env:"COMPRESSION_MIN_SIZE" envDefault:"1024"`
+
+	// GRPCPort is the port the gRPC API listens on, next to the HTTP API.
+	GRPCPort string `env:"GRPC_PORT" envDefault:"9090"`
+
+	// MetricsAddr is the address Prometheus metrics are served on, at
+	// /metrics, apart from the API so they aren't public. It is loopback
+	// only by default; set it to :9100 to let a scraper on another host in,
+	// or leave it empty to not serve metrics.
+	MetricsAddr string `env:"METRICS_ADDR" envDefault:"127.0.0.1:9100"`
+
+	// GraphQLMaxDepth and GraphQLMaxComplexity bound the queries /graphql
+	// runs: how deeply selections nest, and roughly how many fields are
+	// resolved, counting each field under a list once per item of a page.
+	GraphQLMaxDepth      int `env:"GRAPHQL_MAX_DEPTH" envDefault:"8"`
+	GraphQLMaxComplexity int `env:"GRAPHQL_MAX_COMPLEXITY" envDefault:"5000"`
+
+	// DevMode serves development tools, such as the GraphiQL editor at
+	// /graphiql. Leave it off in production.
+	DevMode bool `env:"DEV_MODE" envDefault:"false"`
+
+	// APIDeprecations marks versions of the REST API deprecated, mapping each
+	// to the date it was deprecated and, after a slash, the date it stops
+	// being served: v1=2026-10-01/2027-04-01. Their responses carry
+	// Deprecation and Sunset headers, and each call is logged.
+	APIDeprecations map[string]string `env:"API_DEPRECATIONS" envSeparator:"," envKeyValSeparator:"="`
+
+	// OpenAPIValidate checks requests against the OpenAPI document served at
+	// /openapi.json, answering those that don't match it with 400. In dev
+	// mode responses are checked too, and those that don't match logged.
+	OpenAPIValidate bool `env:"OPENAPI_VALIDATE" envDefault:"false"`
+
+	// HealthCheckTimeout bounds each readiness check. ShutdownDrainDelay is
+	// how long the server keeps serving, while reporting itself unavailable,
+	// before it stops accepting connections on shutdown. It should be longer
+	// than the interval load balancers poll readiness at.
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	ShutdownDrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" envDefault:"5s"`
 }
 
 // New loads configuration from environment variables and a .env file, and returns a
@@ -37,5 +139,11 @@ func New() (Config, error) {
 		return Config{}, fmt.Errorf("[in config.New] failed to parse config: %w", err)
 	}
 
+	// Allowing credentials from any origin would let every site make
+	// requests as the user
+	if cfg.CORSAllowCredentials && slices.Contains(cfg.CORSOrigins, "*") {
+		return Config{}, errors.New("[in config.New] CORS_ALLOW_CREDENTIALS can't be used with CORS_ORIGINS=*: list the origins to allow credentials from")
+	}
+
 	return cfg, nil
 }
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/navid/blog/internal/ratelimit"
+)
+
+// setRequired sets the variables New requires.
+func setRequired(t *testing.T) {
+	t.Helper()
+	for name, value := range map[string]string{
+		"DATABASE_HOST":     "localhost",
+		"DATABASE_USER":     "blog",
+		"DATABASE_PASSWORD": "secret",
+		"DATABASE_NAME":     "blog",
+		"DATABASE_PORT":     "5432",
+		"HOST":              "localhost",
+		"PORT":              "8000",
+		"LOG_LEVEL":         "INFO",
+	} {
+		t.Setenv(name, value)
+	}
+}
+
+func TestNewCORS(t *testing.T) {
+	tests := map[string]struct {
+		origins     string
+		credentials string
+		wantErr     bool
+	}{
+		"any origin": {
+			origins: "*",
+		},
+		"credentials for listed origins": {
+			origins:     "https://app.example.com",
+			credentials: "true",
+		},
+		"credentials for any origin": {
+			origins:     "https://app.example.com,*",
+			credentials: "true",
+			wantErr:     true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			setRequired(t)
+			t.Setenv("CORS_ORIGINS", tc.origins)
+			t.Setenv("CORS_ALLOW_CREDENTIALS", tc.credentials)
+
+			_, err := New()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewRateLimits(t *testing.T) {
+	setRequired(t)
+
+	cfg, err := New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for route, want := range map[string]string{
+		"POST /api/auth/token": "10/m",
+		"POST /api/user":       "10/h",
+		"POST /api/comments":   "10/m",
+	} {
+		if got := cfg.RateLimits[route]; got != want {
+			t.Errorf("want %s limited to %s by default, got %q", route, want, got)
+		}
+	}
+	if _, err := ratelimit.ParseLimits(cfg.RateLimitDefault, cfg.RateLimits); err != nil {
+		t.Errorf("want the default limits to parse, got %v", err)
+	}
+}
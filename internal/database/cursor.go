@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+)
+
+// cursors numbers the cursors Fetch declares, so streams in one transaction
+// don't share a name.
+var cursors atomic.Uint64
+
+// Fetch runs query through a cursor, in a transaction begun on db, reading
+// its rows size at a time with scan. fn is called with each row once the
+// page holding it has been read, so it can make queries of its own even when
+// ctx carries a transaction, while memory use stays at a page whatever the
+// number of rows. Fetch stops at the first error fn returns and returns it.
+func Fetch[T any](ctx context.Context, db *DB, size int, query string, args []any, scan func(*sql.Rows) (T, error), fn func(T) error) error {
+	cursor := fmt.Sprintf("fetch_%d", cursors.Add(1))
+
+	return db.InTx(ctx, func(ctx context.Context) error {
+		if _, err := db.ExecContext(ctx, `DECLARE `+cursor+` NO SCROLL CURSOR FOR `+query, args...); err != nil {
+			return fmt.Errorf("failed to declare cursor: %w", err)
+		}
+
+		page := make([]T, 0, size)
+		for {
+			page = page[:0]
+			rows, err := db.QueryContext(ctx, fmt.Sprintf(`FETCH %d FROM %s`, size, cursor))
+			if err != nil {
+				return fmt.Errorf("failed to fetch rows: %w", err)
+			}
+			for rows.Next() {
+				v, err := scan(rows)
+				if err != nil {
+					rows.Close()
+					return err
+				}
+				page = append(page, v)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("rows iteration error: %w", err)
+			}
+
+			for _, v := range page {
+				if err := fn(v); err != nil {
+					return err
+				}
+			}
+			if len(page) < size {
+				break
+			}
+		}
+
+		if _, err := db.ExecContext(ctx, `CLOSE `+cursor); err != nil {
+			return fmt.Errorf("failed to close cursor: %w", err)
+		}
+		return nil
+	})
+}
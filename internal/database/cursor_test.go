@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func scanID(rows *sql.Rows) (int, error) {
+	var id int
+	err := rows.Scan(&id)
+	return id, err
+}
+
+func TestFetch(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer sqlDB.Close()
+	db := Wrap(sqlDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DECLARE fetch_\d+ NO SCROLL CURSOR FOR SELECT id FROM blogs WHERE author_id = \$1`).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FETCH 2 FROM fetch_\d+`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectQuery(`FETCH 2 FROM fetch_\d+`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectExec(`CLOSE fetch_\d+`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	var got []int
+	err = Fetch(context.Background(), db, 2, `SELECT id FROM blogs WHERE author_id = $1`, []any{7}, scanID, func(id int) error {
+		got = append(got, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("want ids 1, 2 and 3, got %v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	// An error from fn stops the fetch and rolls it back
+	mock.ExpectBegin()
+	mock.ExpectExec(`DECLARE fetch_\d+`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FETCH 2 FROM fetch_\d+`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectRollback()
+
+	stop := errors.New("client went away")
+	calls := 0
+	err = Fetch(context.Background(), db, 2, `SELECT id FROM blogs`, nil, scanID, func(id int) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("want %v, got %v", stop, err)
+	}
+	if calls != 1 {
+		t.Errorf("want fn called once, got %d", calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
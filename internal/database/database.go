@@ -1 +1,44 @@
 package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SchemaVersion is the version of the schema created by database_setup.sql
+// that this build expects. Bump it together with the version inserted into
+// schema_migrations when the schema changes.
+const SchemaVersion = 2
+
+// Ping returns a readiness check that pings db.
+func Ping(db *sql.DB) func(ctx context.Context) (any, error) {
+	return func(ctx context.Context) (any, error) {
+		if err := db.PingContext(ctx); err != nil {
+			return nil, fmt.Errorf("failed to ping database: %w", err)
+		}
+		stats := db.Stats()
+		return map[string]any{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+		}, nil
+	}
+}
+
+// Migrations returns a readiness check that reports the schema version db has
+// been migrated to, failing if it is behind SchemaVersion.
+func Migrations(db *sql.DB) func(ctx context.Context) (any, error) {
+	return func(ctx context.Context) (any, error) {
+		var version int
+		err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema version: %w", err)
+		}
+
+		detail := map[string]any{"version": version, "expected": SchemaVersion}
+		if version < SchemaVersion {
+			return detail, fmt.Errorf("database schema version %d is behind expected version %d", version, SchemaVersion)
+		}
+		return detail, nil
+	}
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Queryer runs queries: a *sql.DB, *sql.Tx, *DB or *Tx.
+type Queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txKey is the context key of the transaction set by WithTx.
+type txKey struct{}
+
+// ambientTx is a transaction queries made with a context run in, and the
+// number of savepoints begun in it so far, to name the next.
+type ambientTx struct {
+	tx         *sql.Tx
+	savepoints int
+}
+
+// WithTx returns a copy of ctx in which queries made through a DB, and
+// transactions begun on it, run inside tx. The caller commits or rolls back
+// tx, and must not use it from more than one goroutine at a time.
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, &ambientTx{tx: tx})
+}
+
+// DB is a database handle that takes part in the transaction of the context
+// it is used with, if WithTx set one, so operations that each query or begin
+// transactions on their own can be made to share one transaction.
+type DB struct {
+	*sql.DB
+}
+
+// Wrap returns a DB querying db.
+func Wrap(db *sql.DB) *DB {
+	return &DB{DB: db}
+}
+
+// queryer returns the transaction in ctx, or the database.
+func (db *DB) queryer(ctx context.Context) Queryer {
+	if ambient, ok := ctx.Value(txKey{}).(*ambientTx); ok {
+		return ambient.tx
+	}
+	return db.DB
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.queryer(ctx).ExecContext(ctx, query, args...)
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.queryer(ctx).QueryContext(ctx, query, args...)
+}
+
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return db.queryer(ctx).QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx begins a transaction, or a savepoint inside the transaction in ctx
+// if there is one, so it can still be rolled back on its own. opts only
+// apply to real transactions.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	ambient, ok := ctx.Value(txKey{}).(*ambientTx)
+	if !ok {
+		tx, err := db.DB.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &Tx{tx: tx}, nil
+	}
+
+	ambient.savepoints++
+	savepoint := fmt.Sprintf("sp_%d", ambient.savepoints)
+	if _, err := ambient.tx.ExecContext(ctx, `SAVEPOINT `+savepoint); err != nil {
+		return nil, err
+	}
+	return &Tx{tx: ambient.tx, ctx: ctx, savepoint: savepoint}, nil
+}
+
+// InTx runs fn in a transaction begun with BeginTx, committing it if fn
+// returns nil and rolling it back otherwise. The context fn is called with
+// carries the transaction, so queries made with it through any DB, such as
+// those of other services, take part in it.
+func (db *DB) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txCtx := ctx
+	if tx.savepoint == "" {
+		txCtx = WithTx(ctx, tx.tx)
+	}
+	if err := fn(txCtx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Tx is a transaction begun by DB.BeginTx: a real transaction, or a
+// savepoint inside the transaction of a context. Like *sql.Tx, it can be
+// rolled back after it has been committed, which does nothing.
+type Tx struct {
+	tx *sql.Tx
+
+	// savepoint names the savepoint of a Tx inside a transaction, begun
+	// with ctx, or is empty for a real transaction
+	ctx       context.Context
+	savepoint string
+	done      bool
+}
+
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, query, args...)
+}
+
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, query, args...)
+}
+
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return t.tx.QueryRowContext(ctx, query, args...)
+}
+
+// Commit commits the transaction, or releases the savepoint, keeping its
+// changes in the enclosing transaction.
+func (t *Tx) Commit() error {
+	if t.savepoint == "" {
+		return t.tx.Commit()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.tx.ExecContext(t.ctx, `RELEASE SAVEPOINT `+t.savepoint)
+	return err
+}
+
+// Rollback rolls back the transaction, or the changes made since the
+// savepoint.
+func (t *Tx) Rollback() error {
+	if t.savepoint == "" {
+		return t.tx.Rollback()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.tx.ExecContext(t.ctx, `ROLLBACK TO SAVEPOINT `+t.savepoint+`; RELEASE SAVEPOINT `+t.savepoint)
+	return err
+}
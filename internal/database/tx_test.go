@@ -0,0 +1,132 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestDB_BeginTx(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer sqlDB.Close()
+	db := Wrap(sqlDB)
+
+	// Without a transaction in the context, BeginTx begins a real one
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE blogs`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tx.ExecContext(context.Background(), `UPDATE blogs SET title = 'a'`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// With one, queries join it and BeginTx begins savepoints
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO users`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO blogs`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`RELEASE SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SAVEPOINT sp_2`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT sp_2; RELEASE SAVEPOINT sp_2`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	outer, err := sqlDB.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := WithTx(context.Background(), outer)
+
+	if _, err := db.ExecContext(ctx, `INSERT INTO users (name) VALUES ('navid')`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	committed, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := committed.ExecContext(ctx, `INSERT INTO blogs (title) VALUES ('First')`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := committed.Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := committed.Rollback(); err == nil {
+		t.Error("want an error rolling back a released savepoint")
+	}
+
+	rolledBack, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := rolledBack.Rollback(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := outer.Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDB_InTx(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer sqlDB.Close()
+	db := Wrap(sqlDB)
+	other := Wrap(sqlDB)
+
+	// Queries made through any DB with the context fn gets join the
+	// transaction, which is committed when fn succeeds
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE moderation_queue`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO comments`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = db.InTx(context.Background(), func(ctx context.Context) error {
+		if _, err := db.ExecContext(ctx, `UPDATE moderation_queue SET status = 'approved'`); err != nil {
+			return err
+		}
+		_, err := other.ExecContext(ctx, `INSERT INTO comments (message) VALUES ('Hello')`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// and rolled back when it fails
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE moderation_queue`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO comments`).WillReturnError(errors.New("insert failed"))
+	mock.ExpectRollback()
+
+	err = db.InTx(context.Background(), func(ctx context.Context) error {
+		if _, err := db.ExecContext(ctx, `UPDATE moderation_queue SET status = 'approved'`); err != nil {
+			return err
+		}
+		_, err := other.ExecContext(ctx, `INSERT INTO comments (message) VALUES ('Hello')`)
+		return err
+	})
+	if err == nil {
+		t.Error("want the error of fn")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,65 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// BannedWords rejects content containing any word or phrase from a fixed
+// list. Matching is case-insensitive and done on whole words, so "class" is
+// not caught by a ban on "ass", and phrases match however the words in
+// between are spaced or punctuated, so a ban on "buy now" catches "Buy...
+// NOW!".
+type BannedWords struct {
+	// phrases holds each banned word or phrase as its words separated by
+	// single spaces, in the order they were listed
+	phrases []string
+}
+
+// NewBannedWords creates a BannedWords filter from the provided list. Empty
+// entries are ignored.
+func NewBannedWords(words []string) *BannedWords {
+	f := &BannedWords{}
+	seen := make(map[string]bool, len(words))
+	for _, w := range words {
+		phrase := wordsOf(w)
+		if phrase != "" && !seen[phrase] {
+			seen[phrase] = true
+			f.phrases = append(f.phrases, phrase)
+		}
+	}
+	return f
+}
+
+// Name implements Filter.
+func (f *BannedWords) Name() string { return "banned_words" }
+
+// Check implements Filter.
+func (f *BannedWords) Check(ctx context.Context, content Content) (Decision, error) {
+	// Padding the text with spaces lets every phrase be matched as " phrase "
+	text := " " + wordsOf(content.Text) + " "
+	for _, phrase := range f.phrases {
+		if strings.Contains(text, " "+phrase+" ") {
+			return Decision{
+				Verdict: Reject,
+				Reason:  fmt.Sprintf("contains banned word %q", phrase),
+			}, nil
+		}
+	}
+	return Decision{Verdict: Allow}, nil
+}
+
+// wordsOf returns the words of text, lowercased and separated by single
+// spaces.
+func wordsOf(text string) string {
+	return strings.Join(tokenize(text), " ")
+}
+
+// tokenize splits text into lowercase words made of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
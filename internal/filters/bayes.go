@@ -0,0 +1,111 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// minTrainingSamples is the number of examples needed in each class before the
+// scorer starts making decisions. Below that the probabilities are noise.
+const minTrainingSamples = 5
+
+// Bayes is a naive Bayes spam scorer trained from moderator decisions. Content
+// scoring at or above the threshold is flagged for moderation; the scorer never
+// rejects on its own since it can only be as good as its training data.
+type Bayes struct {
+	threshold float64
+
+	mu     sync.RWMutex
+	docs   [2]int
+	tokens [2]int
+	counts map[string]*[2]int
+}
+
+// class indexes used in the Bayes count arrays.
+const (
+	ham  = 0
+	spam = 1
+)
+
+// NewBayes creates an untrained Bayes scorer flagging content whose spam
+// probability is at least threshold.
+func NewBayes(threshold float64) *Bayes {
+	return &Bayes{
+		threshold: threshold,
+		counts:    make(map[string]*[2]int),
+	}
+}
+
+// Name implements Filter.
+func (f *Bayes) Name() string { return "bayes" }
+
+// Train adds text as an example of spam or ham.
+func (f *Bayes) Train(text string, isSpam bool) {
+	class := ham
+	if isSpam {
+		class = spam
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.docs[class]++
+	for _, token := range tokenize(text) {
+		c, ok := f.counts[token]
+		if !ok {
+			c = &[2]int{}
+			f.counts[token] = c
+		}
+		c[class]++
+		f.tokens[class]++
+	}
+}
+
+// Score returns the probability that text is spam, and false if the scorer
+// has not seen enough examples to say.
+func (f *Bayes) Score(text string) (float64, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.docs[ham] < minTrainingSamples || f.docs[spam] < minTrainingSamples {
+		return 0, false
+	}
+
+	total := float64(f.docs[ham] + f.docs[spam])
+	vocab := float64(len(f.counts))
+
+	var logProb [2]float64
+	for class := range logProb {
+		logProb[class] = math.Log(float64(f.docs[class]) / total)
+	}
+
+	for _, token := range tokenize(text) {
+		c, ok := f.counts[token]
+		if !ok {
+			// Words never seen in training say nothing either way.
+			continue
+		}
+		for class := range logProb {
+			// Laplace smoothing keeps a word seen in only one class from
+			// zeroing out the other.
+			logProb[class] += math.Log((float64(c[class]) + 1) / (float64(f.tokens[class]) + vocab))
+		}
+	}
+
+	// P(spam) = 1 / (1 + e^(log P(ham) - log P(spam)))
+	return 1 / (1 + math.Exp(logProb[ham]-logProb[spam])), true
+}
+
+// Check implements Filter.
+func (f *Bayes) Check(ctx context.Context, content Content) (Decision, error) {
+	score, ok := f.Score(content.Text)
+	if !ok || score < f.threshold {
+		return Decision{Verdict: Allow}, nil
+	}
+	return Decision{
+		Verdict: Flag,
+		Reason:  fmt.Sprintf("spam score %.2f is above threshold %.2f", score, f.threshold),
+	}, nil
+}
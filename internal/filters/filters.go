@@ -0,0 +1,114 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+)
+
+// Verdict is the outcome of running content through a Filter.
+type Verdict int
+
+const (
+	// Allow lets the content through unchanged.
+	Allow Verdict = iota
+	// Flag holds the content back until a moderator has reviewed it.
+	Flag
+	// Reject refuses the content outright.
+	Reject
+)
+
+// String returns the lowercase name of the verdict.
+func (v Verdict) String() string {
+	switch v {
+	case Allow:
+		return "allow"
+	case Flag:
+		return "flag"
+	case Reject:
+		return "reject"
+	default:
+		return fmt.Sprintf("verdict(%d)", int(v))
+	}
+}
+
+// Kind identifies what type of content is being filtered.
+type Kind string
+
+const (
+	KindComment Kind = "comment"
+	KindBlog    Kind = "blog"
+)
+
+// Content is the user-submitted text a Filter inspects.
+type Content struct {
+	Kind   Kind
+	UserID int
+	Text   string
+}
+
+// Decision is the result of a Filter, including the name of the filter that
+// produced it and a human readable reason.
+type Decision struct {
+	Verdict Verdict `json:"verdict"`
+	Filter  string  `json:"filter,omitempty"`
+	Reason  string  `json:"reason,omitempty"`
+}
+
+// Filter represents a type capable of inspecting content and deciding whether
+// it should be allowed, flagged for moderation, or rejected.
+type Filter interface {
+	// Name returns a short identifier for the filter used when recording
+	// decisions.
+	Name() string
+	// Check inspects the content and returns a Decision.
+	Check(ctx context.Context, content Content) (Decision, error)
+}
+
+// Chain runs a list of filters in order. A Reject from any filter stops the
+// chain immediately, while a Flag is remembered and returned once every filter
+// has run, so a later Reject still takes precedence.
+type Chain []Filter
+
+// Check runs the content through every filter in the chain and returns the
+// strongest Decision.
+func (c Chain) Check(ctx context.Context, content Content) (Decision, error) {
+	result := Decision{Verdict: Allow}
+
+	for _, f := range c {
+		decision, err := f.Check(ctx, content)
+		if err != nil {
+			return Decision{}, fmt.Errorf("[in filters.Chain.Check] filter %s failed: %w", f.Name(), err)
+		}
+		if decision.Filter == "" {
+			decision.Filter = f.Name()
+		}
+
+		switch decision.Verdict {
+		case Reject:
+			return decision, nil
+		case Flag:
+			if result.Verdict == Allow {
+				result = decision
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Recorder represents a type that observes content which made it through the
+// chain. Filters that keep per-user history implement it so they only learn
+// from content that was actually accepted.
+type Recorder interface {
+	Record(ctx context.Context, content Content)
+}
+
+// Record forwards accepted content to every filter in the chain that
+// implements Recorder.
+func (c Chain) Record(ctx context.Context, content Content) {
+	for _, f := range c {
+		if r, ok := f.(Recorder); ok {
+			r.Record(ctx, content)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package filters
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestChain_Check(t *testing.T) {
+	testcases := map[string]struct {
+		chain    Chain
+		input    string
+		expected Decision
+	}{
+		"clean content is allowed": {
+			chain:    Chain{NewBannedWords([]string{"spam"}), NewLinkLimit(1)},
+			input:    "Great post!",
+			expected: Decision{Verdict: Allow},
+		},
+		"banned word is rejected": {
+			chain: Chain{NewBannedWords([]string{"Spam"}), NewLinkLimit(1)},
+			input: "buy my SPAM today",
+			expected: Decision{
+				Verdict: Reject,
+				Filter:  "banned_words",
+				Reason:  `contains banned word "spam"`,
+			},
+		},
+		"banned word must be a whole word": {
+			chain:    Chain{NewBannedWords([]string{"ass"})},
+			input:    "first class content",
+			expected: Decision{Verdict: Allow},
+		},
+		"banned phrase is rejected however it is spaced": {
+			chain: Chain{NewBannedWords([]string{"buy  now"})},
+			input: "Great deals, buy...\nNOW!",
+			expected: Decision{
+				Verdict: Reject,
+				Filter:  "banned_words",
+				Reason:  `contains banned word "buy now"`,
+			},
+		},
+		"banned phrase must be whole words": {
+			chain:    Chain{NewBannedWords([]string{"buy now"})},
+			input:    "I'll buy nowhere near that",
+			expected: Decision{Verdict: Allow},
+		},
+		"too many links are flagged": {
+			chain: Chain{NewLinkLimit(1)},
+			input: "see https://a.example and www.b.example",
+			expected: Decision{
+				Verdict: Flag,
+				Filter:  "link_limit",
+				Reason:  "contains 2 links, limit is 1",
+			},
+		},
+		"reject wins over an earlier flag": {
+			chain: Chain{NewLinkLimit(0), NewBannedWords([]string{"spam"})},
+			input: "spam at https://a.example",
+			expected: Decision{
+				Verdict: Reject,
+				Filter:  "banned_words",
+				Reason:  `contains banned word "spam"`,
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			output, err := tc.chain.Check(context.Background(), Content{Kind: KindComment, UserID: 1, Text: tc.input})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if output != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, output)
+			}
+		})
+	}
+}
+
+func TestRepeatedMessage(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	f := NewRepeatedMessage(10 * time.Minute)
+	f.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	f.Record(ctx, Content{UserID: 1, Text: "Great   post!"})
+
+	testcases := map[string]struct {
+		content  Content
+		advance  time.Duration
+		expected Verdict
+	}{
+		"same user repeating is rejected": {
+			content:  Content{UserID: 1, Text: "great post!"},
+			expected: Reject,
+		},
+		"another user may say the same thing": {
+			content:  Content{UserID: 2, Text: "great post!"},
+			expected: Allow,
+		},
+		"repeat after the window is allowed": {
+			content:  Content{UserID: 1, Text: "great post!"},
+			advance:  11 * time.Minute,
+			expected: Allow,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			f.now = func() time.Time { return now.Add(tc.advance) }
+			output, err := f.Check(ctx, tc.content)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if output.Verdict != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, output.Verdict)
+			}
+		})
+	}
+}
+
+func TestBayes(t *testing.T) {
+	f := NewBayes(0.9)
+	ctx := context.Background()
+
+	// An untrained scorer must not make decisions.
+	if d, _ := f.Check(ctx, Content{Text: "cheap pills casino"}); d.Verdict != Allow {
+		t.Fatalf("expected untrained scorer to allow, got %v", d.Verdict)
+	}
+
+	for _, text := range []string{
+		"cheap pills online casino",
+		"win casino bonus now",
+		"cheap replica watches",
+		"casino bonus cheap",
+		"buy pills cheap now",
+	} {
+		f.Train(text, true)
+	}
+	for _, text := range []string{
+		"great post thanks for sharing",
+		"I tried this recipe at home",
+		"thanks for the tips",
+		"this made me think",
+		"great photos from the trip",
+	} {
+		f.Train(text, false)
+	}
+
+	testcases := map[string]struct {
+		input    string
+		expected Verdict
+	}{
+		"spammy content is flagged": {
+			input:    "cheap casino bonus pills",
+			expected: Flag,
+		},
+		"normal content is allowed": {
+			input:    "thanks for sharing this recipe",
+			expected: Allow,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			output, err := f.Check(ctx, Content{Text: tc.input})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if output.Verdict != tc.expected {
+				t.Errorf("expected %v, got %v (%s)", tc.expected, output.Verdict, output.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// linkPattern matches anything that looks like a link a reader could follow.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// LinkLimit flags content containing more links than allowed. Link-heavy
+// comments are the most common shape of spam, but a real reader occasionally
+// shares a few, so this holds them for review rather than rejecting them.
+type LinkLimit struct {
+	max int
+}
+
+// NewLinkLimit creates a LinkLimit filter allowing at most max links.
+func NewLinkLimit(max int) *LinkLimit {
+	return &LinkLimit{max: max}
+}
+
+// Name implements Filter.
+func (f *LinkLimit) Name() string { return "link_limit" }
+
+// Check implements Filter.
+func (f *LinkLimit) Check(ctx context.Context, content Content) (Decision, error) {
+	count := len(linkPattern.FindAllStringIndex(content.Text, -1))
+	if count > f.max {
+		return Decision{
+			Verdict: Flag,
+			Reason:  fmt.Sprintf("contains %d links, limit is %d", count, f.max),
+		}, nil
+	}
+	return Decision{Verdict: Allow}, nil
+}
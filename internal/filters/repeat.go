@@ -0,0 +1,87 @@
+package filters
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRememberedMessages caps how many recent messages are kept per user.
+const maxRememberedMessages = 20
+
+type seenMessage struct {
+	text string
+	at   time.Time
+}
+
+// RepeatedMessage rejects content a user has already posted within a time
+// window. Messages are compared after lowercasing and collapsing whitespace so
+// trivial variations are still caught. History is kept in memory and only
+// covers content that was accepted, see Recorder.
+type RepeatedMessage struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu   sync.Mutex
+	seen map[int][]seenMessage
+}
+
+// NewRepeatedMessage creates a RepeatedMessage filter remembering messages for
+// the provided window.
+func NewRepeatedMessage(window time.Duration) *RepeatedMessage {
+	return &RepeatedMessage{
+		window: window,
+		now:    time.Now,
+		seen:   make(map[int][]seenMessage),
+	}
+}
+
+// Name implements Filter.
+func (f *RepeatedMessage) Name() string { return "repeated_message" }
+
+// Check implements Filter.
+func (f *RepeatedMessage) Check(ctx context.Context, content Content) (Decision, error) {
+	text := normalize(content.Text)
+	cutoff := f.now().Add(-f.window)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, m := range f.seen[content.UserID] {
+		if m.at.After(cutoff) && m.text == text {
+			return Decision{
+				Verdict: Reject,
+				Reason:  "message repeats one posted recently",
+			}, nil
+		}
+	}
+	return Decision{Verdict: Allow}, nil
+}
+
+// Record implements Recorder.
+func (f *RepeatedMessage) Record(ctx context.Context, content Content) {
+	now := f.now()
+	cutoff := now.Add(-f.window)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Drop expired entries while we are here so history doesn't grow forever.
+	kept := f.seen[content.UserID][:0]
+	for _, m := range f.seen[content.UserID] {
+		if m.at.After(cutoff) {
+			kept = append(kept, m)
+		}
+	}
+	kept = append(kept, seenMessage{text: normalize(content.Text), at: now})
+	if len(kept) > maxRememberedMessages {
+		kept = kept[len(kept)-maxRememberedMessages:]
+	}
+	f.seen[content.UserID] = kept
+}
+
+// normalize lowercases text and collapses runs of whitespace.
+func normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
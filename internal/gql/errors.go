@@ -0,0 +1,88 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+
+	"github.com/navid/blog/internal/auth"
+)
+
+// Codes of the errors a client can act on, in the code extension of the
+// error.
+const (
+	codeBadUserInput      = "BAD_USER_INPUT"
+	codeUnauthenticated   = "UNAUTHENTICATED"
+	codeForbidden         = "FORBIDDEN"
+	codeNotFound          = "NOT_FOUND"
+	codeConflict          = "CONFLICT"
+	codeRejected          = "REJECTED"
+	codePendingModeration = "PENDING_MODERATION"
+	codeInternal          = "INTERNAL_SERVER_ERROR"
+)
+
+// userError is an error reported to the client, with a code and any details
+// in its extensions.
+type userError struct {
+	message    string
+	code       string
+	extensions map[string]any
+}
+
+func (e *userError) Error() string { return e.message }
+
+// Extensions returns the extensions of the error in the response.
+func (e *userError) Extensions() map[string]any {
+	extensions := map[string]any{"code": e.code}
+	maps.Copy(extensions, e.extensions)
+	return extensions
+}
+
+// invalid returns an error for input with problems, keyed like the
+// validation problems of the REST API.
+func invalid(problems map[string]string) error {
+	return &userError{
+		message:    "invalid input",
+		code:       codeBadUserInput,
+		extensions: map[string]any{"problems": problems},
+	}
+}
+
+// notFound returns an error for something that doesn't exist.
+func notFound(message string) error {
+	return &userError{message: message, code: codeNotFound}
+}
+
+// actAs checks that the request may act on behalf of the user with id: it is
+// authenticated as that user or an admin.
+func (s *Schema) actAs(ctx context.Context, id int) error {
+	switch err := auth.ActAs(ctx, id, s.admins); {
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return &userError{message: "authentication required", code: codeUnauthenticated}
+	case errors.Is(err, auth.ErrForbidden):
+		return &userError{message: "forbidden", code: codeForbidden}
+	}
+	return nil
+}
+
+// actAsAuthor checks that the request is authenticated as authorID, the user
+// named as the author of a blog or comment, and returns the authenticated
+// user's id. Admins can't post as other users either.
+func actAsAuthor(ctx context.Context, authorID int) (int, error) {
+	id, ok := auth.UserID(ctx)
+	if !ok {
+		return 0, &userError{message: "authentication required", code: codeUnauthenticated}
+	}
+	if id != authorID {
+		return 0, &userError{message: "cannot act as another user", code: codeForbidden}
+	}
+	return id, nil
+}
+
+// internal logs err and returns an error telling the client only that msg
+// happened.
+func (s *Schema) internal(ctx context.Context, msg string, err error) error {
+	s.logger.ErrorContext(ctx, msg, slog.String("error", err.Error()))
+	return &userError{message: msg, code: codeInternal}
+}
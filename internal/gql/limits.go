@@ -0,0 +1,160 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits bounds the queries a Schema runs, so one request can't make the
+// server resolve an unbounded number of fields.
+type Limits struct {
+	// MaxDepth is how deeply selections can be nested.
+	MaxDepth int
+	// MaxComplexity bounds the estimated number of fields resolved: each
+	// field counts one, and the fields selected under a paginated list count
+	// once for every item the page can hold.
+	MaxComplexity int
+}
+
+// checkLimits measures the operation of doc that will run and returns an
+// error if it is deeper or more complex than the limits allow. doc must be
+// valid, so fragments don't form cycles. Introspection isn't counted, as it
+// only reads the schema.
+func (s *Schema) checkLimits(doc *ast.Document, operationName string, variables map[string]any) error {
+	var op *ast.OperationDefinition
+	m := measurer{schema: s.schema, fragments: make(map[string]*ast.FragmentDefinition), variables: variables}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || def.Name != nil && def.Name.Value == operationName {
+				op = def
+			}
+		case *ast.FragmentDefinition:
+			m.fragments[def.Name.Value] = def
+		}
+	}
+	if op == nil {
+		// Execution reports the missing operation
+		return nil
+	}
+
+	var root graphql.Type = s.schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = s.schema.MutationType()
+	}
+	depth, complexity := m.measure(op.SelectionSet, root)
+	if s.limits.MaxDepth > 0 && depth > s.limits.MaxDepth {
+		return fmt.Errorf("query is %d levels deep, more than the limit of %d", depth, s.limits.MaxDepth)
+	}
+	if s.limits.MaxComplexity > 0 && complexity > s.limits.MaxComplexity {
+		return fmt.Errorf("query has a complexity of %d, more than the limit of %d", complexity, s.limits.MaxComplexity)
+	}
+	return nil
+}
+
+// measurer measures the selections of an operation.
+type measurer struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+// measure returns the depth and complexity of set, selected on parent.
+func (m measurer) measure(set *ast.SelectionSet, parent graphql.Type) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			name := selection.Name.Value
+			if strings.HasPrefix(name, "__") {
+				continue
+			}
+
+			var (
+				fieldType graphql.Type
+				perItem   = 1
+			)
+			if object, ok := parent.(*graphql.Object); ok {
+				if def, ok := object.Fields()[name]; ok {
+					fieldType = namedType(def.Type)
+					if paginated(def) {
+						perItem = m.pageSize(selection)
+					}
+				}
+			}
+			d, c := m.measure(selection.SelectionSet, fieldType)
+			depth = max(depth, d+1)
+			complexity += 1 + perItem*c
+		case *ast.InlineFragment:
+			fragmentType := parent
+			if selection.TypeCondition != nil {
+				fragmentType = m.schema.Type(selection.TypeCondition.Name.Value)
+			}
+			d, c := m.measure(selection.SelectionSet, fragmentType)
+			depth = max(depth, d)
+			complexity += c
+		case *ast.FragmentSpread:
+			if fragment, ok := m.fragments[selection.Name.Value]; ok {
+				d, c := m.measure(fragment.SelectionSet, parent)
+				depth = max(depth, d)
+				complexity += c
+			}
+		}
+	}
+	return depth, complexity
+}
+
+// pageSize returns the limit argument of field, or the default page size.
+func (m measurer) pageSize(field *ast.Field) int {
+	size := defaultPageSize
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				size = n
+			}
+		case *ast.Variable:
+			switch n := m.variables[value.Name.Value].(type) {
+			case int:
+				size = n
+			case float64:
+				size = int(n)
+			}
+		}
+	}
+	return min(max(size, 1), maxPageSize)
+}
+
+// paginated reports whether def is a list taking a limit argument.
+func paginated(def *graphql.FieldDefinition) bool {
+	for _, arg := range def.Args {
+		if arg.Name() == "limit" {
+			return true
+		}
+	}
+	return false
+}
+
+// namedType unwraps the lists and non-null wrappers of t.
+func namedType(t graphql.Type) graphql.Type {
+	for {
+		switch wrapped := t.(type) {
+		case *graphql.NonNull:
+			t = wrapped.OfType
+		case *graphql.List:
+			t = wrapped.OfType
+		default:
+			return t
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package gql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/navid/blog/internal/models"
+)
+
+// loader batches the reads of a request. Keys asked for while one level of
+// a query is resolved are read together, with one call to fetch, when the
+// first of their values is needed; graphql-go only calls the thunks load
+// returns once the whole level has been resolved. Values are kept for the
+// rest of the request.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	values  map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:  fetch,
+		queued: make(map[K]bool),
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// load queues key to be read and returns a thunk returning its value, or
+// the zero V if there is none.
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+			values, err := l.fetch(ctx, keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+				} else if v, ok := values[k]; ok {
+					l.values[k] = v
+				}
+			}
+		}
+		return l.values[key], l.errs[key]
+	}
+}
+
+// loaders are the loaders of a request.
+type loaders struct {
+	users          *loader[int, models.User]
+	blogs          *loader[int, models.Blog]
+	blogsByAuthor  *loader[int, []models.Blog]
+	commentsByBlog *loader[int, []models.Comment]
+}
+
+// loadersKey is the context key of the loaders of a request.
+type loadersKey struct{}
+
+// withLoaders returns a copy of ctx carrying new loaders reading from the
+// schema's services.
+func (s *Schema) withLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		users: newLoader(s.users.ReadUsers),
+		blogs: newLoader(func(ctx context.Context, ids []int) (map[int]models.Blog, error) {
+			blogs, err := s.blogs.ListBlogsWithFilter(ctx, models.BlogFilter{IDs: ids})
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[int]models.Blog, len(blogs))
+			for _, blog := range blogs {
+				byID[int(blog.ID)] = blog
+			}
+			return byID, nil
+		}),
+		blogsByAuthor: newLoader(func(ctx context.Context, ids []int) (map[int][]models.Blog, error) {
+			blogs, err := s.blogs.ListBlogsWithFilter(ctx, models.BlogFilter{AuthorIDs: ids, OrderByNewest: true})
+			if err != nil {
+				return nil, err
+			}
+			byAuthor := make(map[int][]models.Blog)
+			for _, blog := range blogs {
+				byAuthor[blog.AuthorID] = append(byAuthor[blog.AuthorID], blog)
+			}
+			return byAuthor, nil
+		}),
+		commentsByBlog: newLoader(s.comments.ListCommentsForBlogs),
+	})
+}
+
+// loadersFrom returns the loaders of the request with ctx.
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,507 @@
+package gql
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/models"
+	"github.com/navid/blog/internal/validate"
+)
+
+// queryType builds the root query type. Lists filter like the REST list
+// endpoints.
+func (s *Schema) queryType(t objectTypes) *graphql.Object {
+	blogSort := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "BlogSort",
+		Description: "How blogs are ordered.",
+		Values: graphql.EnumValueConfigMap{
+			"SCORE":  &graphql.EnumValueConfig{Value: "score", Description: "Best weighted rating first."},
+			"NEWEST": &graphql.EnumValueConfig{Value: "newest", Description: "Most recently created first."},
+		},
+	})
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"user": &graphql.Field{
+				Type: t.user,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return s.loadUser(p.Context, p.Args["id"].(int)), nil
+				},
+			},
+			"users": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.user))),
+				Description: "Users, optionally those whose name contains a value.",
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.String},
+				}),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					limit, offset, err := page(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					name, _ := p.Args["name"].(string)
+					users, err := s.users.ListUsersWithFilter(p.Context, name)
+					if err != nil {
+						return nil, s.internal(p.Context, "failed to list users", err)
+					}
+					return paginate(users, limit, offset), nil
+				},
+			},
+			"blog": &graphql.Field{
+				Type: t.blog,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					blog, err := s.blogs.GetBlog(p.Context, uint(p.Args["id"].(int)))
+					if err != nil {
+						if strings.Contains(err.Error(), "no blog found") {
+							return nil, nil
+						}
+						return nil, s.internal(p.Context, "failed to read blog", err)
+					}
+					return blog, nil
+				},
+			},
+			"blogs": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.blog))),
+				Description: "Blogs, optionally filtered by title, author and tag.",
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"title":    &graphql.ArgumentConfig{Type: graphql.String, Description: "Only blogs whose title contains the value."},
+					"authorId": &graphql.ArgumentConfig{Type: graphql.Int},
+					"tag":      &graphql.ArgumentConfig{Type: graphql.String},
+					"sort":     &graphql.ArgumentConfig{Type: blogSort},
+				}),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					limit, offset, err := page(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					filter := models.BlogFilter{Limit: limit, Offset: offset}
+					filter.Title, _ = p.Args["title"].(string)
+					filter.AuthorID, _ = p.Args["authorId"].(int)
+					if tag, ok := p.Args["tag"].(string); ok {
+						filter.Tag = strings.ToLower(strings.TrimSpace(tag))
+					}
+					switch p.Args["sort"] {
+					case "score":
+						filter.OrderByScore = true
+					case "newest":
+						filter.OrderByNewest = true
+					}
+
+					blogs, err := s.blogs.ListBlogsWithFilter(p.Context, filter)
+					if err != nil {
+						return nil, s.internal(p.Context, "failed to list blogs", err)
+					}
+					if blogs == nil {
+						blogs = []models.Blog{}
+					}
+					return blogs, nil
+				},
+			},
+			"comments": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.comment))),
+				Description: "Comments, optionally those by a user or on a blog.",
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"authorId": &graphql.ArgumentConfig{Type: graphql.Int},
+					"blogId":   &graphql.ArgumentConfig{Type: graphql.Int},
+				}),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					limit, offset, err := page(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					var authorID, blogID *int
+					if id, ok := p.Args["authorId"].(int); ok {
+						authorID = &id
+					}
+					if id, ok := p.Args["blogId"].(int); ok {
+						blogID = &id
+					}
+					comments, err := s.comments.ListComments(p.Context, authorID, blogID)
+					if err != nil {
+						return nil, s.internal(p.Context, "failed to list comments", err)
+					}
+					return paginate(comments, limit, offset), nil
+				},
+			},
+		},
+	})
+}
+
+// mutationType builds the root mutation type. Each mutation applies the
+// checks of the matching REST handler before calling the service.
+func (s *Schema) mutationType(t objectTypes) *graphql.Object {
+	userInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "UserInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"password": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	blogInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "BlogInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"authorId": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"tags": &graphql.InputObjectFieldConfig{
+				Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+				Description: "The blog's tags. When updating, leaving them out keeps the current tags.",
+			},
+		},
+	})
+	commentInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CommentInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"userId":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"blogId":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"message": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	id := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createUser": &graphql.Field{
+				Type: t.user,
+				Args: graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(userInput)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					user := userFrom(p.Args["input"])
+					if err := check(p.Context, user); err != nil {
+						return nil, err
+					}
+					created, err := s.users.CreateUser(p.Context, user)
+					if err != nil {
+						return nil, s.internal(p.Context, "failed to create user", err)
+					}
+					return created, nil
+				},
+			},
+			"updateUser": &graphql.Field{
+				Type: t.user,
+				Args: graphql.FieldConfigArgument{"id": id, "input": {Type: graphql.NewNonNull(userInput)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if err := s.actAs(p.Context, p.Args["id"].(int)); err != nil {
+						return nil, err
+					}
+					user := userFrom(p.Args["input"])
+					if err := check(p.Context, user); err != nil {
+						return nil, err
+					}
+					updated, err := s.users.UpdateUser(p.Context, uint64(p.Args["id"].(int)), user)
+					if err != nil {
+						if strings.Contains(err.Error(), "no user found") {
+							return nil, notFound("user not found")
+						}
+						return nil, s.internal(p.Context, "failed to update user", err)
+					}
+					return updated, nil
+				},
+			},
+			"deleteUser": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{"id": id},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if err := s.actAs(p.Context, p.Args["id"].(int)); err != nil {
+						return nil, err
+					}
+					if err := s.users.DeleteUser(p.Context, uint64(p.Args["id"].(int))); err != nil {
+						if strings.Contains(err.Error(), "no user found") {
+							return nil, notFound("user not found")
+						}
+						return nil, s.internal(p.Context, "failed to delete user", err)
+					}
+					return true, nil
+				},
+			},
+			"createBlog": &graphql.Field{
+				Type: t.blog,
+				Args: graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(blogInput)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					blog := blogFrom(p.Args["input"])
+					userID, err := actAsAuthor(p.Context, blog.AuthorID)
+					if err != nil {
+						return nil, err
+					}
+					if err := s.checkBlog(p.Context, blog); err != nil {
+						return nil, err
+					}
+					content := filters.Content{
+						Kind:   filters.KindBlog,
+						UserID: userID,
+						Text:   blog.Text(),
+					}
+					if err := s.screen(p.Context, content, blog); err != nil {
+						return nil, err
+					}
+					created, err := s.blogs.CreateBlog(p.Context, blog)
+					if err != nil {
+						return nil, s.internal(p.Context, "failed to create blog", err)
+					}
+					s.screener.Record(p.Context, content)
+					return created, nil
+				},
+			},
+			"updateBlog": &graphql.Field{
+				Type: t.blog,
+				Args: graphql.FieldConfigArgument{"id": id, "input": {Type: graphql.NewNonNull(blogInput)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					blog := blogFrom(p.Args["input"])
+					userID, err := actAsAuthor(p.Context, blog.AuthorID)
+					if err != nil {
+						return nil, err
+					}
+					if err := s.checkBlog(p.Context, blog); err != nil {
+						return nil, err
+					}
+					blog.ID = uint(p.Args["id"].(int))
+					existing, err := s.blogs.GetBlog(p.Context, blog.ID)
+					if err != nil {
+						if strings.Contains(err.Error(), "no blog found") {
+							return nil, notFound("blog not found")
+						}
+						return nil, s.internal(p.Context, "failed to update blog", err)
+					}
+					if existing.AuthorID != userID {
+						return nil, &userError{message: "cannot edit another user's blog", code: codeForbidden}
+					}
+					content := filters.Content{
+						Kind:   filters.KindBlog,
+						UserID: userID,
+						Text:   blog.Text(),
+					}
+					if err := s.screen(p.Context, content, blog); err != nil {
+						return nil, err
+					}
+					updated, err := s.blogs.UpdateBlog(p.Context, blog.ID, blog)
+					if err != nil {
+						if strings.Contains(err.Error(), "no blog found") {
+							return nil, notFound("blog not found")
+						}
+						return nil, s.internal(p.Context, "failed to update blog", err)
+					}
+					s.screener.Record(p.Context, content)
+					return updated, nil
+				},
+			},
+			"deleteBlog": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{"id": id},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if err := s.blogs.DeleteBlog(p.Context, uint(p.Args["id"].(int))); err != nil {
+						if strings.Contains(err.Error(), "no blog found") {
+							return nil, notFound("blog not found")
+						}
+						return nil, s.internal(p.Context, "failed to delete blog", err)
+					}
+					return true, nil
+				},
+			},
+			"createComment": &graphql.Field{
+				Type: t.comment,
+				Args: graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(commentInput)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					comment := commentFrom(p.Args["input"])
+					userID, err := actAsAuthor(p.Context, comment.UserID)
+					if err != nil {
+						return nil, err
+					}
+					if err := s.checkComment(p.Context, comment); err != nil {
+						return nil, err
+					}
+					exists, err := s.comments.DoesCommentExist(p.Context, comment.UserID, comment.BlogID)
+					if err != nil {
+						return nil, s.internal(p.Context, "failed to validate comment", err)
+					}
+					if exists {
+						return nil, &userError{message: "the user has already commented on the blog", code: codeConflict}
+					}
+					content := filters.Content{
+						Kind:   filters.KindComment,
+						UserID: userID,
+						Text:   comment.Message,
+					}
+					if err := s.screen(p.Context, content, comment); err != nil {
+						return nil, err
+					}
+					created, err := s.comments.CreateComment(p.Context, comment)
+					if err != nil {
+						return nil, s.internal(p.Context, "failed to create comment", err)
+					}
+					s.screener.Record(p.Context, content)
+					return created, nil
+				},
+			},
+			"updateComment": &graphql.Field{
+				Type: t.comment,
+				Args: graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(commentInput)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					comment := commentFrom(p.Args["input"])
+					userID, err := actAsAuthor(p.Context, comment.UserID)
+					if err != nil {
+						return nil, err
+					}
+					if err := s.checkComment(p.Context, comment); err != nil {
+						return nil, err
+					}
+					exists, err := s.comments.DoesCommentExist(p.Context, comment.UserID, comment.BlogID)
+					if err != nil {
+						return nil, s.internal(p.Context, "failed to update comment", err)
+					}
+					if !exists {
+						return nil, notFound("comment not found")
+					}
+					comment.CreatedDate = time.Now()
+					content := filters.Content{
+						Kind:   filters.KindComment,
+						UserID: userID,
+						Text:   comment.Message,
+					}
+					if err := s.screen(p.Context, content, comment); err != nil {
+						return nil, err
+					}
+					updated, err := s.comments.UpdateComment(p.Context, comment)
+					if err != nil {
+						if strings.Contains(err.Error(), "no comment found") {
+							return nil, notFound("comment not found")
+						}
+						return nil, s.internal(p.Context, "failed to update comment", err)
+					}
+					s.screener.Record(p.Context, content)
+					return updated, nil
+				},
+			},
+			"deleteComment": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{"userId": id, "blogId": id},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if err := s.comments.DeleteComment(p.Context, p.Args["userId"].(int), p.Args["blogId"].(int)); err != nil {
+						if strings.Contains(err.Error(), "no comment found") {
+							return nil, notFound("comment not found")
+						}
+						return nil, s.internal(p.Context, "failed to delete comment", err)
+					}
+					return true, nil
+				},
+			},
+		},
+	})
+}
+
+// validator is a model that can be validated.
+type validator interface {
+	Valid(ctx context.Context) map[string]string
+}
+
+// check validates v against the rules in its validate struct tags, then with
+// its Valid method, like the REST handlers do with request bodies.
+func check(ctx context.Context, v validator) error {
+	problems := validate.Struct(v)
+	maps.Copy(problems, v.Valid(ctx))
+	if len(problems) > 0 {
+		return invalid(problems)
+	}
+	return nil
+}
+
+// checkBlog validates blog and checks its author exists.
+func (s *Schema) checkBlog(ctx context.Context, blog models.Blog) error {
+	if err := check(ctx, blog); err != nil {
+		return err
+	}
+	if !s.users.DoesUserExist(ctx, blog.AuthorID) {
+		return invalid(map[string]string{"author_id": "user does not exist"})
+	}
+	return nil
+}
+
+// checkComment validates comment and checks its user and blog exist.
+func (s *Schema) checkComment(ctx context.Context, comment models.Comment) error {
+	if err := check(ctx, comment); err != nil {
+		return err
+	}
+	if !s.users.DoesUserExist(ctx, comment.UserID) {
+		return invalid(map[string]string{"user_id": "user does not exist"})
+	}
+	if _, err := s.blogs.GetBlog(ctx, uint(comment.BlogID)); err != nil {
+		if strings.Contains(err.Error(), "no blog found") {
+			return invalid(map[string]string{"blog_id": "blog does not exist"})
+		}
+		return s.internal(ctx, "failed to validate comment", err)
+	}
+	return nil
+}
+
+// screen runs content through the content filters, returning an error if it
+// was rejected or held for moderation.
+func (s *Schema) screen(ctx context.Context, content filters.Content, payload any) error {
+	decision, moderationID, err := s.screener.Screen(ctx, content, payload)
+	if err != nil {
+		return s.internal(ctx, "failed to screen content", err)
+	}
+
+	switch decision.Verdict {
+	case filters.Reject:
+		return &userError{
+			message:    "the content was rejected by the content filters",
+			code:       codeRejected,
+			extensions: map[string]any{"moderationId": moderationID, "filter": decision.Filter, "reason": decision.Reason},
+		}
+	case filters.Flag:
+		return &userError{
+			message:    "the content is held until a moderator has reviewed it",
+			code:       codePendingModeration,
+			extensions: map[string]any{"moderationId": moderationID, "filter": decision.Filter, "reason": decision.Reason},
+		}
+	}
+	return nil
+}
+
+// userFrom reads a UserInput argument.
+func userFrom(input any) models.User {
+	fields, _ := input.(map[string]any)
+	var user models.User
+	user.Name, _ = fields["name"].(string)
+	user.Email, _ = fields["email"].(string)
+	user.Password, _ = fields["password"].(string)
+	return user
+}
+
+// blogFrom reads a BlogInput argument. Tags are left nil when not given.
+func blogFrom(input any) models.Blog {
+	fields, _ := input.(map[string]any)
+	var blog models.Blog
+	blog.Title, _ = fields["title"].(string)
+	blog.AuthorID, _ = fields["authorId"].(int)
+	if tags, ok := fields["tags"].([]any); ok {
+		blog.Tags = make([]string, 0, len(tags))
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok {
+				blog.Tags = append(blog.Tags, tag)
+			}
+		}
+	}
+	return blog
+}
+
+// commentFrom reads a CommentInput argument.
+func commentFrom(input any) models.Comment {
+	fields, _ := input.(map[string]any)
+	var comment models.Comment
+	comment.UserID, _ = fields["userId"].(int)
+	comment.BlogID, _ = fields["blogId"].(int)
+	comment.Message, _ = fields["message"].(string)
+	return comment
+}
@@ -0,0 +1,420 @@
+// Package gql serves users, blogs and comments over GraphQL, alongside the
+// REST API. Queries filter and page like the REST list endpoints, and
+// mutations apply the same checks as the REST handlers before calling the
+// same services.
+package gql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/models"
+)
+
+const (
+	// defaultPageSize is how many items a list returns when no limit is
+	// given.
+	defaultPageSize = 20
+	// maxPageSize is the largest limit a list accepts.
+	maxPageSize = 100
+)
+
+// usersService represents a type capable of reading and writing users in
+// storage.
+type usersService interface {
+	ReadUsers(ctx context.Context, ids []int) (map[int]models.User, error)
+	ListUsersWithFilter(ctx context.Context, name string) ([]models.User, error)
+	CreateUser(ctx context.Context, user models.User) (models.User, error)
+	UpdateUser(ctx context.Context, id uint64, patch models.User) (models.User, error)
+	DeleteUser(ctx context.Context, id uint64) error
+	DoesUserExist(ctx context.Context, userID int) bool
+}
+
+// blogsService represents a type capable of reading and writing blogs in
+// storage.
+type blogsService interface {
+	GetBlog(ctx context.Context, id uint) (models.Blog, error)
+	ListBlogsWithFilter(ctx context.Context, filter models.BlogFilter) ([]models.Blog, error)
+	CreateBlog(ctx context.Context, blog models.Blog) (models.Blog, error)
+	UpdateBlog(ctx context.Context, id uint, blog models.Blog) (models.Blog, error)
+	DeleteBlog(ctx context.Context, id uint) error
+}
+
+// commentsService represents a type capable of reading and writing comments
+// in storage.
+type commentsService interface {
+	ListComments(ctx context.Context, authorID, blogID *int) ([]models.Comment, error)
+	ListCommentsForBlogs(ctx context.Context, blogIDs []int) (map[int][]models.Comment, error)
+	CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error)
+	UpdateComment(ctx context.Context, comment models.Comment) (models.Comment, error)
+	DeleteComment(ctx context.Context, userID, blogID int) error
+	DoesCommentExist(ctx context.Context, userID, blogID int) (bool, error)
+}
+
+// contentScreener represents a type capable of running submitted content
+// through the content filters, and of recording it once it has been stored.
+type contentScreener interface {
+	Screen(ctx context.Context, content filters.Content, payload any) (filters.Decision, uint, error)
+	Record(ctx context.Context, content filters.Content)
+}
+
+// Schema is the GraphQL schema of the blog, resolved by the services.
+type Schema struct {
+	schema   graphql.Schema
+	limits   Limits
+	logger   *slog.Logger
+	users    usersService
+	blogs    blogsService
+	comments commentsService
+	screener contentScreener
+	admins   []int
+}
+
+// NewSchema builds the schema, resolving it with the services and running
+// created content through screener. Queries are refused past limits. Users
+// can only be changed by themselves or by admins, and blogs and comments can
+// only be posted by their authors.
+func NewSchema(logger *slog.Logger, users usersService, blogs blogsService, comments commentsService, screener contentScreener, admins []int, limits Limits) (*Schema, error) {
+	s := &Schema{
+		limits:   limits,
+		logger:   logger,
+		users:    users,
+		blogs:    blogs,
+		comments: comments,
+		screener: screener,
+		admins:   admins,
+	}
+
+	types := s.types()
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    s.queryType(types),
+		Mutation: s.mutationType(types),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("build graphql schema: %w", err)
+	}
+	s.schema = schema
+	return s, nil
+}
+
+// Request is a GraphQL request, as sent to the endpoint.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// ErrReadOnly is returned for mutations sent where only queries may run,
+// such as in GET requests.
+var ErrReadOnly = errors.New("mutations can only be sent with POST")
+
+// RequestError is returned for a request that can't be run at all: one that
+// doesn't parse, isn't valid against the schema or is over the limits.
+type RequestError struct {
+	Errors []gqlerrors.FormattedError `json:"errors"`
+}
+
+func (e *RequestError) Error() string {
+	if len(e.Errors) == 0 {
+		return "invalid graphql request"
+	}
+	return "invalid graphql request: " + e.Errors[0].Message
+}
+
+// Do runs req, refusing mutations when readOnly is set. Errors raised while
+// resolving fields are part of the result; the error returned is
+// ErrReadOnly or a *RequestError.
+func (s *Schema) Do(ctx context.Context, req Request, readOnly bool) (*graphql.Result, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return nil, &RequestError{Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)}}
+	}
+	if result := graphql.ValidateDocument(&s.schema, doc, nil); !result.IsValid {
+		return nil, &RequestError{Errors: result.Errors}
+	}
+	if readOnly && mutates(doc, req.OperationName) {
+		return nil, ErrReadOnly
+	}
+	if err := s.checkLimits(doc, req.OperationName, req.Variables); err != nil {
+		return nil, &RequestError{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())}}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       s.withLoaders(ctx),
+	}), nil
+}
+
+// mutates reports whether the operation of doc that will run is a mutation.
+func mutates(doc *ast.Document, operationName string) bool {
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok || operationName != "" && (op.Name == nil || op.Name.Value != operationName) {
+			continue
+		}
+		if op.Operation == ast.OperationTypeMutation {
+			return true
+		}
+	}
+	return false
+}
+
+// objectTypes are the object types of the schema, shared by queries and
+// mutations.
+type objectTypes struct {
+	user, blog, comment *graphql.Object
+}
+
+// types builds the object types. Their fields are thunks, as users, blogs
+// and comments refer to each other.
+func (s *Schema) types() objectTypes {
+	var t objectTypes
+
+	reactionCount := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ReactionCount",
+		Description: "The number of reactions of one type.",
+		Fields: graphql.Fields{
+			"type":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"count": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+	reactions := &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(reactionCount))),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			switch source := p.Source.(type) {
+			case models.Blog:
+				return reactionCounts(source.Reactions), nil
+			case models.Comment:
+				return reactionCounts(source.Reactions), nil
+			}
+			return nil, nil
+		},
+	}
+
+	t.user = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "User",
+		Description: "A user, who writes blogs and comments.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"name":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"email": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"blogs": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.blog))),
+					Description: "The blogs the user wrote, newest first.",
+					Args:        pageArgs(nil),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						limit, offset, err := page(p.Args)
+						if err != nil {
+							return nil, err
+						}
+						user := p.Source.(models.User)
+						thunk := loadersFrom(p.Context).blogsByAuthor.load(p.Context, int(user.ID))
+						return func() (any, error) {
+							blogs, err := thunk()
+							if err != nil {
+								return nil, s.internal(p.Context, "failed to list blogs", err)
+							}
+							return paginate(blogs, limit, offset), nil
+						}, nil
+					},
+				},
+			}
+		}),
+	})
+
+	t.blog = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Blog",
+		Description: "A blog post.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"title": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"createdDate": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.DateTime),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return p.Source.(models.Blog).CreatedAt, nil
+					},
+				},
+				"score":         &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "The Bayesian-weighted rating used for ranking."},
+				"ratingAverage": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+				"ratingCount":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"tags": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						if tags := p.Source.(models.Blog).Tags; tags != nil {
+							return tags, nil
+						}
+						return []string{}, nil
+					},
+				},
+				"reactions": reactions,
+				"author": &graphql.Field{
+					Type:        t.user,
+					Description: "The user who wrote the blog, or null if they no longer exist.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return s.loadUser(p.Context, p.Source.(models.Blog).AuthorID), nil
+					},
+				},
+				"comments": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.comment))),
+					Description: "The comments on the blog.",
+					Args:        pageArgs(nil),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						limit, offset, err := page(p.Args)
+						if err != nil {
+							return nil, err
+						}
+						blog := p.Source.(models.Blog)
+						thunk := loadersFrom(p.Context).commentsByBlog.load(p.Context, int(blog.ID))
+						return func() (any, error) {
+							comments, err := thunk()
+							if err != nil {
+								return nil, s.internal(p.Context, "failed to list comments", err)
+							}
+							return paginate(comments, limit, offset), nil
+						}, nil
+					},
+				},
+			}
+		}),
+	})
+
+	t.comment = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Comment",
+		Description: "A comment on a blog. A user comments on a blog at most once.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"userId":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"blogId":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"message":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"createdDate": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"reactions":   reactions,
+				"user": &graphql.Field{
+					Type:        t.user,
+					Description: "The user who wrote the comment, or null if they no longer exist.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return s.loadUser(p.Context, p.Source.(models.Comment).UserID), nil
+					},
+				},
+				"blog": &graphql.Field{
+					Type:        t.blog,
+					Description: "The blog commented on, or null if it no longer exists.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						thunk := loadersFrom(p.Context).blogs.load(p.Context, p.Source.(models.Comment).BlogID)
+						return func() (any, error) {
+							blog, err := thunk()
+							if err != nil {
+								return nil, s.internal(p.Context, "failed to read blog", err)
+							}
+							if blog.ID == 0 {
+								return nil, nil
+							}
+							return blog, nil
+						}, nil
+					},
+				},
+			}
+		}),
+	})
+
+	return t
+}
+
+// loadUser returns a thunk resolving to the user with id, or null if there
+// is none.
+func (s *Schema) loadUser(ctx context.Context, id int) func() (any, error) {
+	thunk := loadersFrom(ctx).users.load(ctx, id)
+	return func() (any, error) {
+		user, err := thunk()
+		if err != nil {
+			return nil, s.internal(ctx, "failed to read user", err)
+		}
+		if user.ID == 0 {
+			return nil, nil
+		}
+		return user, nil
+	}
+}
+
+// reactionCount is the number of reactions of one type.
+type reactionCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// reactionCounts returns counts as a list, sorted by type.
+func reactionCounts(counts map[string]int) []reactionCount {
+	list := make([]reactionCount, 0, len(counts))
+	for typ, count := range counts {
+		list = append(list, reactionCount{Type: typ, Count: count})
+	}
+	slices.SortFunc(list, func(a, b reactionCount) int {
+		switch {
+		case a.Type < b.Type:
+			return -1
+		case a.Type > b.Type:
+			return 1
+		}
+		return 0
+	})
+	return list
+}
+
+// pageArgs returns args with the limit and offset arguments of a paginated
+// list added.
+func pageArgs(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	if args == nil {
+		args = graphql.FieldConfigArgument{}
+	}
+	args["limit"] = &graphql.ArgumentConfig{
+		Type:         graphql.Int,
+		DefaultValue: defaultPageSize,
+		Description:  fmt.Sprintf("How many items to return, at most %d.", maxPageSize),
+	}
+	args["offset"] = &graphql.ArgumentConfig{
+		Type:         graphql.Int,
+		DefaultValue: 0,
+		Description:  "How many items to skip.",
+	}
+	return args
+}
+
+// page reads the limit and offset arguments of a paginated list.
+func page(args map[string]any) (limit, offset int, err error) {
+	limit, _ = args["limit"].(int)
+	offset, _ = args["offset"].(int)
+	problems := make(map[string]string)
+	if limit < 1 || limit > maxPageSize {
+		problems["limit"] = fmt.Sprintf("limit must be between 1 and %d", maxPageSize)
+	}
+	if offset < 0 {
+		problems["offset"] = "offset must not be negative"
+	}
+	if len(problems) > 0 {
+		return 0, 0, invalid(problems)
+	}
+	return limit, offset, nil
+}
+
+// paginate returns the page of items after skipping offset, holding at most
+// limit.
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	return items[:min(limit, len(items))]
+}
@@ -0,0 +1,374 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"testing"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/models"
+)
+
+// stubStore serves users, blogs and comments from memory, counting the
+// reads so tests can check relations are read in batches.
+type stubStore struct {
+	blogs    []models.Blog
+	comments []models.Comment
+
+	userReads, blogLists, commentReads int
+	created                            []models.Blog
+}
+
+func (s *stubStore) ReadUsers(ctx context.Context, ids []int) (map[int]models.User, error) {
+	s.userReads++
+	users := make(map[int]models.User)
+	for _, id := range ids {
+		if id != 404 {
+			users[id] = models.User{ID: uint(id), Name: "user" + strconv.Itoa(id)}
+		}
+	}
+	return users, nil
+}
+
+func (s *stubStore) ListUsersWithFilter(ctx context.Context, name string) ([]models.User, error) {
+	return nil, nil
+}
+
+func (s *stubStore) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	user.ID = 1
+	return user, nil
+}
+
+func (s *stubStore) UpdateUser(ctx context.Context, id uint64, patch models.User) (models.User, error) {
+	if id == 404 {
+		return models.User{}, fmt.Errorf("no user found with id: %d", id)
+	}
+	patch.ID = uint(id)
+	return patch, nil
+}
+
+func (s *stubStore) DeleteUser(ctx context.Context, id uint64) error {
+	if id == 404 {
+		return fmt.Errorf("no user found with id: %d", id)
+	}
+	return nil
+}
+
+func (s *stubStore) DoesUserExist(ctx context.Context, userID int) bool {
+	return userID != 404
+}
+
+func (s *stubStore) GetBlog(ctx context.Context, id uint) (models.Blog, error) {
+	for _, blog := range s.blogs {
+		if blog.ID == id {
+			return blog, nil
+		}
+	}
+	return models.Blog{}, fmt.Errorf("no blog found with id: %d", id)
+}
+
+func (s *stubStore) ListBlogsWithFilter(ctx context.Context, filter models.BlogFilter) ([]models.Blog, error) {
+	s.blogLists++
+	blogs := s.blogs
+	if filter.Offset < len(blogs) {
+		blogs = blogs[filter.Offset:]
+	}
+	if filter.Limit > 0 && filter.Limit < len(blogs) {
+		blogs = blogs[:filter.Limit]
+	}
+	return blogs, nil
+}
+
+func (s *stubStore) CreateBlog(ctx context.Context, blog models.Blog) (models.Blog, error) {
+	s.created = append(s.created, blog)
+	blog.ID = 99
+	return blog, nil
+}
+
+func (s *stubStore) UpdateBlog(ctx context.Context, id uint, blog models.Blog) (models.Blog, error) {
+	return models.Blog{}, errors.New("not implemented")
+}
+
+func (s *stubStore) DeleteBlog(ctx context.Context, id uint) error {
+	return fmt.Errorf("no blog found with id: %d", id)
+}
+
+func (s *stubStore) ListComments(ctx context.Context, authorID, blogID *int) ([]models.Comment, error) {
+	return s.comments, nil
+}
+
+func (s *stubStore) ListCommentsForBlogs(ctx context.Context, blogIDs []int) (map[int][]models.Comment, error) {
+	s.commentReads++
+	byBlog := make(map[int][]models.Comment)
+	for _, comment := range s.comments {
+		byBlog[comment.BlogID] = append(byBlog[comment.BlogID], comment)
+	}
+	return byBlog, nil
+}
+
+func (s *stubStore) CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	return comment, nil
+}
+
+func (s *stubStore) UpdateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	return comment, nil
+}
+
+func (s *stubStore) DeleteComment(ctx context.Context, userID, blogID int) error {
+	return nil
+}
+
+func (s *stubStore) DoesCommentExist(ctx context.Context, userID, blogID int) (bool, error) {
+	return userID == 2 && blogID == 1, nil
+}
+
+// stubScreener flags content containing "spam" and allows the rest,
+// remembering the content recorded once stored.
+type stubScreener struct {
+	recorded []filters.Content
+}
+
+func (*stubScreener) Screen(ctx context.Context, content filters.Content, payload any) (filters.Decision, uint, error) {
+	if content.Text == "spam" {
+		return filters.Decision{Verdict: filters.Flag, Filter: "stub"}, 7, nil
+	}
+	return filters.Decision{Verdict: filters.Allow}, 0, nil
+}
+
+func (s *stubScreener) Record(ctx context.Context, content filters.Content) {
+	s.recorded = append(s.recorded, content)
+}
+
+func newTestSchema(t *testing.T, limits Limits) (*Schema, *stubStore, *stubScreener) {
+	t.Helper()
+	store := &stubStore{
+		blogs: []models.Blog{
+			{ID: 1, Title: "First", AuthorID: 1},
+			{ID: 2, Title: "Second", AuthorID: 1},
+			{ID: 3, Title: "Orphan", AuthorID: 404},
+		},
+		comments: []models.Comment{
+			{UserID: 2, BlogID: 1, Message: "first"},
+			{UserID: 3, BlogID: 1, Message: "second"},
+			{UserID: 2, BlogID: 2, Message: "third"},
+		},
+	}
+	screener := &stubScreener{}
+	schema, err := NewSchema(slog.Default(), store, store, store, screener, []int{9}, limits)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return schema, store, screener
+}
+
+func TestSchemaBatching(t *testing.T) {
+	schema, store, _ := newTestSchema(t, Limits{})
+
+	result, err := schema.Do(context.Background(), Request{
+		Query: `{ blogs(limit: 3) { title author { name } comments { message user { name } } } }`,
+	}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+
+	want := `{"blogs":[` +
+		`{"author":{"name":"user1"},"comments":[{"message":"first","user":{"name":"user2"}},{"message":"second","user":{"name":"user3"}}],"title":"First"},` +
+		`{"author":{"name":"user1"},"comments":[{"message":"third","user":{"name":"user2"}}],"title":"Second"},` +
+		`{"author":null,"comments":[],"title":"Orphan"}]}`
+	got, _ := json.Marshal(result.Data)
+	if string(got) != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+
+	// The authors are read together, and the users of every comment with
+	// them or after them, depending on the order the fields of a blog are
+	// resolved in
+	if store.blogLists != 1 || store.commentReads != 1 || store.userReads > 2 {
+		t.Errorf("want 1 blog list, 1 comment read and at most 2 user reads, got %d, %d and %d",
+			store.blogLists, store.commentReads, store.userReads)
+	}
+}
+
+func TestSchemaLimits(t *testing.T) {
+	tests := map[string]struct {
+		query     string
+		variables map[string]any
+		wantErr   bool
+	}{
+		"within limits": {
+			query: `{ blogs(limit: 5) { title comments(limit: 5) { message } } }`,
+		},
+		"too deep": {
+			query:   `{ blogs { comments { blog { comments { blog { title } } } } } }`,
+			wantErr: true,
+		},
+		"too deep through a fragment": {
+			query:   `{ blogs { ...deep } } fragment deep on Blog { comments { blog { comments { blog { title } } } } }`,
+			wantErr: true,
+		},
+		"too complex": {
+			query:   `{ blogs(limit: 100) { title comments(limit: 100) { message } } }`,
+			wantErr: true,
+		},
+		"too complex through variables": {
+			query:     `query($n: Int) { blogs(limit: $n) { title comments(limit: $n) { message } } }`,
+			variables: map[string]any{"n": float64(100)},
+			wantErr:   true,
+		},
+		"introspection is free": {
+			query: `{ __schema { types { name fields { name type { name ofType { name } } } } } }`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			schema, _, _ := newTestSchema(t, Limits{MaxDepth: 4, MaxComplexity: 1000})
+
+			_, err := schema.Do(context.Background(), Request{Query: tc.query, Variables: tc.variables}, true)
+			var reqErr *RequestError
+			if gotErr := errors.As(err, &reqErr); gotErr != tc.wantErr {
+				t.Errorf("want a request error %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSchemaMutations(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		userID   int
+		readOnly bool
+		wantErr  error
+		wantCode string
+		want     string
+		// wantRecorded is the user whose content should be recorded in the
+		// filters' history, if any
+		wantRecorded int
+	}{
+		"create blog": {
+			query:        `mutation { createBlog(input: {title: "New", authorId: 1, tags: ["Go"]}) { id title tags } }`,
+			userID:       1,
+			want:         `{"createBlog":{"id":99,"tags":["Go"],"title":"New"}}`,
+			wantRecorded: 1,
+		},
+		"create blog anonymously": {
+			query:    `mutation { createBlog(input: {title: "New", authorId: 1}) { id } }`,
+			wantCode: codeUnauthenticated,
+		},
+		"create blog as another user": {
+			query:    `mutation { createBlog(input: {title: "New", authorId: 1}) { id } }`,
+			userID:   9,
+			wantCode: codeForbidden,
+		},
+		"invalid blog": {
+			query:    `mutation { createBlog(input: {title: " ", authorId: 1}) { id } }`,
+			userID:   1,
+			wantCode: codeBadUserInput,
+		},
+		"unknown author": {
+			query:    `mutation { createBlog(input: {title: "New", authorId: 404}) { id } }`,
+			userID:   404,
+			wantCode: codeBadUserInput,
+		},
+		"held for moderation": {
+			query:    `mutation { createBlog(input: {title: "spam", authorId: 1}) { id } }`,
+			userID:   1,
+			wantCode: codePendingModeration,
+		},
+		"update another user's blog": {
+			query:    `mutation { updateBlog(id: 1, input: {title: "Mine", authorId: 2}) { id } }`,
+			userID:   2,
+			wantCode: codeForbidden,
+		},
+		"create comment": {
+			query:        `mutation { createComment(input: {userId: 3, blogId: 2, message: "hi"}) { message } }`,
+			userID:       3,
+			want:         `{"createComment":{"message":"hi"}}`,
+			wantRecorded: 3,
+		},
+		"duplicate comment": {
+			query:    `mutation { createComment(input: {userId: 2, blogId: 1, message: "again"}) { message } }`,
+			userID:   2,
+			wantCode: codeConflict,
+		},
+		"missing blog": {
+			query:    `mutation { deleteBlog(id: 5) }`,
+			wantCode: codeNotFound,
+		},
+		"read only": {
+			query:    `mutation { deleteBlog(id: 5) }`,
+			readOnly: true,
+			wantErr:  ErrReadOnly,
+		},
+		"update self": {
+			query:  `mutation { updateUser(id: 2, input: {name: "Two", email: "two@example.com", password: "secret1"}) { id name } }`,
+			userID: 2,
+			want:   `{"updateUser":{"id":2,"name":"Two"}}`,
+		},
+		"update user anonymously": {
+			query:    `mutation { updateUser(id: 2, input: {name: "Two", email: "two@example.com", password: "secret1"}) { id } }`,
+			wantCode: codeUnauthenticated,
+		},
+		"update another user": {
+			query:    `mutation { updateUser(id: 2, input: {name: "Two", email: "two@example.com", password: "secret1"}) { id } }`,
+			userID:   3,
+			wantCode: codeForbidden,
+		},
+		"delete user as admin": {
+			query:  `mutation { deleteUser(id: 2) }`,
+			userID: 9,
+			want:   `{"deleteUser":true}`,
+		},
+		"delete another user": {
+			query:    `mutation { deleteUser(id: 2) }`,
+			userID:   3,
+			wantCode: codeForbidden,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			schema, _, screener := newTestSchema(t, Limits{})
+
+			ctx := context.Background()
+			if tc.userID != 0 {
+				ctx = auth.WithUserID(ctx, tc.userID)
+			}
+			result, err := schema.Do(ctx, Request{Query: tc.query}, tc.readOnly)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if tc.wantCode != "" {
+				if len(result.Errors) != 1 {
+					t.Fatalf("want 1 error, got %v", result.Errors)
+				}
+				if code := result.Errors[0].Extensions["code"]; code != tc.wantCode {
+					t.Errorf("want code %s, got %v", tc.wantCode, code)
+				}
+				return
+			}
+			if len(result.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", result.Errors)
+			}
+			got, _ := json.Marshal(result.Data)
+			if string(got) != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+			if tc.wantRecorded != 0 && (len(screener.recorded) != 1 || screener.recorded[0].UserID != tc.wantRecorded) {
+				t.Errorf("want content of user %d recorded, got %v", tc.wantRecorded, screener.recorded)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/models"
+)
+
+/*
+POST	http://localhost:8000/api/auth/token
+Exchange an email and password for a bearer token. Send it as
+"Authorization: Bearer <token>" on endpoints acting on behalf of a user.
+*/
+
+// userAuthenticator represents a type capable of checking a user's
+// credentials.
+type userAuthenticator interface {
+	Authenticate(ctx context.Context, email, password string) (models.User, error)
+}
+
+// tokenIssuer represents a type capable of issuing a bearer token for a user.
+type tokenIssuer interface {
+	Issue(userID int) (string, time.Time)
+}
+
+// tokenResponse represents the response for a successful login.
+type tokenResponse struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UserID    uint      `json:"user_id"`
+}
+
+// currentUser returns the id of the authenticated user making the request. If
+// the request is anonymous a 401 is written and false is returned.
+func currentUser(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, ok := auth.UserID(r.Context())
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+	}
+	return id, ok
+}
+
+// authorizeAuthor checks that the request is authenticated as authorID, the
+// user a request names as the author of a comment or blog, and returns the
+// authenticated user's id. Anonymous requests get a 401 and other users a
+// 403, and false is returned.
+func authorizeAuthor(w http.ResponseWriter, r *http.Request, authorID int) (int, bool) {
+	id, ok := currentUser(w, r)
+	if !ok {
+		return 0, false
+	}
+	if id != authorID {
+		http.Error(w, "Cannot act as another user", http.StatusForbidden)
+		return 0, false
+	}
+	return id, true
+}
+
+// RequireAdmin only lets the users in admins through to h. Anonymous
+// requests get a 401 and other users a 403.
+func RequireAdmin(admins []int, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+		if !slices.Contains(admins, id) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// RequireSelfOrAdmin only lets the user whose id is the {id} path value, or
+// one of admins, through to h. Anonymous requests get a 401 and other users a
+// 403.
+func RequireSelfOrAdmin(admins []int, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			return
+		}
+		switch err := auth.ActAs(r.Context(), id, admins); {
+		case errors.Is(err, auth.ErrUnauthenticated):
+			currentUser(w, r)
+			return
+		case errors.Is(err, auth.ErrForbidden):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// @Summary		Create Token
+// @Description	Exchange an email and password for a bearer token
+// @Tags			auth
+// @Accept			json
+// @Produce		json
+// @Param			credentials	body		models.Credentials	true	"Credentials"
+// @Success		200			{object}	tokenResponse
+// @Failure		400			{object}	string
+// @Failure		401			{object}	string
+// @Failure		500			{object}	string
+// @Router			/auth/token [post]
+func HandleCreateToken(logger *slog.Logger, authenticator userAuthenticator, issuer tokenIssuer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		creds, problems, err := decodeValid[models.Credentials](r)
+		if err != nil && len(problems) == 0 {
+			writeDecodeError(r.Context(), logger, w, err)
+			return
+		}
+
+		if len(problems) > 0 {
+			w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+			w.WriteHeader(http.StatusBadRequest)
+			if err := formatFrom(ctx).Encode(w, problems); err != nil {
+				logger.ErrorContext(ctx, "failed to encode validation problems", slog.String("error", err.Error()))
+			}
+			return
+		}
+
+		user, err := authenticator.Authenticate(ctx, creds.Email, creds.Password)
+		if err != nil {
+			if strings.Contains(err.Error(), "invalid email or password") {
+				http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+				return
+			}
+			logger.ErrorContext(ctx, "failed to authenticate user", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		token, expires := issuer.Issue(int(user.ID))
+
+		logger.InfoContext(ctx, "token issued", slog.Uint64("user_id", uint64(user.ID)))
+
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		w.Header().Set("Cache-Control", "no-store")
+		if err := formatFrom(ctx).Encode(w, tokenResponse{
+			Token:     token,
+			TokenType: "Bearer",
+			ExpiresAt: expires,
+			UserID:    user.ID,
+		}); err != nil {
+			logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
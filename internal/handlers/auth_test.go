@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/navid/blog/internal/auth"
+)
+
+func TestAuthorizeAuthor(t *testing.T) {
+	testcases := map[string]struct {
+		ctx            context.Context
+		authorID       int
+		expectedOK     bool
+		expectedStatus int
+	}{
+		"the author": {
+			ctx:            auth.WithUserID(context.Background(), 3),
+			authorID:       3,
+			expectedOK:     true,
+			expectedStatus: http.StatusOK,
+		},
+		"anonymous": {
+			ctx:            context.Background(),
+			authorID:       3,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"another user": {
+			ctx:            auth.WithUserID(context.Background(), 4),
+			authorID:       3,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/comments", nil).WithContext(tc.ctx)
+			w := httptest.NewRecorder()
+
+			id, ok := authorizeAuthor(w, r, tc.authorID)
+			if ok != tc.expectedOK {
+				t.Fatalf("expected ok %v, got %v", tc.expectedOK, ok)
+			}
+			if ok && id != tc.authorID {
+				t.Errorf("expected user id %d, got %d", tc.authorID, id)
+			}
+			if w.Code != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
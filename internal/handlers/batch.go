@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/database"
+	"github.com/navid/blog/internal/models"
+	"github.com/navid/blog/internal/render"
+)
+
+/*
+POST	http://localhost:8000/api/batch
+Runs a list of API requests in order, optionally in one transaction.
+*/
+
+// txBeginner represents a type capable of beginning database transactions.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// @Summary		Batch
+// @Description	Run a list of API requests in order, as the caller, and report the status, headers and body of each. Each counts against the rate limit of its own route. An atomic batch runs them in one transaction, stopping at the first that fails, and only commits if all succeed. An operation can use the result of an earlier one with a reference like ${blog.body.id} in its path or body. Batches can't be nested and can't include imports.
+// @Tags			batch
+// @Accept			json
+// @Produce		json
+// @Param			batch	body		models.BatchRequest	true	"Operations"
+// @Success		200		{object}	models.BatchResponse
+// @Failure		400		{object}	string
+// @Failure		500		{object}	string
+// @Router			/batch [post]
+//
+// Operations are served by mux, which should apply the middleware a request
+// of their own would go through on the way to its route, such as rate limits.
+func HandleBatch(logger *slog.Logger, mux http.Handler, db txBeginner) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		batch, ok := decodeValidOrWrite[models.BatchRequest](logger, w, r)
+		if !ok {
+			return
+		}
+
+		runCtx := ctx
+		var tx *sql.Tx
+		if batch.Atomic {
+			var err error
+			tx, err = db.BeginTx(ctx, nil)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to begin batch transaction", slog.String("error", err.Error()))
+				http.Error(w, "Failed to run batch", http.StatusInternalServerError)
+				return
+			}
+			defer tx.Rollback()
+			runCtx = database.WithTx(ctx, tx)
+		}
+
+		results := make([]models.BatchResult, len(batch.Operations))
+		byID := make(map[string]*models.BatchResult)
+		failed := false
+		for i, op := range batch.Operations {
+			result := &results[i]
+			result.ID = op.ID
+			if op.ID != "" {
+				byID[op.ID] = result
+			}
+
+			if failed {
+				result.Status = http.StatusFailedDependency
+				result.Body = "Not run: an earlier operation failed"
+				continue
+			}
+
+			req, err := newBatchRequest(runCtx, r, op, byID)
+			if err != nil {
+				result.Status = http.StatusFailedDependency
+				result.Body = "Not run: " + err.Error()
+			} else {
+				result.Status, result.Headers, result.Body = serveBatchOperation(mux, req)
+			}
+			if batch.Atomic && result.Status >= http.StatusBadRequest {
+				failed = true
+			}
+		}
+
+		response := models.BatchResponse{Results: results}
+		if batch.Atomic {
+			if !failed {
+				if err := tx.Commit(); err != nil {
+					logger.ErrorContext(ctx, "failed to commit batch transaction", slog.String("error", err.Error()))
+					http.Error(w, "Failed to run batch", http.StatusInternalServerError)
+					return
+				}
+			}
+			committed := !failed
+			response.Committed = &committed
+		}
+
+		logger.InfoContext(ctx, "batch run",
+			slog.Int("operations", len(batch.Operations)),
+			slog.Bool("atomic", batch.Atomic),
+			slog.Bool("failed", failed))
+		writeResponse(ctx, logger, w, http.StatusOK, response)
+	})
+}
+
+// newBatchRequest builds the request of op, made by the same client as
+// parent, with the references in its path and body replaced from results.
+func newBatchRequest(ctx context.Context, parent *http.Request, op models.BatchOperation, results map[string]*models.BatchResult) (*http.Request, error) {
+	path, err := expandReferences(op.Path, results, url.PathEscape)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if len(op.Body) > 0 && string(op.Body) != "null" {
+		dec := json.NewDecoder(bytes.NewReader(op.Body))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		if v, err = expandValue(v, results); err != nil {
+			return nil, err
+		}
+		if body, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	// Operations render as JSON whatever the batch is rendered as
+	ctx = context.WithValue(ctx, formatKey{}, render.JSON)
+	req, err := http.NewRequestWithContext(ctx, op.Method, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Host = parent.Host
+	req.RemoteAddr = parent.RemoteAddr
+	// Rate limits and logs see the operation as made by the same client
+	for _, name := range []string{"X-Forwarded-For", "User-Agent"} {
+		if values := parent.Header.Values(name); len(values) > 0 {
+			req.Header[name] = values
+		}
+	}
+	req.Header.Set("Accept", render.JSON.MediaType())
+	if body != nil {
+		req.Header.Set("Content-Type", render.JSON.MediaType())
+	}
+	return req, nil
+}
+
+// expandValue replaces the references in the strings of v, a decoded JSON
+// value. A string that is nothing but a reference becomes the value referred
+// to.
+func expandValue(v any, results map[string]*models.BatchResult) (any, error) {
+	switch v := v.(type) {
+	case string:
+		if ref := models.BatchReference.FindStringSubmatch(v); ref != nil && ref[0] == v {
+			return resolveReference(ref, results)
+		}
+		return expandReferences(v, results, func(s string) string { return s })
+	case []any:
+		for i := range v {
+			var err error
+			if v[i], err = expandValue(v[i], results); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]any:
+		for k := range v {
+			var err error
+			if v[k], err = expandValue(v[k], results); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+// expandReferences replaces every reference in s with the value referred to,
+// as text escaped by escape.
+func expandReferences(s string, results map[string]*models.BatchResult, escape func(string) string) (string, error) {
+	var err error
+	expanded := models.BatchReference.ReplaceAllStringFunc(s, func(match string) string {
+		v, resolveErr := resolveReference(models.BatchReference.FindStringSubmatch(match), results)
+		if resolveErr != nil {
+			err = resolveErr
+			return ""
+		}
+		if text, ok := v.(string); ok {
+			return escape(text)
+		}
+		b, _ := json.Marshal(v)
+		return escape(string(b))
+	})
+	return expanded, err
+}
+
+// resolveReference returns the value a reference matched by
+// models.BatchReference refers to, which must be in the result of an
+// operation that succeeded.
+func resolveReference(ref []string, results map[string]*models.BatchResult) (any, error) {
+	result, ok := results[ref[1]]
+	if !ok || result.Status == 0 {
+		return nil, fmt.Errorf("%s refers to an operation that hasn't run", ref[0])
+	}
+	if result.Status >= http.StatusBadRequest {
+		return nil, fmt.Errorf("operation %q failed", ref[1])
+	}
+
+	// Walk the result as it is rendered, so references follow its JSON
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	for _, key := range strings.Split(strings.TrimPrefix(ref[2], "."), ".") {
+		if key == "" {
+			break
+		}
+		switch node := v.(type) {
+		case map[string]any:
+			v, ok = node[key]
+		case []any:
+			var i int
+			i, err = strconv.Atoi(key)
+			ok = err == nil && i >= 0 && i < len(node)
+			if ok {
+				v = node[i]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s isn't in the result of operation %q", ref[0], ref[1])
+		}
+	}
+	return v, nil
+}
+
+// batchResultHeaders are the headers of an operation's response reported in
+// its result.
+var batchResultHeaders = []string{
+	"Location", "ETag", "Deprecation", "Sunset", "Link",
+	"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After",
+}
+
+// serveBatchOperation serves req on mux, returning the status, headers and
+// body of the response.
+func serveBatchOperation(mux http.Handler, req *http.Request) (int, map[string]string, any) {
+	rec := &batchRecorder{header: make(http.Header)}
+	mux.ServeHTTP(rec, req)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+
+	var headers map[string]string
+	for _, name := range batchResultHeaders {
+		if values := rec.header.Values(name); len(values) > 0 {
+			if headers == nil {
+				headers = make(map[string]string)
+			}
+			headers[name] = strings.Join(values, ", ")
+		}
+	}
+
+	body := bytes.TrimSpace(rec.body.Bytes())
+	if len(body) == 0 {
+		return rec.status, headers, nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(rec.header.Get("Content-Type")); mediaType == render.JSON.MediaType() && json.Valid(body) {
+		return rec.status, headers, json.RawMessage(body)
+	}
+	return rec.status, headers, string(body)
+}
+
+// batchRecorder is the http.ResponseWriter an operation of a batch writes
+// its response to.
+type batchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *batchRecorder) Header() http.Header { return b.header }
+
+func (b *batchRecorder) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *batchRecorder) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
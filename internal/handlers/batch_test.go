@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/middleware"
+	"github.com/navid/blog/internal/ratelimit"
+)
+
+// batchMux is a mux with a few routes for batches to run: creating a blog,
+// reading one back with the caller's id, and one that always fails.
+func batchMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/blog", func(w http.ResponseWriter, r *http.Request) {
+		var blog struct {
+			Title string `json:"title"`
+		}
+		if err := decodeBody(r, &blog); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeResponse(r.Context(), slog.Default(), w, http.StatusCreated, map[string]any{"id": 7, "title": blog.Title})
+	})
+	mux.HandleFunc("GET /api/blog/{id}", func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := auth.UserID(r.Context())
+		writeResponse(r.Context(), slog.Default(), w, http.StatusOK, map[string]any{"id": r.PathValue("id"), "user_id": userID})
+	})
+	mux.HandleFunc("DELETE /api/blog/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Blog not found", http.StatusNotFound)
+	})
+	return mux
+}
+
+func TestHandleBatch(t *testing.T) {
+	committed, rolledBack := true, false
+	tests := map[string]struct {
+		body          string
+		wantStatus    int
+		wantStatuses  []int
+		wantBodies    []string
+		wantCommitted *bool
+	}{
+		"references": {
+			body: `{"operations": [
+				{"id": "blog", "method": "POST", "path": "/api/blog", "body": {"title": "First"}},
+				{"method": "GET", "path": "/api/blog/${blog.body.id}"},
+				{"method": "POST", "path": "/api/blog", "body": {"title": "Re: ${blog.body.title}"}}
+			]}`,
+			wantStatus:   http.StatusOK,
+			wantStatuses: []int{http.StatusCreated, http.StatusOK, http.StatusCreated},
+			wantBodies:   []string{`{"id":7,"title":"First"}`, `{"id":"7","user_id":1}`, `{"id":7,"title":"Re: First"}`},
+		},
+		"unknown reference": {
+			body: `{"operations": [
+				{"id": "gone", "method": "DELETE", "path": "/api/blog/1"},
+				{"method": "GET", "path": "/api/blog/${gone.body.id}"},
+				{"method": "POST", "path": "/api/blog", "body": {"title": "${missing.body}"}}
+			]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"best effort": {
+			body: `{"operations": [
+				{"id": "gone", "method": "DELETE", "path": "/api/blog/1"},
+				{"method": "GET", "path": "/api/blog/${gone.status}"},
+				{"method": "POST", "path": "/api/blog", "body": {"title": "Second"}}
+			]}`,
+			wantStatus:   http.StatusOK,
+			wantStatuses: []int{http.StatusNotFound, http.StatusFailedDependency, http.StatusCreated},
+			wantBodies:   []string{`"Blog not found"`, `"Not run: operation \"gone\" failed"`, `{"id":7,"title":"Second"}`},
+		},
+		"atomic": {
+			body: `{"atomic": true, "operations": [
+				{"id": "blog", "method": "POST", "path": "/api/blog", "body": {"title": "First"}},
+				{"method": "GET", "path": "/api/blog/${blog.body.id}"}
+			]}`,
+			wantStatus:    http.StatusOK,
+			wantStatuses:  []int{http.StatusCreated, http.StatusOK},
+			wantCommitted: &committed,
+		},
+		"atomic failure": {
+			body: `{"atomic": true, "operations": [
+				{"method": "POST", "path": "/api/blog", "body": {"title": "First"}},
+				{"method": "DELETE", "path": "/api/blog/1"},
+				{"method": "POST", "path": "/api/blog", "body": {"title": "Second"}}
+			]}`,
+			wantStatus:    http.StatusOK,
+			wantStatuses:  []int{http.StatusCreated, http.StatusNotFound, http.StatusFailedDependency},
+			wantCommitted: &rolledBack,
+		},
+		"nested batch": {
+			body:       `{"operations": [{"method": "POST", "path": "/api/batch?x=1"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"nested batch in a version": {
+			body:       `{"operations": [{"method": "POST", "path": "/api/v2/batch"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"import": {
+			body:       `{"atomic": true, "operations": [{"method": "POST", "path": "/api/admin/./import?kind=users"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"import in a version": {
+			body:       `{"operations": [{"method": "POST", "path": "/api/v1/admin/import?kind=users"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"not an API route": {
+			body:       `{"operations": [{"method": "GET", "path": "/blog/1"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"duplicate ids": {
+			body:       `{"operations": [{"id": "a", "method": "GET", "path": "/api/blog/1"}, {"id": "a", "method": "GET", "path": "/api/blog/2"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"no operations": {
+			body:       `{"operations": []}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+			if tc.wantCommitted != nil {
+				mock.ExpectBegin()
+				if *tc.wantCommitted {
+					mock.ExpectCommit()
+				} else {
+					mock.ExpectRollback()
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader(tc.body))
+			req = req.WithContext(auth.WithUserID(req.Context(), 1))
+			rec := httptest.NewRecorder()
+
+			HandleBatch(slog.Default(), batchMux(), db).ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body.String())
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Committed *bool `json:"committed"`
+				Results   []struct {
+					Status int             `json:"status"`
+					Body   json.RawMessage `json:"body"`
+				} `json:"results"`
+			}
+			body, _ := io.ReadAll(rec.Body)
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatalf("want a batch response, got %q", body)
+			}
+			if len(response.Results) != len(tc.wantStatuses) {
+				t.Fatalf("want %d results, got %s", len(tc.wantStatuses), body)
+			}
+			for i, result := range response.Results {
+				if result.Status != tc.wantStatuses[i] {
+					t.Errorf("want operation %d status %d, got %d", i, tc.wantStatuses[i], result.Status)
+				}
+				if tc.wantBodies != nil && string(result.Body) != tc.wantBodies[i] {
+					t.Errorf("want operation %d body %s, got %s", i, tc.wantBodies[i], result.Body)
+				}
+			}
+			if (response.Committed == nil) != (tc.wantCommitted == nil) ||
+				(response.Committed != nil && *response.Committed != *tc.wantCommitted) {
+				t.Errorf("want committed %v, got %v", tc.wantCommitted, response.Committed)
+			}
+		})
+	}
+}
+
+// TestHandleBatchRateLimit checks that each operation of a batch is charged
+// against the limit of its own route, so a batch can't exceed it.
+func TestHandleBatchRateLimit(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	mux := batchMux()
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+		"POST /api/blog": {Requests: 2, Period: time.Minute},
+	})
+	h := HandleBatch(logger, middleware.RateLimit(logger, limiter, mux, nil)(mux), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader(`{"operations": [
+		{"method": "POST", "path": "/api/blog", "body": {"title": "First"}},
+		{"method": "POST", "path": "/api/blog", "body": {"title": "Second"}},
+		{"method": "POST", "path": "/api/blog", "body": {"title": "Third"}},
+		{"method": "GET", "path": "/api/blog/7"}
+	]}`))
+	req = req.WithContext(auth.WithUserID(req.Context(), 1))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Results []struct {
+			Status  int               `json:"status"`
+			Headers map[string]string `json:"headers"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("want a batch response, got error %v", err)
+	}
+
+	wantStatuses := []int{http.StatusCreated, http.StatusCreated, http.StatusTooManyRequests, http.StatusOK}
+	if len(response.Results) != len(wantStatuses) {
+		t.Fatalf("want %d results, got %d", len(wantStatuses), len(response.Results))
+	}
+	for i, result := range response.Results {
+		if result.Status != wantStatuses[i] {
+			t.Errorf("want operation %d status %d, got %d", i, wantStatuses[i], result.Status)
+		}
+	}
+	if got := response.Results[2].Headers["Retry-After"]; got == "" {
+		t.Errorf("want the refused operation to report Retry-After, got headers %v", response.Results[2].Headers)
+	}
+}
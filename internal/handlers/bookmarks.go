@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/models"
+)
+
+/*
+GET	http://localhost:8000/api/me/bookmarks
+PUT	http://localhost:8000/api/me/bookmarks/{blog_id}
+DELETE	http://localhost:8000/api/me/bookmarks/{blog_id}
+Manage the authenticated user's bookmarks.
+*/
+
+// bookmarkService represents a type capable of managing a user's bookmarks.
+type bookmarkService interface {
+	ListBookmarks(ctx context.Context, userID int) ([]models.Bookmark, error)
+	SaveBookmark(ctx context.Context, bookmark models.Bookmark) (models.Bookmark, error)
+	DeleteBookmark(ctx context.Context, userID, blogID int) error
+}
+
+// @Summary		List Bookmarks
+// @Description	List the authenticated user's bookmarks, most recent first
+// @Tags			me
+// @Produce		json
+// @Security		BearerAuth
+// @Success		200	{array}		models.Bookmark
+// @Failure		401	{object}	string
+// @Failure		500	{object}	string
+// @Router			/me/bookmarks [get]
+func HandleListBookmarks(logger *slog.Logger, bookmarks bookmarkService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		list, err := bookmarks.ListBookmarks(ctx, userID)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to list bookmarks", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		writeResponse(ctx, logger, w, http.StatusOK, list)
+	})
+}
+
+// @Summary		Save Bookmark
+// @Description	Bookmark a blog for the authenticated user, or update the note on an existing bookmark
+// @Tags			me
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @Param			blog_id		path		string			true	"Blog ID"
+// @Param			bookmark	body		models.Bookmark	false	"Bookmark; only note is read"
+// @Success		200			{object}	models.Bookmark
+// @Failure		400			{object}	string
+// @Failure		401			{object}	string
+// @Failure		404			{object}	string
+// @Failure		500			{object}	string
+// @Router			/me/bookmarks/{blog_id} [put]
+func HandleSaveBookmark(logger *slog.Logger, bookmarks bookmarkService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		blogID, err := strconv.Atoi(r.PathValue("blog_id"))
+		if err != nil {
+			http.Error(w, "Invalid blog_id", http.StatusBadRequest)
+			return
+		}
+
+		// The body is optional; a bookmark without a note is fine
+		var bookmark models.Bookmark
+		if r.ContentLength != 0 {
+			if bookmark, ok = decodeValidOrWrite[models.Bookmark](logger, w, r); !ok {
+				return
+			}
+		}
+		bookmark.UserID = userID
+		bookmark.BlogID = blogID
+
+		saved, err := bookmarks.SaveBookmark(ctx, bookmark)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to save bookmark", slog.String("error", err.Error()))
+			if strings.Contains(err.Error(), "no blog found") {
+				http.Error(w, "Blog not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to save bookmark", http.StatusInternalServerError)
+			return
+		}
+
+		writeResponse(ctx, logger, w, http.StatusOK, saved)
+	})
+}
+
+// @Summary		Delete Bookmark
+// @Description	Remove a blog from the authenticated user's bookmarks
+// @Tags			me
+// @Security		BearerAuth
+// @Param			blog_id	path		string	true	"Blog ID"
+// @Success		204		{object}	nil
+// @Failure		400		{object}	string
+// @Failure		401		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/me/bookmarks/{blog_id} [delete]
+func HandleDeleteBookmark(logger *slog.Logger, bookmarks bookmarkService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		blogID, err := strconv.Atoi(r.PathValue("blog_id"))
+		if err != nil {
+			http.Error(w, "Invalid blog_id", http.StatusBadRequest)
+			return
+		}
+
+		if err := bookmarks.DeleteBookmark(ctx, userID, blogID); err != nil {
+			logger.ErrorContext(ctx, "failed to delete bookmark", slog.String("error", err.Error()))
+			if strings.Contains(err.Error(), "no bookmark found") {
+				http.Error(w, "Bookmark not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to delete bookmark", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -1,25 +1,25 @@
 package handlers
 
 import (
-	"encoding/json"
 	"log/slog"
 	"net/http"
 
+	"github.com/navid/blog/internal/filters"
 	"github.com/navid/blog/internal/models"
 	"github.com/navid/blog/internal/services"
 )
 
-func HandleCreateBlog(logger *slog.Logger, blogsService *services.BlogService, usersService *services.UsersService) http.HandlerFunc {
+func HandleCreateBlog(logger *slog.Logger, blogsService *services.BlogService, usersService *services.UsersService, screener contentScreener) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var blog models.Blog
-		if err := json.NewDecoder(r.Body).Decode(&blog); err != nil {
-			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		// Decode and validate the blog
+		blog, ok := decodeValidOrWrite[models.Blog](logger, w, r)
+		if !ok {
 			return
 		}
 
-		// Validate the blog object
-		if blog.Title == "" || blog.Score <= 0 {
-			http.Error(w, "Invalid blog data: title and score are required", http.StatusBadRequest)
+		// Only the authenticated user can post as themselves
+		userID, ok := authorizeAuthor(w, r, blog.AuthorID)
+		if !ok {
 			return
 		}
 
@@ -29,17 +29,36 @@ func HandleCreateBlog(logger *slog.Logger, blogsService *services.BlogService, u
 			return
 		}
 
+		// Run the blog through the content filters
+		content := filters.Content{
+			Kind:   filters.KindBlog,
+			UserID: userID,
+			Text:   blog.Text(),
+		}
+		decision, moderationID, err := screener.Screen(r.Context(), content, blog)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "failed to screen blog", slog.String("error", err.Error()))
+			http.Error(w, "Failed to validate blog", http.StatusInternalServerError)
+			return
+		}
+		if writeScreened(r.Context(), w, decision, moderationID) {
+			return
+		}
+
 		// Create the blog
 		createdBlog, err := blogsService.CreateBlog(r.Context(), blog)
 		if err != nil {
-			logger.Error("Failed to create blog", slog.String("error", err.Error()))
+			logger.ErrorContext(r.Context(), "Failed to create blog", slog.String("error", err.Error()))
 			http.Error(w, "Failed to create blog", http.StatusInternalServerError)
 			return
 		}
 
+		// Remember the stored blog in the filters' history
+		screener.Record(r.Context(), content)
+
 		// Respond with the created blog
-		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Type", formatFrom(r.Context()).MediaType())
 		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(createdBlog)
+		formatFrom(r.Context()).Encode(w, createdBlog)
 	}
 }
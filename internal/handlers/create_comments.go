@@ -1,31 +1,28 @@
 package handlers
 
 import (
-    "encoding/json"
     "log/slog"
     "net/http"
 
+    "github.com/navid/blog/internal/filters"
     "github.com/navid/blog/internal/models"
     "github.com/navid/blog/internal/services"
 )
 
 // HandleCreateComment handles the creation of a new comment.
-func HandleCreateComment(logger *slog.Logger, commentsService *services.CommentsService, usersService *services.UsersService, blogsService *services.BlogService) http.HandlerFunc {
+func HandleCreateComment(logger *slog.Logger, commentsService *services.CommentsService, usersService *services.UsersService, blogsService *services.BlogService, screener contentScreener) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         ctx := r.Context()
 
         // Decode and validate the comment object
-        var comment models.Comment
-        if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
-            http.Error(w, "Invalid request payload", http.StatusBadRequest)
+        comment, ok := decodeValidOrWrite[models.Comment](logger, w, r)
+        if !ok {
             return
         }
 
-        // Validate the comment object
-        if problems := comment.Valid(ctx); len(problems) > 0 {
-            w.Header().Set("Content-Type", "application/json")
-            w.WriteHeader(http.StatusBadRequest)
-            json.NewEncoder(w).Encode(problems)
+        // Only the authenticated user can comment as themselves
+        userID, ok := authorizeAuthor(w, r, comment.UserID)
+        if !ok {
             return
         }
 
@@ -54,6 +51,22 @@ func HandleCreateComment(logger *slog.Logger, commentsService *services.Comments
             return
         }
 
+        // Run the comment through the content filters
+        content := filters.Content{
+            Kind:   filters.KindComment,
+            UserID: userID,
+            Text:   comment.Message,
+        }
+        decision, moderationID, err := screener.Screen(ctx, content, comment)
+        if err != nil {
+            logger.ErrorContext(ctx, "failed to screen comment", slog.String("error", err.Error()))
+            http.Error(w, "Failed to validate comment", http.StatusInternalServerError)
+            return
+        }
+        if writeScreened(ctx, w, decision, moderationID) {
+            return
+        }
+
         // Create the comment
         createdComment, err := commentsService.CreateComment(ctx, comment)
         if err != nil {
@@ -62,9 +75,12 @@ func HandleCreateComment(logger *slog.Logger, commentsService *services.Comments
             return
         }
 
+        // Remember the stored comment in the filters' history
+        screener.Record(ctx, content)
+
         // Respond with the created comment
-        w.Header().Set("Content-Type", "application/json")
+        w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
         w.WriteHeader(http.StatusCreated)
-        json.NewEncoder(w).Encode(createdComment)
+        formatFrom(ctx).Encode(w, createdComment)
     }
 }
\ No newline at end of file
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
 	"net/http"
 
@@ -21,24 +20,22 @@ type userCreator interface {
 // @Accept			json
 // @Produce		json
 // @Param			user	body		models.User	true	"User"
-// @Success		201		{object}	models.User
+// @Success		201		{object}	readUserResponse
 // @Failure		400		{object}	string
 // @Failure		500		{object}	string
-// @Router			/users [POST]
-func HandleCreateUser(logger *slog.Logger, userCreator userCreator) http.Handler {
+// @Router			/user [POST]
+func HandleCreateUser(logger *slog.Logger, userCreator userCreator, withPasswordField bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user, problems, err := decodeValid[models.User](r)
-		if err != nil {
-			logger.ErrorContext(r.Context(), "failed to decode request body",
-				slog.String("error", err.Error()))
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err != nil && len(problems) == 0 {
+			writeDecodeError(r.Context(), logger, w, err)
 			return
 		}
 
 		if len(problems) > 0 {
-			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Type", formatFrom(r.Context()).MediaType())
 			w.WriteHeader(http.StatusBadRequest)
-			if err := json.NewEncoder(w).Encode(problems); err != nil {
+			if err := formatFrom(r.Context()).Encode(w, problems); err != nil {
 				logger.ErrorContext(r.Context(), "failed to encode validation problems",
 					slog.String("error", err.Error()))
 				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -58,9 +55,9 @@ func HandleCreateUser(logger *slog.Logger, userCreator userCreator) http.Handler
 		logger.InfoContext(r.Context(), "user created",
 			slog.Uint64("id", uint64(createdUser.ID)))
 
-		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Type", formatFrom(r.Context()).MediaType())
 		w.WriteHeader(http.StatusCreated)
-		if err := json.NewEncoder(w).Encode(createdUser); err != nil {
+		if err := formatFrom(r.Context()).Encode(w, showUser(createdUser, withPasswordField)); err != nil {
 			logger.ErrorContext(r.Context(), "failed to encode response",
 				slog.String("error", err.Error()))
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -4,7 +4,6 @@ import (
     "log/slog"
     "net/http"
     "strconv"
-    "strings"
 
     "github.com/navid/blog/internal/services"
 )
@@ -14,17 +13,17 @@ func HandleDeleteBlog(logger *slog.Logger, blogsService *services.BlogService) h
     return func(w http.ResponseWriter, r *http.Request) {
         ctx := r.Context()
 
-        // Extract the blog ID from the URL path
-        pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-        if len(pathParts) < 3 || pathParts[2] == "" {
+        // Get the blog ID from the path
+        idStr := r.PathValue("id")
+        if idStr == "" {
             http.Error(w, "Blog ID not provided", http.StatusBadRequest)
             return
         }
 
-        id, err := strconv.Atoi(pathParts[2])
+        id, err := strconv.Atoi(idStr)
         if err != nil {
             logger.ErrorContext(ctx, "failed to parse id",
-                slog.String("id", pathParts[2]),
+                slog.String("id", idStr),
                 slog.String("error", err.Error()))
             http.Error(w, "Invalid Blog ID", http.StatusBadRequest)
             return
@@ -15,14 +15,17 @@ type userDeleter interface {
 }
 
 // @Summary		Delete User
-// @Description	Delete a user by ID
+// @Description	Delete a user by ID, as that user or an admin
 // @Tags			user
+// @Security		BearerAuth
 // @Param			id	path		string	true	"User ID"
 // @Success		204	{object}	nil
 // @Failure		400	{object}	string
+// @Failure		401	{object}	string
+// @Failure		403	{object}	string
 // @Failure		404	{object}	string
 // @Failure		500	{object}	string
-// @Router			/users/{id} [DELETE]
+// @Router			/user/{id} [DELETE]
 func HandleDeleteUser(logger *slog.Logger, userDeleter userDeleter) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Get id from path using built-in PathValue
@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/navid/blog/internal/models"
+)
+
+// Relations a request can ask to be embedded in the resources it reads, with
+// include=.
+const (
+	includeAuthor      = "author"
+	includeComments    = "comments"
+	includeCommentUser = "comment.user"
+	includeUser        = "user"
+)
+
+// relationPaths is where each relation is embedded in the JSON of a
+// resource.
+var relationPaths = map[string][]string{
+	includeAuthor:      {"author"},
+	includeComments:    {"comments"},
+	includeCommentUser: {"comments", "user"},
+	includeUser:        {"user"},
+}
+
+// userBatchReader represents a type capable of reading many users from
+// storage at once.
+type userBatchReader interface {
+	ReadUsers(ctx context.Context, ids []int) (map[int]models.User, error)
+}
+
+// commentBatchLister represents a type capable of listing the comments on
+// many blogs from storage at once.
+type commentBatchLister interface {
+	ListCommentsForBlogs(ctx context.Context, blogIDs []int) (map[int][]models.Comment, error)
+}
+
+// fieldSet is the tree of properties kept in a response, parsed from
+// fields=, such as title,author.name. A property mapped to nil is kept whole;
+// a nil fieldSet keeps every property.
+type fieldSet map[string]fieldSet
+
+// add keeps the property at path, whole.
+func (f fieldSet) add(path []string) {
+	for i, key := range path {
+		child, ok := f[key]
+		switch {
+		case i == len(path)-1:
+			f[key] = nil
+			return
+		case ok && child == nil:
+			return
+		case !ok:
+			child = fieldSet{}
+			f[key] = child
+		}
+		f = child
+	}
+}
+
+// keep keeps the property at path, whole unless some of its properties are
+// already listed.
+func (f fieldSet) keep(path []string) {
+	for _, key := range path {
+		child, ok := f[key]
+		if !ok {
+			f[key] = nil
+			return
+		}
+		if child == nil {
+			return
+		}
+		f = child
+	}
+}
+
+// expansion is how a request asks for the resources it reads to be shaped:
+// with the relations in include= embedded, and trimmed to the properties in
+// fields=. Embedded relations are kept whole unless fields= lists some of
+// their properties.
+type expansion struct {
+	fields  fieldSet
+	include map[string]bool
+}
+
+// errInvalidFields is returned for a fields= parameter with an empty
+// property name.
+var errInvalidFields = errors.New("fields must be a comma-separated list of properties, like title,author.name")
+
+// parseExpansion reads the fields= and include= parameters of query,
+// allowing relations to be included.
+func parseExpansion(query url.Values, relations ...string) (expansion, error) {
+	exp := expansion{include: make(map[string]bool)}
+	for _, name := range splitList(query["include"]) {
+		if !slices.Contains(relations, name) {
+			return expansion{}, errors.New("include must be some of " + strings.Join(relations, ", "))
+		}
+		exp.include[name] = true
+	}
+	if exp.include[includeCommentUser] {
+		exp.include[includeComments] = true
+	}
+
+	fields := splitList(query["fields"])
+	if len(fields) == 0 {
+		return exp, nil
+	}
+	exp.fields = fieldSet{}
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+		if slices.Contains(path, "") {
+			return expansion{}, errInvalidFields
+		}
+		exp.fields.add(path)
+	}
+	for name := range exp.include {
+		exp.fields.keep(relationPaths[name])
+	}
+	return exp, nil
+}
+
+// expanded reports whether resources have to be reshaped at all.
+func (e expansion) expanded() bool {
+	return e.fields != nil || len(e.include) > 0
+}
+
+// splitList splits comma-separated query values, dropping blanks.
+func splitList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// expandBlogs embeds the relations exp includes in blogs and trims them to
+// its fields. Each relation is read with one query, however many blogs there
+// are.
+func expandBlogs(ctx context.Context, blogs []models.Blog, exp expansion, users userBatchReader, comments commentBatchLister) ([]any, error) {
+	var blogComments map[int][]models.Comment
+	if exp.include[includeComments] {
+		ids := make([]int, len(blogs))
+		for i, blog := range blogs {
+			ids[i] = int(blog.ID)
+		}
+		var err error
+		if blogComments, err = comments.ListCommentsForBlogs(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	var userIDs []int
+	for _, blog := range blogs {
+		if exp.include[includeAuthor] {
+			userIDs = append(userIDs, blog.AuthorID)
+		}
+		if exp.include[includeCommentUser] {
+			for _, comment := range blogComments[int(blog.ID)] {
+				userIDs = append(userIDs, comment.UserID)
+			}
+		}
+	}
+	authors, err := readAuthors(ctx, users, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := make([]any, len(blogs))
+	for i, blog := range blogs {
+		resource, err := toObject(blog)
+		if err != nil {
+			return nil, err
+		}
+		if exp.include[includeAuthor] {
+			resource["author"] = authors.find(blog.AuthorID)
+		}
+		if exp.include[includeComments] {
+			list := make([]any, 0, len(blogComments[int(blog.ID)]))
+			for _, comment := range blogComments[int(blog.ID)] {
+				c, err := embedCommentUser(comment, exp.include[includeCommentUser], authors)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, c)
+			}
+			resource["comments"] = list
+		}
+		if expanded[i], err = project(resource, exp.fields); err != nil {
+			return nil, err
+		}
+	}
+	return expanded, nil
+}
+
+// expandComments embeds the users of comments if exp includes them, with one
+// query, and trims the comments to its fields.
+func expandComments(ctx context.Context, comments []models.Comment, exp expansion, users userBatchReader) ([]any, error) {
+	var userIDs []int
+	if exp.include[includeUser] {
+		for _, comment := range comments {
+			userIDs = append(userIDs, comment.UserID)
+		}
+	}
+	authors, err := readAuthors(ctx, users, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := make([]any, len(comments))
+	for i, comment := range comments {
+		resource, err := embedCommentUser(comment, exp.include[includeUser], authors)
+		if err != nil {
+			return nil, err
+		}
+		if expanded[i], err = project(resource, exp.fields); err != nil {
+			return nil, err
+		}
+	}
+	return expanded, nil
+}
+
+// expandPageSize is how many resources of a list are collected before the
+// relations of them all are read and they are written.
+const expandPageSize = 100
+
+// pageWriter collects the resources of a list streamed to it and writes them
+// to enc a page at a time, with relations embedded by expand, so each
+// relation is read once a page without holding the whole list.
+type pageWriter[T any] struct {
+	enc    *listEncoder
+	expand func([]T) ([]any, error)
+	page   []T
+}
+
+func newPageWriter[T any](enc *listEncoder, expand func([]T) ([]any, error)) *pageWriter[T] {
+	return &pageWriter[T]{enc: enc, expand: expand, page: make([]T, 0, expandPageSize)}
+}
+
+// add collects v, writing the page if it is full.
+func (p *pageWriter[T]) add(v T) error {
+	p.page = append(p.page, v)
+	if len(p.page) < expandPageSize {
+		return nil
+	}
+	return p.flush()
+}
+
+// flush writes the resources collected so far. It has to be called once the
+// list has been read, for the last page.
+func (p *pageWriter[T]) flush() error {
+	if len(p.page) == 0 {
+		return nil
+	}
+	resources, err := p.expand(p.page)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		if err := p.enc.Encode(resource); err != nil {
+			return err
+		}
+	}
+	p.page = p.page[:0]
+	return nil
+}
+
+// embedCommentUser returns comment as a JSON object, with its user embedded
+// if include is set.
+func embedCommentUser(comment models.Comment, include bool, authors authorSet) (map[string]any, error) {
+	resource, err := toObject(comment)
+	if err != nil {
+		return nil, err
+	}
+	if include {
+		resource["user"] = authors.find(comment.UserID)
+	}
+	return resource, nil
+}
+
+// authorSet holds the users embedded in a response, by id.
+type authorSet map[int]models.Author
+
+// find returns the author with id, or nil if the user no longer exists.
+func (a authorSet) find(id int) any {
+	if author, ok := a[id]; ok {
+		return author
+	}
+	return nil
+}
+
+// readAuthors reads the users with ids, in one query if there are any.
+func readAuthors(ctx context.Context, users userBatchReader, ids []int) (authorSet, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	slices.Sort(ids)
+	found, err := users.ReadUsers(ctx, slices.Compact(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make(authorSet, len(found))
+	for id, user := range found {
+		authors[id] = models.Author{ID: user.ID, Name: user.Name}
+	}
+	return authors, nil
+}
+
+// toObject returns v as a JSON object, to embed relations in.
+func toObject(v any) (map[string]any, error) {
+	tree, err := toTree(v)
+	if err != nil {
+		return nil, err
+	}
+	object, ok := tree.(map[string]any)
+	if !ok {
+		return nil, errors.New("resource isn't a JSON object")
+	}
+	return object, nil
+}
+
+// toTree returns the JSON of v as maps, slices and scalars, keeping numbers
+// exact.
+func toTree(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// project trims v to the properties in fields, applying them to every
+// element of a list. Properties that don't exist are ignored.
+func project(v any, fields fieldSet) (any, error) {
+	if fields == nil {
+		return v, nil
+	}
+	switch v.(type) {
+	case map[string]any, []any:
+	default:
+		tree, err := toTree(v)
+		if err != nil {
+			return nil, err
+		}
+		v = tree
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		trimmed := make(map[string]any, len(fields))
+		for key, sub := range fields {
+			value, ok := v[key]
+			if !ok {
+				continue
+			}
+			var err error
+			if trimmed[key], err = project(value, sub); err != nil {
+				return nil, err
+			}
+		}
+		return trimmed, nil
+	case []any:
+		for i := range v {
+			var err error
+			if v[i], err = project(v[i], fields); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
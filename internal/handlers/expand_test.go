@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/navid/blog/internal/models"
+)
+
+// stubRelations serves users and comments from memory, counting the reads
+// so tests can check relations are read in batches.
+type stubRelations struct {
+	userReads, commentReads int
+}
+
+func (s *stubRelations) ReadUsers(ctx context.Context, ids []int) (map[int]models.User, error) {
+	s.userReads++
+	users := make(map[int]models.User)
+	for _, id := range ids {
+		if id != 404 {
+			users[id] = models.User{ID: uint(id), Name: "user" + strconv.Itoa(id), Email: "secret@example.com", Password: "secret"}
+		}
+	}
+	return users, nil
+}
+
+func (s *stubRelations) ListCommentsForBlogs(ctx context.Context, blogIDs []int) (map[int][]models.Comment, error) {
+	s.commentReads++
+	return map[int][]models.Comment{
+		1: {{UserID: 2, BlogID: 1, Message: "first"}, {UserID: 3, BlogID: 1, Message: "second"}},
+	}, nil
+}
+
+// streamedBlogs streams a fixed list of blogs.
+type streamedBlogs []models.Blog
+
+func (s streamedBlogs) StreamBlogsWithFilter(ctx context.Context, filter models.BlogFilter, fn func(models.Blog) error) error {
+	for _, blog := range s {
+		if err := fn(blog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestHandleListBlogsExpansion(t *testing.T) {
+	blogs := streamedBlogs{
+		{ID: 1, Title: "First", AuthorID: 1},
+		{ID: 2, Title: "Second", AuthorID: 1},
+		{ID: 3, Title: "Orphan", AuthorID: 404},
+	}
+
+	tests := map[string]struct {
+		query            string
+		wantStatus       int
+		want             string
+		wantUserReads    int
+		wantCommentReads int
+	}{
+		"fields": {
+			query:      "fields=id,title",
+			wantStatus: http.StatusOK,
+			want:       `[{"id":1,"title":"First"},{"id":2,"title":"Second"},{"id":3,"title":"Orphan"}]`,
+		},
+		"author": {
+			query:         "include=author&fields=title",
+			wantStatus:    http.StatusOK,
+			want:          `[{"author":{"id":1,"name":"user1"},"title":"First"},{"author":{"id":1,"name":"user1"},"title":"Second"},{"author":null,"title":"Orphan"}]`,
+			wantUserReads: 1,
+		},
+		"comment users": {
+			query:            "include=author,comment.user&fields=id,author.name,comments.message",
+			wantStatus:       http.StatusOK,
+			want:             `[{"author":{"name":"user1"},"comments":[{"message":"first","user":{"id":2,"name":"user2"}},{"message":"second","user":{"id":3,"name":"user3"}}],"id":1},{"author":{"name":"user1"},"comments":[],"id":2},{"author":null,"comments":[],"id":3}]`,
+			wantUserReads:    1,
+			wantCommentReads: 1,
+		},
+		"unknown relation": {
+			query:      "include=ratings",
+			wantStatus: http.StatusBadRequest,
+		},
+		"empty field": {
+			query:      "fields=author..name",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/blog?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			relations := &stubRelations{}
+
+			HandleListBlogs(slog.Default(), blogs, relations, relations).ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			// Compare as trees, as maps are encoded in key order
+			var got, want any
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("want a JSON body, got %q", rec.Body.String())
+			}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatalf("bad test case: %v", err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("want %s, got %s", wantJSON, gotJSON)
+			}
+
+			if relations.userReads != tc.wantUserReads || relations.commentReads != tc.wantCommentReads {
+				t.Errorf("want %d user and %d comment reads, got %d and %d",
+					tc.wantUserReads, tc.wantCommentReads, relations.userReads, relations.commentReads)
+			}
+		})
+	}
+}
+
+func TestHandleListBlogsExpansionPages(t *testing.T) {
+	blogs := make(streamedBlogs, 2*expandPageSize+1)
+	for i := range blogs {
+		blogs[i] = models.Blog{ID: uint(i + 1), Title: "Blog", AuthorID: 1}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/blog?include=author&fields=id", nil)
+	rec := httptest.NewRecorder()
+	relations := &stubRelations{}
+
+	HandleListBlogs(slog.Default(), blogs, relations, relations).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("want a JSON array, got %q", rec.Body.String())
+	}
+	if len(got) != len(blogs) {
+		t.Errorf("want %d blogs, got %d", len(blogs), len(got))
+	}
+	// The authors are read once for each page of blogs
+	if relations.userReads != 3 {
+		t.Errorf("want 3 user reads, got %d", relations.userReads)
+	}
+}
+
+func TestParseExpansion(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/blog/1?include=comment.user&fields=title,comments.message", nil)
+
+	exp, err := parseExpansion(req.URL.Query(), includeAuthor, includeComments, includeCommentUser)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !exp.include[includeComments] {
+		t.Error("want comment.user to include comments")
+	}
+
+	// The embedded users are kept, though fields lists other properties of
+	// the comments
+	want := fieldSet{"title": nil, "comments": fieldSet{"message": nil, "user": nil}}
+	gotJSON, _ := json.Marshal(exp.fields)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("want fields %s, got %s", wantJSON, gotJSON)
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/navid/blog/internal/models"
+)
+
+/*
+GET	http://localhost:8000/api/feed?cursor=&limit=
+Return recent blogs from the authors the authenticated user follows, newest
+first.
+*/
+
+// Page size bounds for paginated endpoints.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// feedReader represents a type capable of building a user's home feed.
+type feedReader interface {
+	Feed(ctx context.Context, userID int, after *models.Cursor, limit int) ([]models.Blog, *models.Cursor, error)
+}
+
+// feedResponse represents a page of the home feed.
+type feedResponse struct {
+	Blogs      []models.Blog `json:"blogs"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// parsePage reads the cursor and limit query parameters shared by paginated
+// endpoints. An empty cursor means the first page.
+func parsePage(r *http.Request) (*models.Cursor, int, string) {
+	limit := defaultPageSize
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxPageSize {
+			return nil, 0, "Invalid limit: must be between 1 and " + strconv.Itoa(maxPageSize)
+		}
+		limit = n
+	}
+
+	var cursor *models.Cursor
+	if s := r.URL.Query().Get("cursor"); s != "" {
+		c, err := models.ParseCursor(s)
+		if err != nil {
+			return nil, 0, "Invalid cursor"
+		}
+		cursor = &c
+	}
+
+	return cursor, limit, ""
+}
+
+// @Summary		Home Feed
+// @Description	Recent blogs from the authors the authenticated user follows, newest first. Pass next_cursor back as cursor to get the next page, or follow the Link header. As NDJSON or CSV the response is just the blogs, and the next page is only in the Link header.
+// @Tags			feed
+// @Produce		json
+// @Security		BearerAuth
+// @Param			cursor	query		string	false	"Cursor from a previous page"
+// @Param			limit	query		int		false	"Page size (1-100, default 20)"
+// @Success		200		{object}	feedResponse
+// @Header			200		{string}	Link	"URL of the next page, with rel=\"next\""
+// @Failure		400		{object}	string
+// @Failure		401		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/feed [get]
+func HandleFeed(logger *slog.Logger, feedReader feedReader, userChecker userChecker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		cursor, limit, problem := parsePage(r)
+		if problem != "" {
+			http.Error(w, problem, http.StatusBadRequest)
+			return
+		}
+
+		if !userChecker.DoesUserExist(ctx, userID) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+
+		blogs, next, err := feedReader.Feed(ctx, userID, cursor, limit)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to build feed", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := feedResponse{Blogs: blogs}
+		if next != nil {
+			resp.NextCursor = next.String()
+
+			query := r.URL.Query()
+			query.Set("cursor", resp.NextCursor)
+			nextURL := *r.URL
+			nextURL.RawQuery = query.Encode()
+			w.Header().Set("Link", "<"+nextURL.RequestURI()+`>; rel="next"`)
+		}
+
+		// Formats that can only render lists get the blogs alone
+		var body any = resp
+		if listOnly(formatFrom(ctx)) {
+			body = resp.Blogs
+		}
+
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		if err := formatFrom(ctx).Encode(w, body); err != nil {
+			logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
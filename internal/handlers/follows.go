@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/models"
+)
+
+/*
+PUT	http://localhost:8000/api/user/{id}/follow
+DELETE	http://localhost:8000/api/user/{id}/follow
+GET	http://localhost:8000/api/user/{id}/followers
+GET	http://localhost:8000/api/user/{id}/following
+Follow and unfollow authors as the authenticated user, and list followers and
+followed authors.
+*/
+
+// followService represents a type capable of managing follows between users.
+type followService interface {
+	Follow(ctx context.Context, followerID, followeeID int) (models.Follow, error)
+	Unfollow(ctx context.Context, followerID, followeeID int) error
+}
+
+// followLister represents a type capable of listing a user's followers or the
+// users they follow.
+type followLister interface {
+	ListFollowers(ctx context.Context, userID int) ([]models.FollowUser, error)
+	ListFollowing(ctx context.Context, userID int) ([]models.FollowUser, error)
+}
+
+// @Summary		Follow User
+// @Description	Make the authenticated user follow the author with the given ID
+// @Tags			user
+// @Produce		json
+// @Security		BearerAuth
+// @Param			id	path		string	true	"ID of the user to follow"
+// @Success		200	{object}	models.Follow
+// @Failure		400	{object}	string
+// @Failure		401	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/user/{id}/follow [put]
+func HandleFollow(logger *slog.Logger, followService followService, userChecker userChecker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		followerID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		followeeID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			return
+		}
+
+		if !userChecker.DoesUserExist(ctx, followeeID) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if !userChecker.DoesUserExist(ctx, followerID) {
+			http.Error(w, "Follower not found", http.StatusBadRequest)
+			return
+		}
+
+		created, err := followService.Follow(ctx, followerID, followeeID)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to follow user", slog.String("error", err.Error()))
+			if strings.Contains(err.Error(), "cannot follow themselves") {
+				http.Error(w, "Users cannot follow themselves", http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "Failed to follow user", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		if err := formatFrom(ctx).Encode(w, created); err != nil {
+			logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
+
+// @Summary		Unfollow User
+// @Description	Stop the authenticated user following the author with the given ID
+// @Tags			user
+// @Security		BearerAuth
+// @Param			id	path		string	true	"ID of the followed user"
+// @Success		204	{object}	nil
+// @Failure		400	{object}	string
+// @Failure		401	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/user/{id}/follow [delete]
+func HandleUnfollow(logger *slog.Logger, followService followService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		followerID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		followeeID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := followService.Unfollow(ctx, followerID, followeeID); err != nil {
+			logger.ErrorContext(ctx, "failed to unfollow user", slog.String("error", err.Error()))
+			if strings.Contains(err.Error(), "no follow found") {
+				http.Error(w, "Follow not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to unfollow user", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// @Summary		List Followers
+// @Description	List the users following the user with the given ID
+// @Tags			user
+// @Produce		json
+// @Param			id	path		string	true	"User ID"
+// @Success		200	{array}		models.FollowUser
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/user/{id}/followers [get]
+func HandleListFollowers(logger *slog.Logger, followLister followLister, userChecker userChecker) http.Handler {
+	return handleListFollows(logger, followLister.ListFollowers, userChecker)
+}
+
+// @Summary		List Following
+// @Description	List the users the user with the given ID follows
+// @Tags			user
+// @Produce		json
+// @Param			id	path		string	true	"User ID"
+// @Success		200	{array}		models.FollowUser
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/user/{id}/following [get]
+func HandleListFollowing(logger *slog.Logger, followLister followLister, userChecker userChecker) http.Handler {
+	return handleListFollows(logger, followLister.ListFollowing, userChecker)
+}
+
+func handleListFollows(logger *slog.Logger, list func(ctx context.Context, userID int) ([]models.FollowUser, error), userChecker userChecker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			return
+		}
+
+		if !userChecker.DoesUserExist(ctx, userID) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+
+		users, err := list(ctx, userID)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to list follows", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		if err := formatFrom(ctx).Encode(w, users); err != nil {
+			logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
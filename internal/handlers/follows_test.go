@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/models"
+	"github.com/navid/blog/internal/render"
+)
+
+// fakeFollows records the follows it is asked to add or remove.
+type fakeFollows struct {
+	follows []models.Follow
+}
+
+func (f *fakeFollows) Follow(ctx context.Context, followerID, followeeID int) (models.Follow, error) {
+	follow := models.Follow{FollowerID: followerID, FolloweeID: followeeID}
+	f.follows = append(f.follows, follow)
+	return follow, nil
+}
+
+func (f *fakeFollows) Unfollow(ctx context.Context, followerID, followeeID int) error {
+	f.follows = append(f.follows, models.Follow{FollowerID: followerID, FolloweeID: followeeID})
+	return nil
+}
+
+// fakeFeed records whose feed it built and returns a page of blogs.
+type fakeFeed struct {
+	userIDs []int
+	blogs   []models.Blog
+	next    *models.Cursor
+}
+
+func (f *fakeFeed) Feed(ctx context.Context, userID int, after *models.Cursor, limit int) ([]models.Blog, *models.Cursor, error) {
+	f.userIDs = append(f.userIDs, userID)
+	return f.blogs, f.next, nil
+}
+
+func TestHandleFollow(t *testing.T) {
+	tests := map[string]struct {
+		handler    func(logger *slog.Logger, follows followService) http.Handler
+		method     string
+		userID     int
+		wantStatus int
+	}{
+		"follow as the authenticated user": {
+			handler: func(logger *slog.Logger, follows followService) http.Handler {
+				return HandleFollow(logger, follows, everyUser{})
+			},
+			method:     http.MethodPut,
+			userID:     7,
+			wantStatus: http.StatusOK,
+		},
+		"unfollow as the authenticated user": {
+			handler:    HandleUnfollow,
+			method:     http.MethodDelete,
+			userID:     7,
+			wantStatus: http.StatusNoContent,
+		},
+		"anonymous follow": {
+			handler: func(logger *slog.Logger, follows followService) http.Handler {
+				return HandleFollow(logger, follows, everyUser{})
+			},
+			method:     http.MethodPut,
+			wantStatus: http.StatusUnauthorized,
+		},
+		"anonymous unfollow": {
+			handler:    HandleUnfollow,
+			method:     http.MethodDelete,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			follows := &fakeFollows{}
+			h := tc.handler(slog.New(slog.DiscardHandler), follows)
+
+			// follower_id in the query is ignored in favour of the token.
+			req := httptest.NewRequest(tc.method, "/api/user/3/follow?follower_id=8", nil)
+			req.SetPathValue("id", "3")
+			if tc.userID != 0 {
+				req = req.WithContext(auth.WithUserID(req.Context(), tc.userID))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body)
+			}
+			if tc.userID == 0 {
+				if len(follows.follows) != 0 {
+					t.Errorf("want no follow, got %v", follows.follows)
+				}
+				return
+			}
+			want := models.Follow{FollowerID: tc.userID, FolloweeID: 3}
+			if len(follows.follows) != 1 || follows.follows[0] != want {
+				t.Errorf("want %v, got %v", want, follows.follows)
+			}
+		})
+	}
+}
+
+func TestHandleFeed(t *testing.T) {
+	tests := map[string]struct {
+		userID     int
+		wantStatus int
+	}{
+		"the authenticated user's feed": {
+			userID:     7,
+			wantStatus: http.StatusOK,
+		},
+		"anonymous": {
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			feed := &fakeFeed{}
+			h := HandleFeed(slog.New(slog.DiscardHandler), feed, everyUser{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/feed?user_id=8", nil)
+			if tc.userID != 0 {
+				req = req.WithContext(auth.WithUserID(req.Context(), tc.userID))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body)
+			}
+			if tc.userID == 0 {
+				if len(feed.userIDs) != 0 {
+					t.Errorf("want no feed, got feeds for %v", feed.userIDs)
+				}
+				return
+			}
+			if len(feed.userIDs) != 1 || feed.userIDs[0] != tc.userID {
+				t.Errorf("want the feed of user %d, got feeds for %v", tc.userID, feed.userIDs)
+			}
+		})
+	}
+}
+
+func TestHandleFeed_Formats(t *testing.T) {
+	next := &models.Cursor{CreatedAt: time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC), ID: 3}
+	feed := &fakeFeed{
+		blogs: []models.Blog{{ID: 4, Title: "Fourth", AuthorID: 8}, {ID: 3, Title: "Third", AuthorID: 8}},
+		next:  next,
+	}
+	h := Negotiate(render.ListFormats, HandleFeed(slog.New(slog.DiscardHandler), feed, everyUser{}))
+
+	tests := map[string]struct {
+		accept   string
+		wantType string
+		wantBody []string
+	}{
+		"JSON page": {
+			accept:   "application/json",
+			wantType: "application/json",
+			wantBody: []string{`"blogs":[`, `"next_cursor":"` + next.String() + `"`},
+		},
+		"NDJSON blogs": {
+			accept:   "application/x-ndjson",
+			wantType: "application/x-ndjson",
+			wantBody: []string{`{"id":4,"title":"Fourth"`, "\n{\"id\":3,"},
+		},
+		"CSV blogs": {
+			accept:   "text/csv",
+			wantType: "text/csv",
+			wantBody: []string{"id,title,author_id", "4,Fourth,8"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/feed?limit=2", nil)
+			req.Header.Set("Accept", tc.accept)
+			req = req.WithContext(auth.WithUserID(req.Context(), 7))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("want status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tc.wantType) {
+				t.Errorf("want Content-Type %q, got %q", tc.wantType, got)
+			}
+			wantLink := `</api/feed?cursor=` + next.String() + `&limit=2>; rel="next"`
+			if got := rec.Header().Get("Link"); got != wantLink {
+				t.Errorf("want Link %q, got %q", wantLink, got)
+			}
+			for _, want := range tc.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("want body containing %q, got %s", want, rec.Body)
+				}
+			}
+		})
+	}
+}
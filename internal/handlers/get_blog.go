@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -12,8 +11,9 @@ import (
 )
 
 /*
-GET	http://localhost:8000/api/blog/{id}
-Return a given Blog object based on id.
+GET	http://localhost:8000/api/blog/{id}?include=author,comments,comment.user&fields=title,author.name
+Return a given Blog object based on id, optionally with its author and comments embedded and trimmed
+to the listed fields.
 */
 
 // blogReader represents a type capable of reading a blog from storage
@@ -26,12 +26,14 @@ type blogReader interface {
 // @Tags         blog
 // @Produce      json
 // @Param        id   path        string  true    "Blog ID"
+// @Param        include query    string  false   "Relations to embed: author, comments, comment.user"
+// @Param        fields  query    string  false   "Properties to return, like title,author.name"
 // @Success      200  {object}    models.Blog
 // @Failure      400  {object}    string
 // @Failure      404  {object}    string
 // @Failure      500  {object}    string
-// @Router       /api/blog/{id} [get]
-func HandleGetBlog(logger *slog.Logger, blogReader blogReader) http.Handler {
+// @Router       /blog/{id} [get]
+func HandleGetBlog(logger *slog.Logger, blogReader blogReader, users userBatchReader, comments commentBatchLister) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger.InfoContext(r.Context(), "Handling GET blog by ID request",
 			slog.String("path", r.URL.Path),
@@ -60,6 +62,12 @@ func HandleGetBlog(logger *slog.Logger, blogReader blogReader) http.Handler {
 			return
 		}
 
+		exp, err := parseExpansion(r.URL.Query(), includeAuthor, includeComments, includeCommentUser)
+		if err != nil {
+			http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		blog, err := blogReader.GetBlog(r.Context(), uint(id64))
 		if err != nil {
 			logger.ErrorContext(r.Context(), "failed to get blog",
@@ -74,8 +82,20 @@ func HandleGetBlog(logger *slog.Logger, blogReader blogReader) http.Handler {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(blog); err != nil {
+		var response any = blog
+		if exp.expanded() {
+			expanded, err := expandBlogs(r.Context(), []models.Blog{blog}, exp, users, comments)
+			if err != nil {
+				logger.ErrorContext(r.Context(), "failed to expand blog",
+					slog.String("error", err.Error()))
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			response = expanded[0]
+		}
+
+		w.Header().Set("Content-Type", formatFrom(r.Context()).MediaType())
+		if err := formatFrom(r.Context()).Encode(w, response); err != nil {
 			logger.ErrorContext(r.Context(), "failed to encode response",
 				slog.String("error", err.Error()))
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/navid/blog/internal/gql"
+)
+
+// graphQLRunner represents a type capable of running GraphQL requests.
+type graphQLRunner interface {
+	Do(ctx context.Context, req gql.Request, readOnly bool) (*graphql.Result, error)
+}
+
+/*
+POST /graphql
+GET /graphql?query=...
+
+Run a GraphQL query or mutation against the users, blogs and comments. POST
+bodies hold the query, operationName and variables as JSON; GET requests
+pass them as query parameters, with variables JSON-encoded, and can only run
+queries. Requests that don't parse, aren't valid or are too deep or complex
+get a 400 Bad Request; otherwise the result is returned with a 200 OK, with
+any errors raised while resolving fields listed in it.
+*/
+func HandleGraphQL(logger *slog.Logger, runner graphQLRunner) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req gql.Request
+		readOnly := false
+		switch r.Method {
+		case http.MethodPost:
+			if err := decodeBody(r, &req); err != nil {
+				writeDecodeError(ctx, logger, w, err)
+				return
+			}
+		case http.MethodGet:
+			query := r.URL.Query()
+			req.Query = query.Get("query")
+			req.OperationName = query.Get("operationName")
+			if variables := query.Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					http.Error(w, "Invalid variables: must be a JSON object", http.StatusBadRequest)
+					return
+				}
+			}
+			readOnly = true
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if strings.TrimSpace(req.Query) == "" {
+			http.Error(w, "Invalid request: query is required", http.StatusBadRequest)
+			return
+		}
+
+		result, err := runner.Do(ctx, req, readOnly)
+		var reqErr *gql.RequestError
+		switch {
+		case errors.Is(err, gql.ErrReadOnly):
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "Method Not Allowed: "+err.Error(), http.StatusMethodNotAllowed)
+		case errors.As(err, &reqErr):
+			writeResponse(ctx, logger, w, http.StatusBadRequest, reqErr)
+		case err != nil:
+			logger.ErrorContext(ctx, "failed to run graphql request", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		default:
+			writeResponse(ctx, logger, w, http.StatusOK, result)
+		}
+	})
+}
+
+/*
+GET /graphiql
+
+Serve GraphiQL, an in-browser editor for exploring the GraphQL API. It is
+only served in dev mode.
+*/
+func HandleGraphiQL() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(graphiQLPage))
+	})
+}
+
+// GraphiQLCSP is the content security policy of the GraphiQL page, which
+// loads its scripts and styles from a CDN and is set up by an inline script.
+const GraphiQLCSP = "default-src 'self'; script-src 'self' 'unsafe-inline' https://unpkg.com; style-src 'self' 'unsafe-inline' https://unpkg.com; img-src 'self' data:; frame-ancestors 'none'"
+
+// graphiQLPage loads GraphiQL, pointed at /graphql.
+const graphiQLPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>GraphiQL</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+  <style>body { margin: 0; height: 100vh; } #graphiql { height: 100vh; }</style>
+</head>
+<body>
+  <div id="graphiql">Loading…</div>
+  <script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+  <script>
+    const fetcher = GraphiQL.createFetcher({ url: '/graphql' });
+    ReactDOM.createRoot(document.getElementById('graphiql')).render(React.createElement(GraphiQL, { fetcher }));
+  </script>
+</body>
+</html>
+`
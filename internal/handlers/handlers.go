@@ -3,8 +3,18 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/render"
+	"github.com/navid/blog/internal/validate"
 )
 
 // validator is an object that can be validated.
@@ -15,15 +25,224 @@ type validator interface {
 	Valid(ctx context.Context) (problems map[string]string)
 }
 
-// decodeValid decodes a model from an http request and performs validation
-// on it.
+// decodeValid strictly decodes a model from an http request body and performs
+// validation on it: first against the rules in its validate struct tags, then
+// with its Valid method. Problems are keyed by JSON field path; if the body
+// can't be decoded because of a mistake the client can fix, they say where
+// it is.
 func decodeValid[T validator](r *http.Request) (T, map[string]string, error) {
 	var v T
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
-		return v, nil, fmt.Errorf("decode json: %w", err)
+	if err := decodeBody(r, &v); err != nil {
+		var bodyErr *bodyError
+		if errors.As(err, &bodyErr) {
+			return v, bodyErr.problems, err
+		}
+		return v, nil, err
 	}
-	if problems := v.Valid(r.Context()); len(problems) > 0 {
+
+	if problems := problemsOf(r.Context(), v); len(problems) > 0 {
 		return v, problems, fmt.Errorf("invalid %T: %d problems", v, len(problems))
 	}
 	return v, nil, nil
 }
+
+// problemsOf validates v against the rules in its validate struct tags, then
+// with its Valid method, for models with fields filled in after decoding.
+func problemsOf(ctx context.Context, v validator) map[string]string {
+	problems := validate.Struct(v)
+	maps.Copy(problems, v.Valid(ctx))
+	return problems
+}
+
+// rootPath is the problem key for mistakes in the body as a whole rather than
+// in one of its fields.
+const rootPath = "$"
+
+// bodyError is a request body that couldn't be decoded because of a mistake
+// the client can fix. problems says where it is.
+type bodyError struct {
+	problems map[string]string
+	err      error
+}
+
+func (e *bodyError) Error() string { return "decode body: " + e.err.Error() }
+
+func (e *bodyError) Unwrap() error { return e.err }
+
+// errUnsupportedMediaType is returned for a request body in a format the API
+// doesn't speak.
+var errUnsupportedMediaType = errors.New("unsupported media type")
+
+// decodeBody decodes the request body into v from the format named by its
+// Content-Type, refusing fields v doesn't have and anything after the first
+// value. Mistakes the client can fix are returned as a *bodyError.
+func decodeBody(r *http.Request, v any) error {
+	format, ok := render.ForContentType(r.Header.Get("Content-Type"))
+	if !ok {
+		return fmt.Errorf("%w: %s", errUnsupportedMediaType, r.Header.Get("Content-Type"))
+	}
+	if err := format.Decode(r.Body, v); err != nil {
+		return decodeProblem(err)
+	}
+	return nil
+}
+
+// decodeProblem turns an error from decoding a body into a *bodyError pointing
+// at the field or offset at fault, or returns it unchanged if the client
+// isn't to blame.
+func decodeProblem(err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		problems  map[string]string
+	)
+	switch {
+	case errors.As(err, new(*http.MaxBytesError)):
+		return err
+	case errors.Is(err, render.ErrTrailingData):
+		problems = map[string]string{rootPath: "request body must contain a single value"}
+	case errors.Is(err, render.ErrMalformed):
+		problems = map[string]string{rootPath: err.Error()}
+	case errors.As(err, &syntaxErr):
+		problems = map[string]string{rootPath: fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset)}
+	case errors.Is(err, io.EOF):
+		problems = map[string]string{rootPath: "request body is required"}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		problems = map[string]string{rootPath: "request body ends in the middle of a JSON value"}
+	case errors.As(err, &typeErr):
+		path := fieldPath(typeErr.Field)
+		if path == "" {
+			problems = map[string]string{rootPath: fmt.Sprintf("request body must be a JSON object, not %s", article(typeErr.Value))}
+		} else {
+			problems = map[string]string{path: fmt.Sprintf("%s must be %s, not %s", path, article(jsonType(typeErr.Type)), article(typeErr.Value))}
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		problems = map[string]string{field: "unknown field " + field}
+	default:
+		return err
+	}
+	return &bodyError{problems: problems, err: err}
+}
+
+// fieldPath converts the dotted path encoding/json reports, such as
+// items.0.note, to the form problems use, items[0].note.
+func fieldPath(field string) string {
+	if field == "" {
+		return ""
+	}
+	var b strings.Builder
+	for i, part := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(part); err == nil && i > 0 {
+			fmt.Fprintf(&b, "[%s]", part)
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// jsonType names the JSON type a Go type is decoded from.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Pointer:
+		return jsonType(t.Elem())
+	default:
+		return "object"
+	}
+}
+
+// article prefixes a JSON type name with "a" or "an".
+func article(name string) string {
+	if strings.IndexByte("aeiou", name[0]) >= 0 {
+		return "an " + name
+	}
+	return "a " + name
+}
+
+// writeDecodeError writes the response for a request body that couldn't be
+// decoded: the problems if the client made a mistake it can fix, 413 if the
+// body was larger than the server accepts, 415 if it was in a format the API
+// doesn't speak, or 400 otherwise.
+func writeDecodeError(ctx context.Context, logger *slog.Logger, w http.ResponseWriter, err error) {
+	var (
+		bodyErr  *bodyError
+		tooLarge *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, fmt.Sprintf("Request body too large: the limit is %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, errUnsupportedMediaType):
+		http.Error(w, "Unsupported Media Type: request bodies may be "+contentTypes, http.StatusUnsupportedMediaType)
+	case errors.As(err, &bodyErr):
+		writeResponse(ctx, logger, w, http.StatusBadRequest, bodyErr.problems)
+	default:
+		logger.ErrorContext(ctx, "failed to decode request body", slog.String("error", err.Error()))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	}
+}
+
+// writeResponse writes v with the provided status code, in the format
+// negotiated for the request.
+func writeResponse(ctx context.Context, logger *slog.Logger, w http.ResponseWriter, status int, v any) {
+	format := formatFrom(ctx)
+	w.Header().Set("Content-Type", format.MediaType())
+	w.WriteHeader(status)
+	if err := format.Encode(w, v); err != nil {
+		logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// decodeValidOrWrite decodes and validates a model from the request body. If
+// the body can't be decoded or the model is invalid, a 400 response is written
+// and false is returned.
+func decodeValidOrWrite[T validator](logger *slog.Logger, w http.ResponseWriter, r *http.Request) (T, bool) {
+	v, problems, err := decodeValid[T](r)
+	if len(problems) > 0 {
+		writeResponse(r.Context(), logger, w, http.StatusBadRequest, problems)
+		return v, false
+	}
+	if err != nil {
+		writeDecodeError(r.Context(), logger, w, err)
+		return v, false
+	}
+	return v, true
+}
+
+// authorNames looks up and remembers author names for the duration of a
+// request, so a page or feed full of one author's blogs reads the user once.
+type authorNames struct {
+	users userReader
+	names map[int]string
+}
+
+func newAuthorNames(users userReader) *authorNames {
+	return &authorNames{users: users, names: make(map[int]string)}
+}
+
+// name returns the author's name, or "" if there is no such user.
+func (a *authorNames) name(ctx context.Context, id int) (string, error) {
+	if name, ok := a.names[id]; ok {
+		return name, nil
+	}
+	user, err := a.users.ReadUser(ctx, uint64(id))
+	if err != nil {
+		return "", err
+	}
+	a.names[id] = user.Name
+	return user.Name, nil
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/navid/blog/internal/render"
+)
+
+// testModel is a model with a required field and tagged nested fields, for
+// exercising decoding.
+type testModel struct {
+	Name  string     `json:"name"`
+	Email string     `json:"email" validate:"email"`
+	Items []testItem `json:"items"`
+}
+
+type testItem struct {
+	Count int `json:"count" validate:"min=1,max=10"`
+}
+
+func (m testModel) Valid(ctx context.Context) map[string]string {
+	if m.Name == "" {
+		return map[string]string{"name": "name is required"}
+	}
+	return nil
+}
+
+func TestDecodeValidOrWrite(t *testing.T) {
+	tests := map[string]struct {
+		body        string
+		contentType string
+		limit       int64
+		wantOK      bool
+		wantStatus  int
+		wantProblem string
+	}{
+		"valid": {
+			body:   `{"name": "navid"}`,
+			wantOK: true,
+		},
+		"invalid": {
+			body:        `{"name": ""}`,
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "name",
+		},
+		"invalid email tag": {
+			body:        `{"name": "navid", "email": "not an email"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "email",
+		},
+		"nested range tag": {
+			body:        `{"name": "navid", "items": [{"count": 1}, {"count": 11}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "items[1].count",
+		},
+		"nested type mismatch": {
+			body:        `{"name": "navid", "items": [{"count": "one"}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "items[0].count",
+		},
+		"unknown field": {
+			body:        `{"name": "navid", "nickname": "n"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "nickname",
+		},
+		"trailing data": {
+			body:        `{"name": "navid"} {"name": "again"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "$",
+		},
+		"not an object": {
+			body:        `["navid"]`,
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "$",
+		},
+		"malformed": {
+			body:        `{"name":`,
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "$",
+		},
+		"empty": {
+			body:        ``,
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "$",
+		},
+		"xml": {
+			body:        `<user><name>navid</name><items><item><count>2</count></item></items></user>`,
+			contentType: "application/xml",
+			wantOK:      true,
+		},
+		"xml nested range tag": {
+			body:        `<user><name>navid</name><items><item><count>1</count></item><item><count>11</count></item></items></user>`,
+			contentType: "application/xml",
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "items[1].count",
+		},
+		"malformed xml": {
+			body:        `<user><name>navid</user>`,
+			contentType: "application/xml",
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: "$",
+		},
+		"csv": {
+			body:        "name,email\nnavid,navid@example.com\n",
+			contentType: "text/csv",
+			wantOK:      true,
+		},
+		"unsupported media type": {
+			body:        "name=navid",
+			contentType: "application/x-www-form-urlencoded",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		"too large": {
+			body:       `{"name": "` + strings.Repeat("a", 64) + `"}`,
+			limit:      32,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rec := httptest.NewRecorder()
+			if tc.limit > 0 {
+				// Hide the length so the limit is hit while decoding
+				req.ContentLength = -1
+				req.Body = http.MaxBytesReader(rec, req.Body, tc.limit)
+			}
+
+			_, ok := decodeValidOrWrite[testModel](slog.Default(), rec, req)
+			if ok != tc.wantOK {
+				t.Fatalf("want ok %v, got %v", tc.wantOK, ok)
+			}
+			if !ok && rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.wantProblem != "" {
+				var problems map[string]string
+				if err := json.Unmarshal(rec.Body.Bytes(), &problems); err != nil {
+					t.Fatalf("want problems, got %q", rec.Body.String())
+				}
+				if _, ok := problems[tc.wantProblem]; !ok {
+					t.Errorf("want a problem for %q, got %v", tc.wantProblem, problems)
+				}
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := map[string]struct {
+		accept     string
+		wantStatus int
+		wantType   string
+	}{
+		"default": {
+			wantStatus: http.StatusOK,
+			wantType:   "application/json",
+		},
+		"xml": {
+			accept:     "application/xml",
+			wantStatus: http.StatusOK,
+			wantType:   "application/xml",
+		},
+		"msgpack": {
+			accept:     "application/msgpack",
+			wantStatus: http.StatusOK,
+			wantType:   "application/msgpack",
+		},
+		"not acceptable": {
+			accept:     "text/csv",
+			wantStatus: http.StatusNotAcceptable,
+			wantType:   "text/plain; charset=utf-8",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tc.accept)
+			rec := httptest.NewRecorder()
+
+			Negotiate(render.Formats, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeResponse(r.Context(), slog.Default(), w, http.StatusOK, testModel{Name: "navid"})
+			})).ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tc.wantType {
+				t.Errorf("want content type %q, got %q", tc.wantType, got)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("want Vary: Accept, got %q", got)
+			}
+		})
+	}
+}
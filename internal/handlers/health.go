@@ -1,9 +1,11 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
 	"log/slog"
 	"net/http"
+
+	"github.com/navid/blog/internal/health"
 )
 
 // healthResponse represents the response for the health check.
@@ -24,8 +26,64 @@ func HandleHealthCheck(logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		logger.InfoContext(r.Context(), "health check called")
 
-		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Type", formatFrom(r.Context()).MediaType())
 		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(healthResponse{Status: "ok"})
+		_ = formatFrom(r.Context()).Encode(w, healthResponse{Status: "ok"})
 	}
 }
+
+// readinessChecker represents a type capable of checking whether the service
+// and the components it depends on are ready to serve traffic.
+type readinessChecker interface {
+	Ready(ctx context.Context) health.Report
+}
+
+// HandleLiveness reports that the process is up and serving requests. It
+// doesn't look at any dependency, so a failing database doesn't get the
+// process restarted.
+//
+//	@Summary		Liveness Check
+//	@Description	Reports that the process is up
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	healthResponse
+//	@Router			/health/live [GET]
+func HandleLiveness(logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.DebugContext(r.Context(), "liveness check called")
+
+		w.Header().Set("Content-Type", formatFrom(r.Context()).MediaType())
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		_ = formatFrom(r.Context()).Encode(w, healthResponse{Status: health.StatusOK})
+	})
+}
+
+// HandleReadiness reports whether the service can serve traffic: the
+// database answers a ping within the check timeout, its schema is up to date
+// and the server isn't shutting down. Every component's status and latency
+// is included, along with pending background jobs.
+//
+//	@Summary		Readiness Check
+//	@Description	Reports the status and latency of every component the service depends on, answering 503 if any is unavailable or the server is shutting down
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	health.Report
+//	@Failure		503	{object}	health.Report
+//	@Router			/health/ready [GET]
+func HandleReadiness(logger *slog.Logger, checker readinessChecker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Ready(r.Context())
+
+		status := http.StatusOK
+		if report.Status != health.StatusOK {
+			logger.WarnContext(r.Context(), "readiness check failed", slog.Any("components", report.Components))
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", formatFrom(r.Context()).MediaType())
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = formatFrom(r.Context()).Encode(w, report)
+	})
+}
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"log/slog"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/navid/blog/internal/health"
 )
 
 func TestHandleHealthCheck(t *testing.T) {
@@ -43,3 +48,50 @@ func TestHandleHealthCheck(t *testing.T) {
 		})
 	}
 }
+
+// stubChecker reports a fixed readiness report.
+type stubChecker health.Report
+
+func (c stubChecker) Ready(ctx context.Context) health.Report { return health.Report(c) }
+
+func TestHandleReadiness(t *testing.T) {
+	tests := map[string]struct {
+		report     health.Report
+		wantStatus int
+	}{
+		"ready": {
+			report: health.Report{
+				Status:     health.StatusOK,
+				Components: map[string]health.Component{"database": {Status: health.StatusOK}},
+			},
+			wantStatus: http.StatusOK,
+		},
+		"database down": {
+			report: health.Report{
+				Status:     health.StatusUnavailable,
+				Components: map[string]health.Component{"database": {Status: health.StatusUnavailable, Error: "boom"}},
+			},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/health/ready", nil)
+			rec := httptest.NewRecorder()
+
+			HandleReadiness(slog.Default(), stubChecker(tc.report)).ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+
+			var got health.Report
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("invalid body: %v", err)
+			}
+			if got.Status != tc.report.Status || got.Components["database"].Status != tc.report.Components["database"].Status {
+				t.Errorf("want report %+v, got %+v", tc.report, got)
+			}
+		})
+	}
+}
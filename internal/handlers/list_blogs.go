@@ -2,9 +2,9 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/navid/blog/internal/models"
 	"github.com/navid/blog/internal/services"
@@ -12,58 +12,130 @@ import (
 
 /*
 GET	http://localhost:8000/api/blog
-Return all Blog objects from the database. If the title parameter is provided, filter list by title.
+Return all Blog objects from the database. If the title, author_id or tag parameters are provided,
+filter the list by them. If sort=score is provided, rank the list by weighted rating score; if
+sort=newest is provided, return the most recent blogs first. Blogs are streamed as they are read, as a
+JSON array or, with Accept: application/x-ndjson, one JSON object per line. include= embeds the authors
+and comments of the blogs, read for a page of blogs at a time, and fields= trims them.
 */
 
+// blogStreamer represents a type capable of reading blogs from storage one at
+// a time.
+type blogStreamer interface {
+	StreamBlogsWithFilter(ctx context.Context, filter models.BlogFilter, fn func(models.Blog) error) error
+}
+
 // blogLister represents a type capable of listing blogs from storage and
 // returning them or an error.
 type blogLister interface {
-	ListBlogs(ctx context.Context, title string) ([]models.Blog, error)
+	ListBlogs(ctx context.Context, filter models.BlogFilter) ([]models.Blog, error)
 }
 
 type blogListerAdapter struct {
 	service *services.BlogService
 }
 
-func (a *blogListerAdapter) ListBlogs(ctx context.Context, title string) ([]models.Blog, error) {
+func (a *blogListerAdapter) ListBlogs(ctx context.Context, filter models.BlogFilter) ([]models.Blog, error) {
 	// Delegate to the actual service method
-	return a.service.ListBlogsWithFilter(ctx, title)
+	return a.service.ListBlogsWithFilter(ctx, filter)
 }
 
 func NewBlogListerAdapter(service *services.BlogService) blogLister {
 	return &blogListerAdapter{service: service}
 }
 
+// parseTag normalizes a tag read from a request, returning "" if it isn't a
+// valid tag.
+func parseTag(raw string) string {
+	tags := models.NormalizeTags([]string{raw})
+	if len(tags) == 0 || !models.ValidTag(tags[0]) {
+		return ""
+	}
+	return tags[0]
+}
+
 // @Summary		List Blogs
-// @Description	List all blogs or filter by title
+// @Description	List all blogs or filter by title, author or tag
 // @Tags			blog
 // @Accept			json
-// @Produce		json
-// @Param			title	query		string	false	"Filter by title"
+// @Produce		json,application/x-ndjson
+// @Param			title		query		string	false	"Filter by title"
+// @Param			author_id	query		string	false	"Filter by author"
+// @Param			tag			query		string	false	"Filter by tag"
+// @Param			sort		query		string	false	"Set to score to rank by weighted rating, or newest for most recent first"
+// @Param			include		query		string	false	"Relations to embed: author, comments, comment.user"
+// @Param			fields		query		string	false	"Properties to return, like title,author.name"
 // @Success		200	{array}		models.Blog
+// @Failure		400	{object}	string
 // @Failure		500	{object}	string
-// @Router			/blogs [GET]
-func HandleListBlogs(logger *slog.Logger, blogLister blogLister) http.Handler {
+// @Router			/blog [GET]
+func HandleListBlogs(logger *slog.Logger, blogStreamer blogStreamer, users userBatchReader, comments commentBatchLister) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger.InfoContext(r.Context(), "HandleListBlogs called", slog.String("path", r.URL.Path))
 
-		// Get the "title" query parameter
-		title := r.URL.Query().Get("title")
+		// Get the "title", "author_id", "tag" and "sort" query parameters
+		query := r.URL.Query()
+		filter := models.BlogFilter{Title: query.Get("title")}
+		if authorID := query.Get("author_id"); authorID != "" {
+			id, err := strconv.Atoi(authorID)
+			if err != nil || id <= 0 {
+				http.Error(w, "Invalid author_id", http.StatusBadRequest)
+				return
+			}
+			filter.AuthorID = id
+		}
+		if tag := query.Get("tag"); tag != "" {
+			if filter.Tag = parseTag(tag); filter.Tag == "" {
+				http.Error(w, "Invalid tag", http.StatusBadRequest)
+				return
+			}
+		}
+		switch query.Get("sort") {
+		case "":
+		case "score":
+			filter.OrderByScore = true
+		case "newest":
+			filter.OrderByNewest = true
+		default:
+			http.Error(w, "Invalid sort: must be score or newest", http.StatusBadRequest)
+			return
+		}
 
-		// Retrieve blogs from the blogLister
-		blogs, err := blogLister.ListBlogs(r.Context(), title)
+		exp, err := parseExpansion(query, includeAuthor, includeComments, includeCommentUser)
 		if err != nil {
-			logger.ErrorContext(r.Context(), "failed to list blogs", slog.String("error", err.Error()))
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		// Write the response as JSON
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(blogs); err != nil {
-			logger.ErrorContext(r.Context(), "failed to encode response", slog.String("error", err.Error()))
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		// Stream blogs from the blogStreamer straight to the response. Blogs
+		// with relations embedded are collected a page at a time, so the
+		// relations of a page can be read at once
+		enc := newListEncoder(w, r)
+		if len(exp.include) == 0 {
+			err = blogStreamer.StreamBlogsWithFilter(r.Context(), filter, func(blog models.Blog) error {
+				v, err := project(blog, exp.fields)
+				if err != nil {
+					return err
+				}
+				return enc.Encode(v)
+			})
+		} else {
+			pages := newPageWriter(enc, func(blogs []models.Blog) ([]any, error) {
+				return expandBlogs(r.Context(), blogs, exp, users, comments)
+			})
+			err = blogStreamer.StreamBlogsWithFilter(r.Context(), filter, pages.add)
+			if err == nil {
+				err = pages.flush()
+			}
+		}
+		if err == nil {
+			err = enc.Close()
+		}
+		if err != nil {
+			logger.ErrorContext(r.Context(), "failed to list blogs", slog.String("error", err.Error()))
+			if !enc.Started() {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
 		}
 	})
 }
@@ -1,16 +1,19 @@
 package handlers
 
 import (
-    "encoding/json"
     "log/slog"
     "net/http"
     "strconv"
 
+    "github.com/navid/blog/internal/models"
     "github.com/navid/blog/internal/services"
 )
 
 // HandleListComments handles retrieving all comments, optionally filtering by author_id or blog_id.
-func HandleListComments(logger *slog.Logger, commentsService *services.CommentsService) http.HandlerFunc {
+// Comments are streamed as they are read, as a JSON array or, with Accept: application/x-ndjson,
+// one JSON object per line. include=user embeds the users who wrote them, read for a page of
+// comments at a time, and fields= trims them.
+func HandleListComments(logger *slog.Logger, commentsService *services.CommentsService, users userBatchReader) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         ctx := r.Context()
 
@@ -36,16 +39,40 @@ func HandleListComments(logger *slog.Logger, commentsService *services.CommentsS
             blogID = &id
         }
 
-        // Retrieve comments
-        comments, err := commentsService.ListComments(ctx, authorID, blogID)
+        exp, err := parseExpansion(r.URL.Query(), includeUser)
         if err != nil {
-            logger.ErrorContext(ctx, "failed to list comments", slog.String("error", err.Error()))
-            http.Error(w, "Failed to retrieve comments", http.StatusInternalServerError)
+            http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
             return
         }
 
-        // Respond with comments
-        w.Header().Set("Content-Type", "application/json")
-        json.NewEncoder(w).Encode(comments)
+        // Stream comments straight to the response, or collect them a page
+        // at a time to embed their users
+        enc := newListEncoder(w, r)
+        if len(exp.include) == 0 {
+            err = commentsService.StreamComments(ctx, authorID, blogID, func(comment models.Comment) error {
+                v, err := project(comment, exp.fields)
+                if err != nil {
+                    return err
+                }
+                return enc.Encode(v)
+            })
+        } else {
+            pages := newPageWriter(enc, func(comments []models.Comment) ([]any, error) {
+                return expandComments(ctx, comments, exp, users)
+            })
+            err = commentsService.StreamComments(ctx, authorID, blogID, pages.add)
+            if err == nil {
+                err = pages.flush()
+            }
+        }
+        if err == nil {
+            err = enc.Close()
+        }
+        if err != nil {
+            logger.ErrorContext(ctx, "failed to list comments", slog.String("error", err.Error()))
+            if !enc.Started() {
+                http.Error(w, "Failed to retrieve comments", http.StatusInternalServerError)
+            }
+        }
     }
 }
\ No newline at end of file
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/navid/blog/internal/render"
+)
+
+// listFlushInterval is how many elements a listEncoder writes between
+// flushes, so clients start receiving long lists before they are complete.
+const listFlushInterval = 100
+
+// listEncoder writes a list response one element at a time, in the format
+// negotiated for the request. Nothing is written until the first element, so
+// a handler can still send an error status if the list can't be read at all.
+type listEncoder struct {
+	w       http.ResponseWriter
+	format  render.Format
+	enc     render.ListEncoder
+	started bool
+	n       int
+}
+
+func newListEncoder(w http.ResponseWriter, r *http.Request) *listEncoder {
+	return &listEncoder{w: w, format: formatFrom(r.Context())}
+}
+
+// start sends the headers.
+func (e *listEncoder) start() {
+	e.started = true
+	e.w.Header().Set("Content-Type", e.format.MediaType())
+	e.w.WriteHeader(http.StatusOK)
+	e.enc = e.format.NewListEncoder(e.w)
+}
+
+// Encode writes v as the next element of the list.
+func (e *listEncoder) Encode(v any) error {
+	if !e.started {
+		e.start()
+	}
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+
+	if e.n++; e.n%listFlushInterval == 0 {
+		_ = http.NewResponseController(e.w).Flush()
+	}
+	return nil
+}
+
+// Close ends the list. It must only be called once every element has been
+// written; a list cut short by an error is left unterminated, so clients
+// can't mistake it for a complete one.
+func (e *listEncoder) Close() error {
+	if !e.started {
+		e.start()
+	}
+	return e.enc.Close()
+}
+
+// Started reports whether the response has been started, after which its
+// status can't be changed.
+func (e *listEncoder) Started() bool {
+	return e.started
+}
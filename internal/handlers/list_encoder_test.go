@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/navid/blog/internal/models"
+	"github.com/navid/blog/internal/render"
+)
+
+func TestListEncoder(t *testing.T) {
+	tests := map[string]struct {
+		accept   string
+		items    []string
+		wantType string
+		wantBody string
+	}{
+		"json array": {
+			items:    []string{"a", "b"},
+			wantType: "application/json",
+			wantBody: `["a","b"]` + "\n",
+		},
+		"empty json array": {
+			wantType: "application/json",
+			wantBody: "[]\n",
+		},
+		"csv": {
+			accept:   "text/csv",
+			items:    []string{"a", "b"},
+			wantType: "text/csv",
+			wantBody: "value\na\nb\n",
+		},
+		"ndjson": {
+			accept:   "application/x-ndjson",
+			items:    []string{"a", "b"},
+			wantType: "application/x-ndjson",
+			wantBody: "\"a\"\n\"b\"\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tc.accept)
+			rec := httptest.NewRecorder()
+
+			Negotiate(render.ListFormats, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				enc := newListEncoder(w, r)
+				for _, item := range tc.items {
+					if err := enc.Encode(item); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				}
+				if err := enc.Close(); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			})).ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Type"); got != tc.wantType {
+				t.Errorf("want content type %q, got %q", tc.wantType, got)
+			}
+			if got := rec.Body.String(); got != tc.wantBody {
+				t.Errorf("want body %q, got %q", tc.wantBody, got)
+			}
+		})
+	}
+}
+
+// failingStreamer fails before reading any blog.
+type failingStreamer struct{}
+
+func (failingStreamer) StreamBlogsWithFilter(ctx context.Context, filter models.BlogFilter, fn func(models.Blog) error) error {
+	return errors.New("connection refused")
+}
+
+func TestHandleListBlogs_StreamError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/blog", nil)
+	rec := httptest.NewRecorder()
+
+	HandleListBlogs(slog.Default(), failingStreamer{}, nil, nil).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("want status %d before anything is streamed, got %d", http.StatusInternalServerError, rec.Code)
+	}
+}
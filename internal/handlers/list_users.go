@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
 	"net/http"
 
@@ -35,10 +34,10 @@ func NewUserListerAdapter(service *services.UsersService) userLister {
 // @Accept			json
 // @Produce		json
 // @Param			name	query		string	false	"Filter by name"
-// @Success		200	{array}		models.User
+// @Success		200	{array}		readUserResponse
 // @Failure		500	{object}	string
-// @Router			/users [GET]
-func HandleListUsers(logger *slog.Logger, userLister userLister) http.Handler {
+// @Router			/user [GET]
+func HandleListUsers(logger *slog.Logger, userLister userLister, withPasswordField bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger.InfoContext(r.Context(), "HandleListUsers called", slog.String("path", r.URL.Path))
 
@@ -53,10 +52,15 @@ func HandleListUsers(logger *slog.Logger, userLister userLister) http.Handler {
 			return
 		}
 
+		response := make([]any, len(users))
+		for i, user := range users {
+			response[i] = showUser(user, withPasswordField)
+		}
+
 		// Write the response as JSON
-		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Type", formatFrom(r.Context()).MediaType())
 		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(users); err != nil {
+		if err := formatFrom(r.Context()).Encode(w, response); err != nil {
 			logger.ErrorContext(r.Context(), "failed to encode response", slog.String("error", err.Error()))
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
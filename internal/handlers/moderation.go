@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/models"
+)
+
+// contentScreener represents a type capable of running user-submitted content
+// through the content filters before it is stored, and of recording it once
+// it has been.
+type contentScreener interface {
+	Screen(ctx context.Context, content filters.Content, payload any) (filters.Decision, uint, error)
+	Record(ctx context.Context, content filters.Content)
+}
+
+// moderationLister represents a type capable of listing the moderation queue.
+type moderationLister interface {
+	ListModeration(ctx context.Context, status string) ([]models.ModerationItem, error)
+}
+
+// moderationDecider represents a type capable of recording a moderator's
+// decision on a flagged item, publishing approved items with publish as part
+// of the decision.
+type moderationDecider interface {
+	Decide(ctx context.Context, id uint, approve bool, publish func(ctx context.Context, item models.ModerationItem) error) (models.ModerationItem, error)
+}
+
+// commentPublisher represents a type capable of creating or replacing a
+// comment in storage.
+type commentPublisher interface {
+	CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error)
+	UpdateComment(ctx context.Context, comment models.Comment) (models.Comment, error)
+	DoesCommentExist(ctx context.Context, userID, blogID int) (bool, error)
+}
+
+// blogPublisher represents a type capable of creating or replacing a blog in
+// storage.
+type blogPublisher interface {
+	CreateBlog(ctx context.Context, blog models.Blog) (models.Blog, error)
+	UpdateBlog(ctx context.Context, id uint, blog models.Blog) (models.Blog, error)
+}
+
+// screenResponse is returned when a filter holds back or refuses content.
+type screenResponse struct {
+	ModerationID uint   `json:"moderation_id"`
+	Status       string `json:"status"`
+	Filter       string `json:"filter"`
+	Reason       string `json:"reason"`
+}
+
+// writeScreened writes the response for content that didn't pass the filter
+// chain and reports whether it did so. Allowed content is left to the caller.
+func writeScreened(ctx context.Context, w http.ResponseWriter, decision filters.Decision, id uint) bool {
+	var status int
+	resp := screenResponse{
+		ModerationID: id,
+		Filter:       decision.Filter,
+		Reason:       decision.Reason,
+	}
+
+	switch decision.Verdict {
+	case filters.Reject:
+		status = http.StatusUnprocessableEntity
+		resp.Status = models.ModerationRejected
+	case filters.Flag:
+		status = http.StatusAccepted
+		resp.Status = models.ModerationPending
+	default:
+		return false
+	}
+
+	w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+	w.WriteHeader(status)
+	_ = formatFrom(ctx).Encode(w, resp)
+	return true
+}
+
+// @Summary		List Moderation Queue
+// @Description	List content held or refused by the content filters
+// @Tags			moderation
+// @Produce		json
+// @Security		BearerAuth
+// @Param			status	query		string	false	"Filter by status (pending, approved, rejected)"
+// @Success		200		{array}		models.ModerationItem
+// @Failure		401		{object}	string
+// @Failure		403		{object}	string
+// @Failure		500		{object}	string
+// @Router			/moderation [GET]
+func HandleListModeration(logger *slog.Logger, moderationLister moderationLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		items, err := moderationLister.ListModeration(ctx, r.URL.Query().Get("status"))
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to list moderation queue", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		w.WriteHeader(http.StatusOK)
+		if err := formatFrom(ctx).Encode(w, items); err != nil {
+			logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
+
+// @Summary		Decide Moderation Item
+// @Description	Approve or reject flagged content. Approved content is published.
+// @Security		BearerAuth
+// @Tags			moderation
+// @Produce		json
+// @Param			id	path		string	true	"Moderation item ID"
+// @Success		200	{object}	models.ModerationItem
+// @Failure		400	{object}	string
+// @Failure		401	{object}	string
+// @Failure		403	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/moderation/{id}/approve [POST]
+// @Router			/moderation/{id}/reject [POST]
+func HandleDecideModeration(logger *slog.Logger, approve bool, decider moderationDecider, comments commentPublisher, blogs blogPublisher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		idStr := r.PathValue("id")
+		id64, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to parse id",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()))
+			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			return
+		}
+
+		item, err := decider.Decide(ctx, uint(id64), approve, func(ctx context.Context, item models.ModerationItem) error {
+			return publish(ctx, item, comments, blogs)
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to decide moderation item",
+				slog.Uint64("id", id64),
+				slog.String("error", err.Error()))
+			if strings.Contains(err.Error(), "no pending moderation item") {
+				http.Error(w, "Moderation item not found", http.StatusNotFound)
+				return
+			}
+			if strings.Contains(err.Error(), "failed to publish") {
+				http.Error(w, "Failed to publish approved content", http.StatusInternalServerError)
+				return
+			}
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		logger.InfoContext(ctx, "moderation item decided",
+			slog.Uint64("id", uint64(item.ID)),
+			slog.String("status", item.Status))
+
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		if err := formatFrom(ctx).Encode(w, item); err != nil {
+			logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
+
+// publish creates the comment or blog held in an approved moderation item,
+// or replaces it if the item held back an update: a blog with an id, or a
+// comment on a blog the user has already commented on.
+func publish(ctx context.Context, item models.ModerationItem, comments commentPublisher, blogs blogPublisher) error {
+	switch filters.Kind(item.Kind) {
+	case filters.KindComment:
+		var comment models.Comment
+		if err := json.Unmarshal(item.Payload, &comment); err != nil {
+			return err
+		}
+		exists, err := comments.DoesCommentExist(ctx, comment.UserID, comment.BlogID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			_, err = comments.UpdateComment(ctx, comment)
+		} else {
+			_, err = comments.CreateComment(ctx, comment)
+		}
+		return err
+	case filters.KindBlog:
+		var blog models.Blog
+		if err := json.Unmarshal(item.Payload, &blog); err != nil {
+			return err
+		}
+		var err error
+		if blog.ID != 0 {
+			_, err = blogs.UpdateBlog(ctx, blog.ID, blog)
+		} else {
+			_, err = blogs.CreateBlog(ctx, blog)
+		}
+		return err
+	default:
+		return nil
+	}
+}
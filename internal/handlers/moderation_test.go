@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/navid/blog/internal/models"
+)
+
+// fakePublisher records what publish created and updated.
+type fakePublisher struct {
+	commented bool
+	calls     []string
+}
+
+func (f *fakePublisher) CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	f.calls = append(f.calls, "CreateComment")
+	return comment, nil
+}
+
+func (f *fakePublisher) UpdateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	f.calls = append(f.calls, "UpdateComment")
+	return comment, nil
+}
+
+func (f *fakePublisher) DoesCommentExist(ctx context.Context, userID, blogID int) (bool, error) {
+	return f.commented, nil
+}
+
+func (f *fakePublisher) CreateBlog(ctx context.Context, blog models.Blog) (models.Blog, error) {
+	f.calls = append(f.calls, "CreateBlog")
+	return blog, nil
+}
+
+func (f *fakePublisher) UpdateBlog(ctx context.Context, id uint, blog models.Blog) (models.Blog, error) {
+	f.calls = append(f.calls, "UpdateBlog")
+	return blog, nil
+}
+
+func TestPublish(t *testing.T) {
+	tests := map[string]struct {
+		kind      string
+		payload   any
+		commented bool
+		want      []string
+	}{
+		"new comment": {
+			kind:    "comment",
+			payload: models.Comment{UserID: 1, BlogID: 2, Message: "Hello"},
+			want:    []string{"CreateComment"},
+		},
+		"edited comment": {
+			kind:      "comment",
+			payload:   models.Comment{UserID: 1, BlogID: 2, Message: "Hello again"},
+			commented: true,
+			want:      []string{"UpdateComment"},
+		},
+		"new blog": {
+			kind:    "blog",
+			payload: models.Blog{Title: "Hello", AuthorID: 1},
+			want:    []string{"CreateBlog"},
+		},
+		"edited blog": {
+			kind:    "blog",
+			payload: models.Blog{ID: 3, Title: "Hello again", AuthorID: 1},
+			want:    []string{"UpdateBlog"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			payload, err := json.Marshal(tc.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			publisher := &fakePublisher{commented: tc.commented}
+
+			item := models.ModerationItem{Kind: tc.kind, Payload: payload}
+			if err := publish(context.Background(), item, publisher, publisher); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(publisher.calls, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, publisher.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/navid/blog/internal/render"
+)
+
+// contentTypes lists the media types request bodies may be sent as.
+var contentTypes = mediaTypes(render.ListFormats)
+
+// formatKey is the context key of the format negotiated for a request.
+type formatKey struct{}
+
+// Negotiate picks the format h renders its responses in from the request's
+// Accept header, among offered, and answers 406 Not Acceptable if the client
+// accepts none of them.
+func Negotiate(offered []render.Format, h http.Handler) http.Handler {
+	supported := mediaTypes(offered)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		format, ok := render.Negotiate(r.Header.Get("Accept"), offered)
+		if !ok {
+			http.Error(w, "Not Acceptable: responses are available as "+supported, http.StatusNotAcceptable)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), formatKey{}, format)))
+	})
+}
+
+// formatFrom returns the format negotiated for the request with ctx, or JSON
+// if there wasn't one.
+func formatFrom(ctx context.Context) render.Format {
+	if f, ok := ctx.Value(formatKey{}).(render.Format); ok {
+		return f
+	}
+	return render.JSON
+}
+
+// mediaTypes lists the media types of formats for error messages.
+func mediaTypes(formats []render.Format) string {
+	types := make([]string, len(formats))
+	for i, f := range formats {
+		types[i] = f.MediaType()
+	}
+	return strings.Join(types, ", ")
+}
+
+// listOnly reports whether f can only render lists, so a page of results has
+// to be written as its elements alone.
+func listOnly(f render.Format) bool {
+	return !slices.Contains(render.Formats, f)
+}
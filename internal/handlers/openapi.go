@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/navid/blog/internal/openapi"
+)
+
+// HandleOpenAPI serves doc, the OpenAPI document of the API.
+func HandleOpenAPI(doc *openapi.Document) http.HandlerFunc {
+	body, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		panic("handlers: encoding the OpenAPI document: " + err.Error())
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
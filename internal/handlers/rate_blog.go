@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/models"
+)
+
+/*
+PUT	http://localhost:8000/api/blog/{id}/rating
+Rate a blog from 1 to 5 on behalf of the authenticated user. Rating again
+replaces the user's previous rating.
+*/
+
+// blogRater represents a type capable of recording a user's rating of a blog
+// and returning the blog with its updated rating.
+type blogRater interface {
+	RateBlog(ctx context.Context, blogID uint, rating models.Rating) (models.Blog, error)
+}
+
+// userChecker represents a type capable of checking that a user exists.
+type userChecker interface {
+	DoesUserExist(ctx context.Context, userID int) bool
+}
+
+// @Summary		Rate Blog
+// @Description	Rate a blog from 1 to 5. Each user has one rating per blog which can be changed; authors cannot rate their own blogs.
+// @Tags			blog
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @Param			id		path		string			true	"Blog ID"
+// @Param			rating	body		models.Rating	true	"Rating"
+// @Success		200		{object}	models.Blog
+// @Failure		400		{object}	string
+// @Failure		401		{object}	string
+// @Failure		403		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/blog/{id}/rating [put]
+func HandleRateBlog(logger *slog.Logger, blogRater blogRater, userChecker userChecker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		idStr := r.PathValue("id")
+		id64, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to parse id",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()))
+			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			return
+		}
+
+		var rating models.Rating
+		if err := decodeBody(r, &rating); err != nil {
+			writeDecodeError(ctx, logger, w, err)
+			return
+		}
+		rating.UserID = userID
+
+		if problems := problemsOf(ctx, rating); len(problems) > 0 {
+			w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+			w.WriteHeader(http.StatusBadRequest)
+			if err := formatFrom(ctx).Encode(w, problems); err != nil {
+				logger.ErrorContext(ctx, "failed to encode validation problems",
+					slog.String("error", err.Error()))
+			}
+			return
+		}
+
+		if !userChecker.DoesUserExist(ctx, rating.UserID) {
+			http.Error(w, "User not found", http.StatusBadRequest)
+			return
+		}
+
+		blog, err := blogRater.RateBlog(ctx, uint(id64), rating)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to rate blog",
+				slog.Uint64("id", id64),
+				slog.String("error", err.Error()))
+
+			switch {
+			case strings.Contains(err.Error(), "no blog found"):
+				http.Error(w, "Blog not found", http.StatusNotFound)
+			case strings.Contains(err.Error(), "cannot rate their own"):
+				http.Error(w, "Authors cannot rate their own blog", http.StatusForbidden)
+			default:
+				http.Error(w, "Failed to rate blog", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		if err := formatFrom(ctx).Encode(w, blog); err != nil {
+			logger.ErrorContext(ctx, "failed to encode response",
+				slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
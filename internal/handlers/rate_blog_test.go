@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/models"
+)
+
+// fakeRater records the ratings it is given.
+type fakeRater struct {
+	ratings []models.Rating
+}
+
+func (f *fakeRater) RateBlog(ctx context.Context, blogID uint, rating models.Rating) (models.Blog, error) {
+	f.ratings = append(f.ratings, rating)
+	return models.Blog{ID: blogID}, nil
+}
+
+// everyUser is a userChecker for which every user exists.
+type everyUser struct{}
+
+func (everyUser) DoesUserExist(ctx context.Context, userID int) bool { return true }
+
+func TestHandleRateBlog(t *testing.T) {
+	tests := map[string]struct {
+		userID     int
+		body       string
+		wantStatus int
+		wantRater  int
+	}{
+		"rated as the authenticated user": {
+			userID:     7,
+			body:       `{"rating": 4}`,
+			wantStatus: http.StatusOK,
+			wantRater:  7,
+		},
+		"anonymous": {
+			body:       `{"rating": 4}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		"user_id can't be sent": {
+			userID:     7,
+			body:       `{"user_id": 8, "rating": 4}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"rating out of range": {
+			userID:     7,
+			body:       `{"rating": 6}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rater := &fakeRater{}
+			h := HandleRateBlog(slog.New(slog.DiscardHandler), rater, everyUser{})
+
+			req := httptest.NewRequest(http.MethodPut, "/api/blog/3/rating", strings.NewReader(tc.body))
+			req.SetPathValue("id", "3")
+			if tc.userID != 0 {
+				req = req.WithContext(auth.WithUserID(req.Context(), tc.userID))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body)
+			}
+			if tc.wantRater == 0 {
+				if len(rater.ratings) != 0 {
+					t.Errorf("want no rating, got %v", rater.ratings)
+				}
+				return
+			}
+			if len(rater.ratings) != 1 || rater.ratings[0].UserID != tc.wantRater {
+				t.Errorf("want a rating by user %d, got %v", tc.wantRater, rater.ratings)
+			}
+		})
+	}
+}
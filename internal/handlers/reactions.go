@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/models"
+)
+
+/*
+PUT	http://localhost:8000/api/blog/{id}/reactions/{type}
+DELETE	http://localhost:8000/api/blog/{id}/reactions/{type}
+GET	http://localhost:8000/api/blog/{id}/reactions?type=
+PUT	http://localhost:8000/api/comments/reactions/{type}?author_id=&blog_id=
+DELETE	http://localhost:8000/api/comments/reactions/{type}?author_id=&blog_id=
+GET	http://localhost:8000/api/comments/reactions?author_id=&blog_id=&type=
+Add and remove the authenticated user's reactions on blogs and comments, and
+list who reacted. Reaction types are emoji, such as 👍, percent-encoded in
+paths and queries.
+*/
+
+// reactionService represents a type capable of adding, removing and listing
+// reactions on blogs and comments.
+type reactionService interface {
+	React(ctx context.Context, target models.ReactionTarget, userID int, reactionType string) (models.Reaction, error)
+	Unreact(ctx context.Context, target models.ReactionTarget, userID int, reactionType string) error
+	ListReactions(ctx context.Context, target models.ReactionTarget, reactionType string) ([]models.Reaction, error)
+}
+
+// reactionTargetParser extracts the blog or comment a reaction request is
+// about.
+type reactionTargetParser func(r *http.Request) (models.ReactionTarget, error)
+
+// BlogReactionTarget reads the target blog from the {id} path value.
+func BlogReactionTarget(r *http.Request) (models.ReactionTarget, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return models.ReactionTarget{}, errors.New("Invalid blog ID")
+	}
+	return models.ReactionTarget{BlogID: id}, nil
+}
+
+// CommentReactionTarget reads the target comment from the author_id and
+// blog_id query parameters, mirroring the other comment endpoints.
+func CommentReactionTarget(r *http.Request) (models.ReactionTarget, error) {
+	authorID, err := strconv.Atoi(r.URL.Query().Get("author_id"))
+	if err != nil || authorID <= 0 {
+		return models.ReactionTarget{}, errors.New("Invalid author_id")
+	}
+	blogID, err := strconv.Atoi(r.URL.Query().Get("blog_id"))
+	if err != nil || blogID <= 0 {
+		return models.ReactionTarget{}, errors.New("Invalid blog_id")
+	}
+	return models.ReactionTarget{BlogID: blogID, CommentUserID: authorID}, nil
+}
+
+// writeReactionError maps a reaction service error onto a response.
+func writeReactionError(w http.ResponseWriter, err error) {
+	switch msg := err.Error(); {
+	case strings.Contains(msg, "unknown reaction type"):
+		http.Error(w, "Unknown reaction type", http.StatusBadRequest)
+	case strings.Contains(msg, "no blog found"):
+		http.Error(w, "Blog not found", http.StatusNotFound)
+	case strings.Contains(msg, "no comment found"):
+		http.Error(w, "Comment not found", http.StatusNotFound)
+	case strings.Contains(msg, "no reaction found"):
+		http.Error(w, "Reaction not found", http.StatusNotFound)
+	default:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// @Summary		React
+// @Description	Add the authenticated user's reaction to a blog or comment. Adding the same reaction twice has no effect.
+// @Tags			reactions
+// @Produce		json
+// @Security		BearerAuth
+// @Param			id			path		string			false	"Blog ID (blog reactions)"
+// @Param			author_id	query		string			false	"Comment author ID (comment reactions)"
+// @Param			blog_id		query		string			false	"Comment blog ID (comment reactions)"
+// @Param			type		path		string			true	"Reaction type, an emoji"
+// @Success		200			{object}	models.Reaction
+// @Failure		400			{object}	string
+// @Failure		401			{object}	string
+// @Failure		404			{object}	string
+// @Failure		500			{object}	string
+// @Router			/blog/{id}/reactions/{type} [put]
+// @Router			/comments/reactions/{type} [put]
+func HandleReact(logger *slog.Logger, parseTarget reactionTargetParser, reactions reactionService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		target, err := parseTarget(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		created, err := reactions.React(ctx, target, userID, r.PathValue("type"))
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to add reaction", slog.String("error", err.Error()))
+			writeReactionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		if err := formatFrom(ctx).Encode(w, created); err != nil {
+			logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
+
+// @Summary		Remove Reaction
+// @Description	Remove the authenticated user's reaction from a blog or comment
+// @Tags			reactions
+// @Security		BearerAuth
+// @Param			id			path		string	false	"Blog ID (blog reactions)"
+// @Param			author_id	query		string	false	"Comment author ID (comment reactions)"
+// @Param			blog_id		query		string	false	"Comment blog ID (comment reactions)"
+// @Param			type		path		string	true	"Reaction type, an emoji"
+// @Success		204			{object}	nil
+// @Failure		400			{object}	string
+// @Failure		401			{object}	string
+// @Failure		404			{object}	string
+// @Failure		500			{object}	string
+// @Router			/blog/{id}/reactions/{type} [delete]
+// @Router			/comments/reactions/{type} [delete]
+func HandleUnreact(logger *slog.Logger, parseTarget reactionTargetParser, reactions reactionService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		target, err := parseTarget(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := reactions.Unreact(ctx, target, userID, r.PathValue("type")); err != nil {
+			logger.ErrorContext(ctx, "failed to remove reaction", slog.String("error", err.Error()))
+			writeReactionError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// @Summary		List Reactions
+// @Description	List who reacted to a blog or comment, optionally filtered by reaction type
+// @Tags			reactions
+// @Produce		json
+// @Param			id			path		string	false	"Blog ID (blog reactions)"
+// @Param			author_id	query		string	false	"Comment author ID (comment reactions)"
+// @Param			blog_id		query		string	false	"Comment blog ID (comment reactions)"
+// @Param			type		query		string	false	"Filter by reaction type"
+// @Success		200			{array}		models.Reaction
+// @Failure		400			{object}	string
+// @Failure		404			{object}	string
+// @Failure		500			{object}	string
+// @Router			/blog/{id}/reactions [get]
+// @Router			/comments/reactions [get]
+func HandleListReactions(logger *slog.Logger, parseTarget reactionTargetParser, reactions reactionService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		target, err := parseTarget(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		list, err := reactions.ListReactions(ctx, target, r.URL.Query().Get("type"))
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to list reactions", slog.String("error", err.Error()))
+			writeReactionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		if err := formatFrom(ctx).Encode(w, list); err != nil {
+			logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/models"
+)
+
+// fakeReactions records who reacted and with what.
+type fakeReactions struct {
+	reactions []models.Reaction
+}
+
+func (f *fakeReactions) React(ctx context.Context, target models.ReactionTarget, userID int, reactionType string) (models.Reaction, error) {
+	reaction := models.Reaction{UserID: userID, BlogID: target.BlogID, CommentUserID: target.CommentUserID, Type: reactionType}
+	f.reactions = append(f.reactions, reaction)
+	return reaction, nil
+}
+
+func (f *fakeReactions) Unreact(ctx context.Context, target models.ReactionTarget, userID int, reactionType string) error {
+	f.reactions = append(f.reactions, models.Reaction{UserID: userID, BlogID: target.BlogID, Type: reactionType})
+	return nil
+}
+
+func (f *fakeReactions) ListReactions(ctx context.Context, target models.ReactionTarget, reactionType string) ([]models.Reaction, error) {
+	return f.reactions, nil
+}
+
+func TestHandleReact(t *testing.T) {
+	tests := map[string]struct {
+		handler    func(*slog.Logger, reactionTargetParser, reactionService) http.Handler
+		method     string
+		userID     int
+		wantStatus int
+	}{
+		"react as the authenticated user": {
+			handler:    HandleReact,
+			method:     http.MethodPut,
+			userID:     7,
+			wantStatus: http.StatusOK,
+		},
+		"unreact as the authenticated user": {
+			handler:    HandleUnreact,
+			method:     http.MethodDelete,
+			userID:     7,
+			wantStatus: http.StatusNoContent,
+		},
+		"anonymous react": {
+			handler:    HandleReact,
+			method:     http.MethodPut,
+			wantStatus: http.StatusUnauthorized,
+		},
+		"anonymous unreact": {
+			handler:    HandleUnreact,
+			method:     http.MethodDelete,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reactions := &fakeReactions{}
+			h := tc.handler(slog.New(slog.DiscardHandler), BlogReactionTarget, reactions)
+
+			// user_id in the query is ignored in favour of the token.
+			req := httptest.NewRequest(tc.method, "/api/blog/3/reactions/%F0%9F%91%8D?user_id=8", nil)
+			req.SetPathValue("id", "3")
+			req.SetPathValue("type", "👍")
+			if tc.userID != 0 {
+				req = req.WithContext(auth.WithUserID(req.Context(), tc.userID))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body)
+			}
+			if tc.userID == 0 {
+				if len(reactions.reactions) != 0 {
+					t.Errorf("want no reaction, got %v", reactions.reactions)
+				}
+				return
+			}
+			want := models.Reaction{UserID: tc.userID, BlogID: 3, Type: "👍"}
+			if len(reactions.reactions) != 1 || reactions.reactions[0] != want {
+				t.Errorf("want %v, got %v", want, reactions.reactions)
+			}
+		})
+	}
+}
@@ -2,16 +2,16 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/navid/blog/internal/models"
 )
 
-// readUserResponse represents the response for reading a user.
+// readUserResponse represents the response for reading a user in version 1
+// of the API. Password is always empty: passwords are stored hashed and never
+// shown, but the field is kept for clients that expect it.
 type readUserResponse struct {
 	ID       uint   `json:"id"`
 	Name     string `json:"name"`
@@ -19,6 +19,24 @@ type readUserResponse struct {
 	Password string `json:"password"`
 }
 
+// publicUser is a user as responses show them from version 2 of the API
+// on, without the password field.
+type publicUser struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// showUser returns what a response shows of user, never its password: with
+// an empty password field if withPasswordField, as in version 1 of the API,
+// or without one.
+func showUser(user models.User, withPasswordField bool) any {
+	if withPasswordField {
+		return readUserResponse{ID: user.ID, Name: user.Name, Email: user.Email}
+	}
+	return publicUser{ID: user.ID, Name: user.Name, Email: user.Email}
+}
+
 // userReader represents a type capable of reading a user from storage and
 // returning it or an error.
 type userReader interface {
@@ -35,21 +53,20 @@ type userReader interface {
 //	@Failure		400	{object}	string
 //	@Failure		404	{object}	string
 //	@Failure		500	{object}	string
-//	@Router			/users/{id}  [GET]
+//	@Router			/user/{id}  [GET]
 
-func HandleReadUser(logger *slog.Logger, userReader userReader) http.Handler {
+func HandleReadUser(logger *slog.Logger, userReader userReader, withPasswordField bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger.InfoContext(r.Context(), "HandleReadUser called", slog.String("path", r.URL.Path))
 
 		ctx := r.Context()
 
-		// Extract the "id" from the URL path
-		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-		if len(pathParts) < 3 || pathParts[2] == "" {
+		// Get id from path using built-in PathValue
+		idStr := r.PathValue("id")
+		if idStr == "" {
 			http.Error(w, "User ID not provided", http.StatusNotFound)
 			return
 		}
-		idStr := pathParts[2]
 
 		// Convert the ID from string to uint64
 		id, err := strconv.ParseUint(idStr, 10, 64)
@@ -68,15 +85,10 @@ func HandleReadUser(logger *slog.Logger, userReader userReader) http.Handler {
 		}
 
 		// Write the response as JSON
-		response := readUserResponse{
-			ID:       user.ID,
-			Name:     user.Name,
-			Email:    user.Email,
-			Password: user.Password,
-		}
-		w.Header().Set("Content-Type", "application/json")
+		response := showUser(user, withPasswordField)
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
 		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(response); err != nil {
+		if err := formatFrom(ctx).Encode(w, response); err != nil {
 			logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/models"
+)
+
+/*
+GET	http://localhost:8000/api/me/lists
+POST	http://localhost:8000/api/me/lists
+GET	http://localhost:8000/api/me/lists/{id}
+PUT	http://localhost:8000/api/me/lists/{id}
+DELETE	http://localhost:8000/api/me/lists/{id}
+PUT	http://localhost:8000/api/me/lists/{id}/items/{blog_id}
+DELETE	http://localhost:8000/api/me/lists/{id}/items/{blog_id}
+GET	http://localhost:8000/api/lists/{id}
+Manage the authenticated user's reading lists. Public lists can be read by
+anyone through /api/lists/{id}.
+*/
+
+// readingListService represents a type capable of managing reading lists.
+type readingListService interface {
+	ListReadingLists(ctx context.Context, userID int) ([]models.ReadingList, error)
+	CreateReadingList(ctx context.Context, list models.ReadingList) (models.ReadingList, error)
+	GetReadingList(ctx context.Context, id uint) (models.ReadingList, error)
+	UpdateReadingList(ctx context.Context, userID int, id uint, patch models.ReadingList) (models.ReadingList, error)
+	DeleteReadingList(ctx context.Context, userID int, id uint) error
+	SaveItem(ctx context.Context, userID int, listID uint, item models.ReadingListItem) (models.ReadingListItem, error)
+	RemoveItem(ctx context.Context, userID int, listID uint, blogID int) error
+}
+
+// writeReadingListError maps a reading list service error onto a response.
+func writeReadingListError(w http.ResponseWriter, err error) {
+	switch msg := err.Error(); {
+	case strings.Contains(msg, "no reading list found"):
+		http.Error(w, "Reading list not found", http.StatusNotFound)
+	case strings.Contains(msg, "no reading list item found"):
+		http.Error(w, "Reading list item not found", http.StatusNotFound)
+	case strings.Contains(msg, "no blog found"):
+		http.Error(w, "Blog not found", http.StatusNotFound)
+	default:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// parseListID reads the reading list id from the {id} path value, writing a
+// 400 if it is invalid.
+func parseListID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid reading list ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary		List Reading Lists
+// @Description	List the authenticated user's reading lists
+// @Tags			me
+// @Produce		json
+// @Security		BearerAuth
+// @Success		200	{array}		models.ReadingList
+// @Failure		401	{object}	string
+// @Failure		500	{object}	string
+// @Router			/me/lists [get]
+func HandleListReadingLists(logger *slog.Logger, lists readingListService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		result, err := lists.ListReadingLists(ctx, userID)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to list reading lists", slog.String("error", err.Error()))
+			writeReadingListError(w, err)
+			return
+		}
+
+		writeResponse(ctx, logger, w, http.StatusOK, result)
+	})
+}
+
+// @Summary		Create Reading List
+// @Description	Create a reading list for the authenticated user
+// @Tags			me
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @Param			list	body		models.ReadingList	true	"Reading list; name and public are read"
+// @Success		201		{object}	models.ReadingList
+// @Failure		400		{object}	string
+// @Failure		401		{object}	string
+// @Failure		500		{object}	string
+// @Router			/me/lists [post]
+func HandleCreateReadingList(logger *slog.Logger, lists readingListService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		list, ok := decodeValidOrWrite[models.ReadingList](logger, w, r)
+		if !ok {
+			return
+		}
+		list.UserID = userID
+
+		created, err := lists.CreateReadingList(ctx, list)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create reading list", slog.String("error", err.Error()))
+			writeReadingListError(w, err)
+			return
+		}
+
+		writeResponse(ctx, logger, w, http.StatusCreated, created)
+	})
+}
+
+// @Summary		Get Reading List
+// @Description	Get a reading list and its items in order. Private lists are only visible to their owner.
+// @Tags			me
+// @Produce		json
+// @Param			id	path		string	true	"Reading list ID"
+// @Success		200	{object}	models.ReadingList
+// @Failure		400	{object}	string
+// @Failure		401	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/me/lists/{id} [get]
+// @Router			/lists/{id} [get]
+func HandleGetReadingList(logger *slog.Logger, lists readingListService, requireOwner bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, authenticated := auth.UserID(ctx)
+		if requireOwner && !authenticated {
+			currentUser(w, r)
+			return
+		}
+
+		id, ok := parseListID(w, r)
+		if !ok {
+			return
+		}
+
+		list, err := lists.GetReadingList(ctx, id)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to get reading list", slog.String("error", err.Error()))
+			writeReadingListError(w, err)
+			return
+		}
+
+		// Lists that can't be seen are reported as missing so their existence
+		// isn't leaked
+		owner := authenticated && list.UserID == userID
+		if (requireOwner && !owner) || (!list.Public && !owner) {
+			http.Error(w, "Reading list not found", http.StatusNotFound)
+			return
+		}
+
+		writeResponse(ctx, logger, w, http.StatusOK, list)
+	})
+}
+
+// @Summary		Update Reading List
+// @Description	Rename a reading list or change whether it is public
+// @Tags			me
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @Param			id		path		string				true	"Reading list ID"
+// @Param			list	body		models.ReadingList	true	"Reading list; name and public are read"
+// @Success		200		{object}	models.ReadingList
+// @Failure		400		{object}	string
+// @Failure		401		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/me/lists/{id} [put]
+func HandleUpdateReadingList(logger *slog.Logger, lists readingListService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		id, ok := parseListID(w, r)
+		if !ok {
+			return
+		}
+
+		patch, ok := decodeValidOrWrite[models.ReadingList](logger, w, r)
+		if !ok {
+			return
+		}
+
+		updated, err := lists.UpdateReadingList(ctx, userID, id, patch)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to update reading list", slog.String("error", err.Error()))
+			writeReadingListError(w, err)
+			return
+		}
+
+		writeResponse(ctx, logger, w, http.StatusOK, updated)
+	})
+}
+
+// @Summary		Delete Reading List
+// @Description	Delete a reading list and its items
+// @Tags			me
+// @Security		BearerAuth
+// @Param			id	path		string	true	"Reading list ID"
+// @Success		204	{object}	nil
+// @Failure		400	{object}	string
+// @Failure		401	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/me/lists/{id} [delete]
+func HandleDeleteReadingList(logger *slog.Logger, lists readingListService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		id, ok := parseListID(w, r)
+		if !ok {
+			return
+		}
+
+		if err := lists.DeleteReadingList(ctx, userID, id); err != nil {
+			logger.ErrorContext(ctx, "failed to delete reading list", slog.String("error", err.Error()))
+			writeReadingListError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// @Summary		Save Reading List Item
+// @Description	Add a blog to a reading list, or move it and update its note. A missing or zero position appends to the end.
+// @Tags			me
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @Param			id		path		string					true	"Reading list ID"
+// @Param			blog_id	path		string					true	"Blog ID"
+// @Param			item	body		models.ReadingListItem	false	"Item; position and note are read"
+// @Success		200		{object}	models.ReadingListItem
+// @Failure		400		{object}	string
+// @Failure		401		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/me/lists/{id}/items/{blog_id} [put]
+func HandleSaveReadingListItem(logger *slog.Logger, lists readingListService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		id, ok := parseListID(w, r)
+		if !ok {
+			return
+		}
+
+		blogID, err := strconv.Atoi(r.PathValue("blog_id"))
+		if err != nil {
+			http.Error(w, "Invalid blog_id", http.StatusBadRequest)
+			return
+		}
+
+		var item models.ReadingListItem
+		if r.ContentLength != 0 {
+			if item, ok = decodeValidOrWrite[models.ReadingListItem](logger, w, r); !ok {
+				return
+			}
+		}
+		item.BlogID = blogID
+
+		saved, err := lists.SaveItem(ctx, userID, id, item)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to save reading list item", slog.String("error", err.Error()))
+			writeReadingListError(w, err)
+			return
+		}
+
+		writeResponse(ctx, logger, w, http.StatusOK, saved)
+	})
+}
+
+// @Summary		Remove Reading List Item
+// @Description	Remove a blog from a reading list
+// @Tags			me
+// @Security		BearerAuth
+// @Param			id		path		string	true	"Reading list ID"
+// @Param			blog_id	path		string	true	"Blog ID"
+// @Success		204		{object}	nil
+// @Failure		400		{object}	string
+// @Failure		401		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/me/lists/{id}/items/{blog_id} [delete]
+func HandleRemoveReadingListItem(logger *slog.Logger, lists readingListService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		id, ok := parseListID(w, r)
+		if !ok {
+			return
+		}
+
+		blogID, err := strconv.Atoi(r.PathValue("blog_id"))
+		if err != nil {
+			http.Error(w, "Invalid blog_id", http.StatusBadRequest)
+			return
+		}
+
+		if err := lists.RemoveItem(ctx, userID, id, blogID); err != nil {
+			logger.ErrorContext(ctx, "failed to remove reading list item", slog.String("error", err.Error()))
+			writeReadingListError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
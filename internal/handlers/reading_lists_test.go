@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/models"
+)
+
+// fakeReadingLists serves reading lists from memory.
+type fakeReadingLists struct {
+	lists map[uint]models.ReadingList
+}
+
+func (f *fakeReadingLists) ListReadingLists(ctx context.Context, userID int) ([]models.ReadingList, error) {
+	return nil, nil
+}
+
+func (f *fakeReadingLists) CreateReadingList(ctx context.Context, list models.ReadingList) (models.ReadingList, error) {
+	return list, nil
+}
+
+func (f *fakeReadingLists) GetReadingList(ctx context.Context, id uint) (models.ReadingList, error) {
+	list, ok := f.lists[id]
+	if !ok {
+		return models.ReadingList{}, fmt.Errorf("no reading list found with id: %d", id)
+	}
+	return list, nil
+}
+
+func (f *fakeReadingLists) UpdateReadingList(ctx context.Context, userID int, id uint, patch models.ReadingList) (models.ReadingList, error) {
+	return patch, nil
+}
+
+func (f *fakeReadingLists) DeleteReadingList(ctx context.Context, userID int, id uint) error {
+	return nil
+}
+
+func (f *fakeReadingLists) SaveItem(ctx context.Context, userID int, listID uint, item models.ReadingListItem) (models.ReadingListItem, error) {
+	return item, nil
+}
+
+func (f *fakeReadingLists) RemoveItem(ctx context.Context, userID int, listID uint, blogID int) error {
+	return nil
+}
+
+func TestHandleGetReadingList(t *testing.T) {
+	lists := &fakeReadingLists{lists: map[uint]models.ReadingList{
+		1: {ID: 1, UserID: 7, Name: "Shared", Public: true},
+		2: {ID: 2, UserID: 7, Name: "Private"},
+	}}
+
+	tests := map[string]struct {
+		requireOwner bool
+		listID       string
+		userID       int
+		wantStatus   int
+	}{
+		"public list, anonymous": {
+			listID:     "1",
+			wantStatus: http.StatusOK,
+		},
+		"public list, another user": {
+			listID:     "1",
+			userID:     8,
+			wantStatus: http.StatusOK,
+		},
+		"private list, anonymous": {
+			listID:     "2",
+			wantStatus: http.StatusNotFound,
+		},
+		"private list, another user": {
+			listID:     "2",
+			userID:     8,
+			wantStatus: http.StatusNotFound,
+		},
+		"private list, owner": {
+			listID:     "2",
+			userID:     7,
+			wantStatus: http.StatusOK,
+		},
+		"missing list": {
+			listID:     "3",
+			wantStatus: http.StatusNotFound,
+		},
+		"invalid id": {
+			listID:     "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		"me, anonymous": {
+			requireOwner: true,
+			listID:       "1",
+			wantStatus:   http.StatusUnauthorized,
+		},
+		"me, another user's public list": {
+			requireOwner: true,
+			listID:       "1",
+			userID:       8,
+			wantStatus:   http.StatusNotFound,
+		},
+		"me, another user's private list": {
+			requireOwner: true,
+			listID:       "2",
+			userID:       8,
+			wantStatus:   http.StatusNotFound,
+		},
+		"me, owner's private list": {
+			requireOwner: true,
+			listID:       "2",
+			userID:       7,
+			wantStatus:   http.StatusOK,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := HandleGetReadingList(slog.New(slog.DiscardHandler), lists, tc.requireOwner)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/lists/"+tc.listID, nil)
+			req.SetPathValue("id", tc.listID)
+			if tc.userID != 0 {
+				req = req.WithContext(auth.WithUserID(req.Context(), tc.userID))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+func TestHandleSaveReadingListItem(t *testing.T) {
+	tests := map[string]struct {
+		userID     int
+		blogID     string
+		wantStatus int
+	}{
+		"owner": {
+			userID:     7,
+			blogID:     "4",
+			wantStatus: http.StatusOK,
+		},
+		"anonymous": {
+			blogID:     "4",
+			wantStatus: http.StatusUnauthorized,
+		},
+		"invalid blog id": {
+			userID:     7,
+			blogID:     "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := HandleSaveReadingListItem(slog.New(slog.DiscardHandler), &fakeReadingLists{})
+
+			req := httptest.NewRequest(http.MethodPut, "/api/me/lists/1/items/"+tc.blogID, nil)
+			req.SetPathValue("id", "1")
+			req.SetPathValue("blog_id", tc.blogID)
+			if tc.userID != 0 {
+				req = req.WithContext(auth.WithUserID(req.Context(), tc.userID))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body)
+			}
+		})
+	}
+}
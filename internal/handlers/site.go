@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/models"
+)
+
+/*
+GET	http://localhost:8000/
+GET	http://localhost:8000/blog/{id}
+GET	http://localhost:8000/author/{id}
+GET	http://localhost:8000/tag/{tag}
+Server-rendered HTML pages for reading the blog in a browser.
+*/
+
+// sitePageSize is the number of blogs listed on index, author and tag pages.
+const sitePageSize = 50
+
+// pageRenderer represents a type capable of rendering a named HTML page.
+type pageRenderer interface {
+	Render(w http.ResponseWriter, status int, page string, data any) error
+}
+
+// commentLister represents a type capable of listing comments, optionally
+// filtered by author and blog.
+type commentLister interface {
+	ListComments(ctx context.Context, authorID, blogID *int) ([]models.Comment, error)
+}
+
+// page holds the fields the site layout reads from every page.
+type page struct {
+	Title string
+	// FeedPath is the path of the page's syndication feed without its
+	// extension, advertised in the page head.
+	FeedPath string
+}
+
+// errorPage is the data for the error page.
+type errorPage struct {
+	page
+	Message string
+}
+
+// blogView is a blog along with its author's name.
+type blogView struct {
+	models.Blog
+	AuthorName string
+}
+
+// commentView is a comment along with its author's name.
+type commentView struct {
+	models.Comment
+	UserName string
+}
+
+// listPage is the data for pages listing blogs.
+type listPage struct {
+	page
+	Blogs  []blogView
+	Author models.User
+	Tag    string
+}
+
+// blogPage is the data for a single blog page.
+type blogPage struct {
+	page
+	Blog     blogView
+	Comments []commentView
+}
+
+// renderPage renders a page, logging and falling back to a plain 500 if the
+// template fails.
+func renderPage(logger *slog.Logger, renderer pageRenderer, w http.ResponseWriter, r *http.Request, status int, name string, data any) {
+	if err := renderer.Render(w, status, name, data); err != nil {
+		logger.ErrorContext(r.Context(), "failed to render page", slog.String("page", name), slog.String("error", err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// renderError renders the error page with the status code's text as its
+// title.
+func renderError(logger *slog.Logger, renderer pageRenderer, w http.ResponseWriter, r *http.Request, status int, message string) {
+	renderPage(logger, renderer, w, r, status, "error", errorPage{
+		page:    page{Title: http.StatusText(status)},
+		Message: message,
+	})
+}
+
+// blogViews pairs each blog with its author's name.
+func blogViews(ctx context.Context, authors *authorNames, blogs []models.Blog) ([]blogView, error) {
+	views := make([]blogView, 0, len(blogs))
+	for _, blog := range blogs {
+		name, err := authors.name(ctx, blog.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, blogView{Blog: blog, AuthorName: name})
+	}
+	return views, nil
+}
+
+// HandleIndexPage renders the most recent blogs.
+func HandleIndexPage(logger *slog.Logger, renderer pageRenderer, blogLister blogLister, userReader userReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		blogs, err := blogLister.ListBlogs(ctx, models.BlogFilter{OrderByNewest: true, Limit: sitePageSize})
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to list blogs", slog.String("error", err.Error()))
+			renderError(logger, renderer, w, r, http.StatusInternalServerError, "The blogs could not be loaded.")
+			return
+		}
+
+		views, err := blogViews(ctx, newAuthorNames(userReader), blogs)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to read authors", slog.String("error", err.Error()))
+			renderError(logger, renderer, w, r, http.StatusInternalServerError, "The blogs could not be loaded.")
+			return
+		}
+
+		renderPage(logger, renderer, w, r, http.StatusOK, "index", listPage{
+			page:  page{Title: "Latest blogs", FeedPath: "/feeds/blog"},
+			Blogs: views,
+		})
+	})
+}
+
+// HandleBlogPage renders a blog with its comments.
+func HandleBlogPage(logger *slog.Logger, renderer pageRenderer, blogReader blogReader, commentLister commentLister, userReader userReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+		if err != nil {
+			renderError(logger, renderer, w, r, http.StatusNotFound, "There is no such blog.")
+			return
+		}
+
+		blog, err := blogReader.GetBlog(ctx, uint(id))
+		if err != nil {
+			if strings.Contains(err.Error(), "no blog found") {
+				renderError(logger, renderer, w, r, http.StatusNotFound, "There is no such blog.")
+				return
+			}
+			logger.ErrorContext(ctx, "failed to get blog", slog.String("error", err.Error()))
+			renderError(logger, renderer, w, r, http.StatusInternalServerError, "The blog could not be loaded.")
+			return
+		}
+
+		blogID := int(blog.ID)
+		comments, err := commentLister.ListComments(ctx, nil, &blogID)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to list comments", slog.String("error", err.Error()))
+			renderError(logger, renderer, w, r, http.StatusInternalServerError, "The blog could not be loaded.")
+			return
+		}
+
+		authors := newAuthorNames(userReader)
+		views, err := blogViews(ctx, authors, []models.Blog{blog})
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to read author", slog.String("error", err.Error()))
+			renderError(logger, renderer, w, r, http.StatusInternalServerError, "The blog could not be loaded.")
+			return
+		}
+
+		data := blogPage{
+			page: page{Title: blog.Title, FeedPath: "/feeds/author/" + strconv.Itoa(blog.AuthorID) + "/blog"},
+			Blog: views[0],
+		}
+		for _, comment := range comments {
+			name, err := authors.name(ctx, comment.UserID)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to read commenter", slog.String("error", err.Error()))
+				renderError(logger, renderer, w, r, http.StatusInternalServerError, "The blog could not be loaded.")
+				return
+			}
+			data.Comments = append(data.Comments, commentView{Comment: comment, UserName: name})
+		}
+
+		renderPage(logger, renderer, w, r, http.StatusOK, "blog", data)
+	})
+}
+
+// HandleAuthorPage renders an author's most recent blogs.
+func HandleAuthorPage(logger *slog.Logger, renderer pageRenderer, blogLister blogLister, userReader userReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id <= 0 {
+			renderError(logger, renderer, w, r, http.StatusNotFound, "There is no such author.")
+			return
+		}
+
+		author, err := userReader.ReadUser(ctx, uint64(id))
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to read author", slog.String("error", err.Error()))
+			renderError(logger, renderer, w, r, http.StatusInternalServerError, "The author could not be loaded.")
+			return
+		}
+		if author.ID == 0 {
+			renderError(logger, renderer, w, r, http.StatusNotFound, "There is no such author.")
+			return
+		}
+
+		blogs, err := blogLister.ListBlogs(ctx, models.BlogFilter{AuthorID: id, OrderByNewest: true, Limit: sitePageSize})
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to list blogs", slog.String("error", err.Error()))
+			renderError(logger, renderer, w, r, http.StatusInternalServerError, "The blogs could not be loaded.")
+			return
+		}
+
+		views := make([]blogView, 0, len(blogs))
+		for _, blog := range blogs {
+			views = append(views, blogView{Blog: blog, AuthorName: author.Name})
+		}
+
+		renderPage(logger, renderer, w, r, http.StatusOK, "author", listPage{
+			page:   page{Title: "Blogs by " + author.Name, FeedPath: "/feeds/author/" + strconv.Itoa(id) + "/blog"},
+			Blogs:  views,
+			Author: author,
+		})
+	})
+}
+
+// HandleTagPage renders the most recent blogs carrying a tag.
+func HandleTagPage(logger *slog.Logger, renderer pageRenderer, blogLister blogLister, userReader userReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		tag := parseTag(r.PathValue("tag"))
+		if tag == "" {
+			renderError(logger, renderer, w, r, http.StatusNotFound, "There is no such tag.")
+			return
+		}
+
+		blogs, err := blogLister.ListBlogs(ctx, models.BlogFilter{Tag: tag, OrderByNewest: true, Limit: sitePageSize})
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to list blogs", slog.String("error", err.Error()))
+			renderError(logger, renderer, w, r, http.StatusInternalServerError, "The blogs could not be loaded.")
+			return
+		}
+
+		views, err := blogViews(ctx, newAuthorNames(userReader), blogs)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to read authors", slog.String("error", err.Error()))
+			renderError(logger, renderer, w, r, http.StatusInternalServerError, "The blogs could not be loaded.")
+			return
+		}
+
+		renderPage(logger, renderer, w, r, http.StatusOK, "tag", listPage{
+			page:  page{Title: "Blogs tagged " + tag, FeedPath: "/feeds/tag/" + url.PathEscape(tag) + "/blog"},
+			Blogs: views,
+			Tag:   tag,
+		})
+	})
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/navid/blog/internal/models"
+	"github.com/navid/blog/internal/web"
+)
+
+type stubBlogs struct {
+	blogs  []models.Blog
+	err    error
+	filter models.BlogFilter
+}
+
+func (s *stubBlogs) ListBlogs(ctx context.Context, filter models.BlogFilter) ([]models.Blog, error) {
+	s.filter = filter
+	return s.blogs, s.err
+}
+
+func (s *stubBlogs) GetBlog(ctx context.Context, id uint) (models.Blog, error) {
+	for _, blog := range s.blogs {
+		if blog.ID == id {
+			return blog, nil
+		}
+	}
+	return models.Blog{}, errors.New("no blog found with id")
+}
+
+func (s *stubBlogs) ListComments(ctx context.Context, authorID, blogID *int) ([]models.Comment, error) {
+	return []models.Comment{{UserID: 2, BlogID: *blogID, Message: "Great <b>post</b>!", CreatedDate: time.Now()}}, nil
+}
+
+type stubUsers map[uint64]string
+
+func (s stubUsers) ReadUser(ctx context.Context, id uint64) (models.User, error) {
+	name, ok := s[id]
+	if !ok {
+		return models.User{}, nil
+	}
+	return models.User{ID: uint(id), Name: name}, nil
+}
+
+func TestSitePages(t *testing.T) {
+	renderer, err := web.NewRenderer()
+	if err != nil {
+		t.Fatalf("failed to parse templates: %v", err)
+	}
+
+	blogs := &stubBlogs{blogs: []models.Blog{
+		{ID: 1, Title: "First Blog Post", AuthorID: 1, CreatedAt: time.Date(2024, 5, 14, 9, 0, 0, 0, time.UTC), Tags: []string{"meta"}, RatingAverage: 4, RatingCount: 2},
+	}}
+	users := stubUsers{1: "John Doe", 2: "Jane Smith"}
+	logger := slog.Default()
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /{$}", HandleIndexPage(logger, renderer, blogs, users))
+	mux.Handle("GET /blog/{id}", HandleBlogPage(logger, renderer, blogs, blogs, users))
+	mux.Handle("GET /author/{id}", HandleAuthorPage(logger, renderer, blogs, users))
+	mux.Handle("GET /tag/{tag}", HandleTagPage(logger, renderer, blogs, users))
+
+	testcases := map[string]struct {
+		path       string
+		wantStatus int
+		wantBody   []string
+	}{
+		"index": {
+			path:       "/",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`<a class="blog-title" href="/blog/1">First Blog Post</a>`, `John Doe`, `href="/feeds/blog.rss"`},
+		},
+		"blog": {
+			path:       "/blog/1",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`<h1>First Blog Post</h1>`, `from 2 ratings`, `Jane Smith`, `Great &lt;b&gt;post&lt;/b&gt;!`},
+		},
+		"blog not found": {
+			path:       "/blog/9",
+			wantStatus: http.StatusNotFound,
+			wantBody:   []string{`There is no such blog.`},
+		},
+		"author": {
+			path:       "/author/1",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`<h1>Blogs by John Doe</h1>`, `href="/feeds/author/1/blog.atom"`},
+		},
+		"author not found": {
+			path:       "/author/7",
+			wantStatus: http.StatusNotFound,
+			wantBody:   []string{`There is no such author.`},
+		},
+		"tag": {
+			path:       "/tag/Meta",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`#meta`, `href="/feeds/tag/meta/blog.json"`},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+				t.Errorf("want an html content type, got %q", ct)
+			}
+			for _, want := range tc.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("want body to contain %s, got:\n%s", want, rec.Body.String())
+				}
+			}
+		})
+	}
+}
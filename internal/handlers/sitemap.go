@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/models"
+	"github.com/navid/blog/internal/sitemap"
+)
+
+/*
+GET	http://localhost:8000/sitemap.xml
+GET	http://localhost:8000/sitemaps/{n}.xml
+GET	http://localhost:8000/robots.txt
+Help search engines discover the public site.
+*/
+
+// sitemapCache represents a type capable of serving a possibly sharded
+// sitemap.
+type sitemapCache interface {
+	Root(ctx context.Context) (sitemap.Document, error)
+	Shard(ctx context.Context, n int) (sitemap.Document, bool, error)
+}
+
+// blogModTimeLister represents a type capable of listing when each blog last
+// changed.
+type blogModTimeLister interface {
+	ListBlogModTimes(ctx context.Context) ([]models.ModTime, error)
+}
+
+// authorModTimeLister represents a type capable of listing when each author's
+// page last changed.
+type authorModTimeLister interface {
+	ListAuthorModTimes(ctx context.Context) ([]models.ModTime, error)
+}
+
+// SitemapSource lists the index page, every blog page and every author page
+// of the site, with the index modified whenever any blog is.
+func SitemapSource(baseURL string, blogs blogModTimeLister, authors authorModTimeLister) sitemap.Source {
+	return func(ctx context.Context) ([]sitemap.URL, error) {
+		blogTimes, err := blogs.ListBlogModTimes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		authorTimes, err := authors.ListAuthorModTimes(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		urls := make([]sitemap.URL, 0, 1+len(blogTimes)+len(authorTimes))
+		urls = append(urls, sitemap.URL{Loc: baseURL + "/"})
+		for _, m := range blogTimes {
+			urls = append(urls, sitemap.URL{Loc: fmt.Sprintf("%s/blog/%d", baseURL, m.ID), LastMod: m.Modified})
+			if m.Modified.After(urls[0].LastMod) {
+				urls[0].LastMod = m.Modified
+			}
+		}
+		for _, m := range authorTimes {
+			urls = append(urls, sitemap.URL{Loc: fmt.Sprintf("%s/author/%d", baseURL, m.ID), LastMod: m.Modified})
+		}
+
+		return urls, nil
+	}
+}
+
+// serveSitemap writes a sitemap document, answering conditional requests.
+func serveSitemap(w http.ResponseWriter, r *http.Request, doc sitemap.Document) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	http.ServeContent(w, r, "", doc.Modified, bytes.NewReader(doc.Body))
+}
+
+// @Summary		Sitemap
+// @Description	XML sitemap of the public site. Becomes a sitemap index pointing at /sitemaps/{n}.xml past 50,000 URLs.
+// @Tags			site
+// @Produce		xml
+// @Success		200	{object}	string
+// @Success		304	{object}	nil
+// @Failure		500	{object}	string
+// @Router			/sitemap.xml [get]
+func HandleSitemap(logger *slog.Logger, cache sitemapCache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		doc, err := cache.Root(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to build sitemap", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		serveSitemap(w, r, doc)
+	})
+}
+
+// @Summary		Sitemap Shard
+// @Description	One shard of a sitemap too large for a single file
+// @Tags			site
+// @Produce		xml
+// @Param			n	path		string	true	"Shard number, counting from 1"
+// @Success		200	{object}	string
+// @Success		304	{object}	nil
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/sitemaps/{n}.xml [get]
+func HandleSitemapShard(logger *slog.Logger, cache sitemapCache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		name, ok := strings.CutSuffix(r.PathValue("file"), ".xml")
+		n, err := strconv.Atoi(name)
+		if !ok || err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		doc, ok, err := cache.Shard(ctx, n)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to build sitemap", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		serveSitemap(w, r, doc)
+	})
+}
+
+// @Summary		Robots
+// @Description	robots.txt allowing the public site, keeping crawlers out of the API, and pointing at the sitemap
+// @Tags			site
+// @Produce		plain
+// @Success		200	{object}	string
+// @Router			/robots.txt [get]
+func HandleRobots(baseURL string) http.Handler {
+	body := []byte("User-agent: *\n" +
+		"Disallow: /api/\n" +
+		"Disallow: /swagger/\n" +
+		"Allow: /\n" +
+		"\n" +
+		"Sitemap: " + baseURL + "/sitemap.xml\n")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		_, _ = w.Write(body)
+	})
+}
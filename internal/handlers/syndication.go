@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/navid/blog/internal/models"
+	"github.com/navid/blog/internal/syndication"
+)
+
+/*
+GET	http://localhost:8000/feeds/blog.{rss,atom,json}
+GET	http://localhost:8000/feeds/author/{id}/blog.{rss,atom,json}
+GET	http://localhost:8000/feeds/tag/{tag}/blog.{rss,atom,json}
+Syndicate the most recent blogs as RSS 2.0, Atom or JSON Feed 1.1, site-wide,
+for one author or for one tag.
+*/
+
+// syndicationSize is the number of blogs included in a feed.
+const syndicationSize = 50
+
+// @Summary		Blog Feed
+// @Description	Syndicate the most recent blogs as RSS 2.0, Atom or JSON Feed 1.1. Supports conditional GET via If-Modified-Since and If-None-Match.
+// @Tags			feeds
+// @Produce		xml
+// @Produce		json
+// @Param			id	path		string	false	"Author ID (author feeds)"
+// @Param			tag	path		string	false	"Tag (tag feeds)"
+// @Success		200	{object}	string
+// @Success		304	{object}	nil
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/feeds/blog.rss [get]
+// @Router			/feeds/blog.atom [get]
+// @Router			/feeds/blog.json [get]
+// @Router			/feeds/author/{id}/blog.rss [get]
+// @Router			/feeds/author/{id}/blog.atom [get]
+// @Router			/feeds/author/{id}/blog.json [get]
+// @Router			/feeds/tag/{tag}/blog.rss [get]
+// @Router			/feeds/tag/{tag}/blog.atom [get]
+// @Router			/feeds/tag/{tag}/blog.json [get]
+func HandleBlogFeed(logger *slog.Logger, blogLister blogLister, userReader userReader, format syndication.Format, baseURL string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		filter := models.BlogFilter{OrderByNewest: true, Limit: syndicationSize}
+		feed := syndication.Feed{
+			Title:       "Blog",
+			Description: "The latest blogs",
+			Link:        baseURL + "/",
+			FeedURL:     baseURL + r.URL.Path,
+		}
+
+		// Narrow the feed to an author or a tag if the route has one
+		authors := newAuthorNames(userReader)
+		if idStr := r.PathValue("id"); idStr != "" {
+			id, err := strconv.Atoi(idStr)
+			if err != nil || id <= 0 {
+				http.Error(w, "Invalid author ID", http.StatusBadRequest)
+				return
+			}
+			name, err := authors.name(ctx, id)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to read author", slog.String("error", err.Error()))
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			if name == "" {
+				http.Error(w, "Author not found", http.StatusNotFound)
+				return
+			}
+			filter.AuthorID = id
+			feed.Title = "Blogs by " + name
+			feed.Description = "The latest blogs by " + name
+			feed.Link = baseURL + "/author/" + idStr
+		}
+		if tagStr := r.PathValue("tag"); tagStr != "" {
+			tag := parseTag(tagStr)
+			if tag == "" {
+				http.Error(w, "Invalid tag", http.StatusBadRequest)
+				return
+			}
+			filter.Tag = tag
+			feed.Title = "Blogs tagged " + tag
+			feed.Description = "The latest blogs tagged " + tag
+			feed.Link = baseURL + "/tag/" + url.PathEscape(tag)
+		}
+
+		blogs, err := blogLister.ListBlogs(ctx, filter)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to list blogs for feed", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		for _, blog := range blogs {
+			name, err := authors.name(ctx, blog.AuthorID)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to read author", slog.String("error", err.Error()))
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			link := fmt.Sprintf("%s/blog/%d", baseURL, blog.ID)
+			var updated time.Time
+			if blog.UpdatedAt != nil {
+				updated = *blog.UpdatedAt
+			}
+			feed.Items = append(feed.Items, syndication.Item{
+				ID:         link,
+				Title:      blog.Title,
+				Link:       link,
+				Summary:    blog.Title,
+				AuthorName: name,
+				AuthorLink: fmt.Sprintf("%s/author/%d", baseURL, blog.AuthorID),
+				Published:  blog.CreatedAt,
+				Updated:    updated,
+				Tags:       blog.Tags,
+			})
+		}
+		// Edits count as changes, so an edited blog isn't answered with 304
+		// Not Modified
+		feed.Updated = syndication.LastModified(feed.Items)
+
+		var buf bytes.Buffer
+		if err := format.Write(&buf, feed); err != nil {
+			logger.ErrorContext(ctx, "failed to render feed", slog.String("error", err.Error()))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		// Last-Modified alone misses deletions, so an ETag of the rendered
+		// feed is sent too. ServeContent answers conditional requests with
+		// 304 Not Modified.
+		sum := sha256.Sum256(buf.Bytes())
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		http.ServeContent(w, r, "", feed.Updated, bytes.NewReader(buf.Bytes()))
+	})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/navid/blog/internal/models"
+	"github.com/navid/blog/internal/syndication"
+)
+
+func TestHandleBlogFeedConditional(t *testing.T) {
+	created := time.Date(2024, 5, 14, 9, 0, 0, 0, time.UTC)
+	edited := created.Add(48 * time.Hour)
+
+	tests := map[string]struct {
+		blog       models.Blog
+		wantStatus int
+	}{
+		"unchanged": {
+			blog:       models.Blog{ID: 1, Title: "First Blog Post", AuthorID: 1, CreatedAt: created},
+			wantStatus: http.StatusNotModified,
+		},
+		"edited": {
+			blog:       models.Blog{ID: 1, Title: "First Blog Post, revised", AuthorID: 1, CreatedAt: created, UpdatedAt: &edited},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			blogs := &stubBlogs{blogs: []models.Blog{tc.blog}}
+			h := HandleBlogFeed(slog.New(slog.DiscardHandler), blogs, stubUsers{1: "John Doe"}, syndication.Atom, "http://localhost:8000")
+
+			// The client last fetched the feed before the blog was edited
+			req := httptest.NewRequest(http.MethodGet, "/feeds/blog.atom", nil)
+			req.Header.Set("If-Modified-Since", created.Format(http.TimeFormat))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.blog.UpdatedAt == nil {
+				return
+			}
+			if got, want := rec.Header().Get("Last-Modified"), edited.Format(http.TimeFormat); got != want {
+				t.Errorf("want Last-Modified %s, got %s", want, got)
+			}
+		})
+	}
+}
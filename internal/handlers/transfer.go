@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/navid/blog/internal/models"
+	"github.com/navid/blog/internal/render"
+	"github.com/navid/blog/internal/validate"
+)
+
+/*
+POST	http://localhost:8000/api/admin/import?kind=users&mode=atomic&dry_run=true
+GET	http://localhost:8000/api/admin/export
+Bulk import and export of users, blogs and comments, for administrators.
+*/
+
+// importer represents a type capable of writing imported rows to storage.
+type importer interface {
+	Import(ctx context.Context, rows []models.ImportRow, opts models.ImportOptions) (models.ImportResult, error)
+}
+
+// exporter represents a type capable of writing an archive of all data.
+type exporter interface {
+	Export(ctx context.Context, w io.Writer) error
+}
+
+// @Summary		Import
+// @Description	Import users, blogs or comments from an NDJSON or CSV body, one per line or record. Rows that can't be imported are reported by their position in the body. An atomic import writes nothing if any row fails, a best effort import writes every row it can; a dry run reports what would happen without writing anything. Users' passwords must be bcrypt hashes, as in an export.
+// @Tags			admin
+// @Accept			application/x-ndjson
+// @Accept			text/csv
+// @Produce		json
+// @Security		BearerAuth
+// @Param			kind	query		string	true	"Kind of rows (users, blogs, comments)"
+// @Param			mode	query		string	false	"atomic (default) or best_effort"
+// @Param			dry_run	query		bool	false	"Report without writing"
+// @Success		200		{object}	models.ImportResult
+// @Failure		400		{object}	string
+// @Failure		401		{object}	string
+// @Failure		403		{object}	string
+// @Failure		413		{object}	string
+// @Failure		415		{object}	string
+// @Failure		422		{object}	models.ImportResult
+// @Failure		500		{object}	string
+// @Router			/admin/import [post]
+func HandleImport(logger *slog.Logger, importer importer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		query := r.URL.Query()
+
+		kind := query.Get("kind")
+		readRows, ok := importKinds[kind]
+		if !ok {
+			http.Error(w, "kind must be one of users, blogs, comments", http.StatusBadRequest)
+			return
+		}
+		opts := models.ImportOptions{Mode: models.ImportAtomic}
+		if mode := query.Get("mode"); mode != "" {
+			if mode != models.ImportAtomic && mode != models.ImportBestEffort {
+				http.Error(w, "mode must be atomic or best_effort", http.StatusBadRequest)
+				return
+			}
+			opts.Mode = mode
+		}
+		if s := query.Get("dry_run"); s != "" {
+			dryRun, err := strconv.ParseBool(s)
+			if err != nil {
+				http.Error(w, "dry_run must be true or false", http.StatusBadRequest)
+				return
+			}
+			opts.DryRun = dryRun
+		}
+
+		format, ok := render.ForContentType(r.Header.Get("Content-Type"))
+		var dec render.RowDecoder
+		if ok {
+			dec, ok = render.NewRowDecoder(format, r.Body)
+		}
+		if !ok {
+			http.Error(w, "Unsupported Media Type: imports may be "+mediaTypes([]render.Format{render.NDJSON, render.CSV}), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		rows, rowErrors, err := readRows(ctx, dec)
+		if err != nil {
+			writeDecodeError(ctx, logger, w, decodeProblem(err))
+			return
+		}
+
+		// An atomic import that already has bad rows still runs the rest, to
+		// report every error, but can't write anything
+		writeOpts := opts
+		if opts.Mode == models.ImportAtomic && len(rowErrors) > 0 {
+			writeOpts.DryRun = true
+		}
+		result, err := importer.Import(ctx, rows, writeOpts)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to import rows", slog.String("kind", kind), slog.String("error", err.Error()))
+			http.Error(w, "Failed to import rows", http.StatusInternalServerError)
+			return
+		}
+
+		result.Kind = kind
+		result.Mode = opts.Mode
+		result.DryRun = opts.DryRun
+		result.Rows = len(rows) + len(rowErrors)
+		result.Errors = append(result.Errors, rowErrors...)
+		slices.SortFunc(result.Errors, func(a, b models.ImportError) int { return a.Row - b.Row })
+		result.Failed = len(result.Errors)
+
+		logger.InfoContext(ctx, "rows imported",
+			slog.String("kind", kind),
+			slog.String("mode", opts.Mode),
+			slog.Bool("dry_run", opts.DryRun),
+			slog.Int("imported", result.Imported),
+			slog.Int("failed", result.Failed),
+			slog.Bool("committed", result.Committed))
+
+		status := http.StatusOK
+		if opts.Mode == models.ImportAtomic && result.Failed > 0 {
+			status = http.StatusUnprocessableEntity
+		}
+		writeResponse(ctx, logger, w, status, result)
+	})
+}
+
+// importKinds maps each kind of import to the function reading its rows.
+var importKinds = map[string]func(context.Context, render.RowDecoder) ([]models.ImportRow, []models.ImportError, error){
+	models.ImportUsers:    readImportRows[models.User],
+	models.ImportBlogs:    readImportRows[models.Blog],
+	models.ImportComments: readImportRows[models.Comment],
+}
+
+// readImportRows decodes and validates every row from dec, returning the
+// valid ones and the problems with the rest. An error is returned if the body
+// can't be read any further.
+func readImportRows[T validator](ctx context.Context, dec render.RowDecoder) ([]models.ImportRow, []models.ImportError, error) {
+	var (
+		rows      []models.ImportRow
+		rowErrors []models.ImportError
+	)
+	for n := 1; ; n++ {
+		var v T
+		err := dec.Next(&v)
+		if errors.Is(err, io.EOF) {
+			return rows, rowErrors, nil
+		}
+		if errors.As(err, new(*http.MaxBytesError)) || errors.Is(err, render.ErrMalformed) {
+			return nil, nil, err
+		}
+
+		var problems map[string]string
+		if err != nil {
+			var bodyErr *bodyError
+			if errors.As(decodeProblem(err), &bodyErr) {
+				problems = bodyErr.problems
+			} else {
+				problems = map[string]string{rootPath: err.Error()}
+			}
+		} else {
+			problems = validate.Struct(v)
+			maps.Copy(problems, v.Valid(ctx))
+		}
+
+		if len(problems) > 0 {
+			rowErrors = append(rowErrors, models.ImportError{Row: n, Problems: problems})
+			continue
+		}
+		rows = append(rows, models.ImportRow{Row: n, Value: v})
+	}
+}
+
+// @Summary		Export
+// @Description	Download a zip archive of every user, blog and comment, as CSV files in the form an import reads, with a manifest. The archive is a consistent snapshot.
+// @Tags			admin
+// @Produce		application/zip
+// @Security		BearerAuth
+// @Success		200	{file}		file
+// @Failure		401	{object}	string
+// @Failure		403	{object}	string
+// @Failure		500	{object}	string
+// @Router			/admin/export [get]
+func HandleExport(logger *slog.Logger, exporter exporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="export-%s.zip"`, time.Now().UTC().Format("20060102T150405Z")))
+
+		out := &startedWriter{w: w}
+		if err := exporter.Export(ctx, out); err != nil {
+			logger.ErrorContext(ctx, "failed to export", slog.String("error", err.Error()))
+			if !out.started {
+				w.Header().Del("Content-Disposition")
+				http.Error(w, "Failed to export", http.StatusInternalServerError)
+			}
+			// An archive cut short has no central directory, so it can't be
+			// mistaken for a complete one
+			return
+		}
+		logger.InfoContext(ctx, "data exported", slog.Int64("bytes", out.n))
+	})
+}
+
+// startedWriter records whether anything has been written through it, after
+// which the response status can't be changed.
+type startedWriter struct {
+	w       io.Writer
+	started bool
+	n       int64
+}
+
+func (s *startedWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	s.started = true
+	n, err := s.w.Write(p)
+	s.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/models"
+)
+
+// stubImporter records the rows it is given and reports them all imported.
+type stubImporter struct {
+	rows []models.ImportRow
+	opts models.ImportOptions
+}
+
+func (s *stubImporter) Import(ctx context.Context, rows []models.ImportRow, opts models.ImportOptions) (models.ImportResult, error) {
+	s.rows, s.opts = rows, opts
+	return models.ImportResult{
+		Imported:  len(rows),
+		Committed: !opts.DryRun,
+		Errors:    []models.ImportError{},
+	}, nil
+}
+
+func TestHandleImport(t *testing.T) {
+	tests := map[string]struct {
+		query        string
+		contentType  string
+		body         string
+		wantStatus   int
+		wantRows     []int
+		wantDryRun   bool
+		wantErrorRow int
+		wantProblem  string
+	}{
+		"ndjson users": {
+			query:       "kind=users",
+			contentType: "application/x-ndjson",
+			body: `{"id": 1, "name": "navid", "email": "navid@example.com", "password": "secret1"}` + "\n\n" +
+				`{"name": "sam", "email": "sam@example.com", "password": "secret2"}` + "\n",
+			wantStatus: http.StatusOK,
+			wantRows:   []int{1, 2},
+		},
+		"atomic with a bad row": {
+			query:        "kind=users",
+			contentType:  "application/x-ndjson",
+			body:         `{"name": "navid", "email": "navid@example.com", "password": "secret1"}` + "\n" + `{"name": "sam", "email": "not an email", "password": "secret2"}` + "\n",
+			wantStatus:   http.StatusUnprocessableEntity,
+			wantRows:     []int{1},
+			wantDryRun:   true,
+			wantErrorRow: 2,
+			wantProblem:  "email",
+		},
+		"best effort with a malformed row": {
+			query:        "kind=users&mode=best_effort",
+			contentType:  "application/x-ndjson",
+			body:         `{"name": ` + "\n" + `{"name": "sam", "email": "sam@example.com", "password": "secret2"}` + "\n",
+			wantStatus:   http.StatusOK,
+			wantRows:     []int{2},
+			wantErrorRow: 1,
+			wantProblem:  "$",
+		},
+		"csv blogs": {
+			query:       "kind=blogs&dry_run=true",
+			contentType: "text/csv",
+			body:        "id,title,author_id,created_date,tags\n1,First,1,2024-05-01T12:00:00Z,\"[\"\"go\"\"]\"\n2,Second,1,,\n",
+			wantStatus:  http.StatusOK,
+			wantRows:    []int{1, 2},
+			wantDryRun:  true,
+		},
+		"csv unknown column": {
+			query:        "kind=comments&mode=best_effort",
+			contentType:  "text/csv",
+			body:         "user_id,blog_id,message,colour\n1,1,hi,red\n",
+			wantStatus:   http.StatusOK,
+			wantErrorRow: 1,
+			wantProblem:  "colour",
+		},
+		"csv wrong field count": {
+			query:        "kind=comments&mode=best_effort",
+			contentType:  "text/csv",
+			body:         "user_id,blog_id,message\n1,1\n1,2,hi\n",
+			wantStatus:   http.StatusOK,
+			wantRows:     []int{2},
+			wantErrorRow: 1,
+			wantProblem:  "$",
+		},
+		"malformed csv": {
+			query:       "kind=comments",
+			contentType: "text/csv",
+			body:        "user_id,blog_id,message\n1,1,\"hi\n",
+			wantStatus:  http.StatusBadRequest,
+		},
+		"unknown kind": {
+			query:       "kind=ratings",
+			contentType: "application/x-ndjson",
+			wantStatus:  http.StatusBadRequest,
+		},
+		"unknown mode": {
+			query:       "kind=users&mode=sometimes",
+			contentType: "application/x-ndjson",
+			wantStatus:  http.StatusBadRequest,
+		},
+		"json is not a row format": {
+			query:       "kind=users",
+			contentType: "application/json",
+			body:        `[]`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/import?"+tc.query, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			rec := httptest.NewRecorder()
+			importer := &stubImporter{}
+
+			HandleImport(slog.Default(), importer).ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK && rec.Code != http.StatusUnprocessableEntity {
+				return
+			}
+
+			var gotRows []int
+			for _, row := range importer.rows {
+				gotRows = append(gotRows, row.Row)
+			}
+			if len(gotRows) != len(tc.wantRows) {
+				t.Fatalf("want rows %v imported, got %v", tc.wantRows, gotRows)
+			}
+			for i := range gotRows {
+				if gotRows[i] != tc.wantRows[i] {
+					t.Fatalf("want rows %v imported, got %v", tc.wantRows, gotRows)
+				}
+			}
+			if importer.opts.DryRun != tc.wantDryRun {
+				t.Errorf("want dry run %v, got %v", tc.wantDryRun, importer.opts.DryRun)
+			}
+
+			var result models.ImportResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("want an import result, got %q", rec.Body.String())
+			}
+			if tc.wantErrorRow == 0 {
+				if len(result.Errors) != 0 {
+					t.Errorf("want no errors, got %v", result.Errors)
+				}
+				return
+			}
+			if len(result.Errors) != 1 || result.Errors[0].Row != tc.wantErrorRow {
+				t.Fatalf("want an error on row %d, got %v", tc.wantErrorRow, result.Errors)
+			}
+			if _, ok := result.Errors[0].Problems[tc.wantProblem]; !ok {
+				t.Errorf("want a problem for %q, got %v", tc.wantProblem, result.Errors[0].Problems)
+			}
+		})
+	}
+}
+
+// stubExporter writes body, then fails with err.
+type stubExporter struct {
+	body string
+	err  error
+}
+
+func (s stubExporter) Export(ctx context.Context, w io.Writer) error {
+	if _, err := io.WriteString(w, s.body); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func TestHandleExport(t *testing.T) {
+	tests := map[string]struct {
+		exporter   stubExporter
+		wantStatus int
+		wantType   string
+	}{
+		"archive": {
+			exporter:   stubExporter{body: "PK"},
+			wantStatus: http.StatusOK,
+			wantType:   "application/zip",
+		},
+		"fails before writing": {
+			exporter:   stubExporter{err: errors.New("connection refused")},
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "text/plain; charset=utf-8",
+		},
+		"fails after writing": {
+			exporter:   stubExporter{body: "PK", err: errors.New("connection reset")},
+			wantStatus: http.StatusOK,
+			wantType:   "application/zip",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/export", nil)
+			rec := httptest.NewRecorder()
+
+			HandleExport(slog.Default(), tc.exporter).ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tc.wantType {
+				t.Errorf("want content type %q, got %q", tc.wantType, got)
+			}
+		})
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	tests := map[string]struct {
+		userID     int
+		wantStatus int
+	}{
+		"anonymous": {wantStatus: http.StatusUnauthorized},
+		"not admin": {userID: 2, wantStatus: http.StatusForbidden},
+		"admin":     {userID: 1, wantStatus: http.StatusNoContent},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/export", nil)
+			if tc.userID != 0 {
+				req = req.WithContext(auth.WithUserID(req.Context(), tc.userID))
+			}
+			rec := httptest.NewRecorder()
+
+			RequireAdmin([]int{1}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})).ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+		})
+	}
+}
@@ -2,17 +2,19 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/navid/blog/internal/filters"
 	"github.com/navid/blog/internal/models"
 )
 
-// blogUpdater represents a type capable of updating a blog in storage
+// blogUpdater represents a type capable of reading and updating a blog in
+// storage
 type blogUpdater interface {
+	GetBlog(ctx context.Context, id uint) (models.Blog, error)
 	UpdateBlog(ctx context.Context, id uint, blog models.Blog) (models.Blog, error)
 }
 
@@ -21,14 +23,19 @@ type blogUpdater interface {
 // @Tags			blog
 // @Accept			json
 // @Produce		json
+// @Security		BearerAuth
 // @Param			id		path		string		true	"Blog ID"
 // @Param			blog	body		models.Blog	true	"Blog"
 // @Success		200		{object}	models.Blog
+// @Success		202		{object}	screenResponse
 // @Failure		400		{object}	string
+// @Failure		401		{object}	string
+// @Failure		403		{object}	string
 // @Failure		404		{object}	string
+// @Failure		422		{object}	screenResponse
 // @Failure		500		{object}	string
 // @Router			/blog/{id} [put]
-func HandleUpdateBlog(logger *slog.Logger, blogUpdater blogUpdater, userReader userReader) http.Handler {
+func HandleUpdateBlog(logger *slog.Logger, blogUpdater blogUpdater, userReader userReader, screener contentScreener) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -51,17 +58,15 @@ func HandleUpdateBlog(logger *slog.Logger, blogUpdater blogUpdater, userReader u
 
 		// Decode and validate the blog
 		blog, problems, err := decodeValid[models.Blog](r)
-		if err != nil {
-			logger.ErrorContext(ctx, "failed to decode request body",
-				slog.String("error", err.Error()))
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err != nil && len(problems) == 0 {
+			writeDecodeError(ctx, logger, w, err)
 			return
 		}
 
 		if len(problems) > 0 {
-			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
 			w.WriteHeader(http.StatusBadRequest)
-			if err := json.NewEncoder(w).Encode(problems); err != nil {
+			if err := formatFrom(ctx).Encode(w, problems); err != nil {
 				logger.ErrorContext(ctx, "failed to encode validation problems",
 					slog.String("error", err.Error()))
 				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -69,6 +74,12 @@ func HandleUpdateBlog(logger *slog.Logger, blogUpdater blogUpdater, userReader u
 			return
 		}
 
+		// Only the authenticated user can post as themselves
+		userID, ok := authorizeAuthor(w, r, blog.AuthorID)
+		if !ok {
+			return
+		}
+
 		// Validate that the author exists
 		_, err = userReader.ReadUser(ctx, uint64(blog.AuthorID))
 		if err != nil {
@@ -79,6 +90,39 @@ func HandleUpdateBlog(logger *slog.Logger, blogUpdater blogUpdater, userReader u
 			return
 		}
 
+		// Validate that the blog exists and belongs to the user
+		existing, err := blogUpdater.GetBlog(ctx, id)
+		if err != nil {
+			if strings.Contains(err.Error(), "no blog found") {
+				http.Error(w, "Blog not found", http.StatusNotFound)
+				return
+			}
+			logger.ErrorContext(ctx, "failed to read blog", slog.String("error", err.Error()))
+			http.Error(w, "Failed to update blog", http.StatusInternalServerError)
+			return
+		}
+		if existing.AuthorID != userID {
+			http.Error(w, "Cannot edit another user's blog", http.StatusForbidden)
+			return
+		}
+
+		// Run the new version of the blog through the content filters
+		blog.ID = id
+		content := filters.Content{
+			Kind:   filters.KindBlog,
+			UserID: userID,
+			Text:   blog.Text(),
+		}
+		decision, moderationID, err := screener.Screen(ctx, content, blog)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to screen blog", slog.String("error", err.Error()))
+			http.Error(w, "Failed to validate blog", http.StatusInternalServerError)
+			return
+		}
+		if writeScreened(ctx, w, decision, moderationID) {
+			return
+		}
+
 		// Update the blog
 		updatedBlog, err := blogUpdater.UpdateBlog(ctx, id, blog)
 		if err != nil {
@@ -94,9 +138,12 @@ func HandleUpdateBlog(logger *slog.Logger, blogUpdater blogUpdater, userReader u
 			return
 		}
 
+		// Remember the stored blog in the filters' history
+		screener.Record(ctx, content)
+
 		// Return the updated blog
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(updatedBlog); err != nil {
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		if err := formatFrom(ctx).Encode(w, updatedBlog); err != nil {
 			logger.ErrorContext(ctx, "failed to encode response",
 				slog.String("error", err.Error()))
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -1,17 +1,17 @@
 package handlers
 
 import (
-    "encoding/json"
     "log/slog"
     "net/http"
     "strconv"
 
+    "github.com/navid/blog/internal/filters"
     "github.com/navid/blog/internal/models"
     "github.com/navid/blog/internal/services"
 )
 
 // HandleUpdateComment handles updating a comment by author_id and blog_id.
-func HandleUpdateComment(logger *slog.Logger, commentsService *services.CommentsService, usersService *services.UsersService, blogsService *services.BlogService) http.HandlerFunc {
+func HandleUpdateComment(logger *slog.Logger, commentsService *services.CommentsService, usersService *services.UsersService, blogsService *services.BlogService, screener contentScreener) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         ctx := r.Context()
 
@@ -37,9 +37,8 @@ func HandleUpdateComment(logger *slog.Logger, commentsService *services.Comments
         }
 
         // Decode and validate the comment object
-        var comment models.Comment
-        if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
-            http.Error(w, "Invalid request body", http.StatusBadRequest)
+        comment, ok := decodeValidOrWrite[models.Comment](logger, w, r)
+        if !ok {
             return
         }
 
@@ -48,6 +47,12 @@ func HandleUpdateComment(logger *slog.Logger, commentsService *services.Comments
             return
         }
 
+        // Only the authenticated user can edit their comment
+        userID, ok := authorizeAuthor(w, r, authorID)
+        if !ok {
+            return
+        }
+
         // Validate that the user exists
         if !usersService.DoesUserExist(ctx, authorID) {
             http.Error(w, "User not found", http.StatusBadRequest)
@@ -61,6 +66,34 @@ func HandleUpdateComment(logger *slog.Logger, commentsService *services.Comments
             return
         }
 
+        // Validate that the comment exists
+        exists, err := commentsService.DoesCommentExist(ctx, authorID, blogID)
+        if err != nil {
+            logger.ErrorContext(ctx, "failed to check comment existence", slog.String("error", err.Error()))
+            http.Error(w, "Failed to validate comment", http.StatusInternalServerError)
+            return
+        }
+        if !exists {
+            http.Error(w, "Comment not found", http.StatusNotFound)
+            return
+        }
+
+        // Run the new message through the content filters
+        content := filters.Content{
+            Kind:   filters.KindComment,
+            UserID: userID,
+            Text:   comment.Message,
+        }
+        decision, moderationID, err := screener.Screen(ctx, content, comment)
+        if err != nil {
+            logger.ErrorContext(ctx, "failed to screen comment", slog.String("error", err.Error()))
+            http.Error(w, "Failed to validate comment", http.StatusInternalServerError)
+            return
+        }
+        if writeScreened(ctx, w, decision, moderationID) {
+            return
+        }
+
         // Update the comment
         updatedComment, err := commentsService.UpdateComment(ctx, comment)
         if err != nil {
@@ -69,8 +102,11 @@ func HandleUpdateComment(logger *slog.Logger, commentsService *services.Comments
             return
         }
 
+        // Remember the stored comment in the filters' history
+        screener.Record(ctx, content)
+
         // Respond with the updated comment
-        w.Header().Set("Content-Type", "application/json")
-        json.NewEncoder(w).Encode(updatedComment)
+        w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+        formatFrom(ctx).Encode(w, updatedComment)
     }
 }
\ No newline at end of file
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -18,18 +17,21 @@ type userUpdater interface {
 }
 
 // @Summary		Update User
-// @Description	Update an existing user
+// @Description	Update an existing user, as that user or an admin
 // @Tags			user
 // @Accept			json
 // @Produce		json
+// @Security		BearerAuth
 // @Param			id		path		string		true	"User ID"
 // @Param			user	body		models.User	true	"User"
-// @Success		200		{object}	models.User
+// @Success		200		{object}	readUserResponse
 // @Failure		400		{object}	string
+// @Failure		401		{object}	string
+// @Failure		403		{object}	string
 // @Failure		404		{object}	string
 // @Failure		500		{object}	string
-// @Router			/users/{id} [PUT]
-func HandleUpdateUser(logger *slog.Logger, userUpdater userUpdater) http.Handler {
+// @Router			/user/{id} [PUT]
+func HandleUpdateUser(logger *slog.Logger, userUpdater userUpdater, withPasswordField bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -47,10 +49,9 @@ func HandleUpdateUser(logger *slog.Logger, userUpdater userUpdater) http.Handler
 			return
 		}
 
-		var user models.User
-		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-			logger.ErrorContext(ctx, "failed to decode request", slog.String("error", err.Error()))
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		// Decode and validate the user
+		user, ok := decodeValidOrWrite[models.User](logger, w, r)
+		if !ok {
 			return
 		}
 
@@ -65,8 +66,8 @@ func HandleUpdateUser(logger *slog.Logger, userUpdater userUpdater) http.Handler
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(updatedUser); err != nil {
+		w.Header().Set("Content-Type", formatFrom(ctx).MediaType())
+		if err := formatFrom(ctx).Encode(w, showUser(updatedUser, withPasswordField)); err != nil {
 			logger.ErrorContext(ctx, "failed to encode response", slog.String("error", err.Error()))
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
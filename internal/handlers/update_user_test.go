@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/navid/blog/internal/models"
+)
+
+// fakeUserUpdater returns the user it was asked to store.
+type fakeUserUpdater struct {
+	called bool
+}
+
+func (f *fakeUserUpdater) UpdateUser(ctx context.Context, id uint64, user models.User) (models.User, error) {
+	f.called = true
+	user.ID = uint(id)
+	return user, nil
+}
+
+func TestHandleUpdateUser(t *testing.T) {
+	tests := map[string]struct {
+		body         string
+		wantStatus   int
+		wantProblems []string
+	}{
+		"valid": {
+			body:       `{"name": "navid", "email": "navid@example.com", "password": "secret1"}`,
+			wantStatus: http.StatusOK,
+		},
+		"missing fields": {
+			body:         `{"name": "navid"}`,
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"email", "password"},
+		},
+		"invalid email and short password": {
+			body:         `{"name": "navid", "email": "navid", "password": "abc"}`,
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"email", "password"},
+		},
+		"wrong type": {
+			body:         `{"name": 7, "email": "navid@example.com", "password": "secret1"}`,
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"name"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			updater := &fakeUserUpdater{}
+			h := HandleUpdateUser(slog.New(slog.DiscardHandler), updater, false)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/user/1", strings.NewReader(tc.body))
+			req.SetPathValue("id", "1")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body)
+			}
+			if len(tc.wantProblems) == 0 {
+				return
+			}
+			if updater.called {
+				t.Error("invalid user was stored")
+			}
+			var problems map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &problems); err != nil {
+				t.Fatalf("failed to decode problems: %v", err)
+			}
+			for _, path := range tc.wantProblems {
+				if problems[path] == "" {
+					t.Errorf("want a problem at %q, got %v", path, problems)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Package health runs the readiness checks reported by the health endpoints
+// and tracks background jobs.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status values reported for the service and each of its components.
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// errShuttingDown is reported once the server has started shutting down.
+var errShuttingDown = errors.New("server is shutting down")
+
+// Check reports on one component the service depends on, returning details
+// worth showing to an operator or an error if the component is unusable. The
+// context passed to a check carries the checker's timeout.
+type Check func(ctx context.Context) (any, error)
+
+// Component is the result of running one Check.
+type Component struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Detail    any     `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the result of running every registered Check. Status is ok only
+// if every component is ok and the server isn't shutting down.
+type Report struct {
+	Status     string               `json:"status"`
+	Components map[string]Component `json:"components"`
+}
+
+// Checker runs a set of named checks concurrently, each bounded by a timeout.
+type Checker struct {
+	timeout  time.Duration
+	mu       sync.Mutex
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+// NewChecker creates a Checker whose checks each get at most timeout to
+// complete.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		checks:  make(map[string]Check),
+	}
+}
+
+// Add registers check under name, replacing any check already registered
+// with that name.
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Drain marks the server as shutting down, so it reports itself unavailable
+// and load balancers stop sending it traffic.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Ready runs every registered check and reports the result.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.Lock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.Unlock()
+
+	report := Report{
+		Status:     StatusOK,
+		Components: make(map[string]Component, len(checks)+1),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			component := c.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[name] = component
+		}()
+	}
+	wg.Wait()
+
+	if c.draining.Load() {
+		report.Components["server"] = Component{Status: StatusUnavailable, Error: errShuttingDown.Error()}
+	}
+	for _, component := range report.Components {
+		if component.Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+
+	return report
+}
+
+// run runs check with the checker's timeout and times it. A check that
+// doesn't return in time is reported as unavailable without waiting for it.
+func (c *Checker) run(ctx context.Context, check Check) Component {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	type result struct {
+		detail any
+		err    error
+	}
+	done := make(chan result, 1)
+
+	start := time.Now()
+	go func() {
+		detail, err := check(ctx)
+		done <- result{detail, err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		res.err = ctx.Err()
+	}
+
+	component := Component{
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Detail:    res.detail,
+	}
+	if err := res.err; err != nil {
+		component.Status = StatusUnavailable
+		component.Error = err.Error()
+	}
+	return component
+}
@@ -0,0 +1,152 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestChecker_Ready(t *testing.T) {
+	ok := func(ctx context.Context) (any, error) { return "fine", nil }
+	failing := func(ctx context.Context) (any, error) { return nil, errors.New("boom") }
+	hanging := func(ctx context.Context) (any, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}
+
+	tests := map[string]struct {
+		checks     map[string]Check
+		drain      bool
+		wantStatus string
+		wantFailed []string
+	}{
+		"all ok": {
+			checks:     map[string]Check{"a": ok, "b": ok},
+			wantStatus: StatusOK,
+		},
+		"one failing": {
+			checks:     map[string]Check{"a": ok, "b": failing},
+			wantStatus: StatusUnavailable,
+			wantFailed: []string{"b"},
+		},
+		"timed out": {
+			checks:     map[string]Check{"a": hanging},
+			wantStatus: StatusUnavailable,
+			wantFailed: []string{"a"},
+		},
+		"draining": {
+			checks:     map[string]Check{"a": ok},
+			drain:      true,
+			wantStatus: StatusUnavailable,
+			wantFailed: []string{"server"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			checker := NewChecker(50 * time.Millisecond)
+			for name, check := range tc.checks {
+				checker.Add(name, check)
+			}
+			if tc.drain {
+				checker.Drain()
+			}
+
+			report := checker.Ready(context.Background())
+			if report.Status != tc.wantStatus {
+				t.Errorf("expected status %s, got %s", tc.wantStatus, report.Status)
+			}
+			for _, name := range tc.wantFailed {
+				if c := report.Components[name]; c.Status != StatusUnavailable || c.Error == "" {
+					t.Errorf("expected component %s to be unavailable with an error, got %+v", name, c)
+				}
+			}
+			if c, ok := report.Components["a"]; ok && c.Status == StatusOK && c.Detail != "fine" {
+				t.Errorf("expected component detail to be reported, got %+v", c)
+			}
+		})
+	}
+}
+
+func TestJobs(t *testing.T) {
+	jobs := NewJobs(slog.Default())
+	release := make(chan struct{})
+	jobs.Go(context.Background(), "import", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	if got := jobs.Pending(); len(got) != 1 || got[0] != "import" {
+		t.Errorf("expected the import job to be pending, got %v", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := jobs.Wait(ctx); err == nil {
+		t.Error("expected Wait to time out while the job is running")
+	}
+
+	close(release)
+	if err := jobs.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error waiting for jobs: %v", err)
+	}
+	if got := jobs.Pending(); len(got) != 0 {
+		t.Errorf("expected no pending jobs, got %v", got)
+	}
+}
+
+func TestJobs_Check(t *testing.T) {
+	tests := map[string]struct {
+		required  bool
+		err       error
+		wantReady bool
+	}{
+		"background job succeeded": {
+			wantReady: true,
+		},
+		"background job failed": {
+			err:       errors.New("boom"),
+			wantReady: true,
+		},
+		"required job succeeded": {
+			required:  true,
+			wantReady: true,
+		},
+		"required job failed": {
+			required: true,
+			err:      errors.New("boom"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			jobs := NewJobs(slog.New(slog.DiscardHandler))
+			release := make(chan struct{})
+			fn := func(ctx context.Context) error {
+				<-release
+				return tc.err
+			}
+			if tc.required {
+				jobs.GoRequired(context.Background(), "train", fn)
+			} else {
+				jobs.Go(context.Background(), "train", fn)
+			}
+
+			// Only a pending required job makes the service unavailable
+			if _, err := jobs.Check(context.Background()); (err == nil) != !tc.required {
+				t.Errorf("while pending: expected ready %v, got error %v", !tc.required, err)
+			}
+
+			close(release)
+			if err := jobs.Wait(context.Background()); err != nil {
+				t.Fatalf("unexpected error waiting for jobs: %v", err)
+			}
+
+			if _, err := jobs.Check(context.Background()); (err == nil) != tc.wantReady {
+				t.Errorf("when done: expected ready %v, got error %v", tc.wantReady, err)
+			}
+		})
+	}
+}
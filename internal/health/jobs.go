@@ -0,0 +1,130 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Jobs runs and keeps track of background jobs, so readiness can report the
+// ones still pending and shutdown can wait for them.
+type Jobs struct {
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	pending  map[string]int
+	required map[string]int
+	failed   []string
+}
+
+// NewJobs creates an empty Jobs.
+func NewJobs(logger *slog.Logger) *Jobs {
+	return &Jobs{
+		logger:   logger,
+		pending:  make(map[string]int),
+		required: make(map[string]int),
+	}
+}
+
+// Go runs fn in the background as a job called name. A failed job is logged.
+func (j *Jobs) Go(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	j.run(ctx, name, false, fn)
+}
+
+// GoRequired runs fn in the background like Go, but the service isn't ready
+// until it has succeeded: Check fails while it is pending, and keeps failing
+// if it fails.
+func (j *Jobs) GoRequired(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	j.run(ctx, name, true, fn)
+}
+
+func (j *Jobs) run(ctx context.Context, name string, required bool, fn func(ctx context.Context) error) {
+	j.mu.Lock()
+	j.pending[name]++
+	if required {
+		j.required[name]++
+	}
+	j.mu.Unlock()
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		err := fn(ctx)
+		if err != nil {
+			j.logger.ErrorContext(ctx, "background job failed", slog.String("job", name), slog.String("error", err.Error()))
+		}
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if j.pending[name]--; j.pending[name] == 0 {
+			delete(j.pending, name)
+		}
+		if required {
+			if j.required[name]--; j.required[name] == 0 {
+				delete(j.required, name)
+			}
+			if err != nil {
+				j.failed = append(j.failed, name)
+			}
+		}
+	}()
+}
+
+// Pending returns the sorted names of the jobs still running, with a name
+// repeated for every instance of it.
+func (j *Jobs) Pending() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	names := []string{}
+	for name, n := range j.pending {
+		for range n {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Wait blocks until every job has finished or ctx is done, returning ctx's
+// error in the latter case.
+func (j *Jobs) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Check reports the number and names of pending jobs. Only required jobs
+// make the service unavailable, while they are pending or once one failed.
+func (j *Jobs) Check(ctx context.Context) (any, error) {
+	pending := j.Pending()
+	detail := map[string]any{"pending": len(pending), "jobs": pending}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if len(j.failed) > 0 {
+		return detail, fmt.Errorf("required jobs failed: %s", strings.Join(j.failed, ", "))
+	}
+	if len(j.required) > 0 {
+		waiting := make([]string, 0, len(j.required))
+		for name := range j.required {
+			waiting = append(waiting, name)
+		}
+		sort.Strings(waiting)
+		return detail, fmt.Errorf("waiting for required jobs: %s", strings.Join(waiting, ", "))
+	}
+	return detail, nil
+}
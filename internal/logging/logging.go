@@ -0,0 +1,122 @@
+// Package logging carries a request-scoped *slog.Logger and request ID in a
+// context.Context.
+//
+// Handlers and services are given the application's root logger when they
+// are constructed. A handler made by NewHandler passes every record logged
+// with a context carrying a request logger on to that logger instead, so log
+// lines written by the root logger during a request pick up the request ID,
+// route, remote address and user the request logger was enriched with.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+	requestAttrsKey
+)
+
+// WithLogger returns a copy of ctx carrying logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger carried by ctx, or slog.Default() if there
+// isn't one.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// With returns a copy of ctx whose logger includes the given attributes, in
+// the same form as slog.Logger.With accepts.
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there isn't one.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// requestAttrs collects attributes learned about a request while it is
+// handled.
+type requestAttrs struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// WithRequestAttrs returns a copy of ctx that collects the attributes added
+// with AddRequestAttrs by handlers further down the chain, so middleware
+// that runs before them, like the one logging a completed request, can
+// include them.
+func WithRequestAttrs(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestAttrsKey, &requestAttrs{})
+}
+
+// AddRequestAttrs adds attrs to the attributes collected for the request in
+// ctx, if any are being collected.
+func AddRequestAttrs(ctx context.Context, attrs ...slog.Attr) {
+	if ra, ok := ctx.Value(requestAttrsKey).(*requestAttrs); ok {
+		ra.mu.Lock()
+		ra.attrs = append(ra.attrs, attrs...)
+		ra.mu.Unlock()
+	}
+}
+
+// RequestAttrs returns the attributes collected for the request in ctx.
+func RequestAttrs(ctx context.Context) []slog.Attr {
+	ra, ok := ctx.Value(requestAttrsKey).(*requestAttrs)
+	if !ok {
+		return nil
+	}
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	return append([]slog.Attr(nil), ra.attrs...)
+}
+
+// handler forwards records logged with a context carrying a request logger
+// to that logger's handler. Handlers derived with WithAttrs or WithGroup
+// write to their own handler, as they belong to a logger that has already
+// been enriched.
+type handler struct {
+	slog.Handler
+	root bool
+}
+
+// NewHandler wraps h so records logged with a context carrying a request
+// logger are written by that logger, with its attributes.
+func NewHandler(h slog.Handler) slog.Handler {
+	return &handler{Handler: h, root: true}
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if h.root {
+		if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok && logger.Handler() != slog.Handler(h) {
+			return logger.Handler().Handle(ctx, r)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil)))
+
+	tests := map[string]struct {
+		ctx  context.Context
+		want map[string]any
+	}{
+		"no request logger": {
+			ctx:  context.Background(),
+			want: map[string]any{},
+		},
+		"request logger": {
+			ctx:  WithLogger(context.Background(), logger.With("request_id", "abc")),
+			want: map[string]any{"request_id": "abc"},
+		},
+		"enriched request logger": {
+			ctx:  With(WithLogger(context.Background(), logger.With("request_id", "abc")), "user_id", 7),
+			want: map[string]any{"request_id": "abc", "user_id": float64(7)},
+		},
+		"root logger in context": {
+			ctx:  WithLogger(context.Background(), logger),
+			want: map[string]any{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+			logger.InfoContext(tc.ctx, "handled")
+
+			var line map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("expected exactly one log line, got %q: %v", buf.String(), err)
+			}
+			for _, key := range []string{"request_id", "user_id"} {
+				if line[key] != tc.want[key] {
+					t.Errorf("expected %s %v, got %v", key, tc.want[key], line[key])
+				}
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	if got := RequestID(context.Background()); got != "" {
+		t.Errorf("expected no request ID, got %q", got)
+	}
+	if got := RequestID(WithRequestID(context.Background(), "abc")); got != "abc" {
+		t.Errorf("expected request ID abc, got %q", got)
+	}
+}
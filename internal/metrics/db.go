@@ -0,0 +1,36 @@
+package metrics
+
+import "database/sql"
+
+// statser represents a type reporting database connection pool statistics,
+// such as *sql.DB.
+type statser interface {
+	Stats() sql.DBStats
+}
+
+// RegisterDBStats registers gauges and counters reporting db's connection
+// pool statistics at scrape time.
+func (r *Registry) RegisterDBStats(db statser) {
+	stat := func(fn func(s sql.DBStats) float64) func() float64 {
+		return func() float64 { return fn(db.Stats()) }
+	}
+
+	r.NewGaugeFunc("db_max_open_connections", "Maximum number of open connections to the database.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	r.NewGaugeFunc("db_open_connections", "Number of established connections, both in use and idle.",
+		stat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	r.NewGaugeFunc("db_in_use_connections", "Number of connections currently in use.",
+		stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	r.NewGaugeFunc("db_idle_connections", "Number of idle connections.",
+		stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	r.NewCounterFunc("db_wait_count_total", "Total number of connections waited for.",
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	r.NewCounterFunc("db_wait_duration_seconds_total", "Total time blocked waiting for a new connection.",
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	r.NewCounterFunc("db_max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	r.NewCounterFunc("db_max_idle_time_closed_total", "Total number of connections closed due to SetConnMaxIdleTime.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }))
+	r.NewCounterFunc("db_max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+}
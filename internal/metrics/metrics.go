@@ -0,0 +1,362 @@
+// Package metrics is a small metrics registry exposing counters, gauges and
+// histograms in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Default is the registry the application's metrics are registered with and
+// the /metrics endpoint serves.
+var Default = NewRegistry()
+
+// DefaultBuckets are latency histogram buckets, in seconds, suited to an API
+// backed by a database.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// family is a named group of samples of one type.
+type family interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families and writes them out.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+func (r *Registry) register(f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.families[f.name()]; ok {
+		panic(fmt.Sprintf("metrics: %s registered twice", f.name()))
+	}
+	r.families[f.name()] = f
+}
+
+// ServeHTTP writes every metric in the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	families := make([]family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name() < families[j].name() })
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	_ = bw.Flush()
+}
+
+// desc describes a metric family.
+type desc struct {
+	fqName string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d desc) name() string { return d.fqName }
+
+func (d desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.fqName, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.fqName, d.typ)
+}
+
+// key joins label values into a map key.
+func key(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// formatLabels renders label pairs, with extra appended after the family's
+// labels.
+func formatLabels(names, values []string, extra ...string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, n, escapeLabel(values[i]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extra[i], escapeLabel(extra[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// escapeLabel escapes backslashes, quotes and newlines in a label value.
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func escapeHelp(h string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(h)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// value is a float64 that can be updated atomically.
+type value struct {
+	bits atomic.Uint64
+}
+
+func (v *value) add(delta float64) {
+	for {
+		old := v.bits.Load()
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if v.bits.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+func (v *value) set(x float64) { v.bits.Store(math.Float64bits(x)) }
+
+func (v *value) get() float64 { return math.Float64frombits(v.bits.Load()) }
+
+// vec holds one child per combination of label values.
+type vec[T any] struct {
+	desc
+	mu       sync.RWMutex
+	children map[string]*T
+	values   map[string][]string
+	newChild func() *T
+}
+
+func newVec[T any](d desc, newChild func() *T) *vec[T] {
+	return &vec[T]{desc: d, children: make(map[string]*T), values: make(map[string][]string), newChild: newChild}
+}
+
+func (v *vec[T]) with(values []string) *T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.fqName, len(v.labels), len(values)))
+	}
+	k := key(values)
+
+	v.mu.RLock()
+	child, ok := v.children[k]
+	v.mu.RUnlock()
+	if ok {
+		return child
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok = v.children[k]; !ok {
+		child = v.newChild()
+		v.children[k] = child
+		v.values[k] = append([]string(nil), values...)
+	}
+	return child
+}
+
+// each calls fn for every child in label order.
+func (v *vec[T]) each(fn func(values []string, child *T)) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.children))
+	for k := range v.children {
+		keys = append(keys, k)
+	}
+	v.mu.RUnlock()
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v.mu.RLock()
+		child, values := v.children[k], v.values[k]
+		v.mu.RUnlock()
+		fn(values, child)
+	}
+}
+
+// Counter is a value that only goes up.
+type Counter struct{ v value }
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() { c.v.add(1) }
+
+// Add adds delta, which must not be negative, to the counter.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	c.v.add(delta)
+}
+
+// CounterVec is a family of counters partitioned by label values.
+type CounterVec struct{ *vec[Counter] }
+
+// NewCounterVec registers a counter family with the provided labels.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec(desc{name, help, "counter", labels}, func() *Counter { return &Counter{} })}
+	r.register(c)
+	return c
+}
+
+// With returns the counter for the label values, in the order the labels
+// were declared.
+func (c *CounterVec) With(values ...string) *Counter { return c.with(values) }
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.writeHeader(w)
+	c.each(func(values []string, child *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n", c.fqName, formatLabels(c.labels, values), formatFloat(child.v.get()))
+	})
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct{ v value }
+
+// Inc adds one to the gauge.
+func (g *Gauge) Inc() { g.v.add(1) }
+
+// Dec subtracts one from the gauge.
+func (g *Gauge) Dec() { g.v.add(-1) }
+
+// Set sets the gauge.
+func (g *Gauge) Set(x float64) { g.v.set(x) }
+
+// GaugeVec is a family of gauges partitioned by label values.
+type GaugeVec struct{ *vec[Gauge] }
+
+// NewGaugeVec registers a gauge family with the provided labels.
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newVec(desc{name, help, "gauge", labels}, func() *Gauge { return &Gauge{} })}
+	r.register(g)
+	return g
+}
+
+// With returns the gauge for the label values, in the order the labels were
+// declared.
+func (g *GaugeVec) With(values ...string) *Gauge { return g.with(values) }
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.writeHeader(w)
+	g.each(func(values []string, child *Gauge) {
+		fmt.Fprintf(w, "%s%s %s\n", g.fqName, formatLabels(g.labels, values), formatFloat(child.v.get()))
+	})
+}
+
+// Histogram counts observations into cumulative buckets.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// Observe records a single observation.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// HistogramVec is a family of histograms partitioned by label values.
+type HistogramVec struct {
+	*vec[Histogram]
+	buckets []float64
+}
+
+// NewHistogramVec registers a histogram family with the provided upper
+// bucket bounds, which must be sorted, and labels.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	bounds := append([]float64(nil), buckets...)
+	h := &HistogramVec{
+		vec: newVec(desc{name, help, "histogram", labels}, func() *Histogram {
+			return &Histogram{buckets: bounds, counts: make([]uint64, len(bounds))}
+		}),
+		buckets: bounds,
+	}
+	r.register(h)
+	return h
+}
+
+// With returns the histogram for the label values, in the order the labels
+// were declared.
+func (h *HistogramVec) With(values ...string) *Histogram { return h.with(values) }
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w)
+	h.each(func(values []string, child *Histogram) {
+		child.mu.Lock()
+		counts := append([]uint64(nil), child.counts...)
+		sum, count := child.sum, child.count
+		child.mu.Unlock()
+
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.fqName, formatLabels(h.labels, values, "le", formatFloat(upper)), counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.fqName, formatLabels(h.labels, values, "le", "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.fqName, formatLabels(h.labels, values), formatFloat(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.fqName, formatLabels(h.labels, values), count)
+	})
+}
+
+// funcMetric is an unlabelled metric whose value is read when scraped.
+type funcMetric struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is fn's result at scrape time.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{desc{name, help, "gauge", nil}, fn})
+}
+
+// NewCounterFunc registers a counter whose value is fn's result at scrape
+// time. fn must never return a smaller value than before.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{desc{name, help, "counter", nil}, fn})
+}
+
+func (m *funcMetric) write(w *bufio.Writer) {
+	m.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", m.fqName, formatFloat(m.fn()))
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubDB struct{}
+
+func (stubDB) Stats() sql.DBStats {
+	return sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2, WaitDuration: 1500 * time.Millisecond}
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.NewCounterVec("http_requests_total", "Requests served.", "route", "status")
+	requests.With("GET /api/blog/{id}", "200").Inc()
+	requests.With("GET /api/blog/{id}", "200").Add(2)
+	requests.With(`a "quoted"\path`, "404").Inc()
+
+	inFlight := r.NewGaugeVec("http_requests_in_flight", "Requests being served.", "route")
+	inFlight.With("GET /api/blog").Inc()
+	inFlight.With("GET /api/blog").Inc()
+	inFlight.With("GET /api/blog").Dec()
+
+	latency := r.NewHistogramVec("http_request_duration_seconds", "Request latency.", []float64{0.1, 1}, "route")
+	latency.With("GET /api/blog").Observe(0.05)
+	latency.With("GET /api/blog").Observe(0.5)
+	latency.With("GET /api/blog").Observe(5)
+
+	r.RegisterDBStats(stubDB{})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# HELP http_requests_total Requests served.\n# TYPE http_requests_total counter\n",
+		`http_requests_total{route="GET /api/blog/{id}",status="200"} 3`,
+		`http_requests_total{route="a \"quoted\"\\path",status="404"} 1`,
+		"# TYPE http_requests_in_flight gauge\n",
+		`http_requests_in_flight{route="GET /api/blog"} 1`,
+		"# TYPE http_request_duration_seconds histogram\n",
+		`http_request_duration_seconds_bucket{route="GET /api/blog",le="0.1"} 1`,
+		`http_request_duration_seconds_bucket{route="GET /api/blog",le="1"} 2`,
+		`http_request_duration_seconds_bucket{route="GET /api/blog",le="+Inf"} 3`,
+		`http_request_duration_seconds_sum{route="GET /api/blog"} 5.55`,
+		`http_request_duration_seconds_count{route="GET /api/blog"} 3`,
+		"db_open_connections 3\n",
+		"# TYPE db_wait_duration_seconds_total counter\ndb_wait_duration_seconds_total 1.5\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, body)
+		}
+	}
+
+	// Families are written in name order
+	if strings.Index(body, "db_idle_connections") > strings.Index(body, "http_requests_total") {
+		t.Errorf("expected families sorted by name, got:\n%s", body)
+	}
+}
+
+func TestRegistry_DuplicatePanics(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("ops_total", "Operations.")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a name twice to panic")
+		}
+	}()
+	r.NewGaugeVec("ops_total", "Operations.")
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/logging"
+)
+
+// tokenVerifier represents a type capable of verifying a bearer token and
+// returning the id of the user it was issued for.
+type tokenVerifier interface {
+	Verify(token string) (int, error)
+}
+
+// Authenticate is a middleware that reads a bearer token from the
+// Authorization header and, if it is valid, stores the user's id in the
+// request context. Requests without a token pass through anonymously so each
+// handler decides whether it needs a user; requests with a bad token are
+// refused. The request logger of an authenticated request, and the line
+// logged when it completes, include the user's id.
+func Authenticate(logger *slog.Logger, verifier tokenVerifier) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				http.Error(w, "Unsupported authorization scheme", http.StatusUnauthorized)
+				return
+			}
+
+			userID, err := verifier.Verify(token)
+			if err != nil {
+				logger.InfoContext(r.Context(), "rejected bearer token", slog.String("error", err.Error()))
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := auth.WithUserID(r.Context(), userID)
+			ctx = logging.With(ctx, slog.Int("user_id", userID))
+			logging.AddRequestAttrs(ctx, slog.Int("user_id", userID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
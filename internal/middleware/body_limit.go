@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// MaxBodySize is a middleware that limits request bodies to limit bytes, or
+// to the limit in routes for the pattern they match on router. Requests
+// declaring a larger Content-Length are refused with a 413 before their body
+// is read; reading past the limit of any other body fails with an
+// *http.MaxBytesError, which handlers answer with a 413 too.
+func MaxBodySize(limit int64, router router, routes map[string]int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := limit
+			if routeLimit, ok := routes[RoutePattern(router, r)]; ok {
+				limit = routeLimit
+			}
+
+			if r.ContentLength > limit {
+				http.Error(w, fmt.Sprintf("Request body too large: the limit is %d bytes", limit), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
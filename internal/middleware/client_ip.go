@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/navid/blog/internal/auth"
+)
+
+// TrustedProxies is the set of networks whose X-Forwarded-For headers are
+// believed when working out a client's address.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses a list of CIDR prefixes or single addresses.
+func ParseTrustedProxies(specs []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		if strings.Contains(spec, "/") {
+			prefix, err := netip.ParsePrefix(spec)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", spec, err)
+			}
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", spec, err)
+		}
+		addr = addr.Unmap()
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+// trusts reports whether addr is in one of the trusted networks.
+func (p TrustedProxies) trusts(addr netip.Addr) bool {
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client that made r. If the request
+// came through trusted proxies, it is the right-most address in
+// X-Forwarded-For that isn't a trusted proxy, as everything to the left of
+// it could have been made up by the client.
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	client := addrPort.Addr().Unmap()
+	if !p.trusts(client) {
+		return client.String()
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !p.trusts(client) {
+			break
+		}
+	}
+
+	return client.String()
+}
+
+// Client identifies the client that made r: by user if it is
+// authenticated, as user:42, and otherwise by address, as ip:192.0.2.1.
+func (p TrustedProxies) Client(r *http.Request) string {
+	if userID, ok := auth.UserID(r.Context()); ok {
+		return "user:" + strconv.Itoa(userID)
+	}
+	return "ip:" + p.ClientIP(r)
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := map[string]struct {
+		specs   []string
+		want    []string
+		wantErr bool
+	}{
+		"networks and addresses": {
+			specs: []string{"10.0.0.0/8", "192.168.1.7", "fd00::/8"},
+			want:  []string{"10.0.0.0/8", "192.168.1.7/32", "fd00::/8"},
+		},
+		"network with host bits is masked": {
+			specs: []string{"172.16.5.4/12"},
+			want:  []string{"172.16.0.0/12"},
+		},
+		"mapped address is unmapped": {
+			specs: []string{"::ffff:10.1.2.3"},
+			want:  []string{"10.1.2.3/32"},
+		},
+		"blank entries are skipped": {
+			specs: []string{"", " 10.0.0.1 ", "  "},
+			want:  []string{"10.0.0.1/32"},
+		},
+		"invalid address": {
+			specs:   []string{"10.0.0.256"},
+			wantErr: true,
+		},
+		"invalid network": {
+			specs:   []string{"10.0.0.0/33"},
+			wantErr: true,
+		},
+		"hostname": {
+			specs:   []string{"proxy.internal"},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseTrustedProxies(tc.specs)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("want an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+			for i, prefix := range got {
+				if prefix.String() != tc.want[i] {
+					t.Errorf("want %v, got %v", tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "fd00::/8"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]struct {
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		"direct client": {
+			remoteAddr: "203.0.113.5:5123",
+			want:       "203.0.113.5",
+		},
+		"untrusted peer can't spoof its address": {
+			remoteAddr: "203.0.113.5:5123",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "203.0.113.5",
+		},
+		"trusted proxy without a header": {
+			remoteAddr: "10.0.0.1:5123",
+			want:       "10.0.0.1",
+		},
+		"trusted proxy": {
+			remoteAddr: "10.0.0.1:5123",
+			forwarded:  []string{"203.0.113.5"},
+			want:       "203.0.113.5",
+		},
+		"addresses made up by the client are ignored": {
+			remoteAddr: "10.0.0.1:5123",
+			forwarded:  []string{"198.51.100.1, 192.0.2.9, 203.0.113.5"},
+			want:       "203.0.113.5",
+		},
+		"chain of trusted proxies": {
+			remoteAddr: "10.0.0.1:5123",
+			forwarded:  []string{"198.51.100.1, 203.0.113.5, 10.0.0.3, 10.0.0.2"},
+			want:       "203.0.113.5",
+		},
+		"chain split over several headers": {
+			remoteAddr: "10.0.0.1:5123",
+			forwarded:  []string{"198.51.100.1, 203.0.113.5", "10.0.0.2"},
+			want:       "203.0.113.5",
+		},
+		"untrusted proxy in the chain": {
+			remoteAddr: "10.0.0.1:5123",
+			forwarded:  []string{"203.0.113.5, 198.51.100.1, 10.0.0.2"},
+			want:       "198.51.100.1",
+		},
+		"every hop trusted": {
+			remoteAddr: "10.0.0.1:5123",
+			forwarded:  []string{"10.0.0.3, 10.0.0.2"},
+			want:       "10.0.0.3",
+		},
+		"garbage hop stops the walk": {
+			remoteAddr: "10.0.0.1:5123",
+			forwarded:  []string{"203.0.113.5, not-an-ip, 10.0.0.2"},
+			want:       "10.0.0.2",
+		},
+		"garbage from the client": {
+			remoteAddr: "10.0.0.1:5123",
+			forwarded:  []string{"not-an-ip, 203.0.113.5"},
+			want:       "203.0.113.5",
+		},
+		"IPv6 client through IPv6 proxy": {
+			remoteAddr: "[fd00::1]:5123",
+			forwarded:  []string{"2001:db8::7"},
+			want:       "2001:db8::7",
+		},
+		"mapped IPv4 peer": {
+			remoteAddr: "[::ffff:10.0.0.1]:5123",
+			forwarded:  []string{"::ffff:203.0.113.5"},
+			want:       "203.0.113.5",
+		},
+		"unparseable peer": {
+			remoteAddr: "pipe",
+			forwarded:  []string{"203.0.113.5"},
+			want:       "pipe",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/blogs", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for _, header := range tc.forwarded {
+				req.Header.Add("X-Forwarded-For", header)
+			}
+
+			if got := proxies.ClientIP(req); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// encodings are the content codings Compress can apply, in order of
+// preference when a client accepts several equally.
+var encodings = []string{"zstd", "gzip"}
+
+var (
+	gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+	zstdWriters = sync.Pool{New: func() any {
+		w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+		return w
+	}}
+)
+
+// incompressible lists the media types that are already compressed, so
+// compressing them again only costs time.
+var incompressible = []string{
+	"image/", "video/", "audio/", "font/woff",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/octet-stream", "application/pdf",
+}
+
+// Compress is a middleware that compresses responses with zstd or gzip,
+// whichever the client prefers in Accept-Encoding. Responses shorter than
+// minSize bytes, partial responses, responses that already have a
+// Content-Encoding and media types that are already compressed are sent as
+// they are. Responses are compressed as they are written, so streamed
+// responses stay streamed.
+func Compress(minSize int) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				minSize:        minSize,
+				status:         http.StatusOK,
+			}
+			defer cw.Close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks the encoding to use from an Accept-Encoding
+// header, or "" to send the response as it is.
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	best, bestQ := "", 0.0
+	weights := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		weights[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	for _, encoding := range encodings {
+		q, ok := weights[encoding]
+		if !ok {
+			q, ok = weights["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter holds back the start of a response until it knows whether
+// to compress it: once minSize bytes have been written, the handler flushes
+// or the response ends.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status      int
+	wroteHeader bool
+	decided     bool
+	buf         bytes.Buffer
+	encoder     io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	// Informational responses go straight out
+	if status >= 100 && status < 200 {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		w.buf.Write(p)
+		if w.buf.Len() < w.minSize {
+			return len(p), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// decide sends the headers, compressing the response if large says it is
+// big enough and nothing else rules it out, then writes what was held back.
+func (w *compressWriter) decide(large bool) error {
+	w.decided = true
+
+	header := w.ResponseWriter.Header()
+	if header.Get("Content-Type") == "" && w.buf.Len() > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf.Bytes()))
+	}
+	if large && w.compressible() {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", w.encoding)
+		w.encoder = w.newEncoder()
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+// compressible reports whether the response may be compressed.
+func (w *compressWriter) compressible() bool {
+	header := w.ResponseWriter.Header()
+	if w.status < 200 || w.status == http.StatusNoContent || w.status == http.StatusPartialContent ||
+		w.status == http.StatusNotModified {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	for _, prefix := range incompressible {
+		if strings.HasPrefix(mediaType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *compressWriter) newEncoder() io.WriteCloser {
+	switch w.encoding {
+	case "zstd":
+		enc := zstdWriters.Get().(*zstd.Encoder)
+		enc.Reset(w.ResponseWriter)
+		return enc
+	default:
+		enc := gzipWriters.Get().(*gzip.Writer)
+		enc.Reset(w.ResponseWriter)
+		return enc
+	}
+}
+
+// Flush sends what has been written so far. A response flushed before
+// reaching minSize is being streamed, so it is compressed anyway.
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if f, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Close finishes the response, sending it as it is if it never reached
+// minSize, and returns the encoder to its pool.
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		if !w.wroteHeader {
+			return nil
+		}
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.encoder == nil {
+		return nil
+	}
+
+	err := w.encoder.Close()
+	switch enc := w.encoder.(type) {
+	case *zstd.Encoder:
+		enc.Reset(io.Discard)
+		zstdWriters.Put(enc)
+	case *gzip.Writer:
+		enc.Reset(io.Discard)
+		gzipWriters.Put(enc)
+	}
+	w.encoder = nil
+	return err
+}
+
+// Hijack lets websocket and similar handlers take over the connection.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
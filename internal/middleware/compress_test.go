@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                       "",
+		"gzip":                   "gzip",
+		"gzip, zstd":             "zstd",
+		"zstd;q=0.5, gzip":       "gzip",
+		"br":                     "",
+		"*":                      "zstd",
+		"gzip;q=0, identity":     "",
+		"deflate, gzip;q=0.8, *": "zstd",
+	}
+
+	for header, want := range tests {
+		if got := negotiateEncoding(header); got != want {
+			t.Errorf("Accept-Encoding %q: want %q, got %q", header, want, got)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("hello, world\n", 200)
+
+	tests := map[string]struct {
+		acceptEncoding string
+		contentType    string
+		body           string
+		wantEncoding   string
+	}{
+		"gzip": {
+			acceptEncoding: "gzip",
+			contentType:    "application/json",
+			body:           large,
+			wantEncoding:   "gzip",
+		},
+		"zstd": {
+			acceptEncoding: "zstd, gzip",
+			contentType:    "application/json",
+			body:           large,
+			wantEncoding:   "zstd",
+		},
+		"not accepted": {
+			contentType: "application/json",
+			body:        large,
+		},
+		"small body": {
+			acceptEncoding: "gzip",
+			contentType:    "application/json",
+			body:           "{}",
+		},
+		"already compressed type": {
+			acceptEncoding: "gzip",
+			contentType:    "image/png",
+			body:           large,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := Compress(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				// Write in pieces to cross the size threshold mid-response
+				for chunk := range strings.Lines(tc.body) {
+					_, _ = io.WriteString(w, chunk)
+				}
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != tc.wantEncoding {
+				t.Fatalf("want Content-Encoding %q, got %q", tc.wantEncoding, got)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("want Vary: Accept-Encoding, got %q", got)
+			}
+
+			var body io.Reader = rec.Body
+			switch tc.wantEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("invalid gzip body: %v", err)
+				}
+				body = zr
+			case "zstd":
+				zr, err := zstd.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("invalid zstd body: %v", err)
+				}
+				defer zr.Close()
+				body = zr
+			}
+
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("failed to read body: %v", err)
+			}
+			if string(got) != tc.body {
+				t.Errorf("body doesn't round trip: got %d bytes, want %d", len(got), len(tc.body))
+			}
+		})
+	}
+}
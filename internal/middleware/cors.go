@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures which cross-origin requests browsers may make.
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to call the API, such as
+	// https://app.example.com. "*" allows any origin.
+	AllowedOrigins []string
+	// AllowedMethods and AllowedHeaders are what preflight requests are
+	// told may be used.
+	AllowedMethods []string
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers scripts may read.
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and Authorization headers
+	// from the origins listed in AllowedOrigins and read the response. "*"
+	// doesn't extend it to every origin.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+// CORS is a middleware that answers preflight requests and adds the CORS
+// headers to responses to requests from allowed origins. Requests from other
+// origins are served without them, so browsers keep the response from the
+// calling script.
+func CORS(opts CORSOptions) Middleware {
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			header := w.Header()
+			header.Add("Vary", "Origin")
+
+			if origin == "" || !(anyOrigin || slices.Contains(opts.AllowedOrigins, origin)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Only listed origins are trusted with credentials. Others let in
+			// by "*" get the wildcard, which browsers never send credentials
+			// to, rather than their own origin echoed back
+			if opts.AllowCredentials && slices.Contains(opts.AllowedOrigins, origin) {
+				header.Set("Access-Control-Allow-Origin", origin)
+				header.Set("Access-Control-Allow-Credentials", "true")
+			} else if anyOrigin {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+
+			// Answer preflight requests without passing them on
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+				header.Set("Access-Control-Allow-Methods", methods)
+				header.Set("Access-Control-Allow-Headers", headers)
+				header.Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if exposed != "" {
+				header.Set("Access-Control-Expose-Headers", exposed)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSCredentials(t *testing.T) {
+	tests := map[string]struct {
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+	}{
+		"listed origin": {
+			origin:          "https://app.example.com",
+			wantOrigin:      "https://app.example.com",
+			wantCredentials: "true",
+		},
+		"origin let in by the wildcard": {
+			origin:     "https://evil.example",
+			wantOrigin: "*",
+		},
+	}
+
+	h := CORS(CORSOptions{
+		AllowedOrigins:   []string{"https://app.example.com", "*"},
+		AllowCredentials: true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/blog", nil)
+			req.Header.Set("Origin", tc.origin)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tc.wantOrigin {
+				t.Errorf("want Access-Control-Allow-Origin %q, got %q", tc.wantOrigin, got)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tc.wantCredentials {
+				t.Errorf("want Access-Control-Allow-Credentials %q, got %q", tc.wantCredentials, got)
+			}
+		})
+	}
+}
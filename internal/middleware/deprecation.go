@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/navid/blog/internal/apiversion"
+)
+
+// Deprecated is a middleware that marks the responses of a deprecated
+// version or endpoint of the API, registered at pattern, with the
+// Deprecation header of RFC 9745, a Sunset header (RFC 8594) if it has a
+// sunset and a successor-version link to the same path in its successor.
+// Every call is logged along with the client that made it, identified as by
+// RateLimit, so the clients still to move off it can be found.
+func Deprecated(logger *slog.Logger, proxies TrustedProxies, pattern string, d apiversion.Deprecation) Middleware {
+	deprecation := "@" + strconv.FormatInt(d.Since.Unix(), 10)
+	version, _ := apiversion.Split(pattern)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Set("Deprecation", deprecation)
+			if !d.Sunset.IsZero() {
+				header.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if d.Successor != "" {
+				header.Add("Link", "<"+apiversion.Rewrite(r.URL.Path, d.Successor)+`>; rel="successor-version"`)
+			}
+
+			logger.InfoContext(r.Context(), "deprecated endpoint called",
+				slog.String("pattern", pattern),
+				slog.String("version", version),
+				slog.String("client", proxies.Client(r)),
+				slog.String("user_agent", r.UserAgent()))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/navid/blog/internal/logging"
 )
 
 type wrappedWriter struct {
@@ -16,28 +18,39 @@ func (w *wrappedWriter) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
 }
 
+// Flush passes flushes through, so streamed responses aren't held back.
+func (w *wrappedWriter) Flush() {
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *wrappedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Logger is a middleware that logs the request method, path, duration, and
-// status code.
+// status code, along with what later middleware learned about the request,
+// such as the authenticated user.
 func Logger(logger *slog.Logger) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			ctx := logging.WithRequestAttrs(r.Context())
 
 			wrapped := &wrappedWriter{
 				ResponseWriter: w,
 				statusCode:     http.StatusOK,
 			}
 
-			next.ServeHTTP(wrapped, r)
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
 
-			logger.InfoContext(
-				r.Context(),
-				"request completed",
+			attrs := append([]slog.Attr{
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
 				slog.String("duration", time.Since(start).String()),
 				slog.Int("status", wrapped.statusCode),
-			)
+			}, logging.RequestAttrs(ctx)...)
+			logger.LogAttrs(ctx, slog.LevelInfo, "request completed", attrs...)
 		})
 	}
 }
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeVerifier accepts the token "good" as user 7.
+type fakeVerifier struct{}
+
+func (fakeVerifier) Verify(token string) (int, error) {
+	if token != "good" {
+		return 0, errors.New("invalid token")
+	}
+	return 7, nil
+}
+
+func TestLogger_AuthenticatedUser(t *testing.T) {
+	tests := map[string]struct {
+		authorization string
+		wantStatus    float64
+		wantUserID    any
+	}{
+		"authenticated": {
+			authorization: "Bearer good",
+			wantStatus:    http.StatusOK,
+			wantUserID:    float64(7),
+		},
+		"anonymous": {
+			wantStatus: http.StatusOK,
+		},
+		"bad token": {
+			authorization: "Bearer bad",
+			wantStatus:    http.StatusUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			h := Logger(logger)(Authenticate(slog.New(slog.DiscardHandler), fakeVerifier{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/feed", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			var line map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("expected exactly one log line, got %q: %v", buf.String(), err)
+			}
+			if line["msg"] != "request completed" {
+				t.Fatalf("expected the request completed line, got %v", line["msg"])
+			}
+			if line["status"] != tc.wantStatus {
+				t.Errorf("expected status %v, got %v", tc.wantStatus, line["status"])
+			}
+			if line["user_id"] != tc.wantUserID {
+				t.Errorf("expected user_id %v, got %v", tc.wantUserID, line["user_id"])
+			}
+		})
+	}
+}
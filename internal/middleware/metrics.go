@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/navid/blog/internal/metrics"
+)
+
+// router represents a type that can report which pattern a request matches,
+// such as *http.ServeMux.
+type router interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// RoutePattern returns the pattern registered on router that r matches, or
+// "unmatched". Labelling by pattern rather than raw path keeps metric
+// cardinality bounded.
+func RoutePattern(router router, r *http.Request) string {
+	if _, pattern := router.Handler(r); pattern != "" {
+		return pattern
+	}
+	return "unmatched"
+}
+
+// methodLabel returns the request method, or "other" for non-standard
+// methods so clients can't create unbounded label values.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	default:
+		return "other"
+	}
+}
+
+// Metrics is a middleware that records the rate, errors and duration of
+// requests, labelled by method, the route pattern they matched on router and
+// status code, along with the number of requests in flight.
+func Metrics(registry *metrics.Registry, router router) Middleware {
+	requests := registry.NewCounterVec(
+		"http_requests_total",
+		"Total number of HTTP requests served, by method, route pattern and status code.",
+		"method", "route", "status",
+	)
+	duration := registry.NewHistogramVec(
+		"http_request_duration_seconds",
+		"HTTP request latency in seconds, by method, route pattern and status code.",
+		metrics.DefaultBuckets,
+		"method", "route", "status",
+	)
+	inFlight := registry.NewGaugeVec(
+		"http_requests_in_flight",
+		"Number of HTTP requests currently being served, by method and route pattern.",
+		"method", "route",
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			method, route := methodLabel(r.Method), RoutePattern(router, r)
+
+			gauge := inFlight.With(method, route)
+			gauge.Inc()
+			defer gauge.Dec()
+
+			wrapped := &wrappedWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+
+			next.ServeHTTP(wrapped, r)
+
+			status := strconv.Itoa(wrapped.statusCode)
+			requests.With(method, route, status).Inc()
+			duration.With(method, route, status).Observe(time.Since(start).Seconds())
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/navid/blog/internal/openapi"
+)
+
+// maxCheckedResponse is the size of the largest response body ValidateOpenAPI
+// checks. Larger ones are passed on unchecked rather than held in memory.
+const maxCheckedResponse = 1 << 20
+
+// ValidateOpenAPI is a middleware that checks requests matching the
+// operations of doc on router, answering those that don't with 400 Bad
+// Request and their problems. With checkResponses it also checks the
+// responses, logging a warning for each that doesn't match doc; it is meant
+// for development, to catch the handlers drifting from the spec. Responses
+// projected with fields= aren't checked, as they leave properties out.
+func ValidateOpenAPI(logger *slog.Logger, doc *openapi.Document, router router, checkResponses bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, pattern := router.Handler(r)
+			op, ok := doc.Operation(pattern, r.Method)
+			if pattern == "" || !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Only JSON bodies are checked, so others, such as the large ones
+			// of imports, aren't read
+			var body []byte
+			if op.RequestBody != nil && op.RequestBody.Content["application/json"].Schema != nil {
+				var err error
+				body, err = io.ReadAll(r.Body)
+				if err != nil {
+					// Leave the error, such as the body being too large, for
+					// the handler to report
+					r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err}))
+					next.ServeHTTP(w, r)
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			if problems := doc.ValidateRequest(pattern, r, body); len(problems) > 0 {
+				logger.InfoContext(r.Context(), "request doesn't match the OpenAPI spec",
+					slog.String("pattern", pattern),
+					slog.Any("problems", problems))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(problems)
+				return
+			}
+
+			if !checkResponses || r.URL.Query().Has("fields") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			recorder := &recordingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+			if recorder.overflow {
+				return
+			}
+			if problems := doc.ValidateResponse(pattern, r.Method, recorder.statusCode, w.Header(), recorder.body.Bytes()); len(problems) > 0 {
+				logger.WarnContext(r.Context(), "response doesn't match the OpenAPI spec",
+					slog.String("pattern", pattern),
+					slog.Int("status", recorder.statusCode),
+					slog.Any("problems", problems))
+			}
+		})
+	}
+}
+
+// errReader is a reader that fails with err.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+// recordingWriter is a wrappedWriter that also keeps a copy of the body, up
+// to maxCheckedResponse bytes.
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+	overflow    bool
+}
+
+func (w *recordingWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.statusCode, w.wroteHeader = statusCode, true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	if !w.overflow {
+		if w.body.Len()+len(b) > maxCheckedResponse {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) Flush() {
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/navid/blog/internal/openapi"
+)
+
+func TestValidateOpenAPI(t *testing.T) {
+	tests := map[string]struct {
+		method       string
+		target       string
+		body         string
+		response     string
+		wantStatus   int
+		wantBody     string
+		wantServed   bool
+		wantMismatch bool
+	}{
+		"valid request and response": {
+			method:     http.MethodPost,
+			target:     "/api/blog",
+			body:       `{"title": "Hello", "author_id": 1}`,
+			response:   `{"id": 1, "title": "Hello", "author_id": 1, "created_date": "2024-01-02T03:04:05Z", "score": 0, "rating_average": 0, "rating_count": 0}`,
+			wantStatus: http.StatusCreated,
+			wantServed: true,
+		},
+		"invalid request": {
+			method:     http.MethodPost,
+			target:     "/api/blog",
+			body:       `{"title": "Hello", "author_id": "1"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"author_id":"author_id must be an integer, not a string"}` + "\n",
+		},
+		"invalid response": {
+			method:       http.MethodPost,
+			target:       "/api/blog",
+			body:         `{"title": "Hello", "author_id": 1}`,
+			response:     `{"id": 1, "title": "Hello"}`,
+			wantStatus:   http.StatusCreated,
+			wantServed:   true,
+			wantMismatch: true,
+		},
+		"projected response": {
+			method:     http.MethodPost,
+			target:     "/api/blog?fields=id",
+			body:       `{"title": "Hello", "author_id": 1}`,
+			response:   `{"id": 1}`,
+			wantStatus: http.StatusCreated,
+			wantServed: true,
+		},
+		"undocumented route": {
+			method:     http.MethodGet,
+			target:     "/robots.txt",
+			response:   `{}`,
+			wantStatus: http.StatusCreated,
+			wantServed: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var served bool
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served = true
+				if body, _ := io.ReadAll(r.Body); string(body) != tc.body {
+					t.Errorf("handler read %q, want %q", body, tc.body)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				io.WriteString(w, tc.response)
+			})
+			mux := http.NewServeMux()
+			mux.Handle("POST /api/blog", handler)
+			mux.Handle("GET /robots.txt", handler)
+
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, nil))
+			h := ValidateOpenAPI(logger, openapi.API(), mux, true)(mux)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body)))
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.wantBody != "" && rec.Body.String() != tc.wantBody {
+				t.Errorf("want body %q, got %q", tc.wantBody, rec.Body.String())
+			}
+			if served != tc.wantServed {
+				t.Errorf("want served %t, got %t", tc.wantServed, served)
+			}
+			if mismatch := strings.Contains(logs.String(), "response doesn't match"); mismatch != tc.wantMismatch {
+				t.Errorf("want mismatch logged %t, got %t: %s", tc.wantMismatch, mismatch, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/navid/blog/internal/ratelimit"
+)
+
+// limiter represents a type capable of taking a token from a client's bucket
+// for a route.
+type limiter interface {
+	Allow(ctx context.Context, route, client string) (ratelimit.Result, bool, error)
+}
+
+// RateLimit is a middleware that limits how often each client can call each
+// route matched on router. Authenticated clients are limited by user, others
+// by the address worked out through proxies. Limited responses carry the
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers; refused requests get a 429 with Retry-After. If the limiter's
+// store fails, requests are let through rather than taking the API down with
+// it.
+func RateLimit(logger *slog.Logger, limiter limiter, router router, proxies TrustedProxies) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := proxies.Client(r)
+			res, ok, err := limiter.Allow(r.Context(), RoutePattern(router, r), client)
+			if err != nil {
+				logger.ErrorContext(r.Context(), "rate limiter failed, allowing request", slog.String("error", err.Error()))
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(res.Limit.Requests))
+			header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			header.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", res.Limit.Requests, ceilSeconds(res.Limit.Period)))
+
+			if !res.Allowed {
+				logger.InfoContext(r.Context(), "rate limit exceeded", slog.String("client", client))
+				header.Set("Retry-After", ceilSeconds(res.RetryAfter))
+				http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds formats d as a whole number of seconds, rounded up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/ratelimit"
+)
+
+// fakeLimiter returns a fixed result and remembers who it was asked about.
+type fakeLimiter struct {
+	res    ratelimit.Result
+	ok     bool
+	err    error
+	route  string
+	client string
+}
+
+func (f *fakeLimiter) Allow(ctx context.Context, route, client string) (ratelimit.Result, bool, error) {
+	f.route, f.client = route, client
+	return f.res, f.ok, f.err
+}
+
+func TestRateLimit(t *testing.T) {
+	limit := ratelimit.Limit{Requests: 10, Period: time.Minute}
+
+	tests := map[string]struct {
+		limiter    *fakeLimiter
+		userID     int
+		wantStatus int
+		wantClient string
+		wantHeader map[string]string
+	}{
+		"allowed": {
+			limiter: &fakeLimiter{
+				res: ratelimit.Result{Allowed: true, Limit: limit, Remaining: 7, Reset: 17500 * time.Millisecond},
+				ok:  true,
+			},
+			wantStatus: http.StatusOK,
+			wantClient: "ip:203.0.113.5",
+			wantHeader: map[string]string{
+				"RateLimit-Limit":     "10",
+				"RateLimit-Remaining": "7",
+				"RateLimit-Reset":     "18",
+				"RateLimit-Policy":    "10;w=60",
+				"Retry-After":         "",
+			},
+		},
+		"refused": {
+			limiter: &fakeLimiter{
+				res: ratelimit.Result{Limit: limit, Reset: time.Minute, RetryAfter: 5500 * time.Millisecond},
+				ok:  true,
+			},
+			wantStatus: http.StatusTooManyRequests,
+			wantClient: "ip:203.0.113.5",
+			wantHeader: map[string]string{
+				"RateLimit-Limit":     "10",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "60",
+				"RateLimit-Policy":    "10;w=60",
+				"Retry-After":         "6",
+			},
+		},
+		"authenticated user": {
+			limiter: &fakeLimiter{
+				res: ratelimit.Result{Allowed: true, Limit: limit, Remaining: 9},
+				ok:  true,
+			},
+			userID:     7,
+			wantStatus: http.StatusOK,
+			wantClient: "user:7",
+		},
+		"no limit": {
+			limiter:    &fakeLimiter{},
+			wantStatus: http.StatusOK,
+			wantClient: "ip:203.0.113.5",
+			wantHeader: map[string]string{
+				"RateLimit-Limit": "",
+				"Retry-After":     "",
+			},
+		},
+		"store failure lets the request through": {
+			limiter:    &fakeLimiter{err: errors.New("connection refused")},
+			wantStatus: http.StatusOK,
+			wantClient: "ip:203.0.113.5",
+			wantHeader: map[string]string{
+				"RateLimit-Limit": "",
+				"Retry-After":     "",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /api/blogs", func(w http.ResponseWriter, r *http.Request) {})
+
+			h := RateLimit(slog.New(slog.DiscardHandler), tc.limiter, mux, nil)(mux)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/blogs", nil)
+			req.RemoteAddr = "203.0.113.5:5123"
+			if tc.userID != 0 {
+				req = req.WithContext(auth.WithUserID(req.Context(), tc.userID))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.limiter.route != "GET /api/blogs" {
+				t.Errorf("want route %q, got %q", "GET /api/blogs", tc.limiter.route)
+			}
+			if tc.limiter.client != tc.wantClient {
+				t.Errorf("want client %q, got %q", tc.wantClient, tc.limiter.client)
+			}
+			for header, want := range tc.wantHeader {
+				if got := rec.Header().Get(header); got != want {
+					t.Errorf("want %s %q, got %q", header, want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+
+	"github.com/navid/blog/internal/logging"
+)
+
+// RequestIDHeader is the header a request ID is read from and echoed in.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the length of a request ID accepted from a
+// client, so it can't be used to bloat every log line.
+const maxRequestIDLength = 128
+
+// RequestID is a middleware that tags every request with an ID, reusing the
+// one in an incoming X-Request-ID header if it is well formed or generating
+// one otherwise, and echoes it in the response. The ID is stored in the
+// request context along with a logger enriched with it, the route pattern the
+// request matches on router and the remote address.
+func RequestID(logger *slog.Logger, router router) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx := logging.WithRequestID(r.Context(), id)
+			ctx = logging.WithLogger(ctx, logger.With(
+				slog.String("request_id", id),
+				slog.String("route", RoutePattern(router, r)),
+				slog.String("remote_addr", r.RemoteAddr),
+			))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// validRequestID reports whether id is a non-empty, reasonably sized string
+// of printable ASCII without spaces.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128-bit request ID, hex encoded.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
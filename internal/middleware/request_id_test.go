@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/navid/blog/internal/logging"
+)
+
+func TestRequestID(t *testing.T) {
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	tests := map[string]struct {
+		header string
+		keep   bool
+	}{
+		"valid id is kept": {
+			header: "abc-123_DEF.456",
+			keep:   true,
+		},
+		"longest valid id is kept": {
+			header: strings.Repeat("a", maxRequestIDLength),
+			keep:   true,
+		},
+		"missing id is generated": {},
+		"oversized id is replaced": {
+			header: strings.Repeat("a", maxRequestIDLength+1),
+		},
+		"id with spaces is replaced": {
+			header: "abc 123",
+		},
+		"id with control characters is replaced": {
+			header: "abc\x00123",
+		},
+		"non-ASCII id is replaced": {
+			header: "abc-é",
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/blog/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var seen string
+			h := RequestID(slog.New(slog.DiscardHandler), mux)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = logging.RequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/blog/1", nil)
+			if tc.header != "" {
+				req.Header.Set(RequestIDHeader, tc.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			echoed := rec.Header().Get(RequestIDHeader)
+			if echoed != seen {
+				t.Errorf("expected response header %q to echo the request's id %q", echoed, seen)
+			}
+			if tc.keep {
+				if seen != tc.header {
+					t.Errorf("expected id %q to be kept, got %q", tc.header, seen)
+				}
+			} else if !generated.MatchString(seen) {
+				t.Errorf("expected a generated id, got %q", seen)
+			}
+		})
+	}
+}
+
+func TestRequestID_Unique(t *testing.T) {
+	h := RequestID(slog.New(slog.DiscardHandler), http.NewServeMux())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	ids := map[string]bool{}
+	for range 10 {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		ids[rec.Header().Get(RequestIDHeader)] = true
+	}
+	if len(ids) != 10 {
+		t.Errorf("expected 10 distinct ids, got %d", len(ids))
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/navid/blog/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing is a middleware that starts a server span for every request,
+// continuing the trace from an incoming W3C traceparent header. The span is
+// named after the route pattern the request matches on router and records
+// the method, route and status code; 5xx responses mark it as failed.
+func Tracing(router router) Middleware {
+	tracer := otel.Tracer("github.com/navid/blog/internal/middleware")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = tracing.Extract(r)
+			route := RoutePattern(router, r)
+
+			ctx, span := tracer.Start(r.Context(), route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("client.address", r.RemoteAddr),
+				),
+			)
+			defer span.End()
+
+			wrapped := &wrappedWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("http.response.status_code", wrapped.statusCode))
+			if wrapped.statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(wrapped.statusCode))
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/navid/blog/internal/apiversion"
+)
+
+// BatchRequest is a list of API requests run one after another. An atomic
+// batch runs them all in one transaction, which is only committed if every
+// one succeeds; it stops at the first that fails.
+//
+// An operation can use the result of an earlier one by referring to it in
+// its path or body as ${id.path}, where path leads into the result, such as
+// ${blog.body.id} or ${list.body.0.id}. A body string that is nothing but a
+// reference is replaced by the value referred to, keeping its type.
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,max=50"`
+}
+
+// BatchOperation is a request in a batch. The path is relative to the host,
+// including any query, and must be an API route other than the batch itself
+// or an import.
+type BatchOperation struct {
+	ID     string          `json:"id,omitempty"`
+	Method string          `json:"method" validate:"required,oneof=GET POST PUT DELETE"`
+	Path   string          `json:"path" validate:"required"`
+	Body   json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
+
+// BatchResponse reports the result of every operation of a batch, in order.
+// Committed is only set for atomic batches.
+type BatchResponse struct {
+	Committed *bool         `json:"committed,omitempty"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchResult is the response to an operation: its body as JSON, or as a
+// string if it wasn't JSON, and the headers of it clients act on, such as
+// Deprecation, Location and the RateLimit ones. Operations that weren't run,
+// because an earlier one they depend on failed, have status 424 Failed
+// Dependency.
+type BatchResult struct {
+	ID      string            `json:"id,omitempty"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    any               `json:"body,omitempty"`
+}
+
+// BatchReference matches a reference to the result of an earlier operation
+// of a batch: the id of the operation, then the dotted path into its result.
+var BatchReference = regexp.MustCompile(`\$\{([A-Za-z0-9_-]+)((?:\.[A-Za-z0-9_-]+)*)\}`)
+
+var batchID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Valid checks the BatchRequest object and returns any problems.
+func (b BatchRequest) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+
+	defined := make(map[string]int)
+	for i, op := range b.Operations {
+		field := fmt.Sprintf("operations[%d]", i)
+
+		if op.ID != "" {
+			if !batchID.MatchString(op.ID) {
+				problems[field+".id"] = "id may only contain letters, digits, - and _"
+			} else if j, ok := defined[op.ID]; ok {
+				problems[field+".id"] = fmt.Sprintf("id %q is already used by operations[%d]", op.ID, j)
+			}
+		}
+
+		p, _, _ := strings.Cut(op.Path, "?")
+		_, unversioned := apiversion.Split(path.Clean(p))
+		switch {
+		case !strings.HasPrefix(op.Path, "/api/"):
+			problems[field+".path"] = "path must start with /api/"
+		case unversioned == "/api/batch":
+			problems[field+".path"] = "batches can't be nested"
+		case unversioned == "/api/admin/import":
+			problems[field+".path"] = "imports can't be batched, as they run in a transaction of their own"
+		}
+
+		for _, text := range []string{op.Path, string(op.Body)} {
+			for _, ref := range BatchReference.FindAllStringSubmatch(text, -1) {
+				if _, ok := defined[ref[1]]; !ok {
+					problems[field] = fmt.Sprintf("%s refers to %q, which isn't an earlier operation", ref[0], ref[1])
+				}
+			}
+		}
+
+		if op.ID != "" {
+			if _, ok := defined[op.ID]; !ok {
+				defined[op.ID] = i
+			}
+		}
+	}
+
+	return problems
+}
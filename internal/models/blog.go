@@ -2,17 +2,108 @@ package models
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
 
+const (
+	// MaxTags is the most tags a blog can have.
+	MaxTags = 10
+	// MaxTagLength is the longest a single tag can be.
+	MaxTagLength = 32
+)
+
 // Blog represents a blog in the system.
 type Blog struct {
 	ID        uint      `json:"id,omitempty"`
 	Title     string    `json:"title" validate:"required"`
-	Score     float64   `json:"score"` // Ensure this matches the database type
 	AuthorID  int       `json:"author_id" validate:"required"`
 	CreatedAt time.Time `json:"created_date"` // Ensure this matches the database type
+	// UpdatedAt is when the blog was last edited, or nil if it never was. It
+	// is set by BlogService and ignored when sent in a request body.
+	UpdatedAt *time.Time `json:"updated_date,omitempty"`
+
+	// Rating fields are computed from user ratings by BlogService and are
+	// ignored when sent in a request body.
+	Score         float64 `json:"score"` // Bayesian-weighted rating used for ranking
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
+
+	// Reactions holds the number of reactions of each type, computed by
+	// BlogService.
+	Reactions map[string]int `json:"reactions,omitempty"`
+
+	// Tags are lowercase labels grouping related blogs. When updating a blog
+	// a nil Tags leaves its tags unchanged.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ModTime is the last time the page for a blog or author changed, used for
+// sitemaps.
+type ModTime struct {
+	ID       int
+	Modified time.Time
+}
+
+// BlogFilter holds the options for listing blogs.
+type BlogFilter struct {
+	// Title filters blogs whose title contains the value, case-insensitively.
+	Title string
+	// AuthorID filters blogs written by the user, when non-zero.
+	AuthorID int
+	// Tag filters blogs carrying the tag, when non-empty.
+	Tag string
+	// IDs and AuthorIDs filter blogs with one of the ids, or written by one
+	// of the users, when non-empty.
+	IDs       []int
+	AuthorIDs []int
+	// OrderByScore ranks blogs by their weighted score, best first, instead
+	// of returning them in storage order.
+	OrderByScore bool
+	// OrderByNewest returns the most recently created blogs first.
+	OrderByNewest bool
+	// Limit caps the number of blogs returned, when non-zero, after skipping
+	// the first Offset.
+	Limit  int
+	Offset int
+}
+
+// NormalizeTags lowercases and trims tags, dropping empty ones and
+// duplicates, and returns them sorted. A nil slice stays nil.
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+
+	return normalized
+}
+
+// ValidTag reports whether tag, once normalized, can be used as a tag: it
+// must be made of lowercase letters, digits and hyphens.
+func ValidTag(tag string) bool {
+	if tag == "" || len(tag) > MaxTagLength {
+		return false
+	}
+	for _, r := range tag {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
 }
 
 // Valid checks the Blog object and returns any problems.
@@ -27,5 +118,22 @@ func (b Blog) Valid(ctx context.Context) map[string]string {
 		problems["author_id"] = "author_id is required"
 	}
 
+	tags := NormalizeTags(b.Tags)
+	if len(tags) > MaxTags {
+		problems["tags"] = fmt.Sprintf("at most %d tags are allowed", MaxTags)
+	}
+	for _, tag := range tags {
+		if !ValidTag(tag) {
+			problems["tags"] = fmt.Sprintf("tags must be at most %d lowercase letters, digits or hyphens", MaxTagLength)
+			break
+		}
+	}
+
 	return problems
 }
+
+// Text returns everything the author wrote in the blog, its title followed by
+// its tags, one per line, for the content filters to screen.
+func (b Blog) Text() string {
+	return strings.Join(append([]string{b.Title}, b.Tags...), "\n")
+}
@@ -8,10 +8,14 @@ import (
 
 // Comment represents a comment in the system.
 type Comment struct {
-	UserID      int       `json:"user_id"`
-	BlogID      int       `json:"blog_id"`
-	Message     string    `json:"message"`
+	UserID      int       `json:"user_id" validate:"required"`
+	BlogID      int       `json:"blog_id" validate:"required"`
+	Message     string    `json:"message" validate:"required"`
 	CreatedDate time.Time `json:"created_date"`
+
+	// Reactions holds the number of reactions of each type, computed by
+	// CommentsService.
+	Reactions map[string]int `json:"reactions,omitempty"`
 }
 
 // Valid checks the Comment object and returns any problems.
@@ -19,7 +23,7 @@ func (c Comment) Valid(ctx context.Context) map[string]string {
 	problems := make(map[string]string)
 
 	if strings.TrimSpace(c.Message) == "" {
-		problems["Message"] = "Message is required"
+		problems["message"] = "message is required"
 	}
 
 	return problems
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"context"
+	"strings"
+)
+
+// Credentials are exchanged for a bearer token.
+type Credentials struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Valid checks the Credentials object and returns any problems.
+func (c Credentials) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+
+	if strings.TrimSpace(c.Email) == "" {
+		problems["email"] = "email is required"
+	}
+	if c.Password == "" {
+		problems["password"] = "password is required"
+	}
+
+	return problems
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cursor marks a position in a list of blogs ordered newest first. It holds
+// the created date and id of the last blog on the previous page, so the next
+// page starts right after it even if blogs are added in the meantime.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// ErrInvalidCursor is returned when a cursor string can't be parsed.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// String encodes the cursor as an opaque, URL-safe token.
+func (c Cursor) String() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + strconv.FormatUint(uint64(c.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor decodes a token produced by Cursor.String.
+func ParseCursor(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	i, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return Cursor{CreatedAt: time.Unix(0, n).UTC(), ID: uint(i)}, nil
+}
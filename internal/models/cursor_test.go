@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCursor(t *testing.T) {
+	testcases := map[string]struct {
+		input       string
+		expected    Cursor
+		expectedErr error
+	}{
+		"round trip": {
+			input:    Cursor{CreatedAt: time.Date(2024, 5, 14, 9, 0, 0, 123, time.UTC), ID: 42}.String(),
+			expected: Cursor{CreatedAt: time.Date(2024, 5, 14, 9, 0, 0, 123, time.UTC), ID: 42},
+		},
+		"not base64": {
+			input:       "!!!",
+			expectedErr: ErrInvalidCursor,
+		},
+		"missing id": {
+			input:       "MTIz",
+			expectedErr: ErrInvalidCursor,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			output, err := ParseCursor(tc.input)
+			if err != tc.expectedErr {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if !output.CreatedAt.Equal(tc.expected.CreatedAt) || output.ID != tc.expected.ID {
+				t.Errorf("expected %v, got %v", tc.expected, output)
+			}
+		})
+	}
+}
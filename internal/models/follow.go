@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Follow represents one user following another. Follows are never read from a
+// request body: the REST API follows on behalf of the authenticated user.
+type Follow struct {
+	FollowerID  int       `json:"follower_id"`
+	FolloweeID  int       `json:"followee_id"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+// FollowUser is an entry in a follower or following list.
+type FollowUser struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	FollowedDate time.Time `json:"followed_date"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Moderation statuses.
+const (
+	ModerationPending  = "pending"
+	ModerationApproved = "approved"
+	ModerationRejected = "rejected"
+)
+
+// ModerationItem represents content that a filter flagged or rejected, along
+// with the reason and, for flagged content, the moderator's decision.
+type ModerationItem struct {
+	ID          uint            `json:"id"`
+	Kind        string          `json:"kind"`
+	UserID      int             `json:"user_id"`
+	Text        string          `json:"text"`
+	Payload     json.RawMessage `json:"payload"`
+	Verdict     string          `json:"verdict"`
+	Filter      string          `json:"filter"`
+	Reason      string          `json:"reason"`
+	Status      string          `json:"status"`
+	CreatedDate time.Time       `json:"created_date"`
+	DecidedDate *time.Time      `json:"decided_date,omitempty"`
+}
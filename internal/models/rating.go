@@ -0,0 +1,38 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Rating bounds.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Rating represents a single user's rating of a blog. UserID is the user
+// rating it, who is never read from a request body: the REST API rates on
+// behalf of the authenticated user.
+type Rating struct {
+	UserID      int       `json:"-"`
+	BlogID      int       `json:"blog_id"`
+	Rating      int       `json:"rating" validate:"required,min=1,max=5"`
+	CreatedDate time.Time `json:"created_date"`
+	UpdatedDate time.Time `json:"updated_date"`
+}
+
+// Valid checks the Rating object and returns any problems.
+func (r Rating) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+
+	if r.UserID == 0 {
+		problems["user_id"] = "user_id is required"
+	}
+
+	if r.Rating < MinRating || r.Rating > MaxRating {
+		problems["rating"] = "rating must be between 1 and 5"
+	}
+
+	return problems
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ReactionTarget identifies what a reaction is attached to. Comments have no
+// id of their own, so a comment is identified by its blog and the user who
+// wrote it. A zero CommentUserID targets the blog itself.
+type ReactionTarget struct {
+	BlogID        int
+	CommentUserID int
+}
+
+// IsComment reports whether the target is a comment rather than a blog.
+func (t ReactionTarget) IsComment() bool {
+	return t.CommentUserID != 0
+}
+
+// Reaction represents a single user's reaction to a blog or comment. Type is
+// one of the configured emoji. Reactions are never read from a request body:
+// the REST API reacts on behalf of the authenticated user.
+type Reaction struct {
+	UserID        int       `json:"user_id"`
+	UserName      string    `json:"user_name,omitempty"`
+	BlogID        int       `json:"blog_id"`
+	CommentUserID int       `json:"comment_user_id,omitempty"`
+	Type          string    `json:"type"`
+	CreatedDate   time.Time `json:"created_date"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Bookmark represents a blog a user saved to read later.
+type Bookmark struct {
+	UserID      int       `json:"user_id"`
+	BlogID      int       `json:"blog_id"`
+	BlogTitle   string    `json:"blog_title,omitempty"`
+	Note        string    `json:"note"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+// Valid checks the Bookmark object and returns any problems. The user and
+// blog come from the request, so only the note is checked.
+func (b Bookmark) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+
+	if len(b.Note) > 1000 {
+		problems["note"] = "note must be at most 1000 characters"
+	}
+
+	return problems
+}
+
+// ReadingList represents a named, ordered list of blogs owned by a user.
+// Public lists can be read by anyone.
+type ReadingList struct {
+	ID          uint              `json:"id,omitempty"`
+	UserID      int               `json:"user_id"`
+	Name        string            `json:"name" validate:"required"`
+	Public      bool              `json:"public"`
+	CreatedDate time.Time         `json:"created_date"`
+	Items       []ReadingListItem `json:"items,omitempty"`
+}
+
+// Valid checks the ReadingList object and returns any problems.
+func (l ReadingList) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+
+	if strings.TrimSpace(l.Name) == "" {
+		problems["name"] = "name is required"
+	}
+
+	return problems
+}
+
+// ReadingListItem is a blog in a reading list. Positions start at 1 and are
+// kept contiguous.
+type ReadingListItem struct {
+	BlogID    int       `json:"blog_id"`
+	BlogTitle string    `json:"blog_title,omitempty"`
+	Position  int       `json:"position"`
+	Note      string    `json:"note"`
+	AddedDate time.Time `json:"added_date"`
+}
+
+// Valid checks the ReadingListItem object and returns any problems. A zero
+// position means "append to the end".
+func (i ReadingListItem) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+
+	if i.Position < 0 {
+		problems["position"] = "position must be positive"
+	}
+	if len(i.Note) > 1000 {
+		problems["note"] = "note must be at most 1000 characters"
+	}
+
+	return problems
+}
@@ -0,0 +1,51 @@
+package models
+
+// Kinds of rows that can be imported.
+const (
+	ImportUsers    = "users"
+	ImportBlogs    = "blogs"
+	ImportComments = "comments"
+)
+
+// Import modes. An atomic import writes nothing if any row fails; a best
+// effort import writes every row that can be written.
+const (
+	ImportAtomic     = "atomic"
+	ImportBestEffort = "best_effort"
+)
+
+// ImportRow is a row of an import: a User, Blog or Comment, and its 1-based
+// position among the rows of the body.
+type ImportRow struct {
+	Row   int
+	Value any
+}
+
+// ImportError reports why a row of an import wasn't written, keyed by field
+// path like validation problems.
+type ImportError struct {
+	Row      int               `json:"row"`
+	Problems map[string]string `json:"problems"`
+}
+
+// ImportOptions controls how rows are written. A dry run writes every row
+// inside a transaction that is then rolled back, so it reports the same
+// errors a real import would.
+type ImportOptions struct {
+	Mode   string
+	DryRun bool
+}
+
+// ImportResult reports the outcome of an import. Imported counts the rows
+// written, or that would have been in a dry run or a failed atomic import;
+// Committed says whether they were kept.
+type ImportResult struct {
+	Kind      string        `json:"kind"`
+	Mode      string        `json:"mode"`
+	DryRun    bool          `json:"dry_run"`
+	Rows      int           `json:"rows"`
+	Imported  int           `json:"imported"`
+	Failed    int           `json:"failed"`
+	Committed bool          `json:"committed"`
+	Errors    []ImportError `json:"errors"`
+}
@@ -13,6 +13,13 @@ type User struct {
 	Password string `json:"password" validate:"required,min=6"`
 }
 
+// Author is the public view of a user, embedded in the blogs and comments
+// they wrote.
+type Author struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
 // Valid checks the User object and returns any problems.
 func (u User) Valid(ctx context.Context) map[string]string {
 	problems := make(map[string]string)
@@ -0,0 +1,663 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/navid/blog/internal/apiversion"
+	"github.com/navid/blog/internal/health"
+	"github.com/navid/blog/internal/models"
+)
+
+// The bodies of responses that aren't models.
+
+type token struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UserID    uint      `json:"user_id"`
+}
+
+// v1User is a user as version 1 shows them, with a password that is
+// always empty.
+type v1User struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type feedPage struct {
+	Blogs      []models.Blog `json:"blogs"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// expandedBlog is a blog with the relations include= can embed.
+type expandedBlog struct {
+	models.Blog
+	Author   *models.Author    `json:"author,omitempty"`
+	Comments []expandedComment `json:"comments,omitempty"`
+}
+
+// expandedComment is a comment with the user include= can embed.
+type expandedComment struct {
+	models.Comment
+	User *models.Author `json:"user,omitempty"`
+}
+
+// publicUser is a user as responses show them from version 2 on.
+type publicUser struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type screenResult struct {
+	ModerationID uint   `json:"moderation_id"`
+	Status       string `json:"status" validate:"oneof=pending rejected"`
+	Filter       string `json:"filter"`
+	Reason       string `json:"reason"`
+}
+
+type healthStatus struct {
+	Status string `json:"status"`
+}
+
+type healthReport struct {
+	health.Report
+}
+
+type graphQLRequest struct {
+	Query         string         `json:"query" validate:"required"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data       any            `json:"data,omitempty"`
+	Errors     []graphQLError `json:"errors,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+type graphQLError struct {
+	Message    string           `json:"message"`
+	Locations  []sourceLocation `json:"locations,omitempty"`
+	Path       []any            `json:"path,omitempty"`
+	Extensions map[string]any   `json:"extensions,omitempty"`
+}
+
+type sourceLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// bearerAuth is the name of the security scheme of authenticated operations.
+const bearerAuth = "BearerAuth"
+
+// API returns the OpenAPI document of the REST API, with the operations of
+// every version under its prefix, such as /api/v2, and those of the default
+// version under /api too.
+func API() *Document {
+	b := &builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info: Info{
+				Title:       "Blog Service API",
+				Version:     "1.0",
+				Description: "Users, blogs and comments, with reactions, follows, bookmarks, reading lists and moderation. Responses are JSON unless the Accept header asks for another format; lists can also be streamed as NDJSON or CSV.",
+			},
+			Paths: make(map[string]PathItem),
+			Components: Components{
+				SecuritySchemes: map[string]SecurityScheme{
+					bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				},
+			},
+			Tags: []Tag{
+				{Name: "auth"}, {Name: "users"}, {Name: "follows"}, {Name: "blogs"},
+				{Name: "comments"}, {Name: "reactions"}, {Name: "me"}, {Name: "moderation"},
+				{Name: "batch"}, {Name: "admin"}, {Name: "graphql"}, {Name: "health"},
+			},
+		},
+		schemas:   make(schemas),
+		overrides: make(map[string]map[string]*Operation),
+	}
+
+	var (
+		id         = pathParam("id", "ID")
+		userID     = pathParam("id", "User ID")
+		blogID     = pathParam("id", "Blog ID")
+		listID     = pathParam("id", "Reading list ID")
+		itemBlogID = pathParam("blog_id", "Blog ID")
+		typ        = &Parameter{Name: "type", In: "path", Required: true, Description: "Reaction type, an emoji such as 👍", Schema: String()}
+		authorQ    = query("author_id", "Comment author ID", Integer(), true)
+		blogQ      = query("blog_id", "Comment blog ID", Integer(), true)
+		include    = func(relations ...string) *Parameter {
+			return query("include", "Relations to embed, comma-separated: "+strings.Join(relations, ", "), String(), false)
+		}
+		fields = query("fields", "Properties to return, comma-separated, like title,author.name", String(), false)
+	)
+
+	// Auth
+	b.op("POST /api/auth/token", "createToken", "Exchange credentials for a bearer token", "auth").
+		body(models.Credentials{}, true).
+		ok(http.StatusOK, "The token", token{}).
+		fails(http.StatusUnauthorized)
+
+	// Users
+	b.op("POST /api/user", "createUser", "Create a user", "users").
+		body(models.User{}, true).
+		ok(http.StatusCreated, "The user", v1User{})
+	b.op("GET /api/user", "listUsers", "List users, filtered by name", "users").
+		params(query("name", "Part of the name", String(), false)).
+		ok(http.StatusOK, "The users", []v1User{})
+	b.op("GET /api/user/{id}", "getUser", "Read a user", "users").
+		params(userID).
+		ok(http.StatusOK, "The user", v1User{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("PUT /api/user/{id}", "updateUser", "Replace a user, as that user or an admin", "users").
+		authenticated().
+		params(userID).
+		body(models.User{}, true).
+		ok(http.StatusOK, "The user", v1User{}).
+		fails(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+	b.op("DELETE /api/user/{id}", "deleteUser", "Delete a user, as that user or an admin", "users").
+		authenticated().
+		params(userID).
+		ok(http.StatusNoContent, "Deleted", nil).
+		fails(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+
+	// Version 2 drops the always empty password field of users
+	v2 := b.from("v2")
+	v2.op("POST /api/user", "createUser", "Create a user", "users").
+		body(models.User{}, true).
+		ok(http.StatusCreated, "The user", publicUser{})
+	v2.op("GET /api/user", "listUsers", "List users, filtered by name", "users").
+		params(query("name", "Part of the name", String(), false)).
+		ok(http.StatusOK, "The users", []publicUser{})
+	v2.op("GET /api/user/{id}", "getUser", "Read a user", "users").
+		params(userID).
+		ok(http.StatusOK, "The user", publicUser{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	v2.op("PUT /api/user/{id}", "updateUser", "Replace a user, as that user or an admin", "users").
+		authenticated().
+		params(userID).
+		body(models.User{}, true).
+		ok(http.StatusOK, "The user", publicUser{}).
+		fails(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+
+	// Follows
+	b.op("PUT /api/user/{id}/follow", "follow", "Follow a user as the authenticated user", "follows").
+		authenticated().
+		params(pathParam("id", "ID of the user to follow")).
+		ok(http.StatusOK, "The follow", models.Follow{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("DELETE /api/user/{id}/follow", "unfollow", "Stop following a user as the authenticated user", "follows").
+		authenticated().
+		params(pathParam("id", "ID of the followed user")).
+		ok(http.StatusNoContent, "Unfollowed", nil).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("GET /api/user/{id}/followers", "listFollowers", "List a user's followers", "follows").
+		params(userID).
+		ok(http.StatusOK, "The followers", []models.FollowUser{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("GET /api/user/{id}/following", "listFollowing", "List the users a user follows", "follows").
+		params(userID).
+		ok(http.StatusOK, "The followed users", []models.FollowUser{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	feed := b.op("GET /api/feed", "getFeed", "Read a page of the blogs by the users the authenticated user follows, newest first", "follows").
+		authenticated().
+		params(
+			query("cursor", "Cursor from a previous page", String(), false),
+			query("limit", "Page size", &Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(100.0), Default: 20}, false),
+		).
+		ok(http.StatusOK, "The page", feedPage{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	feed.op.Responses["200"].Headers = map[string]Header{
+		"Link": {Description: "The next page, as rel=\"next\"", Schema: String()},
+	}
+
+	// Blogs
+	b.op("GET /api/blog", "listBlogs", "List blogs, filtered and sorted", "blogs").
+		params(
+			query("title", "Part of the title", String(), false),
+			query("author_id", "Author ID", Integer(), false),
+			query("tag", "Tag", String(), false),
+			query("sort", "score ranks by weighted rating, newest puts the most recent first", Enum("score", "newest"), false),
+			include("author", "comments", "comment.user"),
+			fields,
+		).
+		ok(http.StatusOK, "The blogs", []expandedBlog{}).
+		fails(http.StatusBadRequest)
+	b.op("GET /api/blog/{id}", "getBlog", "Read a blog", "blogs").
+		params(blogID, include("author", "comments", "comment.user"), fields).
+		ok(http.StatusOK, "The blog", expandedBlog{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("POST /api/blog", "createBlog", "Create a blog as the authenticated user, unless the content filters hold it back or reject it", "blogs").
+		authenticated().
+		body(models.Blog{}, true).
+		ok(http.StatusCreated, "The blog", models.Blog{}).
+		screened().
+		fails(http.StatusForbidden)
+	b.op("PUT /api/blog/{id}", "updateBlog", "Replace one of the authenticated user's blogs, unless the content filters hold it back or reject it; tags are kept if left out", "blogs").
+		authenticated().
+		params(blogID).
+		body(models.Blog{}, true).
+		ok(http.StatusOK, "The blog", models.Blog{}).
+		screened().
+		fails(http.StatusForbidden, http.StatusNotFound)
+	b.op("DELETE /api/blog/{id}", "deleteBlog", "Delete a blog", "blogs").
+		params(blogID).
+		ok(http.StatusNoContent, "Deleted", nil).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("PUT /api/blog/{id}/rating", "rateBlog", "Rate a blog from 1 to 5 as the authenticated user", "blogs").
+		authenticated().
+		params(blogID).
+		body(models.Rating{}, true).
+		ok(http.StatusOK, "The blog, with its new score", models.Blog{}).
+		fails(http.StatusForbidden, http.StatusNotFound)
+
+	// Comments
+	b.op("GET /api/comments", "listComments", "List comments, filtered by author and blog", "comments").
+		params(
+			query("author_id", "Author ID", Integer(), false),
+			query("blog_id", "Blog ID", Integer(), false),
+			include("user"),
+			fields,
+		).
+		ok(http.StatusOK, "The comments", []expandedComment{}).
+		fails(http.StatusBadRequest)
+	b.op("POST /api/comments", "createComment", "Comment on a blog as the authenticated user, unless the content filters hold it back or reject it", "comments").
+		authenticated().
+		body(models.Comment{}, true).
+		ok(http.StatusCreated, "The comment", models.Comment{}).
+		screened().
+		fails(http.StatusForbidden)
+	b.op("PUT /api/comments", "updateComment", "Replace the message of one of the authenticated user's comments, unless the content filters hold it back or reject it", "comments").
+		authenticated().
+		params(authorQ, blogQ).
+		body(models.Comment{}, true).
+		ok(http.StatusOK, "The comment", models.Comment{}).
+		screened().
+		fails(http.StatusForbidden, http.StatusNotFound)
+	b.op("DELETE /api/comments", "deleteComment", "Delete a comment", "comments").
+		params(authorQ, blogQ).
+		ok(http.StatusNoContent, "Deleted", nil).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+
+	// Reactions
+	b.op("PUT /api/blog/{id}/reactions/{type}", "reactToBlog", "React to a blog as the authenticated user", "reactions").
+		authenticated().
+		params(blogID, typ).
+		ok(http.StatusOK, "The reaction", models.Reaction{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("DELETE /api/blog/{id}/reactions/{type}", "unreactToBlog", "Remove the authenticated user's reaction to a blog", "reactions").
+		authenticated().
+		params(blogID, typ).
+		ok(http.StatusNoContent, "Removed", nil).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("GET /api/blog/{id}/reactions", "listBlogReactions", "List the reactions to a blog", "reactions").
+		params(blogID, query("type", "Reaction type", String(), false)).
+		ok(http.StatusOK, "The reactions", []models.Reaction{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("PUT /api/comments/reactions/{type}", "reactToComment", "React to a comment as the authenticated user", "reactions").
+		authenticated().
+		params(authorQ, blogQ, typ).
+		ok(http.StatusOK, "The reaction", models.Reaction{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("DELETE /api/comments/reactions/{type}", "unreactToComment", "Remove the authenticated user's reaction to a comment", "reactions").
+		authenticated().
+		params(authorQ, blogQ, typ).
+		ok(http.StatusNoContent, "Removed", nil).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("GET /api/comments/reactions", "listCommentReactions", "List the reactions to a comment", "reactions").
+		params(authorQ, blogQ, query("type", "Reaction type", String(), false)).
+		ok(http.StatusOK, "The reactions", []models.Reaction{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+
+	// The authenticated user's bookmarks and reading lists
+	b.op("GET /api/me/bookmarks", "listBookmarks", "List your bookmarks, most recent first", "me").
+		authenticated().
+		ok(http.StatusOK, "The bookmarks", []models.Bookmark{})
+	b.op("PUT /api/me/bookmarks/{blog_id}", "saveBookmark", "Bookmark a blog, or update the note of its bookmark; only note is read", "me").
+		authenticated().
+		params(itemBlogID).
+		body(models.Bookmark{}, false).
+		ok(http.StatusOK, "The bookmark", models.Bookmark{}).
+		fails(http.StatusNotFound)
+	b.op("DELETE /api/me/bookmarks/{blog_id}", "deleteBookmark", "Remove a bookmark", "me").
+		authenticated().
+		params(itemBlogID).
+		ok(http.StatusNoContent, "Removed", nil).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("GET /api/me/lists", "listReadingLists", "List your reading lists", "me").
+		authenticated().
+		ok(http.StatusOK, "The reading lists", []models.ReadingList{})
+	b.op("POST /api/me/lists", "createReadingList", "Create a reading list; name and public are read", "me").
+		authenticated().
+		body(models.ReadingList{}, true).
+		ok(http.StatusCreated, "The reading list", models.ReadingList{})
+	b.op("GET /api/me/lists/{id}", "getReadingList", "Read one of your reading lists", "me").
+		authenticated().
+		params(listID).
+		ok(http.StatusOK, "The reading list", models.ReadingList{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("PUT /api/me/lists/{id}", "updateReadingList", "Rename a reading list or change whether it is public", "me").
+		authenticated().
+		params(listID).
+		body(models.ReadingList{}, true).
+		ok(http.StatusOK, "The reading list", models.ReadingList{}).
+		fails(http.StatusNotFound)
+	b.op("DELETE /api/me/lists/{id}", "deleteReadingList", "Delete a reading list", "me").
+		authenticated().
+		params(listID).
+		ok(http.StatusNoContent, "Deleted", nil).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("PUT /api/me/lists/{id}/items/{blog_id}", "saveReadingListItem", "Add a blog to a reading list, or move it; position and note are read", "me").
+		authenticated().
+		params(listID, itemBlogID).
+		body(models.ReadingListItem{}, false).
+		ok(http.StatusOK, "The item", models.ReadingListItem{}).
+		fails(http.StatusNotFound)
+	b.op("DELETE /api/me/lists/{id}/items/{blog_id}", "removeReadingListItem", "Take a blog off a reading list", "me").
+		authenticated().
+		params(listID, itemBlogID).
+		ok(http.StatusNoContent, "Removed", nil).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+	b.op("GET /api/lists/{id}", "getPublicReadingList", "Read a public reading list", "me").
+		params(listID).
+		ok(http.StatusOK, "The reading list", models.ReadingList{}).
+		fails(http.StatusBadRequest, http.StatusNotFound)
+
+	// Moderation
+	b.op("GET /api/moderation", "listModeration", "List content held back or rejected by the content filters", "moderation").
+		authenticated().
+		params(query("status", "Status", Enum(models.ModerationPending, models.ModerationApproved, models.ModerationRejected), false)).
+		ok(http.StatusOK, "The items", []models.ModerationItem{}).
+		fails(http.StatusForbidden)
+	for _, decision := range []string{"approve", "reject"} {
+		b.op("POST /api/moderation/{id}/"+decision, decision+"Moderation", strings.ToUpper(decision[:1])+decision[1:]+" a pending item", "moderation").
+			authenticated().
+			params(id).
+			ok(http.StatusOK, "The item", models.ModerationItem{}).
+			fails(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+	}
+
+	// Batches
+	b.op("POST /api/batch", "batch", "Run API calls in order, optionally in one transaction; batches and imports can't be batched", "batch").
+		body(models.BatchRequest{}, true).
+		ok(http.StatusOK, "The result of each call", models.BatchResponse{})
+
+	// Admin
+	rows := &Schema{Type: "string", Description: "One row per line; users' passwords must be bcrypt hashes, as in an export"}
+	b.op("POST /api/admin/import", "import", "Bulk load users, blogs or comments", "admin").
+		authenticated().
+		params(
+			query("kind", "Kind of rows", Enum(models.ImportUsers, models.ImportBlogs, models.ImportComments), true),
+			query("mode", "atomic writes every row or none, best_effort the valid ones", &Schema{Type: "string", Enum: []any{models.ImportAtomic, models.ImportBestEffort}, Default: models.ImportAtomic}, false),
+			query("dry_run", "Report without writing", Boolean(), false),
+		).
+		rawBody(map[string]*Schema{"application/x-ndjson": rows, "text/csv": rows}).
+		ok(http.StatusOK, "The result", models.ImportResult{}).
+		ok(http.StatusUnprocessableEntity, "The result of an atomic import with bad rows, none of which were written", models.ImportResult{}).
+		fails(http.StatusForbidden)
+	b.op("GET /api/admin/export", "export", "Download every user, blog and comment as a zip of CSV files", "admin").
+		authenticated().
+		file(http.StatusOK, "The archive", "application/zip").
+		fails(http.StatusForbidden)
+
+	// GraphQL
+	b.op("POST /graphql", "graphQL", "Run a GraphQL query or mutation", "graphql").
+		body(graphQLRequest{}, true).
+		ok(http.StatusOK, "The result, with any errors raised resolving fields", graphQLResponse{}).
+		ok(http.StatusBadRequest, "The errors of a request that can't be run", graphQLResponse{})
+	b.op("GET /graphql", "graphQLQuery", "Run a GraphQL query", "graphql").
+		params(
+			query("query", "The query", String(), true),
+			query("operationName", "The operation to run", String(), false),
+			query("variables", "The variables, as a JSON object", String(), false),
+		).
+		ok(http.StatusOK, "The result, with any errors raised resolving fields", graphQLResponse{}).
+		ok(http.StatusBadRequest, "The errors of a request that can't be run", graphQLResponse{}).
+		fails(http.StatusMethodNotAllowed)
+
+	// Health
+	b.op("GET /api/health", "health", "Report that the API is up", "health").
+		ok(http.StatusOK, "Up", healthStatus{})
+	b.op("GET /api/health/live", "liveness", "Report that the process is serving requests", "health").
+		ok(http.StatusOK, "Up", healthStatus{})
+	b.op("GET /api/health/ready", "readiness", "Report whether the API and its dependencies are ready", "health").
+		ok(http.StatusOK, "Ready", healthReport{}).
+		ok(http.StatusServiceUnavailable, "Not ready", healthReport{})
+
+	b.versioned()
+	b.doc.Components.Schemas = b.schemas
+	return b.doc
+}
+
+// builder adds operations to a document.
+type builder struct {
+	doc     *Document
+	schemas schemas
+
+	// version is the version of the API the operations added override
+	// from, or "" for the first. overrides holds them by version and
+	// pattern.
+	version   string
+	overrides map[string]map[string]*Operation
+}
+
+// from returns a builder of the operations overriding those of earlier
+// versions from version on.
+func (b *builder) from(version string) *builder {
+	if !slices.Contains(apiversion.Versions, version) {
+		panic(fmt.Sprintf("openapi: unknown API version %q", version))
+	}
+	return &builder{doc: b.doc, schemas: b.schemas, version: version, overrides: b.overrides}
+}
+
+// versioned moves the operations under /api to each version's prefix, with
+// the overrides of the versions up to it, and keeps those of the default
+// version under /api. The operation IDs of each version end in its name,
+// such as getUserV2.
+func (b *builder) versioned() {
+	paths := b.doc.Paths
+	b.doc.Paths = make(map[string]PathItem)
+	put := func(path, method string, op *Operation) {
+		if b.doc.Paths[path] == nil {
+			b.doc.Paths[path] = make(PathItem)
+		}
+		b.doc.Paths[path][method] = op
+	}
+
+	for path, item := range paths {
+		if !strings.HasPrefix(path, "/api/") {
+			b.doc.Paths[path] = item
+			continue
+		}
+		for method, op := range item {
+			route := strings.ToUpper(method) + " " + path
+			for _, version := range apiversion.Versions {
+				if override, ok := b.overrides[version][route]; ok {
+					op = override
+				}
+				patterns := apiversion.Patterns(route, version)
+				d, deprecated := apiversion.Endpoints[patterns[0]]
+				for i, pattern := range patterns {
+					versionedOp := *op
+					if i == 0 {
+						versionedOp.OperationID += strings.ToUpper(version)
+					}
+					if deprecated {
+						deprecate(&versionedOp)
+						if d.Successor != "" {
+							versionedOp.Description = "Deprecated: use " + apiversion.Rewrite(path, d.Successor) + " instead."
+						}
+					}
+					_, versionedPath, _ := strings.Cut(pattern, " ")
+					put(versionedPath, method, &versionedOp)
+				}
+			}
+		}
+	}
+
+	for version, ops := range b.overrides {
+		for route := range ops {
+			method, path, _ := strings.Cut(route, " ")
+			if _, ok := paths[path][strings.ToLower(method)]; !ok {
+				panic(fmt.Sprintf("openapi: %s overrides %s, which the first version doesn't have", version, route))
+			}
+		}
+	}
+}
+
+// op adds the operation for pattern. Every operation can fail with a
+// 406 Not Acceptable, 429 Too Many Requests or 500 Internal Server Error.
+func (b *builder) op(pattern, operationID, summary, tag string) *opBuilder {
+	method, path, _ := strings.Cut(pattern, " ")
+	op := &Operation{
+		OperationID: operationID,
+		Summary:     summary,
+		Tags:        []string{tag},
+		Responses:   make(map[string]*Response),
+	}
+	if b.version == "" {
+		if b.doc.Paths[path] == nil {
+			b.doc.Paths[path] = make(PathItem)
+		}
+		b.doc.Paths[path][strings.ToLower(method)] = op
+	} else {
+		if b.overrides[b.version] == nil {
+			b.overrides[b.version] = make(map[string]*Operation)
+		}
+		b.overrides[b.version][pattern] = op
+	}
+
+	o := &opBuilder{b: b, op: op}
+	o.fails(http.StatusNotAcceptable, http.StatusTooManyRequests, http.StatusInternalServerError)
+	o.op.Responses["429"].Headers = map[string]Header{
+		"Retry-After": {Description: "Seconds to wait before trying again", Schema: Integer()},
+	}
+	return o
+}
+
+// opBuilder describes an operation.
+type opBuilder struct {
+	b  *builder
+	op *Operation
+}
+
+// params adds parameters to the operation.
+func (o *opBuilder) params(params ...*Parameter) *opBuilder {
+	for _, p := range params {
+		o.op.Parameters = append(o.op.Parameters, *p)
+	}
+	return o
+}
+
+// body sets the operation's request body to v encoded as JSON. Bodies can
+// be malformed, too large or in a media type the API doesn't read.
+func (o *opBuilder) body(v any, required bool) *opBuilder {
+	return o.rawBody(map[string]*Schema{"application/json": o.b.schemaOf(v)}).required(required)
+}
+
+// rawBody sets the operation's request body to one of the media types in
+// content.
+func (o *opBuilder) rawBody(content map[string]*Schema) *opBuilder {
+	o.op.RequestBody = &RequestBody{Required: true, Content: make(map[string]MediaType)}
+	for mediaType, schema := range content {
+		o.op.RequestBody.Content[mediaType] = MediaType{Schema: schema}
+	}
+	return o.fails(http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType)
+}
+
+// required sets whether the operation's request body is required.
+func (o *opBuilder) required(required bool) *opBuilder {
+	o.op.RequestBody.Required = required
+	return o
+}
+
+// authenticated marks the operation as needing a bearer token.
+func (o *opBuilder) authenticated() *opBuilder {
+	o.op.Security = []map[string][]string{{bearerAuth: {}}}
+	return o.fails(http.StatusUnauthorized)
+}
+
+// ok adds a response with status and a body of v encoded as JSON, or no
+// body if v is nil.
+func (o *opBuilder) ok(status int, description string, v any) *opBuilder {
+	resp := &Response{Description: description}
+	if v != nil {
+		resp.Content = map[string]MediaType{"application/json": {Schema: o.b.schemaOf(v)}}
+	}
+	o.op.Responses[strconv.Itoa(status)] = resp
+	return o
+}
+
+// file adds a response with status and a body of mediaType.
+func (o *opBuilder) file(status int, description, mediaType string) *opBuilder {
+	o.op.Responses[strconv.Itoa(status)] = &Response{
+		Description: description,
+		Content:     map[string]MediaType{mediaType: {Schema: &Schema{Type: "string", Format: "binary"}}},
+	}
+	return o
+}
+
+// screened adds the responses for content the content filters held back
+// for moderation or rejected.
+func (o *opBuilder) screened() *opBuilder {
+	return o.ok(http.StatusAccepted, "Held back until a moderator has reviewed it", screenResult{}).
+		ok(http.StatusUnprocessableEntity, "Rejected by the content filters", screenResult{})
+}
+
+// fails adds error responses with statuses. Their bodies are plain text,
+// except for 400 Bad Request, which can also be a JSON object of problems
+// keyed by the field at fault.
+func (o *opBuilder) fails(statuses ...int) *opBuilder {
+	for _, status := range statuses {
+		key := strconv.Itoa(status)
+		if _, ok := o.op.Responses[key]; ok {
+			continue
+		}
+		resp := &Response{
+			Description: http.StatusText(status),
+			Content:     map[string]MediaType{"text/plain": {Schema: String()}},
+		}
+		if status == http.StatusBadRequest {
+			resp.Content["application/json"] = MediaType{Schema: o.b.problems()}
+		}
+		o.op.Responses[key] = resp
+	}
+	return o
+}
+
+// schemaOf returns the schema of v, adding the components it refers to.
+func (b *builder) schemaOf(v any) *Schema {
+	return b.schemas.of(reflect.TypeOf(v))
+}
+
+// problems returns the schema of the problems of an invalid request.
+func (b *builder) problems() *Schema {
+	if _, ok := b.schemas["Problems"]; !ok {
+		b.schemas["Problems"] = &Schema{
+			Type:                 "object",
+			Description:          "What is wrong with each field at fault, keyed by its path, or $ for the body as a whole",
+			AdditionalProperties: String(),
+		}
+	}
+	return ref("Problems")
+}
+
+// pathParam returns a required integer path parameter.
+func pathParam(name, description string) *Parameter {
+	return &Parameter{Name: name, In: "path", Required: true, Description: description, Schema: Integer()}
+}
+
+// query returns a query parameter.
+func query(name, description string, schema *Schema, required bool) *Parameter {
+	return &Parameter{Name: name, In: "query", Required: required, Description: description, Schema: schema}
+}
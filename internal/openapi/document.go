@@ -0,0 +1,164 @@
+// Package openapi describes the REST API as an OpenAPI 3.1 document, built
+// from the models the handlers read and write so the schemas can't drift from
+// the code, and checks requests and responses against it.
+package openapi
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/navid/blog/internal/apiversion"
+)
+
+// Version is the version of the OpenAPI specification documents follow.
+const Version = "3.1.0"
+
+// Document is an OpenAPI document. Only the parts of the specification the
+// API uses are modelled.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	Tags       []Tag               `json:"tags,omitempty"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a URL the API is served at.
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Tag groups operations.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations on a path, by lowercase method.
+type PathItem map[string]*Operation
+
+// Operation is an endpoint: a method on a path.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+// Parameter is a path or query parameter of an operation.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the body of an operation's requests.
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response is one of the responses of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header is a header of a response.
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType is the schema of a body in one media type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds the schemas and security schemes operations refer to.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a way of authenticating requests.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Operation returns the operation registered for pattern, a ServeMux pattern
+// such as "GET /api/blog/{id}", and false if there is none. Patterns without
+// a method match the operation for method.
+func (d *Document) Operation(pattern, method string) (*Operation, bool) {
+	path := pattern
+	if m, p, ok := strings.Cut(pattern, " "); ok {
+		method, path = m, p
+	}
+	op, ok := d.Paths[path][strings.ToLower(method)]
+	return op, ok
+}
+
+// Patterns lists the ServeMux patterns of the document's operations.
+func (d *Document) Patterns() []string {
+	var patterns []string
+	for path, item := range d.Paths {
+		for method := range item {
+			patterns = append(patterns, strings.ToUpper(method)+" "+path)
+		}
+	}
+	return patterns
+}
+
+// Deprecate marks the operations of version, a version of the API such as
+// v1, as deprecated.
+func (d *Document) Deprecate(version string) {
+	for path, item := range d.Paths {
+		if v, _ := apiversion.Split(path); v != version {
+			continue
+		}
+		for _, op := range item {
+			deprecate(op)
+		}
+	}
+}
+
+// deprecate marks op as deprecated, documenting the headers its responses
+// carry.
+func deprecate(op *Operation) {
+	if op.Deprecated {
+		return
+	}
+	op.Deprecated = true
+	responses := make(map[string]*Response, len(op.Responses))
+	for status, resp := range op.Responses {
+		withHeaders := *resp
+		withHeaders.Headers = maps.Clone(resp.Headers)
+		if withHeaders.Headers == nil {
+			withHeaders.Headers = make(map[string]Header)
+		}
+		withHeaders.Headers["Deprecation"] = Header{Description: "When the operation was deprecated, as @ followed by a Unix time", Schema: String()}
+		withHeaders.Headers["Sunset"] = Header{Description: "When the operation will stop being served, if that is planned", Schema: String()}
+		withHeaders.Headers["Link"] = Header{Description: "The operation replacing it, as a successor-version link", Schema: String()}
+		responses[status] = &withHeaders
+	}
+	op.Responses = responses
+}
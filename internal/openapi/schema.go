@@ -0,0 +1,243 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is a JSON Schema, in the 2020-12 dialect OpenAPI 3.1 uses. Only the
+// keywords the API needs are modelled.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"` // a type name, or a list of them
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Default              any                `json:"default,omitempty"`
+
+	// closed forbids properties other than those listed, as the JSON decoder
+	// of request bodies does. It is written as additionalProperties: false.
+	closed bool
+}
+
+// MarshalJSON writes s, with additionalProperties: false if it is closed.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	if !s.closed {
+		return json.Marshal((*schema)(s))
+	}
+	return json.Marshal(struct {
+		*schema
+		AdditionalProperties bool `json:"additionalProperties"`
+	}{(*schema)(s), false})
+}
+
+// ref returns a schema referring to the component schema named name.
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// String, Integer and Boolean return schemas of those types.
+func String() *Schema  { return &Schema{Type: "string"} }
+func Integer() *Schema { return &Schema{Type: "integer"} }
+func Boolean() *Schema { return &Schema{Type: "boolean"} }
+
+// Enum returns a schema of strings that must be one of values.
+func Enum(values ...string) *Schema {
+	s := String()
+	for _, v := range values {
+		s.Enum = append(s.Enum, v)
+	}
+	return s
+}
+
+// ArrayOf returns a schema of arrays of items.
+func ArrayOf(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+// schemas builds the component schemas of Go types.
+type schemas map[string]*Schema
+
+// timeType is the type of time.Time, which is encoded as an RFC 3339 string.
+var timeType = reflect.TypeFor[time.Time]()
+
+// rawType is the type of json.RawMessage, which can hold any JSON value.
+var rawType = reflect.TypeFor[json.RawMessage]()
+
+// of returns the schema of the values of t. Named struct types become
+// components, referred to by name; anonymous fields are flattened into the
+// structs embedding them, as encoding/json does.
+func (c schemas) of(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawType || t.Kind() == reflect.Interface:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(c.of(t.Elem()))
+	case reflect.String:
+		return String()
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Integer()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: ptr(0.0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return ArrayOf(c.of(t.Elem()))
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: c.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return c.object(t)
+		}
+		name := componentName(t)
+		if _, ok := c[name]; !ok {
+			c[name] = nil // placeholder, for types referring to themselves
+			c[name] = c.object(t)
+		}
+		return ref(name)
+	}
+	panic(fmt.Sprintf("openapi: no schema for %s", t))
+}
+
+// object returns the schema of the struct type t.
+func (c schemas) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema), closed: true}
+	c.addFields(s, t)
+	return s
+}
+
+// addFields adds the fields of the struct type t to the object schema s.
+func (c schemas) addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			c.addFields(s, ft)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := c.of(field.Type)
+		if opts == "string" {
+			prop = String()
+		}
+		if rules := field.Tag.Get("validate"); rules != "" {
+			if constrain(prop, rules) {
+				s.Required = append(s.Required, name)
+			}
+		}
+		// encoding/json writes nil slices and maps as null, unless they are
+		// left out when empty
+		if k := field.Type.Kind(); (k == reflect.Slice || k == reflect.Map) && field.Type != rawType && !strings.Contains(opts, "omitempty") {
+			prop = nullable(prop)
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// constrain adds the rules of a validate struct tag to s, returning whether
+// they make the property required.
+func constrain(s *Schema, rules string) bool {
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "min", "max":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				panic(fmt.Sprintf("openapi: invalid bound %q", arg))
+			}
+			bound(s, name == "min", n)
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				s.Enum = append(s.Enum, v)
+			}
+		}
+	}
+	return required
+}
+
+// bound sets the lower or upper bound of s to n, as a length, a number of
+// items or a value according to its type.
+func bound(s *Schema, lower bool, n int) {
+	switch s.Type {
+	case "string":
+		if lower {
+			s.MinLength = &n
+		} else {
+			s.MaxLength = &n
+		}
+	case "array":
+		if lower {
+			s.MinItems = &n
+		} else {
+			s.MaxItems = &n
+		}
+	default:
+		v := float64(n)
+		if lower {
+			s.Minimum = &v
+		} else {
+			s.Maximum = &v
+		}
+	}
+}
+
+// nullable returns s, also allowing null.
+func nullable(s *Schema) *Schema {
+	typ, ok := s.Type.(string)
+	if !ok {
+		return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+	}
+	n := *s
+	n.Type = []string{typ, "null"}
+	return &n
+}
+
+// componentName returns the name of the component schema of the named type
+// t, such as Blog for models.Blog, capitalising unexported names.
+func componentName(t reflect.Type) string {
+	name := []rune(t.Name())
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,362 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// rootPath is the key of problems with a body as a whole, as in the problems
+// the handlers report.
+const rootPath = "$"
+
+// ValidateRequest checks r, which matched pattern, against the operation the
+// document has for it, returning the problems keyed by the parameter or body
+// field at fault. body is the request body, already read from r.
+// Only JSON bodies are checked.
+func (d *Document) ValidateRequest(pattern string, r *http.Request, body []byte) map[string]string {
+	op, ok := d.Operation(pattern, r.Method)
+	if !ok {
+		return nil
+	}
+	problems := make(map[string]string)
+
+	values := pathValues(pattern, r.URL.Path)
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var raw []string
+		switch p.In {
+		case "path":
+			if v, ok := values[p.Name]; ok {
+				raw = []string{v}
+			}
+		case "query":
+			raw = query[p.Name]
+		}
+		if len(raw) == 0 {
+			if p.Required {
+				problems[p.Name] = p.Name + " is required"
+			}
+			continue
+		}
+		d.check(p.Schema, parseParam(p.Schema, raw[0]), p.Name, problems)
+	}
+
+	if op.RequestBody == nil {
+		return problems
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			problems[rootPath] = "request body is required"
+		}
+		return problems
+	}
+	mediaType := "application/json"
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, _ = mime.ParseMediaType(ct)
+	}
+	if content, ok := op.RequestBody.Content[mediaType]; ok && mediaType == "application/json" {
+		d.checkJSON(content.Schema, body, problems)
+	}
+	return problems
+}
+
+// ValidateResponse checks a response to a request that matched pattern
+// against the operation the document has for it, returning its
+// mismatches. Only JSON bodies are checked.
+func (d *Document) ValidateResponse(pattern, method string, status int, header http.Header, body []byte) map[string]string {
+	op, ok := d.Operation(pattern, method)
+	if !ok {
+		return nil
+	}
+	problems := make(map[string]string)
+
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		problems[rootPath] = fmt.Sprintf("status %d isn't documented", status)
+		return problems
+	}
+	if len(body) == 0 {
+		return problems
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return problems
+	}
+	content, ok := resp.Content[mediaType]
+	if !ok {
+		problems[rootPath] = fmt.Sprintf("status %d has no documented JSON body", status)
+		return problems
+	}
+	d.checkJSON(content.Schema, body, problems)
+	return problems
+}
+
+// checkJSON checks the JSON document body against s.
+func (d *Document) checkJSON(s *Schema, body []byte, problems map[string]string) {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		problems[rootPath] = "body must be JSON: " + err.Error()
+		return
+	}
+	d.check(s, v, "", problems)
+}
+
+// check checks the decoded JSON value v, found at path, against s.
+func (d *Document) check(s *Schema, v any, path string, problems map[string]string) {
+	name := path
+	if name == "" {
+		name = "body"
+	}
+	if s.Ref != "" {
+		s = d.resolve(s.Ref)
+	}
+
+	if len(s.AnyOf) > 0 {
+		// Report the problems inside the first option of v's type, or that v
+		// is of none of their types
+		var inner map[string]string
+		for _, option := range s.AnyOf {
+			p := make(map[string]string)
+			if d.check(option, v, path, p); len(p) == 0 {
+				return
+			}
+			if _, wrongType := p[orRoot(path)]; !wrongType && inner == nil {
+				inner = p
+			}
+		}
+		if inner == nil {
+			inner = map[string]string{orRoot(path): fmt.Sprintf("%s must be %s", name, strings.Join(typeNames(d, s.AnyOf), " or "))}
+		}
+		maps.Copy(problems, inner)
+		return
+	}
+
+	if types := schemaTypes(s); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return isType(v, t) }) {
+		problems[orRoot(path)] = fmt.Sprintf("%s must be %s, not %s", name, articles(types), article(valueType(v)))
+		return
+	}
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, v) {
+		options := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			options[i] = fmt.Sprint(e)
+		}
+		problems[orRoot(path)] = fmt.Sprintf("%s must be one of %s", name, strings.Join(options, ", "))
+		return
+	}
+
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		switch {
+		case s.MinLength != nil && n < *s.MinLength:
+			problems[orRoot(path)] = fmt.Sprintf("%s must be at least %d characters", name, *s.MinLength)
+		case s.MaxLength != nil && n > *s.MaxLength:
+			problems[orRoot(path)] = fmt.Sprintf("%s must be at most %d characters", name, *s.MaxLength)
+		case !validFormat(s.Format, v):
+			problems[orRoot(path)] = fmt.Sprintf("%s must be a valid %s", name, s.Format)
+		}
+	case float64:
+		switch {
+		case s.Minimum != nil && v < *s.Minimum:
+			problems[orRoot(path)] = fmt.Sprintf("%s must be at least %g", name, *s.Minimum)
+		case s.Maximum != nil && v > *s.Maximum:
+			problems[orRoot(path)] = fmt.Sprintf("%s must be at most %g", name, *s.Maximum)
+		}
+	case []any:
+		switch {
+		case s.MinItems != nil && len(v) < *s.MinItems:
+			problems[orRoot(path)] = fmt.Sprintf("%s must be at least %d items", name, *s.MinItems)
+		case s.MaxItems != nil && len(v) > *s.MaxItems:
+			problems[orRoot(path)] = fmt.Sprintf("%s must be at most %d items", name, *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				d.check(s.Items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case map[string]any:
+		for _, required := range s.Required {
+			if _, ok := v[required]; !ok {
+				problems[join(path, required)] = join(path, required) + " is required"
+			}
+		}
+		for key, value := range v {
+			switch prop, ok := s.Properties[key]; {
+			case ok:
+				d.check(prop, value, join(path, key), problems)
+			case s.AdditionalProperties != nil:
+				d.check(s.AdditionalProperties, value, join(path, key), problems)
+			case s.closed:
+				problems[join(path, key)] = "unknown field " + join(path, key)
+			}
+		}
+	}
+}
+
+// resolve returns the component schema ref refers to.
+func (d *Document) resolve(ref string) *Schema {
+	name := strings.TrimPrefix(ref, "#/components/schemas/")
+	s, ok := d.Components.Schemas[name]
+	if !ok {
+		panic(fmt.Sprintf("openapi: no schema %s", ref))
+	}
+	return s
+}
+
+// parseParam converts the raw value of a parameter to the JSON value its
+// schema describes, leaving it a string if it isn't one.
+func parseParam(s *Schema, raw string) any {
+	switch s.Type {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// pathValues returns the values of the wildcards of pattern in path.
+func pathValues(pattern, path string) map[string]string {
+	if _, p, ok := strings.Cut(pattern, " "); ok {
+		pattern = p
+	}
+	values := make(map[string]string)
+	segments := strings.Split(path, "/")
+	for i, segment := range strings.Split(pattern, "/") {
+		if i < len(segments) && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			values[strings.Trim(segment, "{}")] = segments[i]
+		}
+	}
+	return values
+}
+
+// schemaTypes returns the types s allows, if it restricts them.
+func schemaTypes(s *Schema) []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	}
+	return nil
+}
+
+// isType reports whether the decoded JSON value v is of the JSON Schema type
+// t.
+func isType(v any, t string) bool {
+	switch v := v.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case float64:
+		return t == "number" || t == "integer" && v == float64(int64(v))
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
+
+// valueType names the JSON type of the decoded JSON value v.
+func valueType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	}
+	return "object"
+}
+
+// typeNames names the schemas in options, for problems.
+func typeNames(d *Document, options []*Schema) []string {
+	var names []string
+	for _, s := range options {
+		if s.Ref != "" {
+			s = d.resolve(s.Ref)
+		}
+		if types := schemaTypes(s); len(types) > 0 {
+			names = append(names, articles(types))
+		} else {
+			names = append(names, "any value")
+		}
+	}
+	return names
+}
+
+// validFormat reports whether v is in format, if it is one that is checked.
+func validFormat(format, v string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(v)
+		return v == "" || err == nil && addr.Address == v
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	}
+	return true
+}
+
+// articles prefixes type names with "a" or "an", joined by "or".
+func articles(names []string) string {
+	with := make([]string, len(names))
+	for i, name := range names {
+		with[i] = article(name)
+	}
+	return strings.Join(with, " or ")
+}
+
+// article prefixes a JSON type name with "a" or "an".
+func article(name string) string {
+	if name == "null" {
+		return name
+	}
+	if strings.IndexByte("aeiou", name[0]) >= 0 {
+		return "an " + name
+	}
+	return "a " + name
+}
+
+// join appends name to path.
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// orRoot returns path, or the key of problems with a body as a whole if
+// path is empty.
+func orRoot(path string) string {
+	if path == "" {
+		return rootPath
+	}
+	return path
+}
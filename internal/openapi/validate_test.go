@@ -0,0 +1,261 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAPIRefsResolve(t *testing.T) {
+	doc := API()
+	body, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				if _, ok := doc.Components.Schemas[name]; !ok {
+					t.Errorf("%s doesn't resolve", ref)
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	walk(decoded)
+
+	if doc.OpenAPI != Version {
+		t.Errorf("want openapi %s, got %s", Version, doc.OpenAPI)
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	doc := API()
+
+	tests := map[string]struct {
+		pattern     string
+		method      string
+		target      string
+		contentType string
+		body        string
+		want        map[string]string
+	}{
+		"valid blog": {
+			pattern: "POST /api/blog",
+			method:  http.MethodPost,
+			target:  "/api/blog",
+			body:    `{"title": "Hello", "author_id": 1, "tags": ["go"]}`,
+			want:    map[string]string{},
+		},
+		"missing and unknown fields": {
+			pattern: "POST /api/blog",
+			method:  http.MethodPost,
+			target:  "/api/blog",
+			body:    `{"title": "Hello", "subtitle": "World"}`,
+			want: map[string]string{
+				"author_id": "author_id is required",
+				"subtitle":  "unknown field subtitle",
+			},
+		},
+		"wrong types": {
+			pattern: "POST /api/blog",
+			method:  http.MethodPost,
+			target:  "/api/blog",
+			body:    `{"title": 1, "author_id": 1.5, "tags": [true]}`,
+			want: map[string]string{
+				"title":     "title must be a string, not an integer",
+				"author_id": "author_id must be an integer, not a number",
+				"tags[0]":   "tags[0] must be a string, not a boolean",
+			},
+		},
+		"body not an object": {
+			pattern: "POST /api/user",
+			method:  http.MethodPost,
+			target:  "/api/user",
+			body:    `[]`,
+			want:    map[string]string{"$": "body must be an object, not an array"},
+		},
+		"missing body": {
+			pattern: "POST /api/user",
+			method:  http.MethodPost,
+			target:  "/api/user",
+			want:    map[string]string{"$": "request body is required"},
+		},
+		"constraints from validate tags": {
+			pattern: "POST /api/user",
+			method:  http.MethodPost,
+			target:  "/api/user",
+			body:    `{"name": "Ann", "email": "not an address", "password": "short"}`,
+			want: map[string]string{
+				"email":    "email must be a valid email",
+				"password": "password must be at least 6 characters",
+			},
+		},
+		"nested items": {
+			pattern: "POST /api/batch",
+			method:  http.MethodPost,
+			target:  "/api/batch",
+			body:    `{"operations": [{"method": "PATCH", "path": "/api/blog"}]}`,
+			want:    map[string]string{"operations[0].method": "operations[0].method must be one of GET, POST, PUT, DELETE"},
+		},
+		"bodies in other formats aren't checked": {
+			pattern:     "POST /api/user",
+			method:      http.MethodPost,
+			target:      "/api/user",
+			contentType: "application/xml",
+			body:        `<User/>`,
+			want:        map[string]string{},
+		},
+		"path parameter": {
+			pattern: "GET /api/blog/{id}",
+			method:  http.MethodGet,
+			target:  "/api/blog/abc",
+			want:    map[string]string{"id": "id must be an integer, not a string"},
+		},
+		"query parameters": {
+			pattern: "GET /api/feed",
+			method:  http.MethodGet,
+			target:  "/api/feed?limit=500",
+			want:    map[string]string{"limit": "limit must be at most 100"},
+		},
+		"required query parameter": {
+			pattern: "GET /api/comments/reactions",
+			method:  http.MethodGet,
+			target:  "/api/comments/reactions?author_id=3",
+			want:    map[string]string{"blog_id": "blog_id is required"},
+		},
+		"query enum": {
+			pattern: "GET /api/blog",
+			method:  http.MethodGet,
+			target:  "/api/blog?sort=oldest",
+			want:    map[string]string{"sort": "sort must be one of score, newest"},
+		},
+		"methodless pattern": {
+			pattern: "/graphql",
+			method:  http.MethodPost,
+			target:  "/graphql",
+			body:    `{"query": "{ blogs { id } }", "variables": {"id": 1}}`,
+			want:    map[string]string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			got := doc.ValidateRequest(tc.pattern, req, []byte(tc.body))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	doc := API()
+
+	tests := map[string]struct {
+		pattern     string
+		method      string
+		status      int
+		contentType string
+		body        string
+		want        map[string]string
+	}{
+		"valid blog": {
+			pattern:     "GET /api/blog/{id}",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"id": 1, "title": "Hello", "author_id": 2, "created_date": "2024-01-02T03:04:05Z", "score": 0, "rating_average": 0, "rating_count": 0, "author": null}`,
+			want:        map[string]string{},
+		},
+		"invalid blog": {
+			pattern:     "GET /api/blog/{id}",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"id": 1, "title": "Hello", "author_id": 2, "created_date": "yesterday", "author": {"id": 2, "name": "Ann", "email": "ann@example.com"}}`,
+			want: map[string]string{
+				"created_date": "created_date must be a valid date-time",
+				"author.email": "unknown field author.email",
+			},
+		},
+		"list": {
+			pattern:     "GET /api/user",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `[{"id": 1, "name": "Ann", "email": "ann@example.com", "password": "secret"}, {"id": "2", "name": "Bo", "email": "bo@example.com", "password": "secret"}]`,
+			want:        map[string]string{"[1].id": "[1].id must be an integer, not a string"},
+		},
+		"undocumented status": {
+			pattern:     "DELETE /api/blog/{id}",
+			method:      http.MethodDelete,
+			status:      http.StatusCreated,
+			contentType: "application/json",
+			want:        map[string]string{"$": "status 201 isn't documented"},
+		},
+		"text error": {
+			pattern:     "GET /api/blog/{id}",
+			method:      http.MethodGet,
+			status:      http.StatusNotFound,
+			contentType: "text/plain; charset=utf-8",
+			body:        "Blog not found\n",
+			want:        map[string]string{},
+		},
+		"problems": {
+			pattern:     "POST /api/blog",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+			body:        `{"title": "title is required"}`,
+			want:        map[string]string{},
+		},
+		"screened": {
+			pattern:     "POST /api/comments",
+			method:      http.MethodPost,
+			status:      http.StatusAccepted,
+			contentType: "application/json",
+			body:        `{"moderation_id": 3, "status": "pending", "filter": "bayes", "reason": "spam"}`,
+			want:        map[string]string{},
+		},
+		"undocumented JSON body": {
+			pattern:     "DELETE /api/blog/{id}",
+			method:      http.MethodDelete,
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"error": "not found"}`,
+			want:        map[string]string{"$": "status 404 has no documented JSON body"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{"Content-Type": {tc.contentType}}
+			got := doc.ValidateResponse(tc.pattern, tc.method, tc.status, header, []byte(tc.body))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often stores delete buckets that have been idle long
+// enough to be full again, as a full bucket is the same as no bucket.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in memory. Limits aren't shared between
+// instances of the service and reset when it restarts.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+// memoryBucket is a bucket along with when it is full again.
+type memoryBucket struct {
+	bucket
+	full time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]memoryBucket)}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b.bucket = bucket{tokens: float64(limit.Requests), updated: now}
+	}
+
+	var res Result
+	b.bucket, res = take(b.bucket, limit, now)
+	b.full = now.Add(res.Reset)
+	s.buckets[key] = b
+
+	return res, nil
+}
+
+// sweep deletes the buckets that are full again by now.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
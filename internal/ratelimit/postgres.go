@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table, so limits are
+// shared by every instance of the service and survive restarts.
+type PostgresStore struct {
+	db        *sql.DB
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewPostgresStore creates a PostgresStore keeping buckets in db.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Take implements Store. The bucket is refilled, a token taken and the
+// result returned by a single upsert, so concurrent requests from the same
+// client, on any instance, see each other's tokens taken.
+//
+// A bucket whose last request was refused is stored with one token fewer
+// than it holds, which is below zero, so the statement can report whether
+// the request was allowed without reading the bucket first: every request
+// subtracts a token, and a negative count means there wasn't one to take.
+// full_date is when the bucket is full at the latest, a period after its
+// last request.
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	if err := s.sweep(ctx, now); err != nil {
+		return Result{}, err
+	}
+
+	var tokens float64
+	err := s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO rate_limit_buckets AS b (key, tokens, updated_date, full_date)
+         VALUES ($1, $2::float8 - 1, $3, $3 + make_interval(secs => $5::float8))
+         ON CONFLICT (key) DO UPDATE SET
+             tokens = LEAST(
+                 $2::float8,
+                 CASE WHEN b.tokens < 0 THEN b.tokens + 1 ELSE b.tokens END
+                     + GREATEST(EXTRACT(EPOCH FROM EXCLUDED.updated_date - b.updated_date)::float8, 0) * $4::float8
+             ) - 1,
+             updated_date = GREATEST(b.updated_date, EXCLUDED.updated_date),
+             full_date = EXCLUDED.full_date
+         RETURNING tokens`,
+		key, float64(limit.Requests), now, limit.rate(), limit.Period.Seconds(),
+	).Scan(&tokens)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take from rate limit bucket: %w", err)
+	}
+
+	if tokens < 0 {
+		return result(limit, tokens+1, false), nil
+	}
+	return result(limit, tokens, true), nil
+}
+
+// sweep deletes the buckets that are full again by now, at most once per
+// sweepInterval.
+func (s *PostgresStore) sweep(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE full_date <= $1`, now); err != nil {
+		return fmt.Errorf("failed to delete full rate limit buckets: %w", err)
+	}
+	return nil
+}
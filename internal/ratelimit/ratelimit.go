@@ -0,0 +1,190 @@
+// Package ratelimit limits how often clients can call the API using token
+// buckets kept in a pluggable Store.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRoute names the limit applied to routes without a limit of their
+// own.
+const DefaultRoute = "default"
+
+// Limit allows Requests requests per Period, in bursts of up to Requests. A
+// Limit with no requests doesn't limit anything.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses a limit written as requests/period, such as 60/m or
+// 1000/24h. A period of just a unit means one of it. "off" or "0" disable
+// limiting.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "off" || s == "0" {
+		return Limit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: must be requests/period", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a non-negative integer", s)
+	}
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: period must be a positive duration", s)
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// String formats l the way ParseLimit reads it.
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// Unlimited reports whether l doesn't limit anything.
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0
+}
+
+// rate is the number of tokens added to a bucket per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	Limit   Limit
+	// Remaining is the number of requests that can be made right away.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed, if this one
+	// wasn't.
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets by key.
+type Store interface {
+	// Take refills the bucket for key at limit's rate up to now and removes
+	// a token from it if there is one. A bucket that doesn't exist yet
+	// starts full.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// bucket is the state of one token bucket.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills b at limit's rate up to now and removes a token from it if
+// there is one, returning the new bucket state and the result.
+func take(b bucket, limit Limit, now time.Time) (bucket, Result) {
+	capacity := float64(limit.Requests)
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*limit.rate())
+		b.updated = now
+	}
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return b, result(limit, b.tokens, allowed)
+}
+
+// result is the outcome of a request that was allowed or not, leaving tokens
+// in its bucket.
+func result(limit Limit, tokens float64, allowed bool) Result {
+	res := Result{Limit: limit, Allowed: allowed}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / limit.rate())
+	}
+	res.Remaining = int(tokens)
+	res.Reset = seconds((float64(limit.Requests) - tokens) / limit.rate())
+	return res
+}
+
+// seconds converts a number of seconds to a time.Duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Limiter applies per-route limits to clients, keeping their buckets in a
+// Store.
+type Limiter struct {
+	store  Store
+	limits map[string]Limit
+	now    func() time.Time
+}
+
+// NewLimiter creates a Limiter keeping buckets in store. limits maps route
+// patterns to their limit; DefaultRoute's limit applies to every other
+// route.
+func NewLimiter(store Store, limits map[string]Limit) *Limiter {
+	return &Limiter{
+		store:  store,
+		limits: limits,
+		now:    time.Now,
+	}
+}
+
+// ParseLimits parses the default limit and the per-route limits read from
+// the configuration into the form NewLimiter takes.
+func ParseLimits(def string, routes map[string]string) (map[string]Limit, error) {
+	limits := make(map[string]Limit, len(routes)+1)
+
+	limit, err := ParseLimit(def)
+	if err != nil {
+		return nil, err
+	}
+	limits[DefaultRoute] = limit
+
+	for route, s := range routes {
+		limit, err := ParseLimit(s)
+		if err != nil {
+			return nil, fmt.Errorf("route %q: %w", route, err)
+		}
+		limits[strings.TrimSpace(route)] = limit
+	}
+
+	return limits, nil
+}
+
+// Allow takes a token from client's bucket for route, reporting whether the
+// request may proceed. Routes without a limit of their own share client's
+// default bucket. ok is false if neither route nor the default is limited.
+func (l *Limiter) Allow(ctx context.Context, route, client string) (res Result, ok bool, err error) {
+	limit, found := l.limits[route]
+	if !found {
+		route = DefaultRoute
+		limit = l.limits[DefaultRoute]
+	}
+	if limit.Unlimited() {
+		return Result{}, false, nil
+	}
+
+	res, err = l.store.Take(ctx, route+"|"+client, limit, l.now())
+	if err != nil {
+		return Result{}, false, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	return res, true, nil
+}
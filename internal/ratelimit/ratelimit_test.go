@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    Limit
+		wantErr bool
+	}{
+		"per minute":     {input: "60/m", want: Limit{Requests: 60, Period: time.Minute}},
+		"custom period":  {input: "1000/24h", want: Limit{Requests: 1000, Period: 24 * time.Hour}},
+		"off":            {input: "off", want: Limit{}},
+		"missing period": {input: "60", wantErr: true},
+		"bad requests":   {input: "lots/m", wantErr: true},
+		"bad period":     {input: "60/fortnight", wantErr: true},
+		"zero period":    {input: "60/0s", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseLimit(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 2, Period: 2 * time.Second}
+	start := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		at            time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{at: 0, wantAllowed: true, wantRemaining: 1},
+		{at: 0, wantAllowed: true, wantRemaining: 0},
+		{at: 0, wantAllowed: false, wantRemaining: 0, wantRetry: time.Second},
+		{at: 500 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantRetry: 500 * time.Millisecond},
+		{at: time.Second, wantAllowed: true, wantRemaining: 0},
+		{at: 10 * time.Second, wantAllowed: true, wantRemaining: 1},
+	}
+
+	for i, step := range steps {
+		res, err := store.Take(context.Background(), "key", limit, start.Add(step.at))
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		if res.Allowed != step.wantAllowed || res.Remaining != step.wantRemaining || res.RetryAfter != step.wantRetry {
+			t.Errorf("step %d: expected allowed=%v remaining=%d retry=%v, got %+v",
+				i, step.wantAllowed, step.wantRemaining, step.wantRetry, res)
+		}
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), map[string]Limit{
+		DefaultRoute:           {Requests: 2, Period: time.Minute},
+		"POST /api/auth/token": {Requests: 1, Period: time.Minute},
+		"GET /metrics":         {},
+	})
+	ctx := context.Background()
+
+	allow := func(route, client string) bool {
+		t.Helper()
+		res, ok, err := limiter.Allow(ctx, route, client)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return !ok || res.Allowed
+	}
+
+	if !allow("POST /api/auth/token", "ip:1.2.3.4") || allow("POST /api/auth/token", "ip:1.2.3.4") {
+		t.Error("expected the route's own limit of 1 to apply")
+	}
+	if !allow("POST /api/auth/token", "ip:5.6.7.8") {
+		t.Error("expected clients to have separate buckets")
+	}
+	if !allow("GET /api/blog", "ip:1.2.3.4") || !allow("GET /api/user", "ip:1.2.3.4") || allow("GET /api/blog/{id}", "ip:1.2.3.4") {
+		t.Error("expected routes without a limit to share the default bucket")
+	}
+	if _, ok, _ := limiter.Allow(ctx, "GET /metrics", "ip:1.2.3.4"); ok {
+		t.Error("expected a route limited with off not to be limited")
+	}
+}
+
+func TestPostgresStore_Take(t *testing.T) {
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 10, Period: 10 * time.Second}
+
+	testcases := map[string]struct {
+		stored            float64
+		expectedAllowed   bool
+		expectedRemaining int
+		expectedRetry     time.Duration
+	}{
+		"a token left": {
+			stored:            1.5,
+			expectedAllowed:   true,
+			expectedRemaining: 1,
+		},
+		"last token taken": {
+			stored:            0,
+			expectedAllowed:   true,
+			expectedRemaining: 0,
+		},
+		"no token to take": {
+			stored:            -0.5,
+			expectedAllowed:   false,
+			expectedRemaining: 0,
+			expectedRetry:     500 * time.Millisecond,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM rate_limit_buckets WHERE full_date <= $1`)).
+				WithArgs(now).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO rate_limit_buckets AS b (key, tokens, updated_date, full_date)`)).
+				WithArgs("default|ip:1.2.3.4", 10.0, now, 1.0, 10.0).
+				WillReturnRows(sqlmock.NewRows([]string{"tokens"}).AddRow(tc.stored))
+
+			res, err := NewPostgresStore(db).Take(context.Background(), "default|ip:1.2.3.4", limit, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Allowed != tc.expectedAllowed || res.Remaining != tc.expectedRemaining || res.RetryAfter != tc.expectedRetry {
+				t.Errorf("expected allowed %v with %d remaining and retry after %v, got %+v",
+					tc.expectedAllowed, tc.expectedRemaining, tc.expectedRetry, res)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
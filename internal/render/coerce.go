@@ -0,0 +1,141 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// coerce converts tree, a value decoded from a format that only has strings
+// such as XML or CSV, to fit t: strings become numbers and booleans where t
+// has them, and JSON text in a CSV cell becomes the object or array it
+// holds. Values that don't fit are left alone for the JSON decoder to report.
+func coerce(tree any, t reflect.Type) any {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || tree == nil {
+		return tree
+	}
+
+	if s, ok := tree.(string); ok {
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				return json.Number(strings.TrimSpace(s))
+			}
+			return s
+		case reflect.Bool:
+			if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+				return b
+			}
+			return s
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+			if t == timeType {
+				return s
+			}
+			trimmed := strings.TrimSpace(s)
+			if trimmed == "" {
+				return nil
+			}
+			var v any
+			if (trimmed[0] == '[' || trimmed[0] == '{') && json.Unmarshal([]byte(trimmed), &v) == nil {
+				return coerce(v, t)
+			}
+			if t.Kind() == reflect.Slice {
+				return []any{coerce(s, t.Elem())}
+			}
+			return s
+		default:
+			return s
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := tree.(map[string]any)
+		if !ok || t == timeType {
+			return tree
+		}
+		fields := jsonFields(t)
+		out := make(map[string]any, len(m))
+		for key, value := range m {
+			if ft, ok := fields[key]; ok {
+				out[key] = coerce(value, ft)
+			} else {
+				out[key] = value
+			}
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		// XML wraps list elements in <item> elements
+		if m, ok := tree.(map[string]any); ok && len(m) == 1 {
+			if item, ok := m["item"]; ok {
+				tree = item
+			}
+		}
+		items, ok := tree.([]any)
+		if !ok {
+			items = []any{tree}
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			out[i] = coerce(item, t.Elem())
+		}
+		return out
+	case reflect.Map:
+		m, ok := tree.(map[string]any)
+		if !ok {
+			return tree
+		}
+		out := make(map[string]any, len(m))
+		for key, value := range m {
+			out[key] = coerce(value, t.Elem())
+		}
+		return out
+	default:
+		return tree
+	}
+}
+
+// jsonFields maps the JSON names of struct type t's fields to their types,
+// including the fields of embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous && field.Tag.Get("json") == "" {
+			continue
+		}
+		name, ok := jsonName(field)
+		if ok {
+			fields[name] = field.Type
+		}
+	}
+	return fields
+}
+
+// jsonName returns the name field is encoded as in JSON, and false if it
+// isn't encoded at all.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+func bytesReader(b []byte) io.Reader {
+	return bytes.NewReader(b)
+}
@@ -0,0 +1,212 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// csvValueColumn is the column a list of anything other than objects is
+// written in.
+const csvValueColumn = "value"
+
+// csvFormat renders a list as CSV: a header of the JSON field names of its
+// elements, then a record per element. Nested objects and lists are written
+// as JSON text in their cell. Strings a spreadsheet would take for a
+// formula are written with a leading ' so opening the file doesn't run
+// them. Request bodies hold a header and one record, or any number of
+// records when the body is a list.
+type csvFormat struct{}
+
+func (csvFormat) MediaType() string { return "text/csv" }
+
+func (f csvFormat) Encode(w io.Writer, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		enc := f.NewListEncoder(w)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	enc := &csvListEncoder{w: csv.NewWriter(w), columns: csvColumns(rv.Type().Elem())}
+	for i := range rv.Len() {
+		if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+func (csvFormat) Decode(r io.Reader, v any) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	if len(records) < 2 {
+		return fmt.Errorf("%w: a header and a record are required", ErrMalformed)
+	}
+
+	header, rows := records[0], make([]any, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]any, len(header))
+		for i, column := range header {
+			// an empty cell is a missing field
+			if record[i] != "" {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice {
+		return toJSON(rows, v)
+	}
+	if len(rows) > 1 {
+		return ErrTrailingData
+	}
+	return toJSON(rows[0], v)
+}
+
+func (csvFormat) NewListEncoder(w io.Writer) ListEncoder {
+	return &csvListEncoder{w: csv.NewWriter(w)}
+}
+
+// csvListEncoder writes the header before the first record, taking the
+// columns from the type of the first element if they aren't known already.
+type csvListEncoder struct {
+	w       *csv.Writer
+	columns []string
+	started bool
+}
+
+func (e *csvListEncoder) Encode(v any) error {
+	if e.columns == nil {
+		e.columns = csvColumns(reflect.TypeOf(v))
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if !slices.Equal(e.columns, []string{csvValueColumn}) {
+		if err := json.Unmarshal(b, &fields); err != nil && len(e.columns) > 0 {
+			return err
+		}
+	}
+	switch {
+	case fields == nil:
+		e.columns = []string{csvValueColumn}
+		fields = map[string]json.RawMessage{csvValueColumn: b}
+	case len(e.columns) == 0:
+		// The columns of a list of maps come from the first one's keys
+		e.columns = slices.Sorted(maps.Keys(fields))
+	}
+
+	if err := e.header(); err != nil {
+		return err
+	}
+	record := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		record[i] = csvCell(fields[column])
+	}
+	if err := e.w.Write(record); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvListEncoder) header() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	if len(e.columns) == 0 {
+		return nil
+	}
+	return e.w.Write(e.columns)
+}
+
+func (e *csvListEncoder) Close() error {
+	if err := e.header(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvColumns returns the columns for a list of t: the JSON names of its
+// fields for a struct, none for a map, as they come from the keys, and a
+// single value column for anything else.
+func csvColumns(t reflect.Type) []string {
+	if t == nil {
+		return []string{csvValueColumn}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Map:
+		return nil
+	case t.Kind() == reflect.Interface:
+		return nil
+	case t.Kind() != reflect.Struct || t == timeType || t.Implements(jsonMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return []string{csvValueColumn}
+	}
+
+	var columns []string
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous && field.Tag.Get("json") == "" {
+			continue
+		}
+		if name, ok := jsonName(field); ok {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+// csvCell returns the text of a cell holding the JSON value raw.
+func csvCell(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		return ""
+	case raw[0] == '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return csvText(s)
+		}
+	case raw[0] == '{' || raw[0] == '[':
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err == nil {
+			return buf.String()
+		}
+	}
+	return string(raw)
+}
+
+// csvText returns the cell for the string s, prefixed with ' if it starts
+// with a character that makes spreadsheets read it as a formula.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,114 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+)
+
+type jsonFormat struct{}
+
+func (jsonFormat) MediaType() string { return "application/json" }
+
+func (jsonFormat) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (jsonFormat) Decode(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		var syntaxErr *json.SyntaxError
+		if err != nil && !errors.As(err, &syntaxErr) {
+			return err
+		}
+		return ErrTrailingData
+	}
+	return nil
+}
+
+func (jsonFormat) NewListEncoder(w io.Writer) ListEncoder {
+	return &jsonListEncoder{w: w}
+}
+
+// jsonListEncoder writes a JSON array.
+type jsonListEncoder struct {
+	w       io.Writer
+	started bool
+}
+
+func (e *jsonListEncoder) Encode(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if e.started {
+		b = append([]byte(","), b...)
+	} else {
+		b = append([]byte("["), b...)
+		e.started = true
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonListEncoder) Close() error {
+	if !e.started {
+		_, err := io.WriteString(e.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// ndjsonFormat is newline-delimited JSON, one value per line. Lists are
+// written an element per line; anything else as a single line.
+type ndjsonFormat struct{}
+
+func (ndjsonFormat) MediaType() string { return "application/x-ndjson" }
+
+func (f ndjsonFormat) Encode(w io.Writer, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return json.NewEncoder(w).Encode(v)
+	}
+	enc := f.NewListEncoder(w)
+	for i := range rv.Len() {
+		if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+func (ndjsonFormat) Decode(r io.Reader, v any) error {
+	return JSON.Decode(r, v)
+}
+
+func (ndjsonFormat) NewListEncoder(w io.Writer) ListEncoder {
+	return ndjsonListEncoder{json.NewEncoder(w)}
+}
+
+type ndjsonListEncoder struct {
+	enc *json.Encoder
+}
+
+func (e ndjsonListEncoder) Encode(v any) error { return e.enc.Encode(v) }
+
+func (e ndjsonListEncoder) Close() error { return nil }
+
+// toJSON transcodes tree, a value decoded from another format, to JSON,
+// coercing it to fit the type of target first, and decodes it strictly into
+// target.
+func toJSON(tree any, target any) error {
+	b, err := json.Marshal(coerce(tree, reflect.TypeOf(target)))
+	if err != nil {
+		return err
+	}
+	return JSON.Decode(bytesReader(b), target)
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpackFormat renders the JSON encoding of a value as MessagePack, so
+// times are strings and fields are named by their json tags, as in every
+// other format.
+type msgpackFormat struct{}
+
+func (msgpackFormat) MediaType() string { return "application/msgpack" }
+
+func (msgpackFormat) Encode(w io.Writer, v any) error {
+	tree, err := jsonTree(v)
+	if err != nil {
+		return err
+	}
+	enc := msgpack.NewEncoder(w)
+	enc.SetSortMapKeys(true)
+	return enc.Encode(tree)
+}
+
+func (msgpackFormat) Decode(r io.Reader, v any) error {
+	dec := msgpack.NewDecoder(r)
+	tree, err := dec.DecodeInterface()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: empty body", ErrMalformed)
+		}
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	if _, err := dec.DecodeInterface(); !errors.Is(err, io.EOF) {
+		return ErrTrailingData
+	}
+	if _, err := json.Marshal(tree); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	return toJSON(tree, v)
+}
+
+// MessagePack arrays start with their length, so lists are collected and
+// written when they end.
+func (f msgpackFormat) NewListEncoder(w io.Writer) ListEncoder {
+	return &msgpackListEncoder{w: w}
+}
+
+type msgpackListEncoder struct {
+	w     io.Writer
+	items []any
+}
+
+func (e *msgpackListEncoder) Encode(v any) error {
+	tree, err := jsonTree(v)
+	if err != nil {
+		return err
+	}
+	e.items = append(e.items, tree)
+	return nil
+}
+
+func (e *msgpackListEncoder) Close() error {
+	if e.items == nil {
+		e.items = []any{}
+	}
+	enc := msgpack.NewEncoder(e.w)
+	enc.SetSortMapKeys(true)
+	return enc.Encode(e.items)
+}
+
+// jsonTree returns v as encoding/json sees it, with numbers as int64 where
+// they are whole and float64 otherwise.
+func jsonTree(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return numbers(tree), nil
+}
+
+// numbers replaces the json.Numbers in tree.
+func numbers(tree any) any {
+	switch tree := tree.(type) {
+	case json.Number:
+		if n, err := tree.Int64(); err == nil {
+			return n
+		}
+		f, _ := tree.Float64()
+		return f
+	case map[string]any:
+		for key, value := range tree {
+			tree[key] = numbers(value)
+		}
+	case []any:
+		for i, value := range tree {
+			tree[i] = numbers(value)
+		}
+	}
+	return tree
+}
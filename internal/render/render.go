@@ -0,0 +1,171 @@
+// Package render encodes responses and decodes request bodies in the formats
+// the API speaks: JSON, NDJSON, XML, CSV and MessagePack.
+//
+// Every format is defined in terms of JSON: values are encoded the way
+// encoding/json would encode them, so json struct tags name fields in every
+// format, and request bodies in other formats are transcoded to JSON and
+// decoded strictly, so their mistakes are reported the same way.
+package render
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is a media type responses can be rendered in and request bodies
+// decoded from.
+type Format interface {
+	// MediaType is the media type sent in Content-Type.
+	MediaType() string
+	// Encode writes v to w.
+	Encode(w io.Writer, v any) error
+	// Decode strictly decodes a single value from r into v, refusing fields
+	// v doesn't have and anything after the value.
+	Decode(r io.Reader, v any) error
+	// NewListEncoder returns a ListEncoder writing a list to w.
+	NewListEncoder(w io.Writer) ListEncoder
+}
+
+// ListEncoder writes a list one element at a time.
+type ListEncoder interface {
+	// Encode writes v as the next element of the list.
+	Encode(v any) error
+	// Close ends the list. It must only be called if every element was
+	// written, so a list cut short by an error is left unterminated where
+	// the format allows it.
+	Close() error
+}
+
+var (
+	// ErrMalformed is wrapped by errors decoding a body that isn't valid in
+	// its format.
+	ErrMalformed = errors.New("malformed request body")
+	// ErrTrailingData is returned decoding a body with something after the
+	// value.
+	ErrTrailingData = errors.New("request body must contain a single value")
+)
+
+// The formats the API speaks.
+var (
+	JSON        Format = jsonFormat{}
+	NDJSON      Format = ndjsonFormat{}
+	XML         Format = xmlFormat{}
+	CSV         Format = csvFormat{}
+	MessagePack Format = msgpackFormat{}
+)
+
+// Formats are the formats every API response can be rendered in, in order of
+// preference. ListFormats adds the formats only lists can be rendered in.
+var (
+	Formats     = []Format{JSON, XML, MessagePack}
+	ListFormats = []Format{JSON, NDJSON, XML, CSV, MessagePack}
+)
+
+// aliases maps other media types clients use for a format to it.
+var aliases = map[string]Format{
+	"application/json":        JSON,
+	"application/x-ndjson":    NDJSON,
+	"application/jsonl":       NDJSON,
+	"application/xml":         XML,
+	"text/xml":                XML,
+	"text/csv":                CSV,
+	"application/msgpack":     MessagePack,
+	"application/x-msgpack":   MessagePack,
+	"application/vnd.msgpack": MessagePack,
+}
+
+// ForContentType returns the format of a request body with the given
+// Content-Type header. A body without a Content-Type is taken to be JSON.
+func ForContentType(contentType string) (Format, bool) {
+	if contentType == "" {
+		return JSON, true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	f, ok := aliases[mediaType]
+	return f, ok
+}
+
+// acceptRange is one media range from an Accept header.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// Negotiate picks the format to render a response in from an Accept header,
+// among offered, which are in order of preference. It returns false if the
+// client accepts none of them. A missing Accept header accepts anything.
+func Negotiate(accept string, offered []Format) (Format, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return offered[0], true
+	}
+
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+
+	// Most specific ranges first, so an exact type overrides a wildcard
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return specificity(ranges[i].mediaType) > specificity(ranges[j].mediaType)
+	})
+
+	var (
+		best  Format
+		bestQ float64
+	)
+	for _, f := range offered {
+		for _, r := range ranges {
+			if !matches(r.mediaType, f) {
+				continue
+			}
+			if r.q > bestQ {
+				best, bestQ = f, r.q
+			}
+			break
+		}
+	}
+	return best, best != nil
+}
+
+// specificity ranks a media range: exact types over type/* over */*.
+func specificity(mediaType string) int {
+	switch {
+	case mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matches reports whether the media range accepts f.
+func matches(mediaRange string, f Format) bool {
+	switch {
+	case mediaRange == "*/*":
+		return true
+	case strings.HasSuffix(mediaRange, "/*"):
+		prefix := strings.TrimSuffix(mediaRange, "*")
+		return strings.HasPrefix(f.MediaType(), prefix)
+	default:
+		alias, ok := aliases[mediaRange]
+		return ok && alias == f
+	}
+}
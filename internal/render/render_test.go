@@ -0,0 +1,355 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tag struct {
+	Name string `json:"name"`
+}
+
+type post struct {
+	ID        int               `json:"id"`
+	Title     string            `json:"title"`
+	Published bool              `json:"published"`
+	Score     float64           `json:"score"`
+	Tags      []string          `json:"tags"`
+	Labels    []tag             `json:"labels"`
+	Counts    map[string]int    `json:"counts"`
+	Meta      map[string]string `json:"meta,omitempty"`
+	Created   time.Time         `json:"created"`
+	Note      *string           `json:"note"`
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := map[string]struct {
+		accept  string
+		offered []Format
+		want    Format
+		wantOK  bool
+	}{
+		"no accept header": {
+			offered: Formats,
+			want:    JSON,
+			wantOK:  true,
+		},
+		"anything": {
+			accept:  "*/*",
+			offered: Formats,
+			want:    JSON,
+			wantOK:  true,
+		},
+		"exact type": {
+			accept:  "application/xml",
+			offered: Formats,
+			want:    XML,
+			wantOK:  true,
+		},
+		"alias": {
+			accept:  "text/xml",
+			offered: Formats,
+			want:    XML,
+			wantOK:  true,
+		},
+		"highest q wins": {
+			accept:  "application/json;q=0.5, application/msgpack",
+			offered: Formats,
+			want:    MessagePack,
+			wantOK:  true,
+		},
+		"exact type overrides wildcard": {
+			accept:  "*/*;q=0.9, application/json;q=0.1",
+			offered: Formats,
+			want:    XML,
+			wantOK:  true,
+		},
+		"browser": {
+			accept:  "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			offered: Formats,
+			want:    XML,
+			wantOK:  true,
+		},
+		"csv only for lists": {
+			accept:  "text/csv",
+			offered: Formats,
+			wantOK:  false,
+		},
+		"csv list": {
+			accept:  "text/csv",
+			offered: ListFormats,
+			want:    CSV,
+			wantOK:  true,
+		},
+		"refused": {
+			accept:  "application/json;q=0",
+			offered: []Format{JSON},
+			wantOK:  false,
+		},
+		"unsupported": {
+			accept:  "image/png",
+			offered: ListFormats,
+			wantOK:  false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := Negotiate(tc.accept, tc.offered)
+			if ok != tc.wantOK {
+				t.Fatalf("want ok %v, got %v", tc.wantOK, ok)
+			}
+			if ok && got != tc.want {
+				t.Errorf("want %s, got %s", tc.want.MediaType(), got.MediaType())
+			}
+		})
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		want        Format
+		wantOK      bool
+	}{
+		"missing":     {want: JSON, wantOK: true},
+		"json":        {contentType: "application/json; charset=utf-8", want: JSON, wantOK: true},
+		"xml":         {contentType: "text/xml", want: XML, wantOK: true},
+		"csv":         {contentType: "text/csv", want: CSV, wantOK: true},
+		"msgpack":     {contentType: "application/x-msgpack", want: MessagePack, wantOK: true},
+		"form":        {contentType: "application/x-www-form-urlencoded", wantOK: false},
+		"unparseable": {contentType: "application/", wantOK: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := ForContentType(tc.contentType)
+			if ok != tc.wantOK {
+				t.Fatalf("want ok %v, got %v", tc.wantOK, ok)
+			}
+			if ok && got != tc.want {
+				t.Errorf("want %s, got %s", tc.want.MediaType(), got.MediaType())
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	note := "first"
+	want := post{
+		ID:        7,
+		Title:     "Hello <world> & co",
+		Published: true,
+		Score:     4.5,
+		Tags:      []string{"go", "http"},
+		Labels:    []tag{{Name: "a"}, {Name: "b"}},
+		Counts:    map[string]int{"👍": 2, "heart": 1},
+		Created:   time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Note:      &note,
+	}
+
+	for _, f := range []Format{JSON, NDJSON, XML, CSV, MessagePack} {
+		t.Run(f.MediaType(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := f.Encode(&buf, want); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			var got post
+			if err := f.Decode(&buf, &got); err != nil {
+				t.Fatalf("decode %q: %v", buf.String(), err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("want %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeStrict(t *testing.T) {
+	tests := map[string]struct {
+		format  Format
+		body    string
+		wantErr error
+	}{
+		"json trailing data": {
+			format:  JSON,
+			body:    `{"id":1} {"id":2}`,
+			wantErr: ErrTrailingData,
+		},
+		"xml trailing element": {
+			format:  XML,
+			body:    `<response><id>1</id></response><response/>`,
+			wantErr: ErrTrailingData,
+		},
+		"xml malformed": {
+			format:  XML,
+			body:    `<response><id>1</response>`,
+			wantErr: ErrMalformed,
+		},
+		"csv several records": {
+			format:  CSV,
+			body:    "id\n1\n2\n",
+			wantErr: ErrTrailingData,
+		},
+		"csv header only": {
+			format:  CSV,
+			body:    "id\n",
+			wantErr: ErrMalformed,
+		},
+		"msgpack malformed": {
+			format:  MessagePack,
+			body:    "\xc1",
+			wantErr: ErrMalformed,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got post
+			err := tc.format.Decode(strings.NewReader(tc.body), &got)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("want %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDecodeUnknownField(t *testing.T) {
+	bodies := map[Format]string{
+		XML: `<response><id>1</id><colour>red</colour></response>`,
+		CSV: "id,colour\n1,red\n",
+	}
+	for f, body := range bodies {
+		t.Run(f.MediaType(), func(t *testing.T) {
+			var got post
+			err := f.Decode(strings.NewReader(body), &got)
+			if err == nil || !strings.Contains(err.Error(), `unknown field "colour"`) {
+				t.Errorf("want unknown field error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestListEncoders(t *testing.T) {
+	items := []tag{{Name: "a"}, {Name: "b,c"}}
+
+	tests := map[string]struct {
+		format Format
+		items  []tag
+		want   string
+	}{
+		"json": {
+			format: JSON,
+			items:  items,
+			want:   `[{"name":"a"},{"name":"b,c"}]` + "\n",
+		},
+		"ndjson": {
+			format: NDJSON,
+			items:  items,
+			want:   `{"name":"a"}` + "\n" + `{"name":"b,c"}` + "\n",
+		},
+		"xml": {
+			format: XML,
+			items:  items,
+			want:   `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><item><name>a</name></item><item><name>b,c</name></item></response>` + "\n",
+		},
+		"empty xml": {
+			format: XML,
+			want:   `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response></response>` + "\n",
+		},
+		"csv": {
+			format: CSV,
+			items:  items,
+			want:   "name\na\n\"b,c\"\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var streamed bytes.Buffer
+			enc := tc.format.NewListEncoder(&streamed)
+			for _, item := range tc.items {
+				if err := enc.Encode(item); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := streamed.String(); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+
+			// Encoding the whole list at once gives the same document
+			var whole bytes.Buffer
+			if err := tc.format.Encode(&whole, tc.items); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.items != nil && whole.String() != tc.want {
+				t.Errorf("want %q encoding the list at once, got %q", tc.want, whole.String())
+			}
+		})
+	}
+}
+
+func TestCSVFormulas(t *testing.T) {
+	tests := map[string]string{
+		"=HYPERLINK(\"http://evil.example\")": "'=HYPERLINK(\"http://evil.example\")",
+		"+1":                                  "'+1",
+		"-2+3":                                "'-2+3",
+		"@SUM(A1)":                            "'@SUM(A1)",
+		"\tcmd":                               "'\tcmd",
+		"\rcmd":                               "'\rcmd",
+		"plain":                               "plain",
+		"a=b":                                 "a=b",
+	}
+
+	for title, want := range tests {
+		var buf bytes.Buffer
+		if err := CSV.Encode(&buf, []tag{{Name: title}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := records[1][0]; got != want {
+			t.Errorf("%q: want cell %q, got %q", title, want, got)
+		}
+	}
+
+	// Numbers are left alone
+	var buf bytes.Buffer
+	if err := CSV.Encode(&buf, []int{-5}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := buf.String(), "value\n-5\n"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestMessagePackList(t *testing.T) {
+	var buf bytes.Buffer
+	enc := MessagePack.NewListEncoder(&buf)
+	for _, item := range []tag{{Name: "a"}, {Name: "b"}} {
+		if err := enc.Encode(item); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []tag
+	if err := MessagePack.Decode(&buf, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []tag{{Name: "a"}, {Name: "b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
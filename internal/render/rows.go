@@ -0,0 +1,98 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxRowSize is the longest NDJSON line a RowDecoder reads.
+const maxRowSize = 1 << 20
+
+// RowDecoder decodes the rows of a list body one at a time, so a bad row can
+// be reported without giving up on the rest of the body.
+type RowDecoder interface {
+	// Next decodes the next row into v, strictly, and returns io.EOF after
+	// the last one. An error decoding a row leaves the decoder at the next
+	// one, unless it wraps ErrMalformed, when the body can't be read any
+	// further.
+	Next(v any) error
+}
+
+// NewRowDecoder returns a RowDecoder reading r in format f, and false if f
+// has no rows: only NDJSON and CSV bodies do.
+func NewRowDecoder(f Format, r io.Reader) (RowDecoder, bool) {
+	switch f {
+	case NDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxRowSize)
+		return &ndjsonRowDecoder{scanner: scanner}, true
+	case CSV:
+		reader := csv.NewReader(r)
+		reader.ReuseRecord = true
+		return &csvRowDecoder{reader: reader}, true
+	default:
+		return nil, false
+	}
+}
+
+// ndjsonRowDecoder decodes a row per line, skipping blank lines.
+type ndjsonRowDecoder struct {
+	scanner *bufio.Scanner
+}
+
+func (d *ndjsonRowDecoder) Next(v any) error {
+	for d.scanner.Scan() {
+		line := bytes.TrimSpace(d.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		return JSON.Decode(bytes.NewReader(line), v)
+	}
+	if err := d.scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	return io.EOF
+}
+
+// csvRowDecoder decodes a row per record, naming fields after the header.
+type csvRowDecoder struct {
+	reader *csv.Reader
+	header []string
+}
+
+func (d *csvRowDecoder) Next(v any) error {
+	if d.header == nil {
+		header, err := d.reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return io.EOF
+			}
+			return fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		d.header = append([]string(nil), header...)
+	}
+
+	record, err := d.reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.EOF
+		}
+		if errors.Is(err, csv.ErrFieldCount) {
+			return fmt.Errorf("record has %d fields, the header has %d", len(record), len(d.header))
+		}
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	row := make(map[string]any, len(d.header))
+	for i, column := range d.header {
+		// an empty cell is a missing field
+		if record[i] != "" {
+			row[column] = record[i]
+		}
+	}
+	return toJSON(row, v)
+}
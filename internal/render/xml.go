@@ -0,0 +1,281 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const (
+	// xmlRoot names the root element of every document.
+	xmlRoot = "response"
+	// xmlItem names the elements of a list.
+	xmlItem = "item"
+	// xmlEntry names the elements of an object whose key isn't a valid XML
+	// name; the key goes in its key attribute.
+	xmlEntry = "entry"
+)
+
+// xmlFormat renders the JSON encoding of a value as XML: objects become
+// elements named after their keys, list elements become <item> elements and
+// everything else becomes text. A list is:
+//
+//	<response><item><id>1</id></item><item><id>2</id></item></response>
+type xmlFormat struct{}
+
+func (xmlFormat) MediaType() string { return "application/xml" }
+
+func (xmlFormat) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := encodeXML(enc, xmlRoot, v); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (xmlFormat) Decode(r io.Reader, v any) error {
+	dec := xml.NewDecoder(r)
+
+	var root *xml.StartElement
+	for root == nil {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: empty document", ErrMalformed)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			root = &start
+		}
+	}
+
+	tree, err := decodeXML(dec)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			return ErrTrailingData
+		case xml.CharData:
+			if len(bytes.TrimSpace(tok)) > 0 {
+				return ErrTrailingData
+			}
+		}
+	}
+	return toJSON(tree, v)
+}
+
+func (xmlFormat) NewListEncoder(w io.Writer) ListEncoder {
+	return &xmlListEncoder{w: w, enc: xml.NewEncoder(w)}
+}
+
+// xmlListEncoder writes the same document Encode writes for a list.
+type xmlListEncoder struct {
+	w       io.Writer
+	enc     *xml.Encoder
+	started bool
+}
+
+func (e *xmlListEncoder) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+	return e.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: xmlRoot}})
+}
+
+func (e *xmlListEncoder) Encode(v any) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	if err := encodeXML(e.enc, xmlItem, v); err != nil {
+		return err
+	}
+	return e.enc.Flush()
+}
+
+func (e *xmlListEncoder) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: xmlRoot}}); err != nil {
+		return err
+	}
+	if err := e.enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+// encodeXML writes v as an element called name.
+func encodeXML(enc *xml.Encoder, name string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return jsonToXML(dec, enc, xmlStart(name))
+}
+
+// jsonToXML reads the next JSON value from dec and writes it to enc as the
+// element start, keeping the order of object keys.
+func jsonToXML(dec *json.Decoder, enc *xml.Encoder, start xml.StartElement) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if err := jsonToXML(dec, enc, xmlStart(key.(string))); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for dec.More() {
+				if err := jsonToXML(dec, enc, xmlStart(xmlItem)); err != nil {
+					return err
+				}
+			}
+		}
+		// the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case nil:
+	case string:
+		err = enc.EncodeToken(xml.CharData(tok))
+	default:
+		err = enc.EncodeToken(xml.CharData(fmt.Sprint(tok)))
+	}
+	if err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlStart returns the start of the element for the object key name.
+func xmlStart(name string) xml.StartElement {
+	if validXMLName(name) {
+		return xml.StartElement{Name: xml.Name{Local: name}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: xmlEntry},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+	}
+}
+
+// validXMLName reports whether name can be used as an element name as it
+// is. Names with colons are refused too, as they would be read back as
+// namespaced.
+func validXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// decodeXML reads the content of the element just started from dec: text
+// for an element without children, otherwise an object of its children,
+// where children with the same name become a list.
+func decodeXML(dec *xml.Decoder) (any, error) {
+	var (
+		text     strings.Builder
+		children map[string]any
+	)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.CharData:
+			text.Write(tok)
+		case xml.StartElement:
+			name := tok.Name.Local
+			if name == xmlEntry {
+				for _, attr := range tok.Attr {
+					if attr.Name.Local == "key" {
+						name = attr.Value
+					}
+				}
+			}
+			child, err := decodeXML(dec)
+			if err != nil {
+				return nil, err
+			}
+			if children == nil {
+				children = make(map[string]any)
+			}
+			switch existing := children[name].(type) {
+			case nil:
+				children[name] = child
+			case repeated:
+				children[name] = append(existing, child)
+			default:
+				children[name] = repeated{existing, child}
+			}
+		case xml.EndElement:
+			if children == nil {
+				return text.String(), nil
+			}
+			for name, child := range children {
+				if r, ok := child.(repeated); ok {
+					children[name] = []any(r)
+				}
+			}
+			return children, nil
+		}
+	}
+}
+
+// repeated collects the children of an element that share a name.
+type repeated []any
@@ -1,14 +1,31 @@
 package routes
 
 import (
+	"database/sql"
 	"log/slog"
 	"net/http"
 
+	"github.com/navid/blog/internal/apiversion"
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/gql"
 	"github.com/navid/blog/internal/handlers"
+	"github.com/navid/blog/internal/health"
+	"github.com/navid/blog/internal/middleware"
+	"github.com/navid/blog/internal/openapi"
+	"github.com/navid/blog/internal/render"
 	"github.com/navid/blog/internal/services"
+	"github.com/navid/blog/internal/sitemap"
+	"github.com/navid/blog/internal/syndication"
+	"github.com/navid/blog/internal/tracing"
+	"github.com/navid/blog/internal/web"
 	httpSwagger "github.com/swaggo/http-swagger" // http-swagger middleware
 )
 
+// AddRoutes returns the patterns it registered, other than those of the
+// debugging catch-all and the Swagger UI, for checking them against spec, the
+// OpenAPI document it serves. Metrics are served on a listener of their
+// own, kept off the public API.
+//
 // @title						Blog Service API
 // @version					1.0
 // @description				Practice Go API using the Standard Library and Postgres
@@ -22,26 +39,136 @@ import (
 // @BasePath					/api
 // @externalDocs.description	OpenAPI
 // @externalDocs.url			https://swagger.io/resources/open-api/
-func AddRoutes(mux *http.ServeMux, logger *slog.Logger, usersService *services.UsersService, blogsService *services.BlogService, commentsService *services.CommentsService, baseURL string) {
+// @securityDefinitions.apikey	BearerAuth
+// @in							header
+// @name						Authorization
+func AddRoutes(mux *http.ServeMux, logger *slog.Logger, usersService *services.UsersService, blogsService *services.BlogService, commentsService *services.CommentsService, moderationService *services.ModerationService, reactionsService *services.ReactionsService, followsService *services.FollowsService, bookmarksService *services.BookmarksService, readingListsService *services.ReadingListsService, transferService *services.TransferService, db *sql.DB, graphQL *gql.Schema, tokens *auth.Tokens, renderer *web.Renderer, sitemaps *sitemap.Cache, checker *health.Checker, spec *openapi.Document, deprecations map[string]apiversion.Deprecation, proxies middleware.TrustedProxies, operations middleware.Middleware, adminUserIDs []int, devMode bool, baseURL string) []string {
+	// handle registers h on the mux, recording each call as a span named
+	// after the pattern
+	var patterns []string
+	handle := func(pattern string, h http.Handler) {
+		mux.Handle(pattern, tracing.Handler(pattern, h))
+		patterns = append(patterns, pattern)
+	}
+	// The REST API is served in each version under /api/<version>, and in
+	// the default version under /api too. api adds an endpoint to every
+	// version, rendering its responses in the format the client asks for;
+	// apiFrom overrides it from a version on. list and listFrom do the same
+	// for endpoints returning lists, which can also be rendered as NDJSON
+	// and CSV
+	var rest apiRoutes
+	api := func(route string, h http.Handler) {
+		rest.add(route, handlers.Negotiate(render.Formats, h))
+	}
+	apiFrom := func(version, route string, h http.Handler) {
+		rest.override(version, route, handlers.Negotiate(render.Formats, h))
+	}
+	list := func(route string, h http.Handler) {
+		rest.add(route, handlers.Negotiate(render.ListFormats, h))
+	}
+	listFrom := func(version, route string, h http.Handler) {
+		rest.override(version, route, handlers.Negotiate(render.ListFormats, h))
+	}
+
+	// Auth endpoints
+	api("POST /api/auth/token", handlers.HandleCreateToken(logger, usersService, tokens))
+
 	// User endpoints
-	mux.Handle("POST /api/user", handlers.HandleCreateUser(logger, usersService))
-	mux.Handle("GET /api/user", handlers.HandleListUsers(logger, handlers.NewUserListerAdapter(usersService)))
-	mux.Handle("GET /api/user/{id}", handlers.HandleReadUser(logger, usersService))
-	mux.Handle("PUT /api/user/{id}", handlers.HandleUpdateUser(logger, usersService))
-	mux.Handle("DELETE /api/user/{id}", handlers.HandleDeleteUser(logger, usersService))
+	api("POST /api/user", handlers.HandleCreateUser(logger, usersService, true))
+	list("GET /api/user", handlers.HandleListUsers(logger, handlers.NewUserListerAdapter(usersService), true))
+	api("GET /api/user/{id}", handlers.HandleReadUser(logger, usersService, true))
+	api("PUT /api/user/{id}", handlers.RequireSelfOrAdmin(adminUserIDs, handlers.HandleUpdateUser(logger, usersService, true)))
+	api("DELETE /api/user/{id}", handlers.RequireSelfOrAdmin(adminUserIDs, handlers.HandleDeleteUser(logger, usersService)))
+
+	// Version 2 drops the always empty password field of users
+	apiFrom("v2", "POST /api/user", handlers.HandleCreateUser(logger, usersService, false))
+	listFrom("v2", "GET /api/user", handlers.HandleListUsers(logger, handlers.NewUserListerAdapter(usersService), false))
+	apiFrom("v2", "GET /api/user/{id}", handlers.HandleReadUser(logger, usersService, false))
+	apiFrom("v2", "PUT /api/user/{id}", handlers.RequireSelfOrAdmin(adminUserIDs, handlers.HandleUpdateUser(logger, usersService, false)))
+
+	// Follow endpoints
+	api("PUT /api/user/{id}/follow", handlers.HandleFollow(logger, followsService, usersService))
+	api("DELETE /api/user/{id}/follow", handlers.HandleUnfollow(logger, followsService))
+	list("GET /api/user/{id}/followers", handlers.HandleListFollowers(logger, followsService, usersService))
+	list("GET /api/user/{id}/following", handlers.HandleListFollowing(logger, followsService, usersService))
+	list("GET /api/feed", handlers.HandleFeed(logger, blogsService, usersService))
 
 	// Blog endpoints
-	mux.Handle("GET /api/blog", handlers.HandleListBlogs(logger, handlers.NewBlogListerAdapter(blogsService)))
-	mux.Handle("GET /api/blog/{id}", handlers.HandleGetBlog(logger, blogsService))
-	mux.Handle("PUT /api/blog/{id}", handlers.HandleUpdateBlog(logger, blogsService, usersService))
-	mux.Handle("POST /api/blog", handlers.HandleCreateBlog(logger, blogsService, usersService))
-	mux.Handle("DELETE /api/blog/{id}", handlers.HandleDeleteBlog(logger, blogsService))
+	list("GET /api/blog", handlers.HandleListBlogs(logger, blogsService, usersService, commentsService))
+	api("GET /api/blog/{id}", handlers.HandleGetBlog(logger, blogsService, usersService, commentsService))
+	api("PUT /api/blog/{id}", handlers.HandleUpdateBlog(logger, blogsService, usersService, moderationService))
+	api("POST /api/blog", handlers.HandleCreateBlog(logger, blogsService, usersService, moderationService))
+	api("DELETE /api/blog/{id}", handlers.HandleDeleteBlog(logger, blogsService))
+	api("PUT /api/blog/{id}/rating", handlers.HandleRateBlog(logger, blogsService, usersService))
 
 	// Comment endpoints
-	mux.Handle("GET /api/comments", handlers.HandleListComments(logger, commentsService))
-	mux.Handle("PUT /api/comments", handlers.HandleUpdateComment(logger, commentsService, usersService, blogsService))
-	mux.Handle("POST /api/comments", handlers.HandleCreateComment(logger, commentsService, usersService, blogsService))
-	mux.Handle("DELETE /api/comments", handlers.HandleDeleteComment(logger, commentsService))
+	list("GET /api/comments", handlers.HandleListComments(logger, commentsService, usersService))
+	api("PUT /api/comments", handlers.HandleUpdateComment(logger, commentsService, usersService, blogsService, moderationService))
+	api("POST /api/comments", handlers.HandleCreateComment(logger, commentsService, usersService, blogsService, moderationService))
+	api("DELETE /api/comments", handlers.HandleDeleteComment(logger, commentsService))
+
+	// Reaction endpoints
+	api("PUT /api/blog/{id}/reactions/{type}", handlers.HandleReact(logger, handlers.BlogReactionTarget, reactionsService))
+	api("DELETE /api/blog/{id}/reactions/{type}", handlers.HandleUnreact(logger, handlers.BlogReactionTarget, reactionsService))
+	list("GET /api/blog/{id}/reactions", handlers.HandleListReactions(logger, handlers.BlogReactionTarget, reactionsService))
+	api("PUT /api/comments/reactions/{type}", handlers.HandleReact(logger, handlers.CommentReactionTarget, reactionsService))
+	api("DELETE /api/comments/reactions/{type}", handlers.HandleUnreact(logger, handlers.CommentReactionTarget, reactionsService))
+	list("GET /api/comments/reactions", handlers.HandleListReactions(logger, handlers.CommentReactionTarget, reactionsService))
+
+	// Bookmark and reading list endpoints for the authenticated user
+	list("GET /api/me/bookmarks", handlers.HandleListBookmarks(logger, bookmarksService))
+	api("PUT /api/me/bookmarks/{blog_id}", handlers.HandleSaveBookmark(logger, bookmarksService))
+	api("DELETE /api/me/bookmarks/{blog_id}", handlers.HandleDeleteBookmark(logger, bookmarksService))
+	list("GET /api/me/lists", handlers.HandleListReadingLists(logger, readingListsService))
+	api("POST /api/me/lists", handlers.HandleCreateReadingList(logger, readingListsService))
+	api("GET /api/me/lists/{id}", handlers.HandleGetReadingList(logger, readingListsService, true))
+	api("PUT /api/me/lists/{id}", handlers.HandleUpdateReadingList(logger, readingListsService))
+	api("DELETE /api/me/lists/{id}", handlers.HandleDeleteReadingList(logger, readingListsService))
+	api("PUT /api/me/lists/{id}/items/{blog_id}", handlers.HandleSaveReadingListItem(logger, readingListsService))
+	api("DELETE /api/me/lists/{id}/items/{blog_id}", handlers.HandleRemoveReadingListItem(logger, readingListsService))
+	api("GET /api/lists/{id}", handlers.HandleGetReadingList(logger, readingListsService, false))
+
+	// Moderation endpoints, for admins
+	list("GET /api/moderation", handlers.RequireAdmin(adminUserIDs, handlers.HandleListModeration(logger, moderationService)))
+	api("POST /api/moderation/{id}/approve", handlers.RequireAdmin(adminUserIDs, handlers.HandleDecideModeration(logger, true, moderationService, commentsService, blogsService)))
+	api("POST /api/moderation/{id}/reject", handlers.RequireAdmin(adminUserIDs, handlers.HandleDecideModeration(logger, false, moderationService, commentsService, blogsService)))
+
+	// Batches of requests, run against the endpoints above through the
+	// middleware each of them would go through on its own
+	api("POST /api/batch", handlers.HandleBatch(logger, operations(mux), db))
+
+	// GraphQL, with the GraphiQL editor in dev mode. GraphiQL is set up by an
+	// inline script, which the default content security policy forbids
+	handle("/graphql", handlers.HandleGraphQL(logger, graphQL))
+	if devMode {
+		handle("GET /graphiql", middleware.ContentSecurityPolicy(handlers.GraphiQLCSP)(handlers.HandleGraphiQL()))
+		logger.Info("GraphiQL running", slog.String("url", baseURL+"/graphiql"))
+	}
+
+	// Admin endpoints
+	api(ImportPattern, handlers.RequireAdmin(adminUserIDs, handlers.HandleImport(logger, transferService)))
+	rest.add("GET /api/admin/export", handlers.RequireAdmin(adminUserIDs, handlers.HandleExport(logger, transferService)))
+
+	// Server-rendered site
+	handle("GET /{$}", handlers.HandleIndexPage(logger, renderer, handlers.NewBlogListerAdapter(blogsService), usersService))
+	handle("GET /blog/{id}", handlers.HandleBlogPage(logger, renderer, blogsService, commentsService, usersService))
+	handle("GET /author/{id}", handlers.HandleAuthorPage(logger, renderer, handlers.NewBlogListerAdapter(blogsService), usersService))
+	handle("GET /tag/{tag}", handlers.HandleTagPage(logger, renderer, handlers.NewBlogListerAdapter(blogsService), usersService))
+	handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(web.Static)))
+
+	// Search engine discovery
+	handle("GET /sitemap.xml", handlers.HandleSitemap(logger, sitemaps))
+	handle("GET /sitemaps/{file}", handlers.HandleSitemapShard(logger, sitemaps))
+	handle("GET /robots.txt", handlers.HandleRobots(baseURL))
+
+	// Syndication feeds
+	for _, format := range syndication.Formats {
+		ext := format.Extension()
+		feed := handlers.HandleBlogFeed(logger, handlers.NewBlogListerAdapter(blogsService), usersService, format, baseURL)
+		handle("GET /feeds/blog."+ext, feed)
+		handle("GET /feeds/author/{id}/blog."+ext, feed)
+		handle("GET /feeds/tag/{tag}/blog."+ext, feed)
+	}
 
 	// For debugging purposes, let's add a catch-all handler to help identify mismatched routes
 	mux.HandleFunc("GET /api/blog/", func(w http.ResponseWriter, r *http.Request) {
@@ -51,13 +178,38 @@ func AddRoutes(mux *http.ServeMux, logger *slog.Logger, usersService *services.U
 		http.Error(w, "Route not found. Please use /api/blog/{id} format", http.StatusNotFound)
 	})
 
-	// Swagger docs
+	// Swagger docs. The UI is set up by inline scripts and styles, which the
+	// default content security policy forbids
 	mux.Handle(
 		"/swagger/",
-		httpSwagger.Handler(httpSwagger.URL(baseURL+"/swagger/doc.json")),
+		middleware.ContentSecurityPolicy(swaggerCSP)(
+			httpSwagger.Handler(httpSwagger.URL(baseURL+"/swagger/doc.json")),
+		),
 	)
 	logger.Info("Swagger running", slog.String("url", baseURL+"/swagger/index.html"))
 
-	// Health check
-	mux.Handle("/api/health", handlers.HandleHealthCheck(logger))
+	// The OpenAPI document, generated from the models the handlers use
+	handle("GET /openapi.json", handlers.HandleOpenAPI(spec))
+
+	// Health checks
+	api("/api/health", handlers.HandleHealthCheck(logger))
+	api("GET /api/health/live", handlers.HandleLiveness(logger))
+	api("GET /api/health/ready", handlers.HandleReadiness(logger, checker))
+
+	// Register the REST API in every version, marking deprecated versions
+	// and endpoints
+	rest.register(handle, deprecations, func(pattern string, d apiversion.Deprecation) middleware.Middleware {
+		return middleware.Deprecated(logger, proxies, pattern, d)
+	})
+
+	return patterns
 }
+
+// ImportPattern is the route of bulk imports, which accept larger bodies than
+// other routes. It is served in every version of the API; routers reporting
+// patterns without their version, from apiversion.Unversioned, report it
+// for each.
+const ImportPattern = "POST /api/admin/import"
+
+// swaggerCSP is the content security policy of the Swagger UI.
+const swaggerCSP = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
@@ -0,0 +1,204 @@
+package routes
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/navid/blog/internal/apiversion"
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/gql"
+	"github.com/navid/blog/internal/health"
+	"github.com/navid/blog/internal/openapi"
+	"github.com/navid/blog/internal/services"
+	"github.com/navid/blog/internal/sitemap"
+	"github.com/navid/blog/internal/web"
+)
+
+// newMux registers the routes on a new mux, with services that have no
+// database: the routes are only matched, never served.
+func newMux(t *testing.T, spec *openapi.Document, deprecations map[string]apiversion.Deprecation) (*http.ServeMux, []string) {
+	t.Helper()
+	logger := slog.New(slog.DiscardHandler)
+
+	users := services.NewUsersService(logger, nil)
+	blogs := services.NewBlogService(nil, logger)
+	comments := services.NewCommentsService(nil, logger)
+	moderation := services.NewModerationService(nil, logger, nil, nil)
+	graphQL, err := gql.NewSchema(logger, users, blogs, comments, moderation, nil, gql.Limits{MaxDepth: 8, MaxComplexity: 5000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderer, err := web.NewRenderer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	patterns := AddRoutes(
+		mux,
+		logger,
+		users,
+		blogs,
+		comments,
+		moderation,
+		services.NewReactionsService(nil, logger, nil),
+		services.NewFollowsService(nil, logger),
+		services.NewBookmarksService(nil, logger),
+		services.NewReadingListsService(nil, logger),
+		services.NewTransferService(nil, logger),
+		nil,
+		graphQL,
+		auth.NewTokens([]byte("secret"), time.Hour),
+		renderer,
+		sitemap.NewCache(nil, time.Hour, nil),
+		health.NewChecker(time.Second),
+		spec,
+		deprecations,
+		nil,
+		func(h http.Handler) http.Handler { return h },
+		nil,
+		true,
+		"http://localhost:8000",
+	)
+	return mux, patterns
+}
+
+// TestRoutesMatchOpenAPI checks that every API route is documented, and that
+// every documented operation is routed to the pattern it is documented at.
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	spec := openapi.API()
+	mux, patterns := newMux(t, spec, nil)
+
+	for _, pattern := range patterns {
+		method, path, ok := strings.Cut(pattern, " ")
+		if !ok {
+			method, path = "", pattern
+		}
+		if !strings.HasPrefix(path, "/api/") && path != "/graphql" {
+			continue
+		}
+		if method == "" {
+			if len(spec.Paths[path]) == 0 {
+				t.Errorf("%s is routed but not documented", pattern)
+			}
+			continue
+		}
+		if _, ok := spec.Operation(pattern, method); !ok {
+			t.Errorf("%s is routed but not documented", pattern)
+		}
+	}
+
+	for _, pattern := range spec.Patterns() {
+		method, path, _ := strings.Cut(pattern, " ")
+		target := strings.NewReplacer("{id}", "1", "{blog_id}", "2", "{type}", "like").Replace(path)
+		_, got := mux.Handler(httptest.NewRequest(method, target, nil))
+		if got != pattern && got != path {
+			t.Errorf("%s is documented but %s %s is routed to %q", pattern, method, target, got)
+		}
+	}
+}
+
+func TestVersions(t *testing.T) {
+	since := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+	mux, _ := newMux(t, openapi.API(), map[string]apiversion.Deprecation{
+		"v1": {Since: since, Sunset: sunset, Successor: "v2"},
+	})
+
+	tests := map[string]struct {
+		target          string
+		wantStatus      int
+		wantDeprecation string
+		wantSunset      string
+		wantLink        string
+	}{
+		"deprecated version": {
+			target:          "/api/v1/health",
+			wantStatus:      http.StatusOK,
+			wantDeprecation: "@1790812800",
+			wantSunset:      "Thu, 01 Apr 2027 00:00:00 GMT",
+			wantLink:        `</api/v2/health>; rel="successor-version"`,
+		},
+		"alias of the default version": {
+			target:          "/api/health",
+			wantStatus:      http.StatusOK,
+			wantDeprecation: "@1790812800",
+			wantSunset:      "Thu, 01 Apr 2027 00:00:00 GMT",
+			wantLink:        `</api/v2/health>; rel="successor-version"`,
+		},
+		"latest version": {
+			target:     "/api/v2/health",
+			wantStatus: http.StatusOK,
+		},
+		"deprecated endpoint": {
+			target:          "/api/v1/user/x",
+			wantStatus:      http.StatusBadRequest,
+			wantDeprecation: "@1792281600",
+			wantLink:        `</api/v2/user/x>; rel="successor-version"`,
+		},
+		"overridden endpoint": {
+			target:     "/api/v2/user/x",
+			wantStatus: http.StatusBadRequest,
+		},
+		"unknown version": {
+			target:     "/api/v3/health",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			for header, want := range map[string]string{
+				"Deprecation": tc.wantDeprecation,
+				"Sunset":      tc.wantSunset,
+				"Link":        tc.wantLink,
+			} {
+				if got := rec.Header().Get(header); got != want {
+					t.Errorf("want %s %q, got %q", header, want, got)
+				}
+			}
+		})
+	}
+}
+
+// TestUserMutationsRequireTheUser checks that users can only be replaced or
+// deleted by themselves, in every version.
+func TestUserMutationsRequireTheUser(t *testing.T) {
+	mux, _ := newMux(t, openapi.API(), nil)
+
+	tests := map[string]struct {
+		userID     int
+		wantStatus int
+	}{
+		"anonymous":    {wantStatus: http.StatusUnauthorized},
+		"another user": {userID: 4, wantStatus: http.StatusForbidden},
+	}
+
+	for name, tc := range tests {
+		for _, target := range []string{"PUT /api/user/3", "DELETE /api/user/3", "PUT /api/v2/user/3", "DELETE /api/v2/user/3"} {
+			t.Run(name+" "+target, func(t *testing.T) {
+				method, path, _ := strings.Cut(target, " ")
+				req := httptest.NewRequest(method, path, strings.NewReader(`{"name":"Bob","email":"bob@example.com","password":"secret1"}`))
+				if tc.userID != 0 {
+					req = req.WithContext(auth.WithUserID(req.Context(), tc.userID))
+				}
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+
+				if rec.Code != tc.wantStatus {
+					t.Errorf("want status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/navid/blog/internal/apiversion"
+	"github.com/navid/blog/internal/middleware"
+)
+
+// apiRoute is an endpoint of the REST API, with the handler it has in each
+// version that overrides it.
+type apiRoute struct {
+	route    string // the pattern without a version, such as GET /api/user/{id}
+	handlers map[string]http.Handler
+}
+
+// apiRoutes collects the endpoints of the REST API, to register each in
+// every version once all of them are known. A version serves the handler of
+// the version before it unless it overrides it.
+type apiRoutes struct {
+	routes []*apiRoute
+	byName map[string]*apiRoute
+}
+
+// add adds the endpoint route, served by h from the first version on.
+func (a *apiRoutes) add(route string, h http.Handler) {
+	a.override(apiversion.Versions[0], route, h)
+}
+
+// override serves route with h from version on, or stops serving it if h
+// is nil.
+func (a *apiRoutes) override(version, route string, h http.Handler) {
+	if !slices.Contains(apiversion.Versions, version) {
+		panic(fmt.Sprintf("routes: unknown API version %q", version))
+	}
+	if a.byName == nil {
+		a.byName = make(map[string]*apiRoute)
+	}
+	r, ok := a.byName[route]
+	if !ok {
+		r = &apiRoute{route: route, handlers: make(map[string]http.Handler)}
+		a.routes = append(a.routes, r)
+		a.byName[route] = r
+	}
+	if _, ok := r.handlers[version]; ok {
+		panic(fmt.Sprintf("routes: %s registered twice in %s", route, version))
+	}
+	r.handlers[version] = h
+}
+
+// register registers every endpoint with handle at each pattern it has in
+// each version that serves it. Endpoints deprecated on their own or in a
+// deprecated version, as listed in versions, are wrapped with deprecated.
+func (a *apiRoutes) register(handle func(pattern string, h http.Handler), versions map[string]apiversion.Deprecation, deprecated func(pattern string, d apiversion.Deprecation) middleware.Middleware) {
+	for _, r := range a.routes {
+		var h http.Handler
+		for _, version := range apiversion.Versions {
+			if override, ok := r.handlers[version]; ok {
+				h = override
+			}
+			if h == nil {
+				continue
+			}
+
+			patterns := apiversion.Patterns(r.route, version)
+			d, ok := apiversion.Endpoints[patterns[0]]
+			if !ok {
+				d, ok = versions[version]
+			}
+			for _, pattern := range patterns {
+				if ok {
+					handle(pattern, deprecated(pattern, d)(h))
+				} else {
+					handle(pattern, h)
+				}
+			}
+		}
+	}
+}
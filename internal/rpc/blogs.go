@@ -0,0 +1,194 @@
+package rpc
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/models"
+	blogv1 "github.com/navid/blog/proto/blog/v1"
+)
+
+// blogsServer implements blogv1.BlogsServiceServer.
+type blogsServer struct {
+	blogv1.UnimplementedBlogsServiceServer
+
+	logger   *slog.Logger
+	blogs    blogsService
+	users    usersService
+	screener contentScreener
+}
+
+func (s *blogsServer) CreateBlog(ctx context.Context, req *blogv1.CreateBlogRequest) (*blogv1.Blog, error) {
+	blog := models.Blog{Title: req.GetTitle(), AuthorID: int(req.GetAuthorId()), Tags: req.GetTags()}
+	// Users only post as themselves, like over REST
+	if err := actAs(ctx, blog.AuthorID, nil); err != nil {
+		return nil, err
+	}
+	if err := s.checkBlog(ctx, blog); err != nil {
+		return nil, err
+	}
+	content := filters.Content{
+		Kind:   filters.KindBlog,
+		UserID: blog.AuthorID,
+		Text:   blog.Text(),
+	}
+	if err := screen(ctx, s.logger, s.screener, content, blog); err != nil {
+		return nil, err
+	}
+
+	created, err := s.blogs.CreateBlog(ctx, blog)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to create blog", err)
+	}
+	s.screener.Record(ctx, content)
+	return blogToProto(created), nil
+}
+
+func (s *blogsServer) GetBlog(ctx context.Context, req *blogv1.GetBlogRequest) (*blogv1.Blog, error) {
+	blog, err := s.blogs.GetBlog(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to read blog", err)
+	}
+	return blogToProto(blog), nil
+}
+
+func (s *blogsServer) ListBlogs(ctx context.Context, req *blogv1.ListBlogsRequest) (*blogv1.ListBlogsResponse, error) {
+	filter := models.BlogFilter{
+		Title:    req.GetTitle(),
+		AuthorID: int(req.GetAuthorId()),
+		Tag:      req.GetTag(),
+	}
+	switch req.GetSort() {
+	case blogv1.BlogSort_BLOG_SORT_UNSPECIFIED:
+	case blogv1.BlogSort_BLOG_SORT_SCORE:
+		filter.OrderByScore = true
+	case blogv1.BlogSort_BLOG_SORT_NEWEST:
+		filter.OrderByNewest = true
+	default:
+		return nil, invalid(map[string]string{"sort": "sort must be BLOG_SORT_SCORE or BLOG_SORT_NEWEST"})
+	}
+
+	blogs, err := s.blogs.ListBlogsWithFilter(ctx, filter)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to list blogs", err)
+	}
+
+	resp := &blogv1.ListBlogsResponse{Blogs: make([]*blogv1.Blog, len(blogs))}
+	for i, blog := range blogs {
+		resp.Blogs[i] = blogToProto(blog)
+	}
+	return resp, nil
+}
+
+func (s *blogsServer) UpdateBlog(ctx context.Context, req *blogv1.UpdateBlogRequest) (*blogv1.Blog, error) {
+	blog := models.Blog{Title: req.GetTitle(), AuthorID: int(req.GetAuthorId())}
+	// Repeated fields can't tell empty from unset, so tags are only cleared
+	// when asked to
+	switch {
+	case req.GetClearTags():
+		blog.Tags = []string{}
+	case len(req.GetTags()) > 0:
+		blog.Tags = req.GetTags()
+	}
+	if err := actAs(ctx, blog.AuthorID, nil); err != nil {
+		return nil, err
+	}
+	if err := s.checkBlog(ctx, blog); err != nil {
+		return nil, err
+	}
+	existing, err := s.blogs.GetBlog(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to update blog", err)
+	}
+	if existing.AuthorID != blog.AuthorID {
+		return nil, status.Error(codes.PermissionDenied, "cannot edit another user's blog")
+	}
+	blog.ID = uint(req.GetId())
+	content := filters.Content{
+		Kind:   filters.KindBlog,
+		UserID: blog.AuthorID,
+		Text:   blog.Text(),
+	}
+	if err := screen(ctx, s.logger, s.screener, content, blog); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.blogs.UpdateBlog(ctx, blog.ID, blog)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to update blog", err)
+	}
+	s.screener.Record(ctx, content)
+	return blogToProto(updated), nil
+}
+
+func (s *blogsServer) DeleteBlog(ctx context.Context, req *blogv1.DeleteBlogRequest) (*emptypb.Empty, error) {
+	if err := s.blogs.DeleteBlog(ctx, uint(req.GetId())); err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to delete blog", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *blogsServer) RateBlog(ctx context.Context, req *blogv1.RateBlogRequest) (*blogv1.Blog, error) {
+	// Users only rate as themselves, like over REST
+	if err := actAs(ctx, int(req.GetUserId()), nil); err != nil {
+		return nil, err
+	}
+
+	rating := models.Rating{UserID: int(req.GetUserId()), BlogID: int(req.GetBlogId()), Rating: int(req.GetRating())}
+	if err := check(ctx, rating); err != nil {
+		return nil, err
+	}
+	if !s.users.DoesUserExist(ctx, rating.UserID) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	blog, err := s.blogs.RateBlog(ctx, uint(req.GetBlogId()), rating)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to rate blog", err)
+	}
+	return blogToProto(blog), nil
+}
+
+// checkBlog validates blog and checks its author exists.
+func (s *blogsServer) checkBlog(ctx context.Context, blog models.Blog) error {
+	if err := check(ctx, blog); err != nil {
+		return err
+	}
+	if !s.users.DoesUserExist(ctx, blog.AuthorID) {
+		return invalid(map[string]string{"author_id": "user does not exist"})
+	}
+	return nil
+}
+
+// blogToProto converts blog to its message.
+func blogToProto(blog models.Blog) *blogv1.Blog {
+	return &blogv1.Blog{
+		Id:            uint64(blog.ID),
+		Title:         blog.Title,
+		AuthorId:      int64(blog.AuthorID),
+		CreatedDate:   timestamppb.New(blog.CreatedAt),
+		Score:         blog.Score,
+		RatingAverage: blog.RatingAverage,
+		RatingCount:   int64(blog.RatingCount),
+		Reactions:     reactionsToProto(blog.Reactions),
+		Tags:          blog.Tags,
+	}
+}
+
+// reactionsToProto converts reaction counts to their message field.
+func reactionsToProto(counts map[string]int) map[string]int64 {
+	if len(counts) == 0 {
+		return nil
+	}
+	reactions := make(map[string]int64, len(counts))
+	for typ, count := range counts {
+		reactions[typ] = int64(count)
+	}
+	return reactions
+}
@@ -0,0 +1,154 @@
+package rpc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/models"
+	blogv1 "github.com/navid/blog/proto/blog/v1"
+)
+
+// commentsServer implements blogv1.CommentsServiceServer.
+type commentsServer struct {
+	blogv1.UnimplementedCommentsServiceServer
+
+	logger   *slog.Logger
+	comments commentsService
+	users    usersService
+	blogs    blogsService
+	screener contentScreener
+}
+
+func (s *commentsServer) CreateComment(ctx context.Context, req *blogv1.CreateCommentRequest) (*blogv1.Comment, error) {
+	comment := models.Comment{UserID: int(req.GetUserId()), BlogID: int(req.GetBlogId()), Message: req.GetMessage()}
+	// Users only comment as themselves, like over REST
+	if err := actAs(ctx, comment.UserID, nil); err != nil {
+		return nil, err
+	}
+	if err := s.checkComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.comments.DoesCommentExist(ctx, comment.UserID, comment.BlogID)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to validate comment", err)
+	}
+	if exists {
+		return nil, status.Error(codes.AlreadyExists, "the user has already commented on the blog")
+	}
+	content := filters.Content{
+		Kind:   filters.KindComment,
+		UserID: comment.UserID,
+		Text:   comment.Message,
+	}
+	if err := screen(ctx, s.logger, s.screener, content, comment); err != nil {
+		return nil, err
+	}
+
+	created, err := s.comments.CreateComment(ctx, comment)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to create comment", err)
+	}
+	s.screener.Record(ctx, content)
+	return commentToProto(created), nil
+}
+
+func (s *commentsServer) ListComments(ctx context.Context, req *blogv1.ListCommentsRequest) (*blogv1.ListCommentsResponse, error) {
+	var authorID, blogID *int
+	if req.AuthorId != nil {
+		id := int(req.GetAuthorId())
+		authorID = &id
+	}
+	if req.BlogId != nil {
+		id := int(req.GetBlogId())
+		blogID = &id
+	}
+
+	comments, err := s.comments.ListComments(ctx, authorID, blogID)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to list comments", err)
+	}
+
+	resp := &blogv1.ListCommentsResponse{Comments: make([]*blogv1.Comment, len(comments))}
+	for i, comment := range comments {
+		resp.Comments[i] = commentToProto(comment)
+	}
+	return resp, nil
+}
+
+func (s *commentsServer) UpdateComment(ctx context.Context, req *blogv1.UpdateCommentRequest) (*blogv1.Comment, error) {
+	comment := models.Comment{UserID: int(req.GetUserId()), BlogID: int(req.GetBlogId()), Message: req.GetMessage()}
+	if err := actAs(ctx, comment.UserID, nil); err != nil {
+		return nil, err
+	}
+	if err := s.checkComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.comments.DoesCommentExist(ctx, comment.UserID, comment.BlogID)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to update comment", err)
+	}
+	if !exists {
+		return nil, status.Error(codes.NotFound, "comment not found")
+	}
+
+	comment.CreatedDate = time.Now()
+	content := filters.Content{
+		Kind:   filters.KindComment,
+		UserID: comment.UserID,
+		Text:   comment.Message,
+	}
+	if err := screen(ctx, s.logger, s.screener, content, comment); err != nil {
+		return nil, err
+	}
+	updated, err := s.comments.UpdateComment(ctx, comment)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to update comment", err)
+	}
+	s.screener.Record(ctx, content)
+	return commentToProto(updated), nil
+}
+
+func (s *commentsServer) DeleteComment(ctx context.Context, req *blogv1.DeleteCommentRequest) (*emptypb.Empty, error) {
+	if err := s.comments.DeleteComment(ctx, int(req.GetUserId()), int(req.GetBlogId())); err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to delete comment", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// checkComment validates comment and checks its user and blog exist.
+func (s *commentsServer) checkComment(ctx context.Context, comment models.Comment) error {
+	if err := check(ctx, comment); err != nil {
+		return err
+	}
+	if !s.users.DoesUserExist(ctx, comment.UserID) {
+		return invalid(map[string]string{"user_id": "user does not exist"})
+	}
+	if _, err := s.blogs.GetBlog(ctx, uint(comment.BlogID)); err != nil {
+		err = toStatus(ctx, s.logger, "failed to validate comment", err)
+		if status.Code(err) == codes.NotFound {
+			return invalid(map[string]string{"blog_id": "blog does not exist"})
+		}
+		return err
+	}
+	return nil
+}
+
+// commentToProto converts comment to its message.
+func commentToProto(comment models.Comment) *blogv1.Comment {
+	return &blogv1.Comment{
+		UserId:      int64(comment.UserID),
+		BlogId:      int64(comment.BlogID),
+		Message:     comment.Message,
+		CreatedDate: timestamppb.New(comment.CreatedDate),
+		Reactions:   reactionsToProto(comment.Reactions),
+	}
+}
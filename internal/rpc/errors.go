@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/validate"
+)
+
+// errorDomain is the domain of the ErrorInfo details of errors.
+const errorDomain = "blog.v1"
+
+// notFoundErrors maps the messages the services return for missing records
+// to the message sent to clients.
+var notFoundErrors = map[string]string{
+	"no user found":    "user not found",
+	"no blog found":    "blog not found",
+	"no comment found": "comment not found",
+}
+
+// toStatus maps an error returned by a service to a gRPC status error.
+// Errors the client can't act on are logged and reported as Internal, with
+// msg and no detail.
+func toStatus(ctx context.Context, logger *slog.Logger, msg string, err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case strings.Contains(err.Error(), "cannot rate their own"):
+		return status.Error(codes.PermissionDenied, "authors cannot rate their own blog")
+	}
+	for match, message := range notFoundErrors {
+		if strings.Contains(err.Error(), match) {
+			return status.Error(codes.NotFound, message)
+		}
+	}
+
+	logger.ErrorContext(ctx, msg, slog.String("error", err.Error()))
+	return status.Error(codes.Internal, msg)
+}
+
+// invalid returns an InvalidArgument error listing problems, keyed like the
+// validation problems of the REST API, as field violations.
+func invalid(problems map[string]string) error {
+	details := &errdetails.BadRequest{}
+	for _, field := range slices.Sorted(maps.Keys(problems)) {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: problems[field],
+		})
+	}
+	return withDetails(status.New(codes.InvalidArgument, "invalid request"), details)
+}
+
+// validator is a model that can be validated.
+type validator interface {
+	Valid(ctx context.Context) map[string]string
+}
+
+// check validates v against the rules in its validate struct tags, then with
+// its Valid method, like the REST handlers do with request bodies.
+func check(ctx context.Context, v validator) error {
+	problems := validate.Struct(v)
+	maps.Copy(problems, v.Valid(ctx))
+	if len(problems) > 0 {
+		return invalid(problems)
+	}
+	return nil
+}
+
+// screen runs content through the content filters, returning a
+// PermissionDenied error if they rejected it and FailedPrecondition if they
+// held it for moderation.
+func screen(ctx context.Context, logger *slog.Logger, screener contentScreener, content filters.Content, payload any) error {
+	decision, moderationID, err := screener.Screen(ctx, content, payload)
+	if err != nil {
+		return toStatus(ctx, logger, "failed to screen content", err)
+	}
+
+	info := &errdetails.ErrorInfo{
+		Domain: errorDomain,
+		Metadata: map[string]string{
+			"moderation_id": strconv.FormatUint(uint64(moderationID), 10),
+			"filter":        decision.Filter,
+			"reason":        decision.Reason,
+		},
+	}
+	switch decision.Verdict {
+	case filters.Reject:
+		info.Reason = "CONTENT_REJECTED"
+		return withDetails(status.New(codes.PermissionDenied, "the content was rejected by the content filters"), info)
+	case filters.Flag:
+		info.Reason = "PENDING_MODERATION"
+		return withDetails(status.New(codes.FailedPrecondition, "the content is held until a moderator has reviewed it"), info)
+	}
+	return nil
+}
+
+// withDetails returns st as an error, with details attached if they can be.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
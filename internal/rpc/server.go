@@ -0,0 +1,184 @@
+// Package rpc serves users, blogs and comments over gRPC, for internal
+// services that want typed calls rather than JSON. The services are defined
+// in proto/blog/v1 and apply the same checks as the REST handlers before
+// calling the same service layer. Calls authenticate with the same bearer
+// tokens as the REST API, sent in the authorization metadata.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/logging"
+	"github.com/navid/blog/internal/models"
+	blogv1 "github.com/navid/blog/proto/blog/v1"
+)
+
+// usersService represents a type capable of reading and writing users in
+// storage.
+type usersService interface {
+	CreateUser(ctx context.Context, user models.User) (models.User, error)
+	ReadUser(ctx context.Context, id uint64) (models.User, error)
+	ListUsersWithFilter(ctx context.Context, name string) ([]models.User, error)
+	UpdateUser(ctx context.Context, id uint64, patch models.User) (models.User, error)
+	DeleteUser(ctx context.Context, id uint64) error
+	DoesUserExist(ctx context.Context, userID int) bool
+}
+
+// blogsService represents a type capable of reading and writing blogs in
+// storage.
+type blogsService interface {
+	CreateBlog(ctx context.Context, blog models.Blog) (models.Blog, error)
+	GetBlog(ctx context.Context, id uint) (models.Blog, error)
+	ListBlogsWithFilter(ctx context.Context, filter models.BlogFilter) ([]models.Blog, error)
+	UpdateBlog(ctx context.Context, id uint, blog models.Blog) (models.Blog, error)
+	DeleteBlog(ctx context.Context, id uint) error
+	RateBlog(ctx context.Context, blogID uint, rating models.Rating) (models.Blog, error)
+}
+
+// commentsService represents a type capable of reading and writing comments
+// in storage.
+type commentsService interface {
+	CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error)
+	ListComments(ctx context.Context, authorID, blogID *int) ([]models.Comment, error)
+	UpdateComment(ctx context.Context, comment models.Comment) (models.Comment, error)
+	DeleteComment(ctx context.Context, userID, blogID int) error
+	DoesCommentExist(ctx context.Context, userID, blogID int) (bool, error)
+}
+
+// contentScreener represents a type capable of running submitted content
+// through the content filters, and of recording it once it has been stored.
+type contentScreener interface {
+	Screen(ctx context.Context, content filters.Content, payload any) (filters.Decision, uint, error)
+	Record(ctx context.Context, content filters.Content)
+}
+
+// tokenVerifier represents a type capable of verifying a bearer token and
+// returning the id of the user it was issued for.
+type tokenVerifier interface {
+	Verify(token string) (int, error)
+}
+
+// Server serves the gRPC API, with server reflection and the standard health
+// service.
+type Server struct {
+	*grpc.Server
+	health *health.Server
+}
+
+// NewServer creates a Server for the services, running created content
+// through screener. Calls are authenticated with tokens; users can only be
+// changed by themselves or by admins, and blogs and comments can only be
+// posted by their authors.
+func NewServer(logger *slog.Logger, tokens tokenVerifier, admins []int, users usersService, blogs blogsService, comments commentsService, screener contentScreener) *Server {
+	s := &Server{
+		Server: grpc.NewServer(grpc.ChainUnaryInterceptor(
+			logRequests(logger),
+			recoverPanics(logger),
+			authenticate(logger, tokens),
+		)),
+		health: health.NewServer(),
+	}
+
+	blogv1.RegisterUsersServiceServer(s.Server, &usersServer{logger: logger, users: users, admins: admins})
+	blogv1.RegisterBlogsServiceServer(s.Server, &blogsServer{logger: logger, blogs: blogs, users: users, screener: screener})
+	blogv1.RegisterCommentsServiceServer(s.Server, &commentsServer{logger: logger, comments: comments, users: users, blogs: blogs, screener: screener})
+	healthpb.RegisterHealthServer(s.Server, s.health)
+	reflection.Register(s.Server)
+
+	// The empty name is the health of the server as a whole
+	for _, name := range []string{"", blogv1.UsersService_ServiceDesc.ServiceName, blogv1.BlogsService_ServiceDesc.ServiceName, blogv1.CommentsService_ServiceDesc.ServiceName} {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	return s
+}
+
+// Drain reports every service as not serving, so clients and load balancers
+// stop sending calls before the server stops.
+func (s *Server) Drain() {
+	s.health.Shutdown()
+}
+
+// logRequests logs the method, duration and status code of every call.
+func logRequests(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		logger.InfoContext(
+			ctx,
+			"rpc completed",
+			slog.String("method", info.FullMethod),
+			slog.String("duration", time.Since(start).String()),
+			slog.String("code", status.Code(err).String()),
+		)
+		return resp, err
+	}
+}
+
+// authenticate reads a bearer token from the authorization metadata and, if
+// it is valid, stores the user's id in the call context, like the REST
+// middleware. Calls without a token go through anonymously; calls with a bad
+// one fail with Unauthenticated.
+func authenticate(logger *slog.Logger, verifier tokenVerifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		values := metadata.ValueFromIncomingContext(ctx, "authorization")
+		if len(values) == 0 {
+			return handler(ctx, req)
+		}
+
+		token, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "unsupported authorization scheme")
+		}
+		userID, err := verifier.Verify(token)
+		if err != nil {
+			logger.InfoContext(ctx, "rejected bearer token", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+
+		ctx = auth.WithUserID(ctx, userID)
+		ctx = logging.With(ctx, slog.Int("user_id", userID))
+		return handler(ctx, req)
+	}
+}
+
+// actAs checks that the call may act on behalf of the user with id: it is
+// authenticated as that user or one of admins.
+func actAs(ctx context.Context, id int, admins []int) error {
+	switch err := auth.ActAs(ctx, id, admins); {
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, "authentication required")
+	case errors.Is(err, auth.ErrForbidden):
+		return status.Error(codes.PermissionDenied, "forbidden")
+	}
+	return nil
+}
+
+// recoverPanics turns a panic in a call into an Internal error, so it
+// doesn't take the server down.
+func recoverPanics(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.ErrorContext(ctx, "panic recovered", slog.String("method", info.FullMethod), slog.Any("error", r))
+				err = status.Error(codes.Internal, codes.Internal.String())
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,374 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/navid/blog/internal/auth"
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/models"
+	blogv1 "github.com/navid/blog/proto/blog/v1"
+)
+
+// stubStore serves a fixed set of users, blogs and comments.
+type stubStore struct{}
+
+func (stubStore) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	user.ID = 1
+	return user, nil
+}
+
+func (stubStore) ReadUser(ctx context.Context, id uint64) (models.User, error) {
+	if id != 1 {
+		return models.User{}, nil
+	}
+	return models.User{ID: 1, Name: "Ada", Email: "ada@example.com", Password: "secret"}, nil
+}
+
+func (stubStore) ListUsersWithFilter(ctx context.Context, name string) ([]models.User, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (stubStore) UpdateUser(ctx context.Context, id uint64, patch models.User) (models.User, error) {
+	return models.User{}, fmt.Errorf("no user found with id: %d", id)
+}
+
+func (stubStore) DeleteUser(ctx context.Context, id uint64) error {
+	return nil
+}
+
+func (stubStore) DoesUserExist(ctx context.Context, userID int) bool {
+	return userID == 1 || userID == 2
+}
+
+func (stubStore) CreateBlog(ctx context.Context, blog models.Blog) (models.Blog, error) {
+	blog.ID = 10
+	return blog, nil
+}
+
+func (stubStore) GetBlog(ctx context.Context, id uint) (models.Blog, error) {
+	if id != 10 {
+		return models.Blog{}, fmt.Errorf("no blog found with id: %d", id)
+	}
+	return models.Blog{ID: 10, Title: "Hello", AuthorID: 1}, nil
+}
+
+func (stubStore) ListBlogsWithFilter(ctx context.Context, filter models.BlogFilter) ([]models.Blog, error) {
+	return nil, nil
+}
+
+func (stubStore) UpdateBlog(ctx context.Context, id uint, blog models.Blog) (models.Blog, error) {
+	return blog, nil
+}
+
+func (stubStore) DeleteBlog(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (stubStore) RateBlog(ctx context.Context, blogID uint, rating models.Rating) (models.Blog, error) {
+	return models.Blog{}, errors.New("authors cannot rate their own blog")
+}
+
+func (stubStore) CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	return comment, nil
+}
+
+func (stubStore) ListComments(ctx context.Context, authorID, blogID *int) ([]models.Comment, error) {
+	return nil, nil
+}
+
+func (stubStore) UpdateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	return comment, nil
+}
+
+func (stubStore) DeleteComment(ctx context.Context, userID, blogID int) error {
+	return errors.New("no comment found")
+}
+
+func (stubStore) DoesCommentExist(ctx context.Context, userID, blogID int) (bool, error) {
+	return userID == 2, nil
+}
+
+// stubScreener flags content containing "spam" and allows the rest,
+// remembering the content recorded once stored.
+type stubScreener struct {
+	mu       sync.Mutex
+	recorded []filters.Content
+}
+
+func (*stubScreener) Screen(ctx context.Context, content filters.Content, payload any) (filters.Decision, uint, error) {
+	if content.Text == "spam" {
+		return filters.Decision{Verdict: filters.Flag, Filter: "stub"}, 7, nil
+	}
+	return filters.Decision{Verdict: filters.Allow}, 0, nil
+}
+
+func (s *stubScreener) Record(ctx context.Context, content filters.Content) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorded = append(s.recorded, content)
+}
+
+// testTokens issues the tokens the test server accepts. User 9 is an admin.
+var testTokens = auth.NewTokens([]byte("secret"), time.Hour)
+
+// as returns ctx sending a bearer token for userID.
+func as(ctx context.Context, userID int) context.Context {
+	token, _ := testTokens.Issue(userID)
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// dialTestServer starts a Server over an in-memory connection and returns a
+// client connection to it, and the screener it runs content through.
+func dialTestServer(t *testing.T) (*grpc.ClientConn, *stubScreener) {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	screener := &stubScreener{}
+	server := NewServer(slog.Default(), testTokens, []int{9}, stubStore{}, stubStore{}, stubStore{}, screener)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, screener
+}
+
+func TestServerErrors(t *testing.T) {
+	conn, _ := dialTestServer(t)
+	users := blogv1.NewUsersServiceClient(conn)
+	blogs := blogv1.NewBlogsServiceClient(conn)
+	comments := blogv1.NewCommentsServiceClient(conn)
+
+	tests := map[string]struct {
+		call        func(ctx context.Context) error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		"found": {
+			call: func(ctx context.Context) error {
+				user, err := users.GetUser(ctx, &blogv1.GetUserRequest{Id: 1})
+				if err == nil && user.GetName() != "Ada" {
+					return fmt.Errorf("want Ada, got %v", user)
+				}
+				return err
+			},
+			wantCode: codes.OK,
+		},
+		"missing user": {
+			call: func(ctx context.Context) error {
+				_, err := users.GetUser(ctx, &blogv1.GetUserRequest{Id: 2})
+				return err
+			},
+			wantCode:    codes.NotFound,
+			wantMessage: "user not found",
+		},
+		"missing blog": {
+			call: func(ctx context.Context) error {
+				_, err := blogs.GetBlog(ctx, &blogv1.GetBlogRequest{Id: 11})
+				return err
+			},
+			wantCode:    codes.NotFound,
+			wantMessage: "blog not found",
+		},
+		"not found on update": {
+			call: func(ctx context.Context) error {
+				_, err := users.UpdateUser(as(ctx, 3), &blogv1.UpdateUserRequest{Id: 3, Name: "Bob", Email: "bob@example.com", Password: "secret"})
+				return err
+			},
+			wantCode: codes.NotFound,
+		},
+		"anonymous update": {
+			call: func(ctx context.Context) error {
+				_, err := users.UpdateUser(ctx, &blogv1.UpdateUserRequest{Id: 3, Name: "Bob", Email: "bob@example.com", Password: "secret"})
+				return err
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		"updating another user": {
+			call: func(ctx context.Context) error {
+				_, err := users.UpdateUser(as(ctx, 4), &blogv1.UpdateUserRequest{Id: 3, Name: "Bob", Email: "bob@example.com", Password: "secret"})
+				return err
+			},
+			wantCode: codes.PermissionDenied,
+		},
+		"deleting another user": {
+			call: func(ctx context.Context) error {
+				_, err := users.DeleteUser(as(ctx, 4), &blogv1.DeleteUserRequest{Id: 3})
+				return err
+			},
+			wantCode: codes.PermissionDenied,
+		},
+		"deleting a user as an admin": {
+			call: func(ctx context.Context) error {
+				_, err := users.DeleteUser(as(ctx, 9), &blogv1.DeleteUserRequest{Id: 3})
+				return err
+			},
+			wantCode: codes.OK,
+		},
+		"bad token": {
+			call: func(ctx context.Context) error {
+				_, err := users.DeleteUser(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer nope"), &blogv1.DeleteUserRequest{Id: 3})
+				return err
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		"invalid": {
+			call: func(ctx context.Context) error {
+				_, err := blogs.CreateBlog(as(ctx, 1), &blogv1.CreateBlogRequest{AuthorId: 1})
+				return err
+			},
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "invalid request",
+		},
+		"unknown author": {
+			call: func(ctx context.Context) error {
+				_, err := blogs.CreateBlog(as(ctx, 3), &blogv1.CreateBlogRequest{Title: "Hello", AuthorId: 3})
+				return err
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		"held for moderation": {
+			call: func(ctx context.Context) error {
+				_, err := blogs.CreateBlog(as(ctx, 1), &blogv1.CreateBlogRequest{Title: "spam", AuthorId: 1})
+				return err
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+		"anonymous blog": {
+			call: func(ctx context.Context) error {
+				_, err := blogs.CreateBlog(ctx, &blogv1.CreateBlogRequest{Title: "Hello", AuthorId: 1})
+				return err
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		"blog as another user": {
+			call: func(ctx context.Context) error {
+				_, err := blogs.CreateBlog(as(ctx, 9), &blogv1.CreateBlogRequest{Title: "Hello", AuthorId: 1})
+				return err
+			},
+			wantCode: codes.PermissionDenied,
+		},
+		"updating another user's blog": {
+			call: func(ctx context.Context) error {
+				_, err := blogs.UpdateBlog(as(ctx, 2), &blogv1.UpdateBlogRequest{Id: 10, Title: "Mine", AuthorId: 2})
+				return err
+			},
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "cannot edit another user's blog",
+		},
+		"duplicate comment": {
+			call: func(ctx context.Context) error {
+				_, err := comments.CreateComment(as(ctx, 2), &blogv1.CreateCommentRequest{UserId: 2, BlogId: 10, Message: "again"})
+				return err
+			},
+			wantCode: codes.AlreadyExists,
+		},
+		"own blog": {
+			call: func(ctx context.Context) error {
+				_, err := blogs.RateBlog(as(ctx, 1), &blogv1.RateBlogRequest{BlogId: 10, UserId: 1, Rating: 5})
+				return err
+			},
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "authors cannot rate their own blog",
+		},
+		"rating as another user": {
+			call: func(ctx context.Context) error {
+				_, err := blogs.RateBlog(as(ctx, 2), &blogv1.RateBlogRequest{BlogId: 10, UserId: 1, Rating: 5})
+				return err
+			},
+			wantCode: codes.PermissionDenied,
+		},
+		"internal errors are hidden": {
+			call: func(ctx context.Context) error {
+				_, err := users.ListUsers(ctx, &blogv1.ListUsersRequest{})
+				return err
+			},
+			wantCode:    codes.Internal,
+			wantMessage: "failed to list users",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.call(context.Background())
+			st := status.Convert(err)
+			if st.Code() != tc.wantCode {
+				t.Fatalf("want code %s, got %s: %v", tc.wantCode, st.Code(), err)
+			}
+			if tc.wantMessage != "" && st.Message() != tc.wantMessage {
+				t.Errorf("want message %q, got %q", tc.wantMessage, st.Message())
+			}
+		})
+	}
+}
+
+func TestServerInvalidDetails(t *testing.T) {
+	conn, _ := dialTestServer(t)
+
+	_, err := blogv1.NewBlogsServiceClient(conn).CreateBlog(as(context.Background(), 3), &blogv1.CreateBlogRequest{Title: "Hello", AuthorId: 3})
+
+	var violations []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.GetFieldViolations() {
+				violations = append(violations, v.GetField()+": "+v.GetDescription())
+			}
+		}
+	}
+	if len(violations) != 1 || violations[0] != "author_id: user does not exist" {
+		t.Errorf("want the author_id violation, got %v", violations)
+	}
+}
+
+func TestServerRecordsStoredContent(t *testing.T) {
+	conn, screener := dialTestServer(t)
+	ctx := as(context.Background(), 1)
+
+	if _, err := blogv1.NewBlogsServiceClient(conn).CreateBlog(ctx, &blogv1.CreateBlogRequest{Title: "Hello", AuthorId: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := blogv1.NewBlogsServiceClient(conn).CreateBlog(ctx, &blogv1.CreateBlogRequest{Title: "spam", AuthorId: 1}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("want the blog held, got %v", err)
+	}
+
+	// Only the stored blog is kept in the filters' history
+	if len(screener.recorded) != 1 || screener.recorded[0].UserID != 1 || screener.recorded[0].Text != "Hello" {
+		t.Errorf("want the stored blog of user 1 recorded, got %v", screener.recorded)
+	}
+}
+
+func TestServerHealth(t *testing.T) {
+	conn, _ := dialTestServer(t)
+	client := healthpb.NewHealthClient(conn)
+
+	for _, service := range []string{"", "blog.v1.BlogsService"} {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("want %q to be serving, got %s", service, resp.GetStatus())
+		}
+	}
+}
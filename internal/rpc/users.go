@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/navid/blog/internal/models"
+	blogv1 "github.com/navid/blog/proto/blog/v1"
+)
+
+// usersServer implements blogv1.UsersServiceServer.
+type usersServer struct {
+	blogv1.UnimplementedUsersServiceServer
+
+	logger *slog.Logger
+	users  usersService
+	admins []int
+}
+
+func (s *usersServer) CreateUser(ctx context.Context, req *blogv1.CreateUserRequest) (*blogv1.User, error) {
+	user := models.User{Name: req.GetName(), Email: req.GetEmail(), Password: req.GetPassword()}
+	if err := check(ctx, user); err != nil {
+		return nil, err
+	}
+
+	created, err := s.users.CreateUser(ctx, user)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to create user", err)
+	}
+	return userToProto(created), nil
+}
+
+func (s *usersServer) GetUser(ctx context.Context, req *blogv1.GetUserRequest) (*blogv1.User, error) {
+	user, err := s.users.ReadUser(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to read user", err)
+	}
+	// ReadUser returns an empty user when there is none
+	if user.ID == 0 {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return userToProto(user), nil
+}
+
+func (s *usersServer) ListUsers(ctx context.Context, req *blogv1.ListUsersRequest) (*blogv1.ListUsersResponse, error) {
+	users, err := s.users.ListUsersWithFilter(ctx, req.GetName())
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to list users", err)
+	}
+
+	resp := &blogv1.ListUsersResponse{Users: make([]*blogv1.User, len(users))}
+	for i, user := range users {
+		resp.Users[i] = userToProto(user)
+	}
+	return resp, nil
+}
+
+func (s *usersServer) UpdateUser(ctx context.Context, req *blogv1.UpdateUserRequest) (*blogv1.User, error) {
+	if err := actAs(ctx, int(req.GetId()), s.admins); err != nil {
+		return nil, err
+	}
+
+	user := models.User{Name: req.GetName(), Email: req.GetEmail(), Password: req.GetPassword()}
+	if err := check(ctx, user); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.users.UpdateUser(ctx, req.GetId(), user)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to update user", err)
+	}
+	return userToProto(updated), nil
+}
+
+func (s *usersServer) DeleteUser(ctx context.Context, req *blogv1.DeleteUserRequest) (*emptypb.Empty, error) {
+	if err := actAs(ctx, int(req.GetId()), s.admins); err != nil {
+		return nil, err
+	}
+	if err := s.users.DeleteUser(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, s.logger, "failed to delete user", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// userToProto converts user to its message, leaving out the password.
+func userToProto(user models.User) *blogv1.User {
+	return &blogv1.User{
+		Id:    uint64(user.ID),
+		Name:  user.Name,
+		Email: user.Email,
+	}
+}
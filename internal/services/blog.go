@@ -3,60 +3,150 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/navid/blog/internal/database"
 	"github.com/navid/blog/internal/models"
 )
 
 type BlogService struct {
-	db     *sql.DB
+	db     *database.DB
 	logger *slog.Logger
 }
 
 // NewBlogService creates a new BlogService.
 func NewBlogService(db *sql.DB, logger *slog.Logger) *BlogService {
 	return &BlogService{
-		db:     db,
+		db:     database.Wrap(db),
 		logger: logger,
 	}
 }
 
+// ratingPriorWeight is how many "virtual" ratings at the site-wide average
+// every blog starts with when computing its weighted score. It keeps a blog
+// with a single 5-star rating from outranking one with hundreds of 4.8s.
+const ratingPriorWeight = 5
+
+// blogPrior is a CTE computing the site-wide average rating. Blogs are
+// assumed to be middling (3) until anything has been rated.
+const blogPrior = `prior AS (SELECT COALESCE(AVG(rating), 3)::float8 AS mean FROM ratings)`
+
+// blogColumns selects a blog from a relation aliased b along with its rating
+// average, Bayesian-weighted score, reaction counts and tags. The prior CTE
+// must be in scope.
+var blogColumns = fmt.Sprintf(
+	`b.id, b.title, (b.rating_sum + %[1]d * prior.mean) / (b.rating_count + %[1]d) AS score, b.author_id, b.created_date, b.updated_date,
+         CASE WHEN b.rating_count > 0 THEN b.rating_sum::float8 / b.rating_count ELSE 0 END AS rating_average,
+         b.rating_count,
+         %[2]s AS reactions,
+         (SELECT jsonb_agg(t.tag ORDER BY t.tag) FROM blog_tags t WHERE t.blog_id = b.id) AS tags`,
+	ratingPriorWeight,
+	reactionCounts("b.id", "0"),
+)
+
+func scanBlog(row rowScanner) (models.Blog, error) {
+	var blog models.Blog
+	var reactions, tags []byte
+	err := row.Scan(&blog.ID, &blog.Title, &blog.Score, &blog.AuthorID, &blog.CreatedAt, &blog.UpdatedAt, &blog.RatingAverage, &blog.RatingCount, &reactions, &tags)
+	if err != nil {
+		return models.Blog{}, err
+	}
+	if len(tags) > 0 {
+		if err = json.Unmarshal(tags, &blog.Tags); err != nil {
+			return models.Blog{}, fmt.Errorf("failed to decode tags: %w", err)
+		}
+	}
+	blog.Reactions, err = decodeReactionCounts(reactions)
+	return blog, err
+}
+
+// setBlogTags replaces a blog's tags.
+func setBlogTags(ctx context.Context, tx database.Queryer, blogID uint, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM blog_tags WHERE blog_id = $1`, blogID); err != nil {
+		return fmt.Errorf("failed to clear blog tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to encode blog tags: %w", err)
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO blog_tags (blog_id, tag)
+         SELECT $1, tag FROM jsonb_array_elements_text($2::jsonb) AS tag`,
+		blogID, string(encoded),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save blog tags: %w", err)
+	}
+	return nil
+}
+
 // CreateBlog inserts a new blog into the database.
-func (s *BlogService) CreateBlog(ctx context.Context, blog models.Blog) (models.Blog, error) {
+func (s *BlogService) CreateBlog(ctx context.Context, blog models.Blog) (_ models.Blog, err error) {
 	s.logger.DebugContext(ctx, "Creating blog", "title", blog.Title)
+	ctx, span := startOperation(ctx, "blog", "create_blog")
+	defer func() { endOperation(span, err) }()
 
 	// Set the CreatedAt field to the current time
 	blog.CreatedAt = time.Now()
 
-	var createdBlog models.Blog
-	err := s.db.QueryRowContext(
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Blog{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	createdBlog, err := scanBlog(tx.QueryRowContext(
 		ctx,
-		`INSERT INTO blogs (title, score, author_id, created_date)
-         VALUES ($1, $2, $3, $4)
-         RETURNING id, title, score, author_id, created_date`,
-		blog.Title, blog.Score, blog.AuthorID, blog.CreatedAt,
-	).Scan(&createdBlog.ID, &createdBlog.Title, &createdBlog.Score, &createdBlog.AuthorID, &createdBlog.CreatedAt)
+		`WITH `+blogPrior+`,
+         b AS (
+             INSERT INTO blogs (title, author_id, created_date)
+             VALUES ($1, $2, $3)
+             RETURNING *
+         )
+         SELECT `+blogColumns+`
+         FROM b CROSS JOIN prior`,
+		blog.Title, blog.AuthorID, blog.CreatedAt,
+	))
 	if err != nil {
 		return models.Blog{}, fmt.Errorf("failed to create blog: %w", err)
 	}
 
+	createdBlog.Tags = models.NormalizeTags(blog.Tags)
+	if err = setBlogTags(ctx, tx, createdBlog.ID, createdBlog.Tags); err != nil {
+		return models.Blog{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Blog{}, fmt.Errorf("failed to commit blog: %w", err)
+	}
+
 	return createdBlog, nil
 }
 
 // GetBlog retrieves a blog by its ID.
-func (s *BlogService) GetBlog(ctx context.Context, id uint) (models.Blog, error) {
+func (s *BlogService) GetBlog(ctx context.Context, id uint) (_ models.Blog, err error) {
 	s.logger.DebugContext(ctx, "Retrieving blog", "id", id)
+	ctx, span := startOperation(ctx, "blog", "get_blog")
+	defer func() { endOperation(span, err) }()
 
-	var blog models.Blog
-	err := s.db.QueryRowContext(
+	blog, err := scanBlog(s.db.QueryRowContext(
 		ctx,
-		`SELECT id, title, score, author_id, created_date
-         FROM blogs
-         WHERE id = $1`,
+		`WITH `+blogPrior+`
+         SELECT `+blogColumns+`
+         FROM blogs b CROSS JOIN prior
+         WHERE b.id = $1`,
 		id,
-	).Scan(&blog.ID, &blog.Title, &blog.Score, &blog.AuthorID, &blog.CreatedAt)
+	))
 
 	if err == sql.ErrNoRows {
 		return models.Blog{}, fmt.Errorf("no blog found with id: %d", id)
@@ -67,19 +157,32 @@ func (s *BlogService) GetBlog(ctx context.Context, id uint) (models.Blog, error)
 	return blog, nil
 }
 
-// UpdateBlog updates an existing blog in the database.
-func (s *BlogService) UpdateBlog(ctx context.Context, id uint, blog models.Blog) (models.Blog, error) {
+// UpdateBlog updates an existing blog in the database. Its tags are replaced
+// unless blog.Tags is nil.
+func (s *BlogService) UpdateBlog(ctx context.Context, id uint, blog models.Blog) (_ models.Blog, err error) {
 	s.logger.DebugContext(ctx, "Updating blog", "id", id)
+	ctx, span := startOperation(ctx, "blog", "update_blog")
+	defer func() { endOperation(span, err) }()
 
-	var updatedBlog models.Blog
-	err := s.db.QueryRowContext(
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Blog{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	updatedBlog, err := scanBlog(tx.QueryRowContext(
 		ctx,
-		`UPDATE blogs
-         SET title = $1, score = $2, created_date = $3
-         WHERE id = $4
-         RETURNING id, title, score, author_id, created_date`,
-		blog.Title, blog.Score, blog.CreatedAt, id,
-	).Scan(&updatedBlog.ID, &updatedBlog.Title, &updatedBlog.Score, &updatedBlog.AuthorID, &updatedBlog.CreatedAt)
+		`WITH `+blogPrior+`,
+         b AS (
+             UPDATE blogs
+             SET title = $1, created_date = $2, updated_date = $4
+             WHERE id = $3
+             RETURNING *
+         )
+         SELECT `+blogColumns+`
+         FROM b CROSS JOIN prior`,
+		blog.Title, blog.CreatedAt, id, time.Now(),
+	))
 
 	if err == sql.ErrNoRows {
 		return models.Blog{}, fmt.Errorf("no blog found with id: %d", id)
@@ -87,67 +190,368 @@ func (s *BlogService) UpdateBlog(ctx context.Context, id uint, blog models.Blog)
 		return models.Blog{}, fmt.Errorf("failed to update blog: %w", err)
 	}
 
+	if blog.Tags != nil {
+		updatedBlog.Tags = models.NormalizeTags(blog.Tags)
+		if err = setBlogTags(ctx, tx, id, updatedBlog.Tags); err != nil {
+			return models.Blog{}, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Blog{}, fmt.Errorf("failed to commit blog: %w", err)
+	}
+
 	return updatedBlog, nil
 }
 
-// DeleteBlog deletes a blog by its ID.
-func (s *BlogService) DeleteBlog(ctx context.Context, id uint) error {
-    s.logger.DebugContext(ctx, "Deleting blog", "id", id)
+// DeleteBlog deletes a blog by its ID, along with everything that refers to
+// it: comments, reactions, ratings, bookmarks, reading list entries and tags.
+func (s *BlogService) DeleteBlog(ctx context.Context, id uint) (err error) {
+	s.logger.DebugContext(ctx, "Deleting blog", "id", id)
+	ctx, span := startOperation(ctx, "blog", "delete_blog")
+	defer func() { endOperation(span, err) }()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Close the gap the blog leaves in any reading list it is part of
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE reading_list_items i
+         SET position = i.position - 1
+         FROM reading_list_items d
+         WHERE d.blog_id = $1 AND i.list_id = d.list_id AND i.position > d.position`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reorder reading lists for blog: %w", err)
+	}
+
+	// Delete everything referring to the blog
+	for _, table := range []string{"comments", "reactions", "ratings", "bookmarks", "reading_list_items", "blog_tags"} {
+		if _, err = tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE blog_id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete %s for blog: %w", table, err)
+		}
+	}
+
+	// Delete the blog
+	result, err := tx.ExecContext(ctx, `DELETE FROM blogs WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete blog: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no blog found with id: %d", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit blog deletion: %w", err)
+	}
+
+	return nil
+}
+
+// ListBlogsWithFilter retrieves all blogs, optionally filtering by title,
+// author and tag, and ranking by weighted score or recency.
+func (s *BlogService) ListBlogsWithFilter(ctx context.Context, filter models.BlogFilter) (_ []models.Blog, err error) {
+	s.logger.DebugContext(ctx, "Listing blogs", slog.String("title", filter.Title), slog.Int("author_id", filter.AuthorID), slog.String("tag", filter.Tag), slog.Bool("order_by_score", filter.OrderByScore))
+	ctx, span := startOperation(ctx, "blog", "list_blogs_with_filter")
+	defer func() { endOperation(span, err) }()
 
-    // Delete associated comments
-    _, err := s.db.ExecContext(ctx, `DELETE FROM comments WHERE blog_id = $1`, id)
-    if err != nil {
-        return fmt.Errorf("failed to delete comments for blog: %w", err)
-    }
+	var blogs []models.Blog
+	err = s.streamBlogs(ctx, filter, func(blog models.Blog) error {
+		blogs = append(blogs, blog)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-    // Delete the blog
-    result, err := s.db.ExecContext(ctx, `DELETE FROM blogs WHERE id = $1`, id)
-    if err != nil {
-        return fmt.Errorf("failed to delete blog: %w", err)
-    }
+	return blogs, nil
+}
 
-    rowsAffected, err := result.RowsAffected()
-    if err != nil {
-        return fmt.Errorf("failed to get affected rows: %w", err)
-    }
+// streamPageSize is how many rows streams read at a time.
+const streamPageSize = 100
 
-    if rowsAffected == 0 {
-        return fmt.Errorf("no blog found with id: %d", id)
-    }
+// StreamBlogsWithFilter retrieves the same blogs as ListBlogsWithFilter, but
+// calls fn with each one as it is read instead of collecting them, so memory
+// use doesn't grow with the number of blogs. Blogs are read a page at a
+// time, and fn is only called once the page holding the blog has been, so it
+// can query the database too. It stops at the first error fn returns and
+// returns it.
+func (s *BlogService) StreamBlogsWithFilter(ctx context.Context, filter models.BlogFilter, fn func(models.Blog) error) (err error) {
+	s.logger.DebugContext(ctx, "Streaming blogs", slog.String("title", filter.Title), slog.Int("author_id", filter.AuthorID), slog.String("tag", filter.Tag), slog.Bool("order_by_score", filter.OrderByScore))
+	ctx, span := startOperation(ctx, "blog", "stream_blogs_with_filter")
+	defer func() { endOperation(span, err) }()
 
-    return nil
+	query, args := blogQuery(filter)
+	return database.Fetch(ctx, s.db, streamPageSize, query, args, func(rows *sql.Rows) (models.Blog, error) {
+		blog, err := scanBlog(rows)
+		if err != nil {
+			return models.Blog{}, fmt.Errorf("failed to scan blog: %w", err)
+		}
+		return blog, nil
+	}, fn)
 }
 
-// ListBlogsWithFilter retrieves all blogs, optionally filtering by title.
-func (s *BlogService) ListBlogsWithFilter(ctx context.Context, title string) ([]models.Blog, error) {
-	s.logger.DebugContext(ctx, "Listing blogs", slog.String("title", title))
+// streamBlogs queries the blogs matching filter and calls fn with each row.
+func (s *BlogService) streamBlogs(ctx context.Context, filter models.BlogFilter, fn func(models.Blog) error) error {
+	query, args := blogQuery(filter)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list blogs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		blog, err := scanBlog(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan blog: %w", err)
+		}
+		if err := fn(blog); err != nil {
+			return err
+		}
+	}
 
-	query := `SELECT id, title, score, author_id, created_date FROM blogs`
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// blogQuery returns the query for the blogs matching filter, and its
+// arguments.
+func blogQuery(filter models.BlogFilter) (string, []any) {
+	query := `WITH ` + blogPrior + ` SELECT ` + blogColumns + ` FROM blogs b CROSS JOIN prior`
 	var args []interface{}
+	var conditions []string
+
+	if filter.Title != "" {
+		args = append(args, "%"+filter.Title+"%")
+		conditions = append(conditions, fmt.Sprintf(`b.title ILIKE $%d`, len(args)))
+	}
+
+	if filter.AuthorID != 0 {
+		args = append(args, filter.AuthorID)
+		conditions = append(conditions, fmt.Sprintf(`b.author_id = $%d`, len(args)))
+	}
+
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (SELECT 1 FROM blog_tags t WHERE t.blog_id = b.id AND t.tag = $%d)`, len(args)))
+	}
+
+	if len(filter.IDs) > 0 {
+		args = append(args, filter.IDs)
+		conditions = append(conditions, fmt.Sprintf(`b.id = ANY($%d)`, len(args)))
+	}
+
+	if len(filter.AuthorIDs) > 0 {
+		args = append(args, filter.AuthorIDs)
+		conditions = append(conditions, fmt.Sprintf(`b.author_id = ANY($%d)`, len(args)))
+	}
 
-	if title != "" {
-		query += ` WHERE title ILIKE $1`
-		args = append(args, "%"+title+"%")
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+
+	switch {
+	case filter.OrderByScore:
+		query += ` ORDER BY score DESC, b.id`
+	case filter.OrderByNewest:
+		query += ` ORDER BY b.created_date DESC, b.id DESC`
+	case filter.Offset > 0:
+		// Pages need a stable order
+		query += ` ORDER BY b.id`
+	}
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(` OFFSET $%d`, len(args))
 	}
 
+	return query, args
+}
+
+// ListBlogModTimes retrieves when each blog was last created or edited, for
+// building sitemaps.
+func (s *BlogService) ListBlogModTimes(ctx context.Context) (_ []models.ModTime, err error) {
+	s.logger.DebugContext(ctx, "Listing blog modification times")
+	ctx, span := startOperation(ctx, "blog", "list_blog_mod_times")
+	defer func() { endOperation(span, err) }()
+
+	return listModTimes(ctx, s.db, `
+        SELECT id, COALESCE(updated_date, created_date)
+        FROM blogs
+        ORDER BY id
+        `)
+}
+
+// listModTimes runs a query selecting an id and a time and collects the rows.
+func listModTimes(ctx context.Context, db database.Queryer, query string) ([]models.ModTime, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modification times: %w", err)
+	}
+	defer rows.Close()
+
+	modTimes := []models.ModTime{}
+	for rows.Next() {
+		var m models.ModTime
+		if err := rows.Scan(&m.ID, &m.Modified); err != nil {
+			return nil, fmt.Errorf("failed to scan modification time: %w", err)
+		}
+		modTimes = append(modTimes, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return modTimes, nil
+}
+
+// RateBlog records a user's rating of a blog, replacing any rating they gave
+// it before, and keeps the blog's rating sum and count in step. Authors cannot
+// rate their own blogs. The blog is returned with its updated rating.
+func (s *BlogService) RateBlog(ctx context.Context, blogID uint, rating models.Rating) (_ models.Blog, err error) {
+	s.logger.DebugContext(ctx, "Rating blog", slog.Uint64("id", uint64(blogID)), slog.Int("user_id", rating.UserID))
+	ctx, span := startOperation(ctx, "blog", "rate_blog")
+	defer func() { endOperation(span, err) }()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Blog{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the blog row so concurrent ratings can't race on the totals
+	var authorID int
+	err = tx.QueryRowContext(ctx, `SELECT author_id FROM blogs WHERE id = $1 FOR UPDATE`, blogID).Scan(&authorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Blog{}, fmt.Errorf("no blog found with id: %d", blogID)
+	} else if err != nil {
+		return models.Blog{}, fmt.Errorf("failed to retrieve blog: %w", err)
+	}
+
+	if authorID == rating.UserID {
+		return models.Blog{}, fmt.Errorf("authors cannot rate their own blog")
+	}
+
+	var previous sql.NullInt64
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT rating FROM ratings WHERE user_id = $1 AND blog_id = $2`,
+		rating.UserID, blogID,
+	).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.Blog{}, fmt.Errorf("failed to retrieve previous rating: %w", err)
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO ratings (user_id, blog_id, rating, created_date, updated_date)
+         VALUES ($1, $2, $3, $4, $4)
+         ON CONFLICT (user_id, blog_id)
+         DO UPDATE SET rating = EXCLUDED.rating, updated_date = EXCLUDED.updated_date`,
+		rating.UserID, blogID, rating.Rating, now,
+	)
+	if err != nil {
+		return models.Blog{}, fmt.Errorf("failed to save rating: %w", err)
+	}
+
+	// A changed rating only moves the sum; a new one also bumps the count
+	sumDelta, countDelta := int64(rating.Rating), 1
+	if previous.Valid {
+		sumDelta -= previous.Int64
+		countDelta = 0
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE blogs
+         SET rating_sum = rating_sum + $1, rating_count = rating_count + $2
+         WHERE id = $3`,
+		sumDelta, countDelta, blogID,
+	)
+	if err != nil {
+		return models.Blog{}, fmt.Errorf("failed to update blog rating: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Blog{}, fmt.Errorf("failed to commit rating: %w", err)
+	}
+
+	return s.GetBlog(ctx, blogID)
+}
+
+// Feed retrieves the most recent blogs by the authors userID follows, newest
+// first, starting after the provided cursor (nil for the first page). Blogs
+// are read straight from the followed authors at request time rather than
+// copied into per-user inboxes on write, relying on the follows primary key
+// and the blogs (author_id, created_date, id) index. The returned cursor points
+// at the next page and is nil when there are no more blogs.
+func (s *BlogService) Feed(ctx context.Context, userID int, after *models.Cursor, limit int) (_ []models.Blog, _ *models.Cursor, err error) {
+	s.logger.DebugContext(ctx, "Building feed", slog.Int("user_id", userID), slog.Int("limit", limit))
+	ctx, span := startOperation(ctx, "blog", "feed")
+	defer func() { endOperation(span, err) }()
+
+	query := `WITH ` + blogPrior + `
+         SELECT ` + blogColumns + `
+         FROM follows f
+         JOIN blogs b ON b.author_id = f.followee_id
+         CROSS JOIN prior
+         WHERE f.follower_id = $1`
+	args := []interface{}{userID}
+
+	if after != nil {
+		query += ` AND (b.created_date, b.id) < ($2, $3)`
+		args = append(args, after.CreatedAt, after.ID)
+	}
+
+	// Fetch one extra row to learn whether there is another page
+	query += fmt.Sprintf(` ORDER BY b.created_date DESC, b.id DESC LIMIT $%d`, len(args)+1)
+	args = append(args, limit+1)
+
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list blogs: %w", err)
+		return nil, nil, fmt.Errorf("failed to build feed: %w", err)
 	}
 	defer rows.Close()
 
-	var blogs []models.Blog
+	blogs := []models.Blog{}
 	for rows.Next() {
-		var blog models.Blog
-		if err := rows.Scan(&blog.ID, &blog.Title, &blog.Score, &blog.AuthorID, &blog.CreatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan blog: %w", err)
+		blog, err := scanBlog(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan blog: %w", err)
 		}
 		blogs = append(blogs, blog)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows iteration error: %w", err)
+		return nil, nil, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	return blogs, nil
+	var next *models.Cursor
+	if len(blogs) > limit {
+		blogs = blogs[:limit]
+		last := blogs[len(blogs)-1]
+		next = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return blogs, next, nil
 }
@@ -6,6 +6,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"log/slog"
+	"reflect"
 	"regexp"
 	"testing"
 	"time"
@@ -27,23 +28,27 @@ func TestBlogService_ReadBlog(t *testing.T) {
         "happy path": {
             mockCalled:    true,
             mockInputArgs: []driver.Value{1},
-            mockOutput: sqlmock.NewRows([]string{"id", "title", "score", "author_id", "created_date"}).
-                AddRow(1, "Test Blog", 5, 1, parseTime("2024-05-15T10:00:00Z")),
+            mockOutput: sqlmock.NewRows([]string{"id", "title", "score", "author_id", "created_date", "updated_date", "rating_average", "rating_count", "reactions", "tags"}).
+                AddRow(1, "Test Blog", 4.5, 1, parseTime("2024-05-15T10:00:00Z"), nil, 5, 2, []byte(`{"like": 3}`), []byte(`["go", "testing"]`)),
             mockError: nil,
             input:     1,
             expectedOutput: models.Blog{
-                ID:        1,
-                Title:     "Test Blog",
-                Score:     5,
-                AuthorID:  1,
-                CreatedAt: parseTime("2024-05-15T10:00:00Z"),
+                ID:            1,
+                Title:         "Test Blog",
+                Score:         4.5,
+                AuthorID:      1,
+                CreatedAt:     parseTime("2024-05-15T10:00:00Z"),
+                RatingAverage: 5,
+                RatingCount:   2,
+                Reactions:     map[string]int{"like": 3},
+                Tags:          []string{"go", "testing"},
             },
             expectedError: nil,
         },
         "blog not found": {
             mockCalled:     true,
             mockInputArgs:  []driver.Value{2},
-            mockOutput:     sqlmock.NewRows([]string{"id", "title", "score", "author_id", "created_date"}), // No rows
+            mockOutput:     sqlmock.NewRows([]string{"id", "title", "score", "author_id", "created_date", "updated_date", "rating_average", "rating_count", "reactions", "tags"}), // No rows
             mockError:      nil,
             input:          2,
             expectedOutput: models.Blog{},
@@ -71,10 +76,10 @@ func TestBlogService_ReadBlog(t *testing.T) {
             logger := slog.Default()
 
             if tc.mockCalled {
-                query := regexp.QuoteMeta(`
-                    SELECT id, title, score, author_id, created_date
-                    FROM blogs
-                    WHERE id = $1
+                query := regexp.QuoteMeta(`WITH ` + blogPrior + `
+                    SELECT ` + blogColumns + `
+                    FROM blogs b CROSS JOIN prior
+                    WHERE b.id = $1
                 `)
                 if tc.mockError != nil {
                     mock.ExpectQuery(query).
@@ -98,7 +103,7 @@ func TestBlogService_ReadBlog(t *testing.T) {
                 t.Errorf("expected no error, got %v", err)
             }
 
-            if output != tc.expectedOutput {
+            if !reflect.DeepEqual(output, tc.expectedOutput) {
                 t.Errorf("expected output %v, got %v", tc.expectedOutput, output)
             }
 
@@ -111,6 +116,125 @@ func TestBlogService_ReadBlog(t *testing.T) {
     }
 }
 
+func TestBlogService_RateBlog(t *testing.T) {
+    testcases := map[string]struct {
+        rating        models.Rating
+        authorID      int
+        previous      *int
+        expectedSum   int64
+        expectedCount int
+        expectedError error
+    }{
+        "first rating": {
+            rating:        models.Rating{UserID: 2, Rating: 4},
+            authorID:      1,
+            expectedSum:   4,
+            expectedCount: 1,
+        },
+        "changed rating": {
+            rating:        models.Rating{UserID: 2, Rating: 2},
+            authorID:      1,
+            previous:      intPtr(5),
+            expectedSum:   -3,
+            expectedCount: 0,
+        },
+        "author rating own blog": {
+            rating:        models.Rating{UserID: 1, Rating: 5},
+            authorID:      1,
+            expectedError: fmt.Errorf("authors cannot rate their own blog"),
+        },
+    }
+
+    for name, tc := range testcases {
+        t.Run(name, func(t *testing.T) {
+            db, mock, err := sqlmock.New()
+            if err != nil {
+                t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+            }
+            defer db.Close()
+
+            mock.ExpectBegin()
+            mock.ExpectQuery(regexp.QuoteMeta(`SELECT author_id FROM blogs WHERE id = $1 FOR UPDATE`)).
+                WithArgs(1).
+                WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(tc.authorID))
+
+            if tc.expectedError != nil {
+                mock.ExpectRollback()
+            } else {
+                previous := sqlmock.NewRows([]string{"rating"})
+                if tc.previous != nil {
+                    previous.AddRow(*tc.previous)
+                }
+                mock.ExpectQuery(regexp.QuoteMeta(`SELECT rating FROM ratings WHERE user_id = $1 AND blog_id = $2`)).
+                    WithArgs(tc.rating.UserID, 1).
+                    WillReturnRows(previous)
+                mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO ratings`)).
+                    WithArgs(tc.rating.UserID, 1, tc.rating.Rating, sqlmock.AnyArg()).
+                    WillReturnResult(sqlmock.NewResult(0, 1))
+                mock.ExpectExec(regexp.QuoteMeta(`UPDATE blogs SET rating_sum = rating_sum + $1, rating_count = rating_count + $2 WHERE id = $3`)).
+                    WithArgs(tc.expectedSum, tc.expectedCount, 1).
+                    WillReturnResult(sqlmock.NewResult(0, 1))
+                mock.ExpectCommit()
+                mock.ExpectQuery(regexp.QuoteMeta(`WITH ` + blogPrior)).
+                    WithArgs(1).
+                    WillReturnRows(sqlmock.NewRows([]string{"id", "title", "score", "author_id", "created_date", "updated_date", "rating_average", "rating_count", "reactions", "tags"}).
+                        AddRow(1, "Test Blog", 3.2, tc.authorID, parseTime("2024-05-15T10:00:00Z"), nil, tc.rating.Rating, 1, []byte(`{}`), nil))
+            }
+
+            blogService := NewBlogService(db, slog.Default())
+
+            _, err = blogService.RateBlog(context.TODO(), 1, tc.rating)
+            if err != nil && tc.expectedError != nil && err.Error() != tc.expectedError.Error() {
+                t.Errorf("expected error %v, got %v", tc.expectedError, err)
+            } else if err == nil && tc.expectedError != nil {
+                t.Errorf("expected error %v, got nil", tc.expectedError)
+            } else if err != nil && tc.expectedError == nil {
+                t.Errorf("expected no error, got %v", err)
+            }
+
+            if err = mock.ExpectationsWereMet(); err != nil {
+                t.Errorf("there were unfulfilled expectations: %s", err)
+            }
+        })
+    }
+}
+
+func TestBlogService_UpdateBlog(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    edited := parseTime("2024-06-01T09:00:00Z")
+
+    // The edit time is written to updated_date, so feeds and sitemaps see the change
+    mock.ExpectBegin()
+    mock.ExpectQuery(regexp.QuoteMeta(`SET title = $1, created_date = $2, updated_date = $4`)).
+        WithArgs("Edited", parseTime("2024-05-15T10:00:00Z"), 1, sqlmock.AnyArg()).
+        WillReturnRows(sqlmock.NewRows([]string{"id", "title", "score", "author_id", "created_date", "updated_date", "rating_average", "rating_count", "reactions", "tags"}).
+            AddRow(1, "Edited", 3.2, 1, parseTime("2024-05-15T10:00:00Z"), edited, 0, 0, []byte(`{}`), []byte(`[]`)))
+    mock.ExpectCommit()
+
+    blogService := NewBlogService(db, slog.Default())
+
+    output, err := blogService.UpdateBlog(context.TODO(), 1, models.Blog{Title: "Edited", CreatedAt: parseTime("2024-05-15T10:00:00Z")})
+    if err != nil {
+        t.Fatalf("expected no error, got %v", err)
+    }
+    if output.UpdatedAt == nil || !output.UpdatedAt.Equal(edited) {
+        t.Errorf("expected updated at %v, got %v", edited, output.UpdatedAt)
+    }
+
+    if err = mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
+func intPtr(i int) *int {
+    return &i
+}
+
 func parseTime(value string) time.Time {
     t, _ := time.Parse(time.RFC3339, value)
     return t
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/navid/blog/internal/database"
+	"github.com/navid/blog/internal/models"
+)
+
+// BookmarksService is a service capable of saving blogs for a user to read
+// later.
+type BookmarksService struct {
+	db     *database.DB
+	logger *slog.Logger
+}
+
+// NewBookmarksService creates a new BookmarksService.
+func NewBookmarksService(db *sql.DB, logger *slog.Logger) *BookmarksService {
+	return &BookmarksService{
+		db:     database.Wrap(db),
+		logger: logger,
+	}
+}
+
+// ListBookmarks retrieves a user's bookmarks, most recent first.
+func (s *BookmarksService) ListBookmarks(ctx context.Context, userID int) (_ []models.Bookmark, err error) {
+	s.logger.DebugContext(ctx, "Listing bookmarks", slog.Int("user_id", userID))
+	ctx, span := startOperation(ctx, "bookmarks", "list_bookmarks")
+	defer func() { endOperation(span, err) }()
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT bm.user_id, bm.blog_id, b.title, bm.note, bm.created_date
+         FROM bookmarks bm
+         JOIN blogs b ON b.id = bm.blog_id
+         WHERE bm.user_id = $1
+         ORDER BY bm.created_date DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	bookmarks := []models.Bookmark{}
+	for rows.Next() {
+		var b models.Bookmark
+		if err := rows.Scan(&b.UserID, &b.BlogID, &b.BlogTitle, &b.Note, &b.CreatedDate); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+		bookmarks = append(bookmarks, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return bookmarks, nil
+}
+
+// SaveBookmark bookmarks a blog for a user, or updates the note on an
+// existing bookmark.
+func (s *BookmarksService) SaveBookmark(ctx context.Context, bookmark models.Bookmark) (_ models.Bookmark, err error) {
+	s.logger.DebugContext(ctx, "Saving bookmark", slog.Int("user_id", bookmark.UserID), slog.Int("blog_id", bookmark.BlogID))
+	ctx, span := startOperation(ctx, "bookmarks", "save_bookmark")
+	defer func() { endOperation(span, err) }()
+
+	var saved models.Bookmark
+	err = s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO bookmarks (user_id, blog_id, note, created_date)
+         SELECT $1, b.id, $3, $4 FROM blogs b WHERE b.id = $2
+         ON CONFLICT (user_id, blog_id) DO UPDATE SET note = EXCLUDED.note
+         RETURNING user_id, blog_id, note, created_date`,
+		bookmark.UserID, bookmark.BlogID, bookmark.Note, time.Now(),
+	).Scan(&saved.UserID, &saved.BlogID, &saved.Note, &saved.CreatedDate)
+	if err == sql.ErrNoRows {
+		// Nothing was selected to insert, so the blog doesn't exist
+		return models.Bookmark{}, fmt.Errorf("no blog found with id: %d", bookmark.BlogID)
+	} else if err != nil {
+		return models.Bookmark{}, fmt.Errorf("failed to save bookmark: %w", err)
+	}
+
+	return saved, nil
+}
+
+// DeleteBookmark removes a user's bookmark of a blog.
+func (s *BookmarksService) DeleteBookmark(ctx context.Context, userID, blogID int) (err error) {
+	s.logger.DebugContext(ctx, "Deleting bookmark", slog.Int("user_id", userID), slog.Int("blog_id", blogID))
+	ctx, span := startOperation(ctx, "bookmarks", "delete_bookmark")
+	defer func() { endOperation(span, err) }()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM bookmarks WHERE user_id = $1 AND blog_id = $2`,
+		userID, blogID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete bookmark: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no bookmark found")
+	}
+
+	return nil
+}
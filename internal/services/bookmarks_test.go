@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/navid/blog/internal/models"
+)
+
+func TestBookmarksService_SaveBookmark(t *testing.T) {
+	testcases := map[string]struct {
+		noBlog        bool
+		expectedError error
+	}{
+		"save a bookmark": {},
+		"missing blog": {
+			noBlog:        true,
+			expectedError: fmt.Errorf("no blog found with id: 4"),
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			query := mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO bookmarks`)).
+				WithArgs(7, 4, "later", sqlmock.AnyArg())
+			if tc.noBlog {
+				query.WillReturnRows(sqlmock.NewRows([]string{"user_id", "blog_id", "note", "created_date"}))
+			} else {
+				query.WillReturnRows(sqlmock.NewRows([]string{"user_id", "blog_id", "note", "created_date"}).
+					AddRow(7, 4, "later", parseTime("2024-05-15T10:00:00Z")))
+			}
+
+			bookmarksService := NewBookmarksService(db, slog.Default())
+
+			output, err := bookmarksService.SaveBookmark(context.TODO(), models.Bookmark{UserID: 7, BlogID: 4, Note: "later"})
+			if tc.expectedError != nil {
+				if err == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected error %v, got %v", tc.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			} else if output.UserID != 7 || output.BlogID != 4 || output.Note != "later" {
+				t.Errorf("unexpected bookmark %+v", output)
+			}
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
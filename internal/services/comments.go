@@ -8,42 +8,109 @@ import (
 	"strings"
 	"time"
 
+	"github.com/navid/blog/internal/database"
 	"github.com/navid/blog/internal/models"
 )
 
 type CommentsService struct {
-	db     *sql.DB
+	db     *database.DB
 	logger *slog.Logger
 }
 
 // NewCommentsService creates a new CommentsService.
 func NewCommentsService(db *sql.DB, logger *slog.Logger) *CommentsService {
 	return &CommentsService{
-		db:     db,
+		db:     database.Wrap(db),
 		logger: logger,
 	}
 }
 
 // ListComments retrieves all comments, optionally filtering by author_id or blog_id.
-func (s *CommentsService) ListComments(ctx context.Context, authorID, blogID *int) ([]models.Comment, error) {
+func (s *CommentsService) ListComments(ctx context.Context, authorID, blogID *int) (_ []models.Comment, err error) {
 	s.logger.DebugContext(ctx, "Listing comments", slog.Any("author_id", authorID), slog.Any("blog_id", blogID))
+	ctx, span := startOperation(ctx, "comments", "list_comments")
+	defer func() { endOperation(span, err) }()
 
-	query := `SELECT user_id, blog_id, message, created_date FROM comments`
+	var comments []models.Comment
+	err = s.streamComments(ctx, authorID, blogID, func(comment models.Comment) error {
+		comments = append(comments, comment)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// StreamComments retrieves the same comments as ListComments, but calls fn
+// with each one as it is read instead of collecting them, so memory use
+// doesn't grow with the number of comments. Comments are read a page at a
+// time, and fn is only called once the page holding the comment has been, so
+// it can query the database too. It stops at the first error fn returns and
+// returns it.
+func (s *CommentsService) StreamComments(ctx context.Context, authorID, blogID *int, fn func(models.Comment) error) (err error) {
+	s.logger.DebugContext(ctx, "Streaming comments", slog.Any("author_id", authorID), slog.Any("blog_id", blogID))
+	ctx, span := startOperation(ctx, "comments", "stream_comments")
+	defer func() { endOperation(span, err) }()
+
+	conditions, args := commentConditions(authorID, blogID)
+	return database.Fetch(ctx, s.db, streamPageSize, commentQuery(conditions, ""), args, scanComment, fn)
+}
+
+// ListCommentsForBlogs retrieves the comments on the provided blogs in one
+// query, oldest first, grouped by blog id, for embedding them in the blogs.
+func (s *CommentsService) ListCommentsForBlogs(ctx context.Context, blogIDs []int) (_ map[int][]models.Comment, err error) {
+	s.logger.DebugContext(ctx, "Listing comments for blogs", slog.Int("count", len(blogIDs)))
+	ctx, span := startOperation(ctx, "comments", "list_comments_for_blogs")
+	defer func() { endOperation(span, err) }()
+
+	comments := make(map[int][]models.Comment, len(blogIDs))
+	if len(blogIDs) == 0 {
+		return comments, nil
+	}
+
+	conditions := []string{"c.blog_id = ANY($1)"}
+	err = s.queryComments(ctx, conditions, "c.created_date, c.user_id", []any{blogIDs}, func(comment models.Comment) error {
+		comments[comment.BlogID] = append(comments[comment.BlogID], comment)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// streamComments queries the comments matching the filters and calls fn with
+// each row.
+func (s *CommentsService) streamComments(ctx context.Context, authorID, blogID *int, fn func(models.Comment) error) error {
+	conditions, args := commentConditions(authorID, blogID)
+	return s.queryComments(ctx, conditions, "", args, fn)
+}
+
+// commentConditions returns the conditions on comments filtering by author
+// and blog, and their arguments.
+func commentConditions(authorID, blogID *int) ([]string, []interface{}) {
 	var args []interface{}
 	var conditions []string
 
 	if authorID != nil {
-		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)+1))
+		conditions = append(conditions, fmt.Sprintf("c.user_id = $%d", len(args)+1))
 		args = append(args, *authorID)
 	}
 	if blogID != nil {
-		conditions = append(conditions, fmt.Sprintf("blog_id = $%d", len(args)+1))
+		conditions = append(conditions, fmt.Sprintf("c.blog_id = $%d", len(args)+1))
 		args = append(args, *blogID)
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
+	return conditions, args
+}
+
+// queryComments queries the comments matching all of conditions, ordered by
+// orderBy if it isn't empty, and calls fn with each row.
+func (s *CommentsService) queryComments(ctx context.Context, conditions []string, orderBy string, args []interface{}, fn func(models.Comment) error) error {
+	query := commentQuery(conditions, orderBy)
 
 	// Log the constructed query and arguments
 	s.logger.DebugContext(ctx, "Constructed query", slog.String("query", query), slog.Any("args", args))
@@ -51,38 +118,69 @@ func (s *CommentsService) ListComments(ctx context.Context, authorID, blogID *in
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "Failed to execute query", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to list comments: %w", err)
+		return fmt.Errorf("failed to list comments: %w", err)
 	}
 	defer rows.Close()
 
-	var comments []models.Comment
 	for rows.Next() {
-		var comment models.Comment
-		if err := rows.Scan(&comment.UserID, &comment.BlogID, &comment.Message, &comment.CreatedDate); err != nil {
-			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		comment, err := scanComment(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(comment); err != nil {
+			return err
 		}
-		comments = append(comments, comment)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows iteration error: %w", err)
+		return fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	return comments, nil
+	return nil
 }
 
-func (s *CommentsService) UpdateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
+// commentQuery returns the query for the comments matching all of
+// conditions, ordered by orderBy if it isn't empty.
+func commentQuery(conditions []string, orderBy string) string {
+	query := `SELECT c.user_id, c.blog_id, c.message, c.created_date, ` + reactionCounts("c.blog_id", "c.user_id") + ` FROM comments c`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if orderBy != "" {
+		query += " ORDER BY " + orderBy
+	}
+	return query
+}
+
+// scanComment scans a row of a comment query.
+func scanComment(rows *sql.Rows) (models.Comment, error) {
+	var comment models.Comment
+	var reactions []byte
+	if err := rows.Scan(&comment.UserID, &comment.BlogID, &comment.Message, &comment.CreatedDate, &reactions); err != nil {
+		return models.Comment{}, fmt.Errorf("failed to scan comment: %w", err)
+	}
+	var err error
+	if comment.Reactions, err = decodeReactionCounts(reactions); err != nil {
+		return models.Comment{}, err
+	}
+	return comment, nil
+}
+
+func (s *CommentsService) UpdateComment(ctx context.Context, comment models.Comment) (_ models.Comment, err error) {
 	s.logger.DebugContext(ctx, "Updating comment", slog.Int("user_id", comment.UserID), slog.Int("blog_id", comment.BlogID))
+	ctx, span := startOperation(ctx, "comments", "update_comment")
+	defer func() { endOperation(span, err) }()
 
 	var updatedComment models.Comment
-	err := s.db.QueryRowContext(
+	var reactions []byte
+	err = s.db.QueryRowContext(
 		ctx,
 		`UPDATE comments
          SET message = $1, created_date = $2
          WHERE user_id = $3 AND blog_id = $4
-         RETURNING user_id, blog_id, message, created_date`,
+         RETURNING user_id, blog_id, message, created_date, `+reactionCounts("comments.blog_id", "comments.user_id"),
 		comment.Message, comment.CreatedDate, comment.UserID, comment.BlogID,
-	).Scan(&updatedComment.UserID, &updatedComment.BlogID, &updatedComment.Message, &updatedComment.CreatedDate)
+	).Scan(&updatedComment.UserID, &updatedComment.BlogID, &updatedComment.Message, &updatedComment.CreatedDate, &reactions)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return models.Comment{}, fmt.Errorf("no comment found with user_id: %d and blog_id: %d", comment.UserID, comment.BlogID)
@@ -90,18 +188,24 @@ func (s *CommentsService) UpdateComment(ctx context.Context, comment models.Comm
 		return models.Comment{}, fmt.Errorf("failed to update comment: %w", err)
 	}
 
+	if updatedComment.Reactions, err = decodeReactionCounts(reactions); err != nil {
+		return models.Comment{}, err
+	}
+
 	return updatedComment, nil
 }
 
-func (s *CommentsService) CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
+func (s *CommentsService) CreateComment(ctx context.Context, comment models.Comment) (_ models.Comment, err error) {
 	s.logger.DebugContext(ctx, "Creating comment", slog.Int("user_id", comment.UserID), slog.Int("blog_id", comment.BlogID))
+	ctx, span := startOperation(ctx, "comments", "create_comment")
+	defer func() { endOperation(span, err) }()
 
 	// Set the CreatedDate field to the current time
 	comment.CreatedDate = time.Now()
 	s.logger.DebugContext(ctx, "Setting created_date", slog.Time("created_date", comment.CreatedDate))
 
 	var createdComment models.Comment
-	err := s.db.QueryRowContext(
+	err = s.db.QueryRowContext(
 		ctx,
 		`INSERT INTO comments (user_id, blog_id, message, created_date)
          VALUES ($1, $2, $3, $4)
@@ -117,11 +221,13 @@ func (s *CommentsService) CreateComment(ctx context.Context, comment models.Comm
 }
 
 // DoesCommentExist checks if a comment with the given user_id and blog_id already exists.
-func (s *CommentsService) DoesCommentExist(ctx context.Context, userID, blogID int) (bool, error) {
+func (s *CommentsService) DoesCommentExist(ctx context.Context, userID, blogID int) (_ bool, err error) {
 	s.logger.DebugContext(ctx, "Checking if comment exists", slog.Int("user_id", userID), slog.Int("blog_id", blogID))
+	ctx, span := startOperation(ctx, "comments", "does_comment_exist")
+	defer func() { endOperation(span, err) }()
 
 	var exists bool
-	err := s.db.QueryRowContext(
+	err = s.db.QueryRowContext(
 		ctx,
 		`SELECT EXISTS(SELECT 1 FROM comments WHERE user_id = $1 AND blog_id = $2)`,
 		userID, blogID,
@@ -133,8 +239,19 @@ func (s *CommentsService) DoesCommentExist(ctx context.Context, userID, blogID i
 	return exists, nil
 }
 
-func (s *CommentsService) DeleteComment(ctx context.Context, userID, blogID int) error {
+func (s *CommentsService) DeleteComment(ctx context.Context, userID, blogID int) (err error) {
 	s.logger.DebugContext(ctx, "Deleting comment", slog.Int("user_id", userID), slog.Int("blog_id", blogID))
+	ctx, span := startOperation(ctx, "comments", "delete_comment")
+	defer func() { endOperation(span, err) }()
+
+	_, err = s.db.ExecContext(
+		ctx,
+		`DELETE FROM reactions WHERE comment_user_id = $1 AND blog_id = $2`,
+		userID, blogID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete reactions for comment: %w", err)
+	}
 
 	result, err := s.db.ExecContext(
 		ctx,
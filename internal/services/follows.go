@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/navid/blog/internal/database"
+	"github.com/navid/blog/internal/models"
+)
+
+// FollowsService is a service capable of managing which users follow which
+// authors.
+type FollowsService struct {
+	db     *database.DB
+	logger *slog.Logger
+}
+
+// NewFollowsService creates a new FollowsService.
+func NewFollowsService(db *sql.DB, logger *slog.Logger) *FollowsService {
+	return &FollowsService{
+		db:     database.Wrap(db),
+		logger: logger,
+	}
+}
+
+// Follow makes followerID follow followeeID. Following someone twice is not an
+// error; the existing follow is returned.
+func (s *FollowsService) Follow(ctx context.Context, followerID, followeeID int) (_ models.Follow, err error) {
+	s.logger.DebugContext(ctx, "Following user", slog.Int("follower_id", followerID), slog.Int("followee_id", followeeID))
+	ctx, span := startOperation(ctx, "follows", "follow")
+	defer func() { endOperation(span, err) }()
+
+	if followerID == followeeID {
+		return models.Follow{}, fmt.Errorf("users cannot follow themselves")
+	}
+
+	follow := models.Follow{FollowerID: followerID, FolloweeID: followeeID}
+	err = s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO follows (follower_id, followee_id, created_date)
+         VALUES ($1, $2, $3)
+         ON CONFLICT (follower_id, followee_id)
+         DO UPDATE SET follower_id = EXCLUDED.follower_id
+         RETURNING created_date`,
+		followerID, followeeID, time.Now(),
+	).Scan(&follow.CreatedDate)
+	if err != nil {
+		return models.Follow{}, fmt.Errorf("failed to follow user: %w", err)
+	}
+
+	return follow, nil
+}
+
+// Unfollow stops followerID following followeeID.
+func (s *FollowsService) Unfollow(ctx context.Context, followerID, followeeID int) (err error) {
+	s.logger.DebugContext(ctx, "Unfollowing user", slog.Int("follower_id", followerID), slog.Int("followee_id", followeeID))
+	ctx, span := startOperation(ctx, "follows", "unfollow")
+	defer func() { endOperation(span, err) }()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`,
+		followerID, followeeID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no follow found")
+	}
+
+	return nil
+}
+
+// ListFollowers retrieves the users following userID, most recent first.
+func (s *FollowsService) ListFollowers(ctx context.Context, userID int) (_ []models.FollowUser, err error) {
+	s.logger.DebugContext(ctx, "Listing followers", slog.Int("user_id", userID))
+	ctx, span := startOperation(ctx, "follows", "list_followers")
+	defer func() { endOperation(span, err) }()
+
+	return s.listFollowUsers(ctx, `
+        SELECT u.id, u.name, f.created_date
+        FROM follows f
+        JOIN users u ON u.id = f.follower_id
+        WHERE f.followee_id = $1
+        ORDER BY f.created_date DESC
+        `, userID)
+}
+
+// ListFollowing retrieves the users userID follows, most recent first.
+func (s *FollowsService) ListFollowing(ctx context.Context, userID int) (_ []models.FollowUser, err error) {
+	s.logger.DebugContext(ctx, "Listing following", slog.Int("user_id", userID))
+	ctx, span := startOperation(ctx, "follows", "list_following")
+	defer func() { endOperation(span, err) }()
+
+	return s.listFollowUsers(ctx, `
+        SELECT u.id, u.name, f.created_date
+        FROM follows f
+        JOIN users u ON u.id = f.followee_id
+        WHERE f.follower_id = $1
+        ORDER BY f.created_date DESC
+        `, userID)
+}
+
+func (s *FollowsService) listFollowUsers(ctx context.Context, query string, userID int) ([]models.FollowUser, error) {
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list follows: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.FollowUser{}
+	for rows.Next() {
+		var u models.FollowUser
+		if err := rows.Scan(&u.ID, &u.Name, &u.FollowedDate); err != nil {
+			return nil, fmt.Errorf("failed to scan follow: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return users, nil
+}
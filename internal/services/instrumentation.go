@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"github.com/navid/blog/internal/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// operations counts calls to each service operation.
+var operations = metrics.Default.NewCounterVec(
+	"service_operations_total",
+	"Total number of service operations performed, by service and operation.",
+	"service", "operation",
+)
+
+var tracer = otel.Tracer("github.com/navid/blog/internal/services")
+
+// startOperation counts a service operation and starts a span covering it and
+// the queries it runs. The span must be ended with endOperation when the
+// operation returns.
+func startOperation(ctx context.Context, service, operation string) (context.Context, trace.Span) {
+	operations.With(service, operation).Inc()
+
+	return tracer.Start(ctx, service+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "postgresql"),
+			attribute.String("service.operation", operation),
+		),
+	)
+}
+
+// endOperation ends an operation's span, marking it failed with err if the
+// operation returned an error.
+func endOperation(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEndOperation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	testcases := map[string]struct {
+		err            error
+		expectedStatus codes.Code
+		expectedEvents int
+	}{
+		"success": {
+			expectedStatus: codes.Unset,
+		},
+		"failure": {
+			err:            errors.New("failed to read user: connection refused"),
+			expectedStatus: codes.Error,
+			expectedEvents: 1,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, span := startOperation(context.Background(), "users", "read_user")
+			endOperation(span, tc.err)
+
+			ended := recorder.Ended()
+			got := ended[len(ended)-1]
+			if got.Status().Code != tc.expectedStatus {
+				t.Errorf("expected status %v, got %v", tc.expectedStatus, got.Status().Code)
+			}
+			if tc.err != nil && got.Status().Description != tc.err.Error() {
+				t.Errorf("expected description %q, got %q", tc.err.Error(), got.Status().Description)
+			}
+			if len(got.Events()) != tc.expectedEvents {
+				t.Errorf("expected %d events, got %d", tc.expectedEvents, len(got.Events()))
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/navid/blog/internal/database"
+	"github.com/navid/blog/internal/filters"
+	"github.com/navid/blog/internal/models"
+)
+
+// ModerationService runs submitted content through the filter chain, records
+// every decision that isn't a plain Allow, and lets moderators approve or
+// reject flagged content. Moderator decisions are fed back into the Bayes
+// scorer.
+type ModerationService struct {
+	db     *database.DB
+	logger *slog.Logger
+	chain  filters.Chain
+	bayes  *filters.Bayes
+}
+
+// NewModerationService creates a new ModerationService. bayes may be nil if no
+// scorer is part of the chain.
+func NewModerationService(db *sql.DB, logger *slog.Logger, chain filters.Chain, bayes *filters.Bayes) *ModerationService {
+	return &ModerationService{
+		db:     database.Wrap(db),
+		logger: logger,
+		chain:  chain,
+		bayes:  bayes,
+	}
+}
+
+const moderationColumns = `id, kind, user_id, text, payload, verdict, filter, reason, status, created_date, decided_date`
+
+// Screen runs content through the filter chain. Flagged and rejected content
+// is written to the moderation queue together with payload, the original
+// request model, so it can be published later if a moderator approves it.
+// The queue id is returned for flagged and rejected content. Allowed content
+// isn't recorded until the caller has stored it and calls Record.
+func (s *ModerationService) Screen(ctx context.Context, content filters.Content, payload any) (_ filters.Decision, _ uint, err error) {
+	s.logger.DebugContext(ctx, "Screening content", slog.String("kind", string(content.Kind)), slog.Int("user_id", content.UserID))
+	ctx, span := startOperation(ctx, "moderation", "screen")
+	defer func() { endOperation(span, err) }()
+
+	decision, err := s.chain.Check(ctx, content)
+	if err != nil {
+		return filters.Decision{}, 0, fmt.Errorf("[in services.ModerationService.Screen] %w", err)
+	}
+
+	if decision.Verdict == filters.Allow {
+		return decision, 0, nil
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return filters.Decision{}, 0, fmt.Errorf("[in services.ModerationService.Screen] failed to marshal payload: %w", err)
+	}
+
+	// Rejected content never reaches a moderator, so it is stored as already
+	// decided.
+	now := time.Now()
+	status := models.ModerationPending
+	var decided *time.Time
+	if decision.Verdict == filters.Reject {
+		status = models.ModerationRejected
+		decided = &now
+	}
+
+	var id uint
+	err = s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO moderation_queue (kind, user_id, text, payload, verdict, filter, reason, status, created_date, decided_date)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+         RETURNING id`,
+		string(content.Kind), content.UserID, content.Text, raw,
+		decision.Verdict.String(), decision.Filter, decision.Reason, status, now, decided,
+	).Scan(&id)
+	if err != nil {
+		return filters.Decision{}, 0, fmt.Errorf("[in services.ModerationService.Screen] failed to record decision: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "content held by filter",
+		slog.Uint64("id", uint64(id)),
+		slog.String("verdict", decision.Verdict.String()),
+		slog.String("filter", decision.Filter),
+		slog.String("reason", decision.Reason))
+
+	return decision, id, nil
+}
+
+// Record keeps content that passed Screen and has been stored in the
+// chain's history-keeping filters.
+func (s *ModerationService) Record(ctx context.Context, content filters.Content) {
+	s.chain.Record(ctx, content)
+}
+
+// ListModeration retrieves moderation queue entries, optionally filtering by
+// status. Entries are returned oldest first so moderators work through the
+// queue in order.
+func (s *ModerationService) ListModeration(ctx context.Context, status string) (_ []models.ModerationItem, err error) {
+	s.logger.DebugContext(ctx, "Listing moderation queue", slog.String("status", status))
+	ctx, span := startOperation(ctx, "moderation", "list_moderation")
+	defer func() { endOperation(span, err) }()
+
+	query := `SELECT ` + moderationColumns + ` FROM moderation_queue`
+	var args []interface{}
+	if status != "" {
+		query += ` WHERE status = $1`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_date, id`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderation queue: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.ModerationItem
+	for rows.Next() {
+		item, err := scanModerationItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan moderation item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return items, nil
+}
+
+// Decide records a moderator's decision on a pending item and trains the Bayes
+// scorer with it. Approved items are passed to publish in the same
+// transaction as the decision, so an item whose content can't be published
+// stays pending and can be decided again. An error is returned if the item
+// doesn't exist or has already been decided.
+func (s *ModerationService) Decide(ctx context.Context, id uint, approve bool, publish func(ctx context.Context, item models.ModerationItem) error) (_ models.ModerationItem, err error) {
+	s.logger.DebugContext(ctx, "Deciding moderation item", slog.Uint64("id", uint64(id)), slog.Bool("approve", approve))
+	ctx, span := startOperation(ctx, "moderation", "decide")
+	defer func() { endOperation(span, err) }()
+
+	status := models.ModerationRejected
+	if approve {
+		status = models.ModerationApproved
+	}
+
+	var item models.ModerationItem
+	err = s.db.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		item, err = scanModerationItem(s.db.QueryRowContext(
+			ctx,
+			`UPDATE moderation_queue
+             SET status = $1, decided_date = $2
+             WHERE id = $3 AND status = $4
+             RETURNING `+moderationColumns,
+			status, time.Now(), id, models.ModerationPending,
+		))
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no pending moderation item found with id: %d", id)
+		} else if err != nil {
+			return fmt.Errorf("failed to decide moderation item: %w", err)
+		}
+
+		if approve && publish != nil {
+			if err := publish(ctx, item); err != nil {
+				return fmt.Errorf("failed to publish approved content: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.ModerationItem{}, err
+	}
+
+	if s.bayes != nil {
+		s.bayes.Train(item.Text, !approve)
+	}
+	if approve {
+		s.chain.Record(ctx, filters.Content{Kind: filters.Kind(item.Kind), UserID: item.UserID, Text: item.Text})
+	}
+
+	return item, nil
+}
+
+// Train replays every past moderator decision into the Bayes scorer. It is
+// meant to be called once at startup since the scorer only lives in memory.
+func (s *ModerationService) Train(ctx context.Context) (err error) {
+	ctx, span := startOperation(ctx, "moderation", "train")
+	defer func() { endOperation(span, err) }()
+
+	if s.bayes == nil {
+		return nil
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT text, status FROM moderation_queue WHERE verdict = $1 AND status <> $2`,
+		filters.Flag.String(), models.ModerationPending,
+	)
+	if err != nil {
+		return fmt.Errorf("[in services.ModerationService.Train] failed to load decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var n int
+	for rows.Next() {
+		var text, status string
+		if err := rows.Scan(&text, &status); err != nil {
+			return fmt.Errorf("[in services.ModerationService.Train] failed to scan decision: %w", err)
+		}
+		s.bayes.Train(text, status == models.ModerationRejected)
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("[in services.ModerationService.Train] rows iteration error: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "spam scorer trained", slog.Int("decisions", n))
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanModerationItem(row rowScanner) (models.ModerationItem, error) {
+	var item models.ModerationItem
+	var payload []byte
+	var decided sql.NullTime
+	err := row.Scan(
+		&item.ID, &item.Kind, &item.UserID, &item.Text, &payload,
+		&item.Verdict, &item.Filter, &item.Reason, &item.Status,
+		&item.CreatedDate, &decided,
+	)
+	if err != nil {
+		return models.ModerationItem{}, err
+	}
+	item.Payload = payload
+	if decided.Valid {
+		item.DecidedDate = &decided.Time
+	}
+	return item, nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/navid/blog/internal/database"
+	"github.com/navid/blog/internal/models"
+)
+
+// ReactionsService is a service capable of adding, removing and listing
+// reactions on blogs and comments. Only reaction types from a fixed set are
+// accepted.
+type ReactionsService struct {
+	db     *database.DB
+	logger *slog.Logger
+	types  []string
+}
+
+// NewReactionsService creates a new ReactionsService accepting the provided
+// reaction types.
+func NewReactionsService(db *sql.DB, logger *slog.Logger, types []string) *ReactionsService {
+	return &ReactionsService{
+		db:     database.Wrap(db),
+		logger: logger,
+		types:  types,
+	}
+}
+
+// reactionCounts selects the per-type reaction counts of the blog (when
+// commentUserID is the literal 0) or comment identified by the provided SQL
+// expressions, as a JSON object.
+func reactionCounts(blogID, commentUserID string) string {
+	return `(SELECT COALESCE(jsonb_object_agg(type, n), '{}'::jsonb)
+             FROM (SELECT type, COUNT(*) AS n
+                   FROM reactions
+                   WHERE blog_id = ` + blogID + ` AND comment_user_id = ` + commentUserID + `
+                   GROUP BY type) AS counts)`
+}
+
+// decodeReactionCounts turns the JSON produced by reactionCounts into a map,
+// returning nil when there are no reactions.
+func decodeReactionCounts(raw []byte) (map[string]int, error) {
+	var counts map[string]int
+	if err := json.Unmarshal(raw, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode reaction counts: %w", err)
+	}
+	if len(counts) == 0 {
+		return nil, nil
+	}
+	return counts, nil
+}
+
+// Types returns the accepted reaction types.
+func (s *ReactionsService) Types() []string {
+	return slices.Clone(s.types)
+}
+
+// React adds a reaction of the provided type from userID to the target.
+// Reacting twice with the same type is not an error; the existing reaction is
+// returned.
+func (s *ReactionsService) React(ctx context.Context, target models.ReactionTarget, userID int, reactionType string) (_ models.Reaction, err error) {
+	s.logger.DebugContext(ctx, "Adding reaction",
+		slog.Int("blog_id", target.BlogID),
+		slog.Int("comment_user_id", target.CommentUserID),
+		slog.Int("user_id", userID),
+		slog.String("type", reactionType))
+	ctx, span := startOperation(ctx, "reactions", "react")
+	defer func() { endOperation(span, err) }()
+
+	if !slices.Contains(s.types, reactionType) {
+		return models.Reaction{}, fmt.Errorf("unknown reaction type: %s", reactionType)
+	}
+
+	if err := s.checkTarget(ctx, target); err != nil {
+		return models.Reaction{}, err
+	}
+
+	reaction := models.Reaction{
+		UserID:        userID,
+		BlogID:        target.BlogID,
+		CommentUserID: target.CommentUserID,
+		Type:          reactionType,
+	}
+
+	// DO UPDATE rather than DO NOTHING so the existing row is returned
+	err = s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO reactions (blog_id, comment_user_id, user_id, type, created_date)
+         VALUES ($1, $2, $3, $4, $5)
+         ON CONFLICT (blog_id, comment_user_id, user_id, type)
+         DO UPDATE SET type = EXCLUDED.type
+         RETURNING created_date`,
+		target.BlogID, target.CommentUserID, userID, reactionType, time.Now(),
+	).Scan(&reaction.CreatedDate)
+	if err != nil {
+		return models.Reaction{}, fmt.Errorf("failed to add reaction: %w", err)
+	}
+
+	return reaction, nil
+}
+
+// Unreact removes a reaction of the provided type from userID on the target.
+func (s *ReactionsService) Unreact(ctx context.Context, target models.ReactionTarget, userID int, reactionType string) (err error) {
+	s.logger.DebugContext(ctx, "Removing reaction",
+		slog.Int("blog_id", target.BlogID),
+		slog.Int("comment_user_id", target.CommentUserID),
+		slog.Int("user_id", userID),
+		slog.String("type", reactionType))
+	ctx, span := startOperation(ctx, "reactions", "unreact")
+	defer func() { endOperation(span, err) }()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM reactions
+         WHERE blog_id = $1 AND comment_user_id = $2 AND user_id = $3 AND type = $4`,
+		target.BlogID, target.CommentUserID, userID, reactionType,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no reaction found")
+	}
+
+	return nil
+}
+
+// ListReactions retrieves who reacted to the target, newest first, optionally
+// filtering by reaction type.
+func (s *ReactionsService) ListReactions(ctx context.Context, target models.ReactionTarget, reactionType string) (_ []models.Reaction, err error) {
+	s.logger.DebugContext(ctx, "Listing reactions",
+		slog.Int("blog_id", target.BlogID),
+		slog.Int("comment_user_id", target.CommentUserID),
+		slog.String("type", reactionType))
+	ctx, span := startOperation(ctx, "reactions", "list_reactions")
+	defer func() { endOperation(span, err) }()
+
+	if err := s.checkTarget(ctx, target); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT r.user_id, u.name, r.blog_id, r.comment_user_id, r.type, r.created_date
+              FROM reactions r
+              JOIN users u ON u.id = r.user_id
+              WHERE r.blog_id = $1 AND r.comment_user_id = $2`
+	args := []interface{}{target.BlogID, target.CommentUserID}
+
+	if reactionType != "" {
+		query += ` AND r.type = $3`
+		args = append(args, reactionType)
+	}
+	query += ` ORDER BY r.created_date DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reactions: %w", err)
+	}
+	defer rows.Close()
+
+	var reactions []models.Reaction
+	for rows.Next() {
+		var r models.Reaction
+		if err := rows.Scan(&r.UserID, &r.UserName, &r.BlogID, &r.CommentUserID, &r.Type, &r.CreatedDate); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction: %w", err)
+		}
+		reactions = append(reactions, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return reactions, nil
+}
+
+// checkTarget returns an error if the blog or comment being reacted to
+// doesn't exist.
+func (s *ReactionsService) checkTarget(ctx context.Context, target models.ReactionTarget) error {
+	var exists bool
+	var err error
+	if target.IsComment() {
+		err = s.db.QueryRowContext(
+			ctx,
+			`SELECT EXISTS(SELECT 1 FROM comments WHERE user_id = $1 AND blog_id = $2)`,
+			target.CommentUserID, target.BlogID,
+		).Scan(&exists)
+	} else {
+		err = s.db.QueryRowContext(
+			ctx,
+			`SELECT EXISTS(SELECT 1 FROM blogs WHERE id = $1)`,
+			target.BlogID,
+		).Scan(&exists)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check reaction target: %w", err)
+	}
+
+	if !exists {
+		if target.IsComment() {
+			return fmt.Errorf("no comment found with user_id: %d and blog_id: %d", target.CommentUserID, target.BlogID)
+		}
+		return fmt.Errorf("no blog found with id: %d", target.BlogID)
+	}
+
+	return nil
+}
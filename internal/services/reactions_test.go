@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/navid/blog/internal/models"
+)
+
+func TestReactionsService_React(t *testing.T) {
+	testcases := map[string]struct {
+		target        models.ReactionTarget
+		reactionType  string
+		existsQuery   string
+		existsArgs    []driver.Value
+		exists        bool
+		expectedError error
+	}{
+		"react to blog": {
+			target:       models.ReactionTarget{BlogID: 2},
+			reactionType: "like",
+			existsQuery:  `SELECT EXISTS(SELECT 1 FROM blogs WHERE id = $1)`,
+			existsArgs:   []driver.Value{2},
+			exists:       true,
+		},
+		"react to comment": {
+			target:       models.ReactionTarget{BlogID: 2, CommentUserID: 4},
+			reactionType: "love",
+			existsQuery:  `SELECT EXISTS(SELECT 1 FROM comments WHERE user_id = $1 AND blog_id = $2)`,
+			existsArgs:   []driver.Value{4, 2},
+			exists:       true,
+		},
+		"missing comment": {
+			target:        models.ReactionTarget{BlogID: 2, CommentUserID: 4},
+			reactionType:  "love",
+			existsQuery:   `SELECT EXISTS(SELECT 1 FROM comments WHERE user_id = $1 AND blog_id = $2)`,
+			existsArgs:    []driver.Value{4, 2},
+			exists:        false,
+			expectedError: fmt.Errorf("no comment found with user_id: 4 and blog_id: 2"),
+		},
+		"unknown type": {
+			target:        models.ReactionTarget{BlogID: 2},
+			reactionType:  "shrug",
+			expectedError: fmt.Errorf("unknown reaction type: shrug"),
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			if tc.existsQuery != "" {
+				mock.ExpectQuery(regexp.QuoteMeta(tc.existsQuery)).
+					WithArgs(tc.existsArgs...).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.exists))
+			}
+			if tc.expectedError == nil {
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO reactions`)).
+					WithArgs(tc.target.BlogID, tc.target.CommentUserID, 1, tc.reactionType, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"created_date"}).AddRow(parseTime("2024-05-15T10:00:00Z")))
+			}
+
+			reactionsService := NewReactionsService(db, slog.Default(), []string{"like", "love"})
+
+			output, err := reactionsService.React(context.TODO(), tc.target, 1, tc.reactionType)
+			if err != nil && tc.expectedError != nil && err.Error() != tc.expectedError.Error() {
+				t.Errorf("expected error %v, got %v", tc.expectedError, err)
+			} else if err == nil && tc.expectedError != nil {
+				t.Errorf("expected error %v, got nil", tc.expectedError)
+			} else if err != nil && tc.expectedError == nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if tc.expectedError == nil && (output.Type != tc.reactionType || output.BlogID != tc.target.BlogID || output.CommentUserID != tc.target.CommentUserID) {
+				t.Errorf("unexpected reaction %+v", output)
+			}
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
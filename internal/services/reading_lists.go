@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/navid/blog/internal/database"
+	"github.com/navid/blog/internal/models"
+)
+
+// ReadingListsService is a service capable of managing users' named reading
+// lists and the ordered blogs in them.
+type ReadingListsService struct {
+	db     *database.DB
+	logger *slog.Logger
+}
+
+// NewReadingListsService creates a new ReadingListsService.
+func NewReadingListsService(db *sql.DB, logger *slog.Logger) *ReadingListsService {
+	return &ReadingListsService{
+		db:     database.Wrap(db),
+		logger: logger,
+	}
+}
+
+// ListReadingLists retrieves a user's reading lists without their items.
+func (s *ReadingListsService) ListReadingLists(ctx context.Context, userID int) (_ []models.ReadingList, err error) {
+	s.logger.DebugContext(ctx, "Listing reading lists", slog.Int("user_id", userID))
+	ctx, span := startOperation(ctx, "reading_lists", "list_reading_lists")
+	defer func() { endOperation(span, err) }()
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT id, user_id, name, public, created_date
+         FROM reading_lists
+         WHERE user_id = $1
+         ORDER BY created_date, id`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reading lists: %w", err)
+	}
+	defer rows.Close()
+
+	lists := []models.ReadingList{}
+	for rows.Next() {
+		var l models.ReadingList
+		if err := rows.Scan(&l.ID, &l.UserID, &l.Name, &l.Public, &l.CreatedDate); err != nil {
+			return nil, fmt.Errorf("failed to scan reading list: %w", err)
+		}
+		lists = append(lists, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return lists, nil
+}
+
+// CreateReadingList creates an empty reading list.
+func (s *ReadingListsService) CreateReadingList(ctx context.Context, list models.ReadingList) (_ models.ReadingList, err error) {
+	s.logger.DebugContext(ctx, "Creating reading list", slog.Int("user_id", list.UserID), slog.String("name", list.Name))
+	ctx, span := startOperation(ctx, "reading_lists", "create_reading_list")
+	defer func() { endOperation(span, err) }()
+
+	var created models.ReadingList
+	err = s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO reading_lists (user_id, name, public, created_date)
+         VALUES ($1, $2, $3, $4)
+         RETURNING id, user_id, name, public, created_date`,
+		list.UserID, list.Name, list.Public, time.Now(),
+	).Scan(&created.ID, &created.UserID, &created.Name, &created.Public, &created.CreatedDate)
+	if err != nil {
+		return models.ReadingList{}, fmt.Errorf("failed to create reading list: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetReadingList retrieves a reading list and its items in order. Callers are
+// responsible for checking the list is public or owned by the requester.
+func (s *ReadingListsService) GetReadingList(ctx context.Context, id uint) (_ models.ReadingList, err error) {
+	s.logger.DebugContext(ctx, "Retrieving reading list", slog.Uint64("id", uint64(id)))
+	ctx, span := startOperation(ctx, "reading_lists", "get_reading_list")
+	defer func() { endOperation(span, err) }()
+
+	var list models.ReadingList
+	err = s.db.QueryRowContext(
+		ctx,
+		`SELECT id, user_id, name, public, created_date FROM reading_lists WHERE id = $1`,
+		id,
+	).Scan(&list.ID, &list.UserID, &list.Name, &list.Public, &list.CreatedDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ReadingList{}, fmt.Errorf("no reading list found with id: %d", id)
+	} else if err != nil {
+		return models.ReadingList{}, fmt.Errorf("failed to retrieve reading list: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT i.blog_id, b.title, i.position, i.note, i.added_date
+         FROM reading_list_items i
+         JOIN blogs b ON b.id = i.blog_id
+         WHERE i.list_id = $1
+         ORDER BY i.position`,
+		id,
+	)
+	if err != nil {
+		return models.ReadingList{}, fmt.Errorf("failed to list reading list items: %w", err)
+	}
+	defer rows.Close()
+
+	list.Items = []models.ReadingListItem{}
+	for rows.Next() {
+		var item models.ReadingListItem
+		if err := rows.Scan(&item.BlogID, &item.BlogTitle, &item.Position, &item.Note, &item.AddedDate); err != nil {
+			return models.ReadingList{}, fmt.Errorf("failed to scan reading list item: %w", err)
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.ReadingList{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return list, nil
+}
+
+// UpdateReadingList renames a user's reading list and sets whether it is
+// public.
+func (s *ReadingListsService) UpdateReadingList(ctx context.Context, userID int, id uint, patch models.ReadingList) (_ models.ReadingList, err error) {
+	s.logger.DebugContext(ctx, "Updating reading list", slog.Uint64("id", uint64(id)))
+	ctx, span := startOperation(ctx, "reading_lists", "update_reading_list")
+	defer func() { endOperation(span, err) }()
+
+	var updated models.ReadingList
+	err = s.db.QueryRowContext(
+		ctx,
+		`UPDATE reading_lists
+         SET name = $1, public = $2
+         WHERE id = $3 AND user_id = $4
+         RETURNING id, user_id, name, public, created_date`,
+		patch.Name, patch.Public, id, userID,
+	).Scan(&updated.ID, &updated.UserID, &updated.Name, &updated.Public, &updated.CreatedDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ReadingList{}, fmt.Errorf("no reading list found with id: %d", id)
+	} else if err != nil {
+		return models.ReadingList{}, fmt.Errorf("failed to update reading list: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteReadingList deletes a user's reading list and its items.
+func (s *ReadingListsService) DeleteReadingList(ctx context.Context, userID int, id uint) (err error) {
+	s.logger.DebugContext(ctx, "Deleting reading list", slog.Uint64("id", uint64(id)))
+	ctx, span := startOperation(ctx, "reading_lists", "delete_reading_list")
+	defer func() { endOperation(span, err) }()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM reading_lists WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete reading list: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no reading list found with id: %d", id)
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM reading_list_items WHERE list_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete reading list items: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reading list deletion: %w", err)
+	}
+
+	return nil
+}
+
+// SaveItem adds a blog to a user's reading list, or moves it and updates its
+// note if it is already there. The item is placed at item.Position, shifting
+// later items down; a zero or out of range position appends it to the end.
+func (s *ReadingListsService) SaveItem(ctx context.Context, userID int, listID uint, item models.ReadingListItem) (_ models.ReadingListItem, err error) {
+	s.logger.DebugContext(ctx, "Saving reading list item", slog.Uint64("list_id", uint64(listID)), slog.Int("blog_id", item.BlogID))
+	ctx, span := startOperation(ctx, "reading_lists", "save_item")
+	defer func() { endOperation(span, err) }()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ReadingListItem{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = lockReadingList(ctx, tx, userID, listID); err != nil {
+		return models.ReadingListItem{}, err
+	}
+
+	var exists bool
+	if err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM blogs WHERE id = $1)`, item.BlogID).Scan(&exists); err != nil {
+		return models.ReadingListItem{}, fmt.Errorf("failed to check blog existence: %w", err)
+	}
+	if !exists {
+		return models.ReadingListItem{}, fmt.Errorf("no blog found with id: %d", item.BlogID)
+	}
+
+	var count int
+	if err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM reading_list_items WHERE list_id = $1`, listID).Scan(&count); err != nil {
+		return models.ReadingListItem{}, fmt.Errorf("failed to count reading list items: %w", err)
+	}
+
+	// Take the item out of the sequence first if it is already in the list
+	var current int
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT position FROM reading_list_items WHERE list_id = $1 AND blog_id = $2`,
+		listID, item.BlogID,
+	).Scan(&current)
+	switch {
+	case err == nil:
+		if _, err = tx.ExecContext(
+			ctx,
+			`UPDATE reading_list_items SET position = position - 1
+             WHERE list_id = $1 AND position > $2 AND blog_id <> $3`,
+			listID, current, item.BlogID,
+		); err != nil {
+			return models.ReadingListItem{}, fmt.Errorf("failed to reorder reading list: %w", err)
+		}
+		count--
+	case !errors.Is(err, sql.ErrNoRows):
+		return models.ReadingListItem{}, fmt.Errorf("failed to retrieve reading list item: %w", err)
+	}
+
+	position := item.Position
+	if position < 1 || position > count+1 {
+		position = count + 1
+	}
+
+	if _, err = tx.ExecContext(
+		ctx,
+		`UPDATE reading_list_items SET position = position + 1
+         WHERE list_id = $1 AND position >= $2 AND blog_id <> $3`,
+		listID, position, item.BlogID,
+	); err != nil {
+		return models.ReadingListItem{}, fmt.Errorf("failed to reorder reading list: %w", err)
+	}
+
+	saved := models.ReadingListItem{BlogID: item.BlogID}
+	err = tx.QueryRowContext(
+		ctx,
+		`INSERT INTO reading_list_items (list_id, blog_id, position, note, added_date)
+         VALUES ($1, $2, $3, $4, $5)
+         ON CONFLICT (list_id, blog_id) DO UPDATE SET position = EXCLUDED.position, note = EXCLUDED.note
+         RETURNING position, note, added_date`,
+		listID, item.BlogID, position, item.Note, time.Now(),
+	).Scan(&saved.Position, &saved.Note, &saved.AddedDate)
+	if err != nil {
+		return models.ReadingListItem{}, fmt.Errorf("failed to save reading list item: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.ReadingListItem{}, fmt.Errorf("failed to commit reading list item: %w", err)
+	}
+
+	return saved, nil
+}
+
+// RemoveItem removes a blog from a user's reading list, closing the gap it
+// leaves.
+func (s *ReadingListsService) RemoveItem(ctx context.Context, userID int, listID uint, blogID int) (err error) {
+	s.logger.DebugContext(ctx, "Removing reading list item", slog.Uint64("list_id", uint64(listID)), slog.Int("blog_id", blogID))
+	ctx, span := startOperation(ctx, "reading_lists", "remove_item")
+	defer func() { endOperation(span, err) }()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = lockReadingList(ctx, tx, userID, listID); err != nil {
+		return err
+	}
+
+	var position int
+	err = tx.QueryRowContext(
+		ctx,
+		`DELETE FROM reading_list_items WHERE list_id = $1 AND blog_id = $2 RETURNING position`,
+		listID, blogID,
+	).Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no reading list item found")
+	} else if err != nil {
+		return fmt.Errorf("failed to remove reading list item: %w", err)
+	}
+
+	if _, err = tx.ExecContext(
+		ctx,
+		`UPDATE reading_list_items SET position = position - 1 WHERE list_id = $1 AND position > $2`,
+		listID, position,
+	); err != nil {
+		return fmt.Errorf("failed to reorder reading list: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reading list item removal: %w", err)
+	}
+
+	return nil
+}
+
+// lockReadingList checks the list exists and belongs to userID, locking it so
+// concurrent edits to its order are serialized.
+func lockReadingList(ctx context.Context, tx database.Queryer, userID int, listID uint) error {
+	var id uint
+	err := tx.QueryRowContext(
+		ctx,
+		`SELECT id FROM reading_lists WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		listID, userID,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no reading list found with id: %d", listID)
+	} else if err != nil {
+		return fmt.Errorf("failed to retrieve reading list: %w", err)
+	}
+	return nil
+}
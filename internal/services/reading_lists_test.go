@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/navid/blog/internal/models"
+)
+
+func TestReadingListsService_SaveItem(t *testing.T) {
+	testcases := map[string]struct {
+		item          models.ReadingListItem
+		notOwned      bool
+		noBlog        bool
+		count         int
+		current       int
+		wantPosition  int
+		expectedError error
+	}{
+		"append to the end": {
+			item:         models.ReadingListItem{BlogID: 4},
+			count:        2,
+			wantPosition: 3,
+		},
+		"insert at the front": {
+			item:         models.ReadingListItem{BlogID: 4, Position: 1, Note: "first"},
+			count:        2,
+			wantPosition: 1,
+		},
+		"out of range position appends": {
+			item:         models.ReadingListItem{BlogID: 4, Position: 9},
+			count:        2,
+			wantPosition: 3,
+		},
+		"move an item to the end": {
+			item:         models.ReadingListItem{BlogID: 4, Position: 3},
+			count:        3,
+			current:      1,
+			wantPosition: 3,
+		},
+		"move an item without a position to the end": {
+			item:         models.ReadingListItem{BlogID: 4},
+			count:        3,
+			current:      2,
+			wantPosition: 3,
+		},
+		"another user's list": {
+			item:          models.ReadingListItem{BlogID: 4},
+			notOwned:      true,
+			expectedError: fmt.Errorf("no reading list found with id: 1"),
+		},
+		"missing blog": {
+			item:          models.ReadingListItem{BlogID: 4},
+			noBlog:        true,
+			expectedError: fmt.Errorf("no blog found with id: 4"),
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			lock := mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM reading_lists WHERE id = $1 AND user_id = $2 FOR UPDATE`)).
+				WithArgs(1, 7)
+			if tc.notOwned {
+				lock.WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			} else {
+				lock.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM blogs WHERE id = $1)`)).
+					WithArgs(tc.item.BlogID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(!tc.noBlog))
+			}
+
+			if tc.expectedError == nil {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM reading_list_items WHERE list_id = $1`)).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tc.count))
+
+				current := mock.ExpectQuery(regexp.QuoteMeta(`SELECT position FROM reading_list_items WHERE list_id = $1 AND blog_id = $2`)).
+					WithArgs(1, tc.item.BlogID)
+				if tc.current == 0 {
+					current.WillReturnError(sql.ErrNoRows)
+				} else {
+					current.WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(tc.current))
+					// The item leaves its place, closing the gap behind it
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE reading_list_items SET position = position - 1`)).
+						WithArgs(1, tc.current, tc.item.BlogID).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+
+				// and later items make room at its new position
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE reading_list_items SET position = position + 1`)).
+					WithArgs(1, tc.wantPosition, tc.item.BlogID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO reading_list_items`)).
+					WithArgs(1, tc.item.BlogID, tc.wantPosition, tc.item.Note, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"position", "note", "added_date"}).
+						AddRow(tc.wantPosition, tc.item.Note, parseTime("2024-05-15T10:00:00Z")))
+				mock.ExpectCommit()
+			} else if !tc.notOwned {
+				mock.ExpectRollback()
+			}
+
+			readingListsService := NewReadingListsService(db, slog.Default())
+
+			output, err := readingListsService.SaveItem(context.TODO(), 7, 1, tc.item)
+			if tc.expectedError != nil {
+				if err == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected error %v, got %v", tc.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			} else if output.Position != tc.wantPosition || output.BlogID != tc.item.BlogID {
+				t.Errorf("expected blog %d at position %d, got %+v", tc.item.BlogID, tc.wantPosition, output)
+			}
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestReadingListsService_RemoveItem(t *testing.T) {
+	testcases := map[string]struct {
+		position      int
+		expectedError error
+	}{
+		"remove an item": {
+			position: 2,
+		},
+		"missing item": {
+			expectedError: fmt.Errorf("no reading list item found"),
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM reading_lists WHERE id = $1 AND user_id = $2 FOR UPDATE`)).
+				WithArgs(1, 7).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			remove := mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM reading_list_items WHERE list_id = $1 AND blog_id = $2 RETURNING position`)).
+				WithArgs(1, 4)
+			if tc.expectedError != nil {
+				remove.WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			} else {
+				remove.WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(tc.position))
+				// Later items move up to close the gap
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE reading_list_items SET position = position - 1 WHERE list_id = $1 AND position > $2`)).
+					WithArgs(1, tc.position).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+
+			readingListsService := NewReadingListsService(db, slog.Default())
+
+			err = readingListsService.RemoveItem(context.TODO(), 7, 1, 4)
+			if tc.expectedError != nil {
+				if err == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected error %v, got %v", tc.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
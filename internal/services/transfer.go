@@ -0,0 +1,397 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/navid/blog/internal/database"
+	"github.com/navid/blog/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// exportTime formats a TIMESTAMP column as RFC 3339, so exported files can
+// be imported again.
+const exportTime = `to_char(%s, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')`
+
+// exports are the files of an export archive and the queries that fill them.
+// Each file has the columns an import of its kind reads.
+var exports = []struct {
+	file  string
+	query string
+}{
+	{
+		file:  "users.csv",
+		query: `SELECT id, name, email, password FROM users ORDER BY id`,
+	},
+	{
+		file: "blogs.csv",
+		query: `SELECT b.id, b.title, b.author_id, ` + fmt.Sprintf(exportTime, "b.created_date") + ` AS created_date,
+                       COALESCE((SELECT json_agg(t.tag ORDER BY t.tag) FROM blog_tags t WHERE t.blog_id = b.id), '[]') AS tags
+                FROM blogs b ORDER BY b.id`,
+	},
+	{
+		file:  "comments.csv",
+		query: `SELECT user_id, blog_id, message, ` + fmt.Sprintf(exportTime, "created_date") + ` AS created_date FROM comments ORDER BY blog_id, user_id`,
+	},
+}
+
+// exportManifest describes an export archive. It is written last, as
+// manifest.json.
+type exportManifest struct {
+	SchemaVersion int              `json:"schema_version"`
+	ExportedAt    time.Time        `json:"exported_at"`
+	Rows          map[string]int64 `json:"rows"`
+}
+
+// TransferService imports users, blogs and comments in bulk and exports them
+// as an archive.
+type TransferService struct {
+	db     *sql.DB
+	logger *slog.Logger
+	// copyFrom copies rows into a table over conn, in the transaction open
+	// on it.
+	copyFrom func(ctx context.Context, conn *sql.Conn, table string, columns []string, rows [][]any) error
+}
+
+// NewTransferService creates a new TransferService.
+func NewTransferService(db *sql.DB, logger *slog.Logger) *TransferService {
+	return &TransferService{db: db, logger: logger, copyFrom: copyFrom}
+}
+
+// copyFrom copies rows into table with COPY, which needs the pgx connection
+// under conn.
+func copyFrom(ctx context.Context, conn *sql.Conn, table string, columns []string, rows [][]any) error {
+	return conn.Raw(func(driverConn any) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("import requires a pgx connection, got %T", driverConn)
+		}
+		_, err := pgxConn.Conn().CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+		return err
+	})
+}
+
+// importTable describes how rows of one kind are staged and written. Rows
+// are copied into a temporary staging table, checked there against the
+// stored rows and each other, and the rows without problems written from it
+// in bulk.
+type importTable struct {
+	// staging is the name of the temporary table and create the statement
+	// creating it. Every staging table has a body_row column holding the
+	// row's position in the body, and a problems column check fills in.
+	staging string
+	create  string
+	// columns are the columns copied into the staging table, body_row
+	// first, and values returns the rest of them for a row, or problems if
+	// it can't be written whatever is stored.
+	columns []string
+	values  func(value any) ([]any, map[string]string)
+	// check sets problems on the staged rows that can't be written,
+	// returning their row and problems.
+	check string
+	// write writes the staged rows without problems, the first statement
+	// reporting how many there were.
+	write []string
+}
+
+// importTables are the staging and writing of each kind of row, by type.
+var importTables = map[reflect.Type]importTable{
+	reflect.TypeFor[models.User](): {
+		staging: "import_users",
+		create: `CREATE TEMPORARY TABLE import_users (
+                     body_row INTEGER PRIMARY KEY, id BIGINT, name TEXT, email TEXT, password TEXT, problems JSONB
+                 ) ON COMMIT DROP`,
+		columns: []string{"body_row", "id", "name", "email", "password"},
+		values: func(value any) ([]any, map[string]string) {
+			v := value.(models.User)
+			// Exports carry password hashes, so they are stored as they are,
+			// and anything else would be a password no one can log in with
+			if _, err := bcrypt.Cost([]byte(v.Password)); err != nil {
+				return nil, map[string]string{"password": "password must be a bcrypt hash, as exported"}
+			}
+			return []any{optionalID(v.ID), v.Name, v.Email, v.Password}, nil
+		},
+		check: `WITH checked AS (
+                    SELECT body_row, jsonb_strip_nulls(jsonb_build_object(
+                        '$', CASE WHEN id IS NOT NULL AND (
+                                      EXISTS (SELECT 1 FROM users u WHERE u.id = i.id)
+                                      OR lag(body_row) OVER (PARTITION BY id ORDER BY body_row) IS NOT NULL
+                                  ) THEN 'row already exists' END
+                    )) AS problems
+                    FROM import_users i
+                )
+                UPDATE import_users i SET problems = c.problems
+                FROM checked c
+                WHERE c.body_row = i.body_row AND c.problems <> '{}'
+                RETURNING i.body_row, i.problems`,
+		write: []string{
+			`INSERT INTO users (id, name, email, password)
+             SELECT COALESCE(id, nextval(pg_get_serial_sequence('users', 'id'))), name, email, password
+             FROM import_users WHERE problems IS NULL ORDER BY body_row`,
+		},
+	},
+	reflect.TypeFor[models.Blog](): {
+		staging: "import_blogs",
+		create: `CREATE TEMPORARY TABLE import_blogs (
+                     body_row INTEGER PRIMARY KEY, id BIGINT, title TEXT, author_id INTEGER, created_date TIMESTAMP, tags TEXT[], problems JSONB
+                 ) ON COMMIT DROP`,
+		columns: []string{"body_row", "id", "title", "author_id", "created_date", "tags"},
+		values: func(value any) ([]any, map[string]string) {
+			v := value.(models.Blog)
+			if v.CreatedAt.IsZero() {
+				v.CreatedAt = time.Now()
+			}
+			tags := models.NormalizeTags(v.Tags)
+			if tags == nil {
+				tags = []string{}
+			}
+			return []any{optionalID(v.ID), v.Title, v.AuthorID, v.CreatedAt, tags}, nil
+		},
+		check: `WITH checked AS (
+                    SELECT body_row, jsonb_strip_nulls(jsonb_build_object(
+                        'author_id', CASE WHEN NOT EXISTS (SELECT 1 FROM users u WHERE u.id = i.author_id)
+                                     THEN 'no user found with id: ' || author_id END,
+                        '$', CASE WHEN id IS NOT NULL AND (
+                                      EXISTS (SELECT 1 FROM blogs b WHERE b.id = i.id)
+                                      OR lag(body_row) OVER (PARTITION BY id ORDER BY body_row) IS NOT NULL
+                                  ) THEN 'row already exists' END
+                    )) AS problems
+                    FROM import_blogs i
+                )
+                UPDATE import_blogs i SET problems = c.problems
+                FROM checked c
+                WHERE c.body_row = i.body_row AND c.problems <> '{}'
+                RETURNING i.body_row, i.problems`,
+		write: []string{
+			// Blogs without ids get theirs first, so their tags can follow
+			`UPDATE import_blogs SET id = nextval(pg_get_serial_sequence('blogs', 'id'))
+             WHERE id IS NULL AND problems IS NULL`,
+			`INSERT INTO blogs (id, title, author_id, created_date)
+             SELECT id, title, author_id, created_date
+             FROM import_blogs WHERE problems IS NULL ORDER BY body_row`,
+			`INSERT INTO blog_tags (blog_id, tag)
+             SELECT id, unnest(tags) FROM import_blogs WHERE problems IS NULL`,
+		},
+	},
+	reflect.TypeFor[models.Comment](): {
+		staging: "import_comments",
+		create: `CREATE TEMPORARY TABLE import_comments (
+                     body_row INTEGER PRIMARY KEY, user_id BIGINT, blog_id BIGINT, message TEXT, created_date TIMESTAMP, problems JSONB
+                 ) ON COMMIT DROP`,
+		columns: []string{"body_row", "user_id", "blog_id", "message", "created_date"},
+		values: func(value any) ([]any, map[string]string) {
+			v := value.(models.Comment)
+			if v.CreatedDate.IsZero() {
+				v.CreatedDate = time.Now()
+			}
+			return []any{v.UserID, v.BlogID, v.Message, v.CreatedDate}, nil
+		},
+		check: `WITH checked AS (
+                    SELECT body_row, jsonb_strip_nulls(jsonb_build_object(
+                        'user_id', CASE WHEN NOT EXISTS (SELECT 1 FROM users u WHERE u.id = i.user_id)
+                                   THEN 'no user found with id: ' || user_id END,
+                        'blog_id', CASE WHEN NOT EXISTS (SELECT 1 FROM blogs b WHERE b.id = i.blog_id)
+                                   THEN 'no blog found with id: ' || blog_id END,
+                        '$', CASE WHEN EXISTS (SELECT 1 FROM comments c WHERE c.user_id = i.user_id AND c.blog_id = i.blog_id)
+                                       OR lag(body_row) OVER (PARTITION BY user_id, blog_id ORDER BY body_row) IS NOT NULL
+                                  THEN 'row already exists' END
+                    )) AS problems
+                    FROM import_comments i
+                )
+                UPDATE import_comments i SET problems = c.problems
+                FROM checked c
+                WHERE c.body_row = i.body_row AND c.problems <> '{}'
+                RETURNING i.body_row, i.problems`,
+		write: []string{
+			`INSERT INTO comments (user_id, blog_id, message, created_date)
+             SELECT user_id, blog_id, message, created_date
+             FROM import_comments WHERE problems IS NULL ORDER BY body_row`,
+		},
+	},
+}
+
+// Import writes rows, which must all be of one kind, in a single
+// transaction. Rows that can't be written are reported in the result rather
+// than failing the import; the transaction is only committed if this isn't a
+// dry run and, for an atomic import, every row was written. Rows keep the
+// ids they have, and get the next free one if they have none. Users must
+// have bcrypt hashes for passwords, as an export has. An error is returned
+// if the import couldn't run at all.
+func (s *TransferService) Import(ctx context.Context, rows []models.ImportRow, opts models.ImportOptions) (_ models.ImportResult, err error) {
+	s.logger.DebugContext(ctx, "Importing rows", "rows", len(rows), "mode", opts.Mode, "dry_run", opts.DryRun)
+	ctx, span := startOperation(ctx, "transfer", "import")
+	defer func() { endOperation(span, err) }()
+
+	result := models.ImportResult{Mode: opts.Mode, DryRun: opts.DryRun, Errors: []models.ImportError{}}
+	if len(rows) == 0 {
+		return result, nil
+	}
+	table, ok := importTables[reflect.TypeOf(rows[0].Value)]
+	if !ok {
+		return models.ImportResult{}, fmt.Errorf("cannot import %T", rows[0].Value)
+	}
+
+	staged := make([][]any, 0, len(rows))
+	for _, row := range rows {
+		values, problems := table.values(row.Value)
+		if len(problems) > 0 {
+			result.Errors = append(result.Errors, models.ImportError{Row: row.Row, Problems: problems})
+			continue
+		}
+		staged = append(staged, append([]any{row.Row}, values...))
+	}
+
+	// COPY has to run on the connection the transaction is open on
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return models.ImportResult{}, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ImportResult{}, fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, table.create); err != nil {
+		return models.ImportResult{}, fmt.Errorf("failed to create %s: %w", table.staging, err)
+	}
+	if err := s.copyFrom(ctx, conn, table.staging, table.columns, staged); err != nil {
+		return models.ImportResult{}, fmt.Errorf("failed to copy rows into %s: %w", table.staging, err)
+	}
+
+	failed, err := tx.QueryContext(ctx, table.check)
+	if err != nil {
+		return models.ImportResult{}, fmt.Errorf("failed to check imported rows: %w", err)
+	}
+	defer failed.Close()
+	for failed.Next() {
+		var (
+			importErr models.ImportError
+			problems  []byte
+		)
+		if err := failed.Scan(&importErr.Row, &problems); err != nil {
+			return models.ImportResult{}, fmt.Errorf("failed to scan import problems: %w", err)
+		}
+		if err := json.Unmarshal(problems, &importErr.Problems); err != nil {
+			return models.ImportResult{}, fmt.Errorf("failed to decode import problems: %w", err)
+		}
+		result.Errors = append(result.Errors, importErr)
+	}
+	if err := failed.Err(); err != nil {
+		return models.ImportResult{}, fmt.Errorf("failed to check imported rows: %w", err)
+	}
+	failed.Close()
+
+	for i, query := range table.write {
+		res, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return models.ImportResult{}, fmt.Errorf("failed to write imported rows: %w", err)
+		}
+		if i == 0 {
+			imported, err := res.RowsAffected()
+			if err != nil {
+				return models.ImportResult{}, fmt.Errorf("failed to count imported rows: %w", err)
+			}
+			result.Imported = int(imported)
+		}
+	}
+	result.Failed = len(result.Errors)
+
+	if opts.DryRun || opts.Mode == models.ImportAtomic && result.Failed > 0 || result.Imported == 0 {
+		return result, nil
+	}
+
+	// Rows imported with ids leave the sequences behind them
+	for _, table := range []string{"users", "blogs"} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			`SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %[1]s`,
+			table,
+		)); err != nil {
+			return models.ImportResult{}, fmt.Errorf("failed to advance %s sequence: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.ImportResult{}, fmt.Errorf("failed to commit import: %w", err)
+	}
+	result.Committed = true
+	return result, nil
+}
+
+// optionalID returns id as a column value, NULL if it is unset.
+func optionalID(id uint) any {
+	if id == 0 {
+		return nil
+	}
+	return int64(id)
+}
+
+// Export writes a zip archive of every user, blog and comment to w, as CSV
+// files an import reads, followed by a manifest. The tables are read with
+// COPY inside one repeatable read transaction, so the archive is a
+// consistent snapshot. Nothing is written to w if the export can't start.
+func (s *TransferService) Export(ctx context.Context, w io.Writer) (err error) {
+	s.logger.DebugContext(ctx, "Exporting data")
+	ctx, span := startOperation(ctx, "transfer", "export")
+	defer func() { endOperation(span, err) }()
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("export requires a pgx connection, got %T", driverConn)
+		}
+
+		tx, err := pgxConn.Conn().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+		if err != nil {
+			return fmt.Errorf("failed to begin export: %w", err)
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		manifest := exportManifest{
+			SchemaVersion: database.SchemaVersion,
+			ExportedAt:    time.Now().UTC(),
+			Rows:          make(map[string]int64, len(exports)),
+		}
+		archive := zip.NewWriter(w)
+		for _, export := range exports {
+			file, err := archive.Create(export.file)
+			if err != nil {
+				return fmt.Errorf("failed to add %s to export: %w", export.file, err)
+			}
+			tag, err := tx.Conn().PgConn().CopyTo(ctx, file, `COPY (`+export.query+`) TO STDOUT WITH (FORMAT csv, HEADER)`)
+			if err != nil {
+				return fmt.Errorf("failed to export %s: %w", export.file, err)
+			}
+			manifest.Rows[export.file] = tag.RowsAffected()
+		}
+
+		file, err := archive.Create("manifest.json")
+		if err != nil {
+			return fmt.Errorf("failed to add manifest to export: %w", err)
+		}
+		if err := json.NewEncoder(file).Encode(manifest); err != nil {
+			return fmt.Errorf("failed to write export manifest: %w", err)
+		}
+		if err := archive.Close(); err != nil {
+			return fmt.Errorf("failed to finish export: %w", err)
+		}
+		return tx.Commit(ctx)
+	})
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/navid/blog/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeCopy records the rows an import copies into its staging table.
+type fakeCopy struct {
+	table   string
+	columns []string
+	rows    [][]any
+	err     error
+}
+
+func (f *fakeCopy) copyFrom(ctx context.Context, conn *sql.Conn, table string, columns []string, rows [][]any) error {
+	f.table, f.columns, f.rows = table, columns, rows
+	return f.err
+}
+
+func TestTransferService_Import(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	rows := []models.ImportRow{
+		{Row: 1, Value: models.User{ID: 1, Name: "navid", Email: "navid@example.com", Password: string(hash)}},
+		{Row: 2, Value: models.User{Name: "sam", Email: "sam@example.com", Password: "secret2"}},
+		{Row: 3, Value: models.User{ID: 1, Name: "alex", Email: "alex@example.com", Password: string(hash)}},
+	}
+
+	testcases := map[string]struct {
+		opts          models.ImportOptions
+		wantCommitted bool
+	}{
+		"best effort": {
+			opts:          models.ImportOptions{Mode: models.ImportBestEffort},
+			wantCommitted: true,
+		},
+		"atomic": {
+			opts: models.ImportOptions{Mode: models.ImportAtomic},
+		},
+		"dry run": {
+			opts: models.ImportOptions{Mode: models.ImportBestEffort, DryRun: true},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`CREATE TEMPORARY TABLE import_users`)).WillReturnResult(sqlmock.NewResult(0, 0))
+			// The third row has the id of the first
+			mock.ExpectQuery(regexp.QuoteMeta(`UPDATE import_users i SET problems = c.problems`)).
+				WillReturnRows(sqlmock.NewRows([]string{"body_row", "problems"}).AddRow(3, []byte(`{"$": "row already exists"}`)))
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id, name, email, password)`)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			if tc.wantCommitted {
+				mock.ExpectExec(regexp.QuoteMeta(`SELECT setval(pg_get_serial_sequence('users', 'id')`)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`SELECT setval(pg_get_serial_sequence('blogs', 'id')`)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			copied := &fakeCopy{}
+			service := NewTransferService(db, slog.Default())
+			service.copyFrom = copied.copyFrom
+
+			result, err := service.Import(context.Background(), rows, tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Imported != 1 {
+				t.Errorf("want 1 imported, got %d", result.Imported)
+			}
+			if result.Committed != tc.wantCommitted {
+				t.Errorf("want committed %v, got %v", tc.wantCommitted, result.Committed)
+			}
+			// The second row's password isn't a hash, so it isn't staged
+			if copied.table != "import_users" || len(copied.rows) != 2 || copied.rows[0][0] != 1 || copied.rows[1][0] != 3 {
+				t.Errorf("want rows 1 and 3 copied into import_users, got %s %v", copied.table, copied.rows)
+			}
+			if len(result.Errors) != 2 ||
+				result.Errors[0].Row != 2 || result.Errors[0].Problems["password"] == "" ||
+				result.Errors[1].Row != 3 || result.Errors[1].Problems["$"] != "row already exists" {
+				t.Errorf("want a password problem on row 2 and a duplicate on row 3, got %v", result.Errors)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestTransferService_ImportBlogs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	created := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TEMPORARY TABLE import_blogs`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE import_blogs i SET problems = c.problems`)).
+		WillReturnRows(sqlmock.NewRows([]string{"body_row", "problems"}).AddRow(2, []byte(`{"author_id": "no user found with id: 9"}`)))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE import_blogs SET id = nextval(pg_get_serial_sequence('blogs', 'id'))`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO blogs (id, title, author_id, created_date)`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO blog_tags (blog_id, tag)`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT setval(pg_get_serial_sequence('users', 'id')`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT setval(pg_get_serial_sequence('blogs', 'id')`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	copied := &fakeCopy{}
+	service := NewTransferService(db, slog.Default())
+	service.copyFrom = copied.copyFrom
+
+	result, err := service.Import(context.Background(), []models.ImportRow{
+		{Row: 1, Value: models.Blog{Title: "First", AuthorID: 1, CreatedAt: created, Tags: []string{"Go", "go", "sql"}}},
+		{Row: 2, Value: models.Blog{Title: "Second", AuthorID: 9, CreatedAt: created}},
+	}, models.ImportOptions{Mode: models.ImportBestEffort})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Imported != 1 || !result.Committed {
+		t.Errorf("want 1 row imported and committed, got %+v", result)
+	}
+	if len(copied.rows) != 2 || copied.rows[0][1] != nil || len(copied.rows[0][5].([]string)) != 2 {
+		t.Errorf("want both rows copied without ids and with normalized tags, got %v", copied.rows)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Row != 2 || result.Errors[0].Problems["author_id"] == "" {
+		t.Errorf("want an author_id problem on row 2, got %v", result.Errors)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTransferService_ImportCopyError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TEMPORARY TABLE import_comments`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	service := NewTransferService(db, slog.Default())
+	service.copyFrom = (&fakeCopy{err: errors.New("connection reset")}).copyFrom
+
+	_, err = service.Import(context.Background(), []models.ImportRow{
+		{Row: 1, Value: models.Comment{UserID: 1, BlogID: 4, Message: "hi"}},
+	}, models.ImportOptions{Mode: models.ImportBestEffort})
+	if err == nil {
+		t.Fatal("want an error, got none")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
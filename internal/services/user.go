@@ -6,7 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/navid/blog/internal/database"
 	"github.com/navid/blog/internal/models"
 )
 
@@ -14,24 +18,48 @@ import (
 // models.User models.
 type UsersService struct {
 	logger *slog.Logger
-	db     *sql.DB
+	db     *database.DB
 }
 
 // NewUsersService creates a new UsersService and returns a pointer to it.
 func NewUsersService(logger *slog.Logger, db *sql.DB) *UsersService {
 	return &UsersService{
 		logger: logger,
-		db:     db,
+		db:     database.Wrap(db),
+	}
+}
+
+// hashPassword returns the bcrypt hash of password, which is what is stored
+// in place of the password.
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
 	}
+	return string(hash), nil
 }
 
-// CreateUser attempts to create the provided user, returning a fully hydrated
-// models.User or an error.
-func (s *UsersService) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+// unknownUserHash is compared against when authenticating an unknown email,
+// so that takes as long as a wrong password.
+var unknownUserHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("unknown user"), bcrypt.DefaultCost)
+	return hash
+})
+
+// CreateUser attempts to create the provided user, storing a hash of their
+// password, and returns a fully hydrated models.User or an error.
+func (s *UsersService) CreateUser(ctx context.Context, user models.User) (_ models.User, err error) {
 	s.logger.DebugContext(ctx, "Creating user", "email", user.Email)
+	ctx, span := startOperation(ctx, "users", "create_user")
+	defer func() { endOperation(span, err) }()
+
+	hash, err := hashPassword(user.Password)
+	if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.CreateUser] failed to hash password: %w", err)
+	}
 
 	var createdUser models.User
-	err := s.db.QueryRowContext(
+	err = s.db.QueryRowContext(
 		ctx,
 		`
         INSERT INTO users (name, email, password)
@@ -40,7 +68,7 @@ func (s *UsersService) CreateUser(ctx context.Context, user models.User) (models
         `,
 		user.Name,
 		user.Email,
-		user.Password,
+		hash,
 	).Scan(&createdUser.ID, &createdUser.Name, &createdUser.Email, &createdUser.Password)
 	if err != nil {
 		return models.User{}, fmt.Errorf(
@@ -54,8 +82,10 @@ func (s *UsersService) CreateUser(ctx context.Context, user models.User) (models
 
 // ReadUser attempts to read a user from the database using the provided id. A
 // fully hydrated models.User or error is returned.
-func (s *UsersService) ReadUser(ctx context.Context, id uint64) (models.User, error) {
+func (s *UsersService) ReadUser(ctx context.Context, id uint64) (_ models.User, err error) {
 	s.logger.DebugContext(ctx, "Reading user", "id", id)
+	ctx, span := startOperation(ctx, "users", "read_user")
+	defer func() { endOperation(span, err) }()
 
 	row := s.db.QueryRowContext(
 		ctx,
@@ -72,7 +102,7 @@ func (s *UsersService) ReadUser(ctx context.Context, id uint64) (models.User, er
 
 	var user models.User
 
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password)
+	err = row.Scan(&user.ID, &user.Name, &user.Email, &user.Password)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -88,14 +118,55 @@ func (s *UsersService) ReadUser(ctx context.Context, id uint64) (models.User, er
 	return user, nil
 }
 
+// ReadUsers reads the users with the provided ids in one query, for
+// embedding them in other resources. Only the properties shown of users are
+// read, leaving out their passwords. Ids with no user are left out of the
+// returned map.
+func (s *UsersService) ReadUsers(ctx context.Context, ids []int) (_ map[int]models.User, err error) {
+	s.logger.DebugContext(ctx, "Reading users", slog.Int("count", len(ids)))
+	ctx, span := startOperation(ctx, "users", "read_users")
+	defer func() { endOperation(span, err) }()
+
+	users := make(map[int]models.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, email FROM users WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.UsersService.ReadUsers] failed to read users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Name, &user.Email); err != nil {
+			return nil, fmt.Errorf("[in services.UsersService.ReadUsers] failed to scan user: %w", err)
+		}
+		users[int(user.ID)] = user
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.UsersService.ReadUsers] rows iteration error: %w", err)
+	}
+
+	return users, nil
+}
+
 // UpdateUser attempts to perform an update of the user with the provided id,
-// updating, it to reflect the properties on the provided patch object. A
-// models.User or an error.
-func (s *UsersService) UpdateUser(ctx context.Context, id uint64, patch models.User) (models.User, error) {
+// updating, it to reflect the properties on the provided patch object, with
+// its password stored hashed. A models.User or an error.
+func (s *UsersService) UpdateUser(ctx context.Context, id uint64, patch models.User) (_ models.User, err error) {
 	s.logger.DebugContext(ctx, "Updating user", "id", id)
+	ctx, span := startOperation(ctx, "users", "update_user")
+	defer func() { endOperation(span, err) }()
+
+	hash, err := hashPassword(patch.Password)
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to hash password: %w", err)
+	}
 
 	var updatedUser models.User
-	err := s.db.QueryRowContext(
+	err = s.db.QueryRowContext(
 		ctx,
 		`
         UPDATE users 
@@ -106,7 +177,7 @@ func (s *UsersService) UpdateUser(ctx context.Context, id uint64, patch models.U
 		id,
 		patch.Name,
 		patch.Email,
-		patch.Password,
+		hash,
 	).Scan(&updatedUser.ID, &updatedUser.Name, &updatedUser.Email, &updatedUser.Password)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -120,8 +191,10 @@ func (s *UsersService) UpdateUser(ctx context.Context, id uint64, patch models.U
 
 // DeleteUser attempts to delete the user with the provided id. An error is
 // returned if the delete fails.
-func (s *UsersService) DeleteUser(ctx context.Context, id uint64) error {
+func (s *UsersService) DeleteUser(ctx context.Context, id uint64) (err error) {
 	s.logger.DebugContext(ctx, "Deleting user", "id", id)
+	ctx, span := startOperation(ctx, "users", "delete_user")
+	defer func() { endOperation(span, err) }()
 
 	// Simple direct deletion for now
 	result, err := s.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
@@ -148,8 +221,10 @@ func (s *UsersService) DeleteUser(ctx context.Context, id uint64) error {
 
 // ListUsers attempts to list all users in the database. A slice of models.User
 // or an error is returned.
-func (s *UsersService) ListUsers(ctx context.Context, id uint64) ([]models.User, error) {
+func (s *UsersService) ListUsers(ctx context.Context, id uint64) (_ []models.User, err error) {
 	s.logger.DebugContext(ctx, "Listing users", "id", id)
+	ctx, span := startOperation(ctx, "users", "list_users")
+	defer func() { endOperation(span, err) }()
 
 	rows, err := s.db.QueryContext(
 		ctx,
@@ -182,15 +257,16 @@ func (s *UsersService) ListUsers(ctx context.Context, id uint64) ([]models.User,
 }
 
 // ListUsersWithFilter retrieves all users from the database, optionally filtering by name.
-func (s *UsersService) ListUsersWithFilter(ctx context.Context, name string) ([]models.User, error) {
+func (s *UsersService) ListUsersWithFilter(ctx context.Context, name string) (_ []models.User, err error) {
 	s.logger.DebugContext(ctx, "Listing users with filter", "name", name)
+	ctx, span := startOperation(ctx, "users", "list_users_with_filter")
+	defer func() { endOperation(span, err) }()
 
 	query := `
         SELECT id, name, email, password
         FROM users
     `
 	var rows *sql.Rows
-	var err error
 
 	if name != "" {
 		// Add a WHERE clause to filter by name
@@ -222,12 +298,30 @@ func (s *UsersService) ListUsersWithFilter(ctx context.Context, name string) ([]
 	return users, nil
 }
 
+// ListAuthorModTimes retrieves, for every user who has written a blog, when
+// their most recent blog was created or edited, for building sitemaps.
+func (s *UsersService) ListAuthorModTimes(ctx context.Context) (_ []models.ModTime, err error) {
+	s.logger.DebugContext(ctx, "Listing author modification times")
+	ctx, span := startOperation(ctx, "users", "list_author_mod_times")
+	defer func() { endOperation(span, err) }()
+
+	return listModTimes(ctx, s.db, `
+        SELECT author_id, MAX(COALESCE(updated_date, created_date))
+        FROM blogs
+        GROUP BY author_id
+        ORDER BY author_id
+        `)
+}
+
 // DoesUserExist checks if a user exists in the database by userID.
 func (s *UsersService) DoesUserExist(ctx context.Context, userID int) bool {
 	s.logger.DebugContext(ctx, "Checking if user exists", "user_id", userID)
+	ctx, span := startOperation(ctx, "users", "does_user_exist")
+	var err error
+	defer func() { endOperation(span, err) }()
 
 	var exists bool
-	err := s.db.QueryRowContext(
+	err = s.db.QueryRowContext(
 		ctx,
 		`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`,
 		userID,
@@ -239,3 +333,35 @@ func (s *UsersService) DoesUserExist(ctx context.Context, userID int) bool {
 
 	return exists
 }
+
+// Authenticate checks the provided email and password against the stored
+// user's password hash, returning the user if they match. The same error is
+// returned whether the email is unknown or the password is wrong.
+func (s *UsersService) Authenticate(ctx context.Context, email, password string) (_ models.User, err error) {
+	s.logger.DebugContext(ctx, "Authenticating user", "email", email)
+	ctx, span := startOperation(ctx, "users", "authenticate")
+	defer func() { endOperation(span, err) }()
+
+	var user models.User
+	err = s.db.QueryRowContext(
+		ctx,
+		`
+		SELECT id, name, email, password
+		FROM users
+		WHERE email = $1
+		`,
+		email,
+	).Scan(&user.ID, &user.Name, &user.Email, &user.Password)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = bcrypt.CompareHashAndPassword(unknownUserHash(), []byte(password))
+		return models.User{}, fmt.Errorf("invalid email or password")
+	} else if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.Authenticate] failed to read user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return models.User{}, fmt.Errorf("invalid email or password")
+	}
+
+	return user, nil
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql/driver"
 	"log/slog"
+	"reflect"
 	"regexp"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/navid/blog/internal/models"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestUsersService_ReadUser(t *testing.T) {
@@ -80,3 +82,118 @@ func TestUsersService_ReadUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUsersService_ReadUsers(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(arrayConverter{}))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, email FROM users WHERE id = ANY($1)`)).
+		WithArgs([]int{1, 2, 3}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
+			AddRow(1, "john", "john@me.com").
+			AddRow(3, "jane", "jane@me.com"))
+
+	service := NewUsersService(slog.Default(), db)
+	users, err := service.ReadUsers(context.Background(), []int{1, 2, 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(users) != 2 || users[1].Name != "john" || users[3].Name != "jane" {
+		t.Errorf("want users 1 and 3, got %v", users)
+	}
+	if users[1].Password != "" {
+		t.Errorf("want no password read, got %q", users[1].Password)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// arrayConverter passes slices through to the stub database as they are,
+// like pgx does for array parameters, and converts other values the way
+// database/sql does.
+type arrayConverter struct{}
+
+func (arrayConverter) ConvertValue(v any) (driver.Value, error) {
+	if reflect.TypeOf(v).Kind() == reflect.Slice {
+		return v, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+// hashOf matches a bcrypt hash of password.
+type hashOf string
+
+func (h hashOf) Match(v driver.Value) bool {
+	hash, ok := v.(string)
+	return ok && bcrypt.CompareHashAndPassword([]byte(hash), []byte(h)) == nil
+}
+
+func TestUsersService_CreateUserHashesPassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO users (name, email, password)`)).
+		WithArgs("john", "john@me.com", hashOf("password123!")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "password"}).
+			AddRow(1, "john", "john@me.com", "hash"))
+
+	service := NewUsersService(slog.Default(), db)
+	if _, err := service.CreateUser(context.Background(), models.User{Name: "john", Email: "john@me.com", Password: "password123!"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUsersService_Authenticate(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testcases := map[string]struct {
+		password    string
+		stored      string
+		expectedErr bool
+	}{
+		"right password":            {password: "password123!", stored: string(hash)},
+		"wrong password":            {password: "password124!", stored: string(hash), expectedErr: true},
+		"plaintext stored password": {password: "password123!", stored: "password123!", expectedErr: true},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, email, password`)).
+				WithArgs("john@me.com").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "password"}).
+					AddRow(1, "john", "john@me.com", tc.stored))
+
+			service := NewUsersService(slog.Default(), db)
+			user, err := service.Authenticate(context.Background(), "john@me.com", tc.password)
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error, got user %v", user)
+				}
+				return
+			}
+			if err != nil || user.ID != 1 {
+				t.Errorf("expected user 1, got %v (%v)", user, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Package sitemap builds XML sitemaps following the sitemaps.org protocol,
+// splitting them into a sitemap index once there are too many URLs for one
+// file, and caches the result.
+package sitemap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// MaxURLs is the most URLs the protocol allows in a single sitemap.
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL is a page to list in a sitemap.
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// Source returns every URL that should be in the sitemap.
+type Source func(ctx context.Context) ([]URL, error)
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	XMLNS   string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name   `xml:"sitemapindex"`
+	XMLNS    string     `xml:"xmlns,attr"`
+	Sitemaps []urlEntry `xml:"sitemap"`
+}
+
+type urlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func entry(u URL) urlEntry {
+	e := urlEntry{Loc: u.Loc}
+	if !u.LastMod.IsZero() {
+		e.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+	}
+	return e
+}
+
+// WriteURLSet writes a sitemap listing urls.
+func WriteURLSet(w io.Writer, urls []URL) error {
+	doc := urlSet{XMLNS: namespace, URLs: make([]urlEntry, 0, len(urls))}
+	for _, u := range urls {
+		doc.URLs = append(doc.URLs, entry(u))
+	}
+	return writeXML(w, doc)
+}
+
+// WriteIndex writes a sitemap index pointing at sitemaps.
+func WriteIndex(w io.Writer, sitemaps []URL) error {
+	doc := sitemapIndex{XMLNS: namespace, Sitemaps: make([]urlEntry, 0, len(sitemaps))}
+	for _, u := range sitemaps {
+		doc.Sitemaps = append(doc.Sitemaps, entry(u))
+	}
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Document is a rendered sitemap or sitemap index.
+type Document struct {
+	Body []byte
+	// Modified is the latest LastMod of the URLs the document covers.
+	Modified time.Time
+}
+
+// Cache builds the sitemap from a Source and keeps it for a while, so it
+// isn't regenerated on every request. When the source has more than MaxURLs
+// URLs, the root document is an index of numbered shards served from
+// ShardURL.
+type Cache struct {
+	source Source
+	ttl    time.Duration
+	// shardURL returns the absolute URL of shard n, counting from 1.
+	shardURL func(n int) string
+	now      func() time.Time
+
+	mu      sync.Mutex
+	expires time.Time
+	root    Document
+	shards  []Document
+}
+
+// NewCache creates a Cache that rebuilds the sitemap from source at most once
+// per ttl. shardURL returns the absolute URL shard n (counting from 1) is
+// served from.
+func NewCache(source Source, ttl time.Duration, shardURL func(n int) string) *Cache {
+	return &Cache{
+		source:   source,
+		ttl:      ttl,
+		shardURL: shardURL,
+		now:      time.Now,
+	}
+}
+
+// Root returns the document served at /sitemap.xml: the sitemap itself, or a
+// sitemap index if it had to be sharded.
+func (c *Cache) Root(ctx context.Context) (Document, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.refresh(ctx); err != nil {
+		return Document{}, err
+	}
+	return c.root, nil
+}
+
+// Shard returns shard n, counting from 1. ok is false if there is no such
+// shard.
+func (c *Cache) Shard(ctx context.Context, n int) (doc Document, ok bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.refresh(ctx); err != nil {
+		return Document{}, false, err
+	}
+	if n < 1 || n > len(c.shards) {
+		return Document{}, false, nil
+	}
+	return c.shards[n-1], true, nil
+}
+
+// refresh rebuilds the documents if they have expired. c.mu must be held.
+func (c *Cache) refresh(ctx context.Context) error {
+	now := c.now()
+	if now.Before(c.expires) {
+		return nil
+	}
+
+	urls, err := c.source(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list sitemap urls: %w", err)
+	}
+
+	root, shards, err := build(urls, c.shardURL)
+	if err != nil {
+		return err
+	}
+
+	c.root, c.shards = root, shards
+	c.expires = now.Add(c.ttl)
+	return nil
+}
+
+// build renders urls as a single sitemap, or as shards of at most MaxURLs
+// URLs and an index pointing at them.
+func build(urls []URL, shardURL func(n int) string) (Document, []Document, error) {
+	if len(urls) <= MaxURLs {
+		doc, err := render(urls)
+		return doc, nil, err
+	}
+
+	var shards []Document
+	var index []URL
+	for start := 0; start < len(urls); start += MaxURLs {
+		end := min(start+MaxURLs, len(urls))
+		shard, err := render(urls[start:end])
+		if err != nil {
+			return Document{}, nil, err
+		}
+		shards = append(shards, shard)
+		index = append(index, URL{Loc: shardURL(len(shards)), LastMod: shard.Modified})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteIndex(&buf, index); err != nil {
+		return Document{}, nil, fmt.Errorf("failed to render sitemap index: %w", err)
+	}
+	return Document{Body: buf.Bytes(), Modified: latest(index)}, shards, nil
+}
+
+func render(urls []URL) (Document, error) {
+	var buf bytes.Buffer
+	if err := WriteURLSet(&buf, urls); err != nil {
+		return Document{}, fmt.Errorf("failed to render sitemap: %w", err)
+	}
+	return Document{Body: buf.Bytes(), Modified: latest(urls)}, nil
+}
+
+func latest(urls []URL) time.Time {
+	var t time.Time
+	for _, u := range urls {
+		if u.LastMod.After(t) {
+			t = u.LastMod
+		}
+	}
+	return t
+}
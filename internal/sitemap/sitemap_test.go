@@ -0,0 +1,127 @@
+package sitemap
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func shardURL(n int) string {
+	return fmt.Sprintf("http://localhost:8000/sitemaps/%d.xml", n)
+}
+
+func TestCache_Root(t *testing.T) {
+	modified := time.Date(2024, 5, 14, 9, 0, 0, 0, time.UTC)
+
+	testcases := map[string]struct {
+		count      int
+		wantShards int
+		wantRoot   string
+	}{
+		"single sitemap": {
+			count:      3,
+			wantShards: 0,
+			wantRoot:   "<urlset",
+		},
+		"exactly full": {
+			count:      MaxURLs,
+			wantShards: 0,
+			wantRoot:   "<urlset",
+		},
+		"sharded": {
+			count:      MaxURLs*2 + 1,
+			wantShards: 3,
+			wantRoot:   "<sitemapindex",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			urls := make([]URL, tc.count)
+			for i := range urls {
+				urls[i] = URL{Loc: fmt.Sprintf("http://localhost:8000/blog/%d", i+1), LastMod: modified.Add(-time.Duration(i) * time.Second)}
+			}
+			c := NewCache(func(ctx context.Context) ([]URL, error) { return urls, nil }, time.Hour, shardURL)
+
+			root, err := c.Root(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(string(root.Body), tc.wantRoot) {
+				t.Errorf("expected root to be a %s, got %.200s", tc.wantRoot, root.Body)
+			}
+			if !root.Modified.Equal(modified) {
+				t.Errorf("expected modified %v, got %v", modified, root.Modified)
+			}
+
+			if tc.wantShards > 0 {
+				var index sitemapIndex
+				if err := xml.Unmarshal(root.Body, &index); err != nil {
+					t.Fatalf("invalid index: %v", err)
+				}
+				if len(index.Sitemaps) != tc.wantShards {
+					t.Fatalf("expected %d shards in the index, got %d", tc.wantShards, len(index.Sitemaps))
+				}
+				if index.Sitemaps[0].Loc != shardURL(1) {
+					t.Errorf("expected first shard at %s, got %s", shardURL(1), index.Sitemaps[0].Loc)
+				}
+			}
+
+			total := 0
+			for n := 1; ; n++ {
+				shard, ok, err := c.Shard(context.Background(), n)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !ok {
+					if n-1 != tc.wantShards {
+						t.Errorf("expected %d shards, got %d", tc.wantShards, n-1)
+					}
+					break
+				}
+				var set urlSet
+				if err := xml.Unmarshal(shard.Body, &set); err != nil {
+					t.Fatalf("invalid shard: %v", err)
+				}
+				if len(set.URLs) > MaxURLs {
+					t.Errorf("shard %d has %d urls", n, len(set.URLs))
+				}
+				total += len(set.URLs)
+			}
+			if tc.wantShards > 0 && total != tc.count {
+				t.Errorf("expected %d urls across shards, got %d", tc.count, total)
+			}
+		})
+	}
+}
+
+func TestCache_Expiry(t *testing.T) {
+	calls := 0
+	c := NewCache(func(ctx context.Context) ([]URL, error) {
+		calls++
+		return []URL{{Loc: "http://localhost:8000/"}}, nil
+	}, time.Minute, shardURL)
+
+	now := time.Date(2024, 5, 14, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Root(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the source to be read once, got %d", calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := c.Root(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the source to be read again after expiry, got %d", calls)
+	}
+}
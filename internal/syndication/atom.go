@@ -0,0 +1,80 @@
+package syndication
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomAuthor     `xml:"author"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func writeAtom(w io.Writer, feed Feed) error {
+	// Atom requires an updated time even for an empty feed
+	updated := feed.Updated
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+
+	doc := atomFeed{
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		ID:       feed.FeedURL,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: feed.FeedURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: feed.Link, Rel: "alternate"},
+		},
+	}
+
+	for _, item := range feed.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.ID,
+			Link:      atomLink{Href: item.Link, Rel: "alternate"},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.updated().UTC().Format(time.RFC3339),
+			Author:    atomAuthor{Name: item.AuthorName, URI: item.AuthorLink},
+			Summary:   item.Summary,
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return writeXML(w, doc)
+}
@@ -0,0 +1,104 @@
+// Package syndication renders lists of blogs as RSS 2.0, Atom and JSON Feed
+// 1.1 documents.
+package syndication
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Feed is a format-independent description of a syndication feed.
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the page the feed describes.
+	Link string
+	// FeedURL is where the feed itself is served.
+	FeedURL string
+	// Updated is when the feed's content last changed.
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is a single entry in a Feed.
+type Item struct {
+	// ID uniquely and permanently identifies the item.
+	ID         string
+	Title      string
+	Link       string
+	Summary    string
+	AuthorName string
+	AuthorLink string
+	Published  time.Time
+	Updated    time.Time
+	Tags       []string
+}
+
+// Format is a feed document format.
+type Format string
+
+const (
+	RSS  Format = "rss"
+	Atom Format = "atom"
+	JSON Format = "json"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{RSS, Atom, JSON}
+
+// Extension returns the file extension feeds in the format are served with.
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case RSS:
+		return "application/rss+xml; charset=utf-8"
+	case Atom:
+		return "application/atom+xml; charset=utf-8"
+	case JSON:
+		return "application/feed+json; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Write renders feed to w in the format.
+func (f Format) Write(w io.Writer, feed Feed) error {
+	switch f {
+	case RSS:
+		return writeRSS(w, feed)
+	case Atom:
+		return writeAtom(w, feed)
+	case JSON:
+		return writeJSON(w, feed)
+	default:
+		return fmt.Errorf("unknown feed format: %q", string(f))
+	}
+}
+
+// LastModified returns the most recent publish or update time of the items,
+// or the zero time if there are none.
+func LastModified(items []Item) time.Time {
+	var latest time.Time
+	for _, item := range items {
+		if item.Published.After(latest) {
+			latest = item.Published
+		}
+		if item.Updated.After(latest) {
+			latest = item.Updated
+		}
+	}
+	return latest
+}
+
+// updated returns the item's update time, falling back to its publish time.
+func (i Item) updated() time.Time {
+	if i.Updated.IsZero() {
+		return i.Published
+	}
+	return i.Updated
+}
@@ -0,0 +1,128 @@
+package syndication
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	published := time.Date(2024, 5, 14, 9, 0, 0, 0, time.UTC)
+	return Feed{
+		Title:       "Blog",
+		Description: "Latest posts",
+		Link:        "http://localhost:8000/",
+		FeedURL:     "http://localhost:8000/feeds/blog.rss",
+		Updated:     published,
+		Items: []Item{
+			{
+				ID:         "http://localhost:8000/blog/1",
+				Title:      "First <Blog> Post",
+				Link:       "http://localhost:8000/blog/1",
+				Summary:    "First <Blog> Post",
+				AuthorName: "John Doe",
+				AuthorLink: "http://localhost:8000/author/1",
+				Published:  published,
+				Tags:       []string{"go", "meta"},
+			},
+		},
+	}
+}
+
+func TestFormat_Write(t *testing.T) {
+	testcases := map[string]struct {
+		format Format
+		want   []string
+	}{
+		"rss": {
+			format: RSS,
+			want: []string{
+				`<rss version="2.0"`,
+				`<atom:link href="http://localhost:8000/feeds/blog.rss" rel="self" type="application/rss+xml"></atom:link>`,
+				`<title>First &lt;Blog&gt; Post</title>`,
+				`<guid isPermaLink="true">http://localhost:8000/blog/1</guid>`,
+				`<pubDate>Tue, 14 May 2024 09:00:00 +0000</pubDate>`,
+				`<dc:creator>John Doe</dc:creator>`,
+				`<category>meta</category>`,
+			},
+		},
+		"atom": {
+			format: Atom,
+			want: []string{
+				`<feed xmlns="http://www.w3.org/2005/Atom">`,
+				`<updated>2024-05-14T09:00:00Z</updated>`,
+				`<link href="http://localhost:8000/blog/1" rel="alternate"></link>`,
+				`<name>John Doe</name>`,
+				`<category term="go"></category>`,
+			},
+		},
+		"json": {
+			format: JSON,
+			want: []string{
+				`"version": "https://jsonfeed.org/version/1.1"`,
+				`"date_published": "2024-05-14T09:00:00Z"`,
+				`"name": "John Doe"`,
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.format.Write(&buf, testFeed()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// The document must be well formed
+			switch tc.format {
+			case JSON:
+				var v map[string]any
+				if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+					t.Fatalf("invalid json: %v", err)
+				}
+			default:
+				var v struct{}
+				if err := xml.Unmarshal(buf.Bytes(), &v); err != nil {
+					t.Fatalf("invalid xml: %v", err)
+				}
+			}
+
+			for _, want := range tc.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected output to contain %s, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestFormat_WriteEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := JSON.Write(&buf, Feed{Title: "Empty"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"items": []`) {
+		t.Errorf("expected an empty items array, got:\n%s", buf.String())
+	}
+
+	if err := Format("yaml").Write(&buf, Feed{}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestLastModified(t *testing.T) {
+	older := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	if got := LastModified(nil); !got.IsZero() {
+		t.Errorf("expected zero time, got %v", got)
+	}
+
+	got := LastModified([]Item{{Published: older}, {Published: older, Updated: newer}})
+	if !got.Equal(newer) {
+		t.Errorf("expected %v, got %v", newer, got)
+	}
+}
@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentation = "github.com/navid/blog/internal/tracing"
+
+// Handler wraps h so each call is recorded as a span named after the route
+// pattern it is registered under, nested inside the request's server span.
+func Handler(pattern string, h http.Handler) http.Handler {
+	tracer := otel.Tracer(instrumentation)
+	name := "handler " + pattern
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name, trace.WithAttributes(attribute.String("http.route", pattern)))
+		defer span.End()
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Extract returns r's context with the remote span context from its
+// traceparent header, if any.
+func Extract(r *http.Request) *http.Request {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return r.WithContext(ctx)
+}
+
+// Inject writes the span context in r's context to its traceparent header,
+// for requests made to other services.
+func Inject(r *http.Request) {
+	otel.GetTextMapPropagator().Inject(r.Context(), propagation.HeaderCarrier(r.Header))
+}
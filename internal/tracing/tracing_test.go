@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestHandler(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	h := Handler("GET /api/blog/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.InfoContext(r.Context(), "handled")
+	}))
+
+	// Continue the trace from an incoming traceparent header
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/blog/1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), Extract(req))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if got := spans[0].Name(); got != "handler GET /api/blog/{id}" {
+		t.Errorf("unexpected span name %q", got)
+	}
+	if got := spans[0].SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("expected the span to continue trace %s, got %s", traceID, got)
+	}
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("invalid log line: %v", err)
+	}
+	if line["trace_id"] != traceID {
+		t.Errorf("expected trace_id %s in log, got %v", traceID, line["trace_id"])
+	}
+	if line["span_id"] != spans[0].SpanContext().SpanID().String() {
+		t.Errorf("expected span_id %s in log, got %v", spans[0].SpanContext().SpanID(), line["span_id"])
+	}
+}
+
+func TestLogHandler_NoSpan(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+	logger.InfoContext(context.Background(), "untraced")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("invalid log line: %v", err)
+	}
+	if _, ok := line["trace_id"]; ok {
+		t.Errorf("expected no trace_id without a span, got %v", line)
+	}
+	if line["component"] != "test" {
+		t.Errorf("expected attributes added with With to be kept, got %v", line)
+	}
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+}
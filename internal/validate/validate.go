@@ -0,0 +1,221 @@
+// Package validate checks structs against the rules in their validate struct
+// tags.
+//
+// Rules are separated by commas:
+//
+//	required  the field must not be its zero value; strings must not be blank
+//	email     a non-empty string must be a bare email address
+//	min=N     strings must have at least N characters, slices and maps at
+//	          least N elements, and numbers must be at least N
+//	max=N     the same as min, as an upper bound
+//	oneof=A B a non-empty string or a number must be one of the listed values
+//
+// Problems are keyed by the path of the field in the JSON document, using the
+// names from json struct tags, such as items[0].note.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Struct checks v, a struct or a pointer to one, and the structs nested in
+// it, returning a problem for every field that breaks one of its rules. It
+// panics if a validate tag is malformed, as that is a programming error.
+func Struct(v any) map[string]string {
+	problems := make(map[string]string)
+	walk(reflect.ValueOf(v), "", problems)
+	return problems
+}
+
+// walk validates the fields of the struct v, if it is one, and recurses into
+// nested structs, slices and maps.
+func walk(v reflect.Value, path string, problems map[string]string) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, ok := jsonName(field)
+			if !ok {
+				continue
+			}
+			fieldPath := join(path, name)
+			if field.Anonymous && field.Tag.Get("json") == "" {
+				fieldPath = path
+			}
+
+			fv := v.Field(i)
+			if tag := field.Tag.Get("validate"); tag != "" {
+				if problem := check(fv, fieldPath, tag); problem != "" {
+					problems[fieldPath] = problem
+					continue
+				}
+			}
+			walk(fv, fieldPath, problems)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			walk(iter.Value(), join(path, fmt.Sprint(iter.Key().Interface())), problems)
+		}
+	}
+}
+
+// jsonName returns the name field is encoded as in JSON, and false if it
+// isn't encoded at all.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// join appends name to path.
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// check applies the rules in tag to v, returning the first broken one's
+// problem or "".
+func check(v reflect.Value, path, tag string) string {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if slices.Contains(strings.Split(tag, ","), "required") {
+				return path + " is required"
+			}
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		var problem string
+		switch name {
+		case "required":
+			problem = required(v, path)
+		case "email":
+			problem = email(v, path)
+		case "min":
+			problem = bound(v, path, arg, true)
+		case "max":
+			problem = bound(v, path, arg, false)
+		case "oneof":
+			problem = oneOf(v, path, strings.Fields(arg))
+		default:
+			panic(fmt.Sprintf("validate: unknown rule %q on %s", name, path))
+		}
+		if problem != "" {
+			return problem
+		}
+	}
+	return ""
+}
+
+func required(v reflect.Value, path string) string {
+	if v.Kind() == reflect.String && strings.TrimSpace(v.String()) == "" || v.IsZero() {
+		return path + " is required"
+	}
+	return ""
+}
+
+func email(v reflect.Value, path string) string {
+	if v.Kind() != reflect.String {
+		panic(fmt.Sprintf("validate: email rule on non-string %s", path))
+	}
+	s := v.String()
+	if s == "" {
+		return ""
+	}
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return path + " must be a valid email address"
+	}
+	return ""
+}
+
+// bound checks v against the min (lower) or max bound arg.
+func bound(v reflect.Value, path, arg string, lower bool) string {
+	n, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: invalid bound %q on %s", arg, path))
+	}
+
+	var (
+		got  float64
+		unit string
+	)
+	switch v.Kind() {
+	case reflect.String:
+		got, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		got, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		got = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		got = v.Float()
+	default:
+		panic(fmt.Sprintf("validate: bound on unsupported %s %s", v.Kind(), path))
+	}
+
+	switch {
+	case lower && got < n:
+		return fmt.Sprintf("%s must be at least %s%s", path, arg, unit)
+	case !lower && got > n:
+		return fmt.Sprintf("%s must be at most %s%s", path, arg, unit)
+	}
+	return ""
+}
+
+func oneOf(v reflect.Value, path string, options []string) string {
+	var s string
+	switch v.Kind() {
+	case reflect.String:
+		s = v.String()
+		if s == "" {
+			return ""
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strconv.FormatUint(v.Uint(), 10)
+	default:
+		panic(fmt.Sprintf("validate: oneof on unsupported %s %s", v.Kind(), path))
+	}
+
+	if !slices.Contains(options, s) {
+		return fmt.Sprintf("%s must be one of %s", path, strings.Join(options, ", "))
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+)
+
+type account struct {
+	Name     string   `json:"name" validate:"required"`
+	Email    string   `json:"email" validate:"required,email"`
+	Password string   `json:"password" validate:"required,min=6"`
+	Age      int      `json:"age" validate:"min=13,max=130"`
+	Role     string   `json:"role" validate:"oneof=reader writer"`
+	Tags     []string `json:"tags" validate:"max=2"`
+	Address  *address `json:"address"`
+	Links    []link   `json:"links"`
+	internal string   `validate:"required"`
+}
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type link struct {
+	URL string `json:"url" validate:"required"`
+}
+
+func TestStruct(t *testing.T) {
+	valid := account{
+		Name:     "Navid",
+		Email:    "navid@example.com",
+		Password: "secret1",
+		Age:      30,
+	}
+
+	tests := map[string]struct {
+		input    func(a *account)
+		expected map[string]string
+	}{
+		"valid": {
+			input:    func(a *account) {},
+			expected: map[string]string{},
+		},
+		"missing required": {
+			input: func(a *account) {
+				a.Name = "  "
+				a.Email = ""
+				a.Password = ""
+			},
+			expected: map[string]string{
+				"name":     "name is required",
+				"email":    "email is required",
+				"password": "password is required",
+			},
+		},
+		"bad email": {
+			input:    func(a *account) { a.Email = "Navid <navid@example.com>" },
+			expected: map[string]string{"email": "email must be a valid email address"},
+		},
+		"short password": {
+			input:    func(a *account) { a.Password = "abc" },
+			expected: map[string]string{"password": "password must be at least 6 characters"},
+		},
+		"out of range": {
+			input:    func(a *account) { a.Age = 200 },
+			expected: map[string]string{"age": "age must be at most 130"},
+		},
+		"not one of": {
+			input:    func(a *account) { a.Role = "admin" },
+			expected: map[string]string{"role": "role must be one of reader, writer"},
+		},
+		"too many items": {
+			input:    func(a *account) { a.Tags = []string{"a", "b", "c"} },
+			expected: map[string]string{"tags": "tags must be at most 2 items"},
+		},
+		"nested": {
+			input: func(a *account) {
+				a.Address = &address{}
+				a.Links = []link{{URL: "https://example.com"}, {}}
+			},
+			expected: map[string]string{
+				"address.city": "address.city is required",
+				"links[1].url": "links[1].url is required",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := valid
+			tc.input(&a)
+			if got := Struct(a); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestStruct_UnknownRule(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an unknown rule")
+		}
+	}()
+	Struct(struct {
+		Name string `validate:"shiny"`
+	}{})
+}
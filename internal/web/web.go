@@ -0,0 +1,92 @@
+// Package web holds the templates and static assets of the server-rendered
+// site and renders its pages.
+package web
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//go:embed templates static
+var assets embed.FS
+
+// Static holds the site's stylesheets and other static files, to be served
+// under /static/.
+var Static = mustSub(assets, "static")
+
+// funcs are the helpers available to every template.
+var funcs = template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format("2 January 2006")
+	},
+	"isoDate": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+	"stars": func(average float64) string {
+		full := int(average + 0.5)
+		return strings.Repeat("★", full) + strings.Repeat("☆", 5-full)
+	},
+}
+
+// Renderer renders the site's pages. Each page template is parsed together
+// with the shared layout.
+type Renderer struct {
+	pages map[string]*template.Template
+}
+
+// NewRenderer parses the embedded templates.
+func NewRenderer() (*Renderer, error) {
+	names, err := fs.Glob(assets, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	r := &Renderer{pages: make(map[string]*template.Template)}
+	for _, name := range names {
+		page := strings.TrimSuffix(strings.TrimPrefix(name, "templates/"), ".html")
+		if page == "layout" {
+			continue
+		}
+		t, err := template.New(page).Funcs(funcs).ParseFS(assets, "templates/layout.html", name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+		r.pages[page] = t
+	}
+
+	return r, nil
+}
+
+// Render writes the named page with the provided data and status code. The
+// page is rendered in full before anything is written, so a template error
+// can still be reported as a 500.
+func (r *Renderer) Render(w http.ResponseWriter, status int, page string, data any) error {
+	t, ok := r.pages[page]
+	if !ok {
+		return fmt.Errorf("unknown page: %s", page)
+	}
+
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return fmt.Errorf("failed to render page %s: %w", page, err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListModeration lists the items awaiting or past moderation, only those
+// with status (pending, approved or rejected) if it isn't empty.
+func (c *Client) ListModeration(ctx context.Context, status string) ([]ModerationItem, error) {
+	q := url.Values{}
+	setString(q, "status", status)
+	var items []ModerationItem
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/moderation", query: q}, &items)
+	return items, err
+}
+
+// ApproveModeration approves the pending item with id, publishing its
+// content.
+func (c *Client) ApproveModeration(ctx context.Context, id uint) (ModerationItem, error) {
+	var item ModerationItem
+	err := c.do(ctx, request{method: http.MethodPost, path: pathf("/api/moderation/%s/approve", id)}, &item)
+	return item, err
+}
+
+// RejectModeration rejects the pending item with id.
+func (c *Client) RejectModeration(ctx context.Context, id uint) (ModerationItem, error) {
+	var item ModerationItem
+	err := c.do(ctx, request{method: http.MethodPost, path: pathf("/api/moderation/%s/reject", id)}, &item)
+	return item, err
+}
+
+// Batch runs the operations of batch in order, in one request.
+func (c *Client) Batch(ctx context.Context, batch BatchRequest) (BatchResponse, error) {
+	var resp BatchResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/batch", body: batch}, &resp)
+	return resp, err
+}
+
+// Kinds of rows Import reads.
+const (
+	ImportUsers    = "users"
+	ImportBlogs    = "blogs"
+	ImportComments = "comments"
+)
+
+// Import modes.
+const (
+	ImportAtomic     = "atomic"      // write every row or none
+	ImportBestEffort = "best_effort" // write the rows that are valid
+)
+
+// ImportOptions says how Import writes rows.
+type ImportOptions struct {
+	Mode   string // ImportAtomic, the default, or ImportBestEffort
+	DryRun bool   // report without writing
+}
+
+// Import bulk loads rows of kind (ImportUsers, ImportBlogs or
+// ImportComments) from body, which is NDJSON or CSV as contentType says.
+// Users' passwords must be bcrypt hashes, as Export writes them. It needs an
+// admin's token. An atomic import with bad rows returns its
+// result alongside an *Error, so the problems of each row can be reported.
+func (c *Client) Import(ctx context.Context, kind, contentType string, body io.Reader, opts ImportOptions) (ImportResult, error) {
+	q := url.Values{"kind": {kind}}
+	setString(q, "mode", opts.Mode)
+	if opts.DryRun {
+		q.Set("dry_run", strconv.FormatBool(opts.DryRun))
+	}
+
+	var result ImportResult
+	err := c.do(ctx, request{
+		method:      http.MethodPost,
+		path:        "/api/admin/import",
+		query:       q,
+		bodyReader:  body,
+		contentType: contentType,
+	}, &result)
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity && apiErr.Body != nil {
+		if jsonErr := json.Unmarshal(apiErr.Body, &result); jsonErr != nil {
+			return ImportResult{}, err
+		}
+	}
+	return result, err
+}
+
+// Export writes a zip archive of every user, blog and comment to w, as CSV
+// files in the form Import reads. It needs an admin's token.
+func (c *Client) Export(ctx context.Context, w io.Writer) error {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: "/api/admin/export", accept: "application/zip"})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("reading export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Relations blog and comment reads can include with Expand.
+const (
+	IncludeAuthor      = "author"       // a blog's author
+	IncludeComments    = "comments"     // a blog's comments
+	IncludeCommentUser = "comment.user" // the authors of a blog's comments
+	IncludeUser        = "user"         // a comment's author
+)
+
+// Expand shapes the blogs and comments a read returns.
+type Expand struct {
+	// Include lists the relations to embed, such as IncludeAuthor.
+	Include []string
+	// Fields lists the properties to keep, such as "title" or
+	// "author.name"; the rest are left zero. Empty keeps them all.
+	Fields []string
+}
+
+// set adds the fields= and include= parameters of e to q.
+func (e *Expand) set(q url.Values) {
+	if e == nil {
+		return
+	}
+	setString(q, "include", strings.Join(e.Include, ","))
+	setString(q, "fields", strings.Join(e.Fields, ","))
+}
+
+// Blog sort orders.
+const (
+	SortScore  = "score"  // highest weighted rating first
+	SortNewest = "newest" // most recent first
+)
+
+// ListBlogsOptions filters and orders the blogs ListBlogs lists.
+type ListBlogsOptions struct {
+	Title    string // part of the title
+	AuthorID int
+	Tag      string
+	Sort     string // SortScore, SortNewest or empty for the default order
+	Expand
+}
+
+// ListBlogs lists the blogs matching opts.
+func (c *Client) ListBlogs(ctx context.Context, opts ListBlogsOptions) ([]Blog, error) {
+	var blogs []Blog
+	err := c.do(ctx, listBlogsRequest(opts), &blogs)
+	return blogs, err
+}
+
+// Blogs streams the blogs ListBlogs lists, decoding each as it arrives.
+func (c *Client) Blogs(ctx context.Context, opts ListBlogsOptions) iter.Seq2[Blog, error] {
+	return stream[Blog](ctx, c, listBlogsRequest(opts))
+}
+
+func listBlogsRequest(opts ListBlogsOptions) request {
+	q := url.Values{}
+	setString(q, "title", opts.Title)
+	setInt(q, "author_id", opts.AuthorID)
+	setString(q, "tag", opts.Tag)
+	setString(q, "sort", opts.Sort)
+	opts.Expand.set(q)
+	return request{method: http.MethodGet, path: "/api/blog", query: q}
+}
+
+// GetBlog reads the blog with id, shaped by expand if it isn't nil.
+func (c *Client) GetBlog(ctx context.Context, id uint, expand *Expand) (Blog, error) {
+	q := url.Values{}
+	expand.set(q)
+	var blog Blog
+	err := c.do(ctx, request{method: http.MethodGet, path: pathf("/api/blog/%s", id), query: q}, &blog)
+	return blog, err
+}
+
+// CreateBlog creates a blog. Blogs the content filters hold back return an
+// error matching ErrPendingModeration, and those they refuse one matching
+// ErrRejected, with the outcome in its Moderation.
+func (c *Client) CreateBlog(ctx context.Context, blog BlogInput) (Blog, error) {
+	var created Blog
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/blog", body: blog}, &created)
+	return created, err
+}
+
+// UpdateBlog replaces the blog with id.
+func (c *Client) UpdateBlog(ctx context.Context, id uint, blog BlogInput) (Blog, error) {
+	var updated Blog
+	err := c.do(ctx, request{method: http.MethodPut, path: pathf("/api/blog/%s", id), body: blog}, &updated)
+	return updated, err
+}
+
+// DeleteBlog deletes the blog with id.
+func (c *Client) DeleteBlog(ctx context.Context, id uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: pathf("/api/blog/%s", id)}, nil)
+}
+
+// RateBlog sets the authenticated user's rating of the blog with id, from 1
+// to 5, and returns the blog with its new score.
+func (c *Client) RateBlog(ctx context.Context, id uint, rating int) (Blog, error) {
+	var blog Blog
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   pathf("/api/blog/%s/rating", id),
+		body:   map[string]int{"rating": rating},
+	}, &blog)
+	return blog, err
+}
+
+// Feed reads a page of the blogs by the users the authenticated user
+// follows, newest first. An empty cursor reads the first page and a zero
+// limit the default page size.
+func (c *Client) Feed(ctx context.Context, cursor string, limit int) (FeedPage, error) {
+	q := url.Values{}
+	setString(q, "cursor", cursor)
+	setInt(q, "limit", limit)
+	var page FeedPage
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/feed", query: q}, &page)
+	return page, err
+}
+
+// FeedAll ranges over the whole of the authenticated user's feed, reading
+// pages of pageSize blogs as it goes. It stops after the first error.
+func (c *Client) FeedAll(ctx context.Context, pageSize int) iter.Seq2[Blog, error] {
+	return func(yield func(Blog, error) bool) {
+		cursor := ""
+		for {
+			page, err := c.Feed(ctx, cursor, pageSize)
+			if err != nil {
+				yield(Blog{}, err)
+				return
+			}
+			for _, blog := range page.Blogs {
+				if !yield(blog, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// Package client is a typed Go client for the blog API. It covers every
+// route under /api, plus /graphql, with a method per operation.
+//
+// Calls take a context and return typed values, or an *Error decoded from
+// the API's error response that can be matched with errors.Is against
+// ErrNotFound, ErrInvalid and the other sentinel errors. Idempotent calls
+// are retried with backoff when the API is unavailable or rate limiting, and
+// lists can be ranged over page by page or as they stream in.
+//
+//	c, err := client.New("http://localhost:8080", client.WithToken(token))
+//	if err != nil {
+//		return err
+//	}
+//	blog, err := c.GetBlog(ctx, 42, nil)
+//	if errors.Is(err, client.ErrNotFound) {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the blog API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	tokens     TokenSource
+	retry      RetryPolicy
+	userAgent  string
+}
+
+// TokenSource supplies the bearer token sent with each request. An empty
+// token sends the request anonymously.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource that always returns the same token.
+type StaticToken string
+
+func (t StaticToken) Token(ctx context.Context) (string, error) { return string(t), nil }
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) { return f(ctx) }
+
+// RetryPolicy says how idempotent requests (GET, PUT, DELETE) are retried
+// after network errors and 429, 502, 503 and 504 responses. Each retry waits
+// a random time up to MinBackoff doubled for each attempt so far, capped at
+// MaxBackoff, or as long as the response's Retry-After header asks.
+type RetryPolicy struct {
+	MaxAttempts int // including the first; 1 turns retries off
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultRetryPolicy is the RetryPolicy of clients that don't set one.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient makes the client send requests with hc instead of
+// http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithToken authenticates every request with token.
+func WithToken(token string) Option {
+	return WithTokenSource(StaticToken(token))
+}
+
+// WithTokenSource authenticates every request with a token from ts, which
+// is asked again for each attempt, so it can refresh expired tokens.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) { c.tokens = ts }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// New returns a client for the API served at baseURL, such as
+// "https://blog.example.com".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawQuery, u.Fragment = "", ""
+
+	c := &Client{
+		baseURL:    u.String(),
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+		userAgent:  "blog-go-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.retry.MaxAttempts = max(c.retry.MaxAttempts, 1)
+	return c, nil
+}
+
+// request describes a call to the API.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        any       // encoded as JSON, unless bodyReader is set
+	bodyReader  io.Reader // sent as is, with contentType; never retried
+	contentType string
+	accept      string
+	once        bool // not retried even if idempotent
+}
+
+// idempotent reports whether req can be sent again without changing its
+// effect, and should be.
+func (req request) idempotent() bool {
+	if req.once || req.bodyReader != nil {
+		return false
+	}
+	switch req.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// do sends req and decodes its JSON response into out, unless out is nil.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// send sends req, retrying it if it can be, and returns the successful
+// response. The caller must close its body. Error responses are returned as
+// an *Error.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("encoding %s %s request: %w", req.method, req.path, err)
+		}
+	}
+
+	attempts := 1
+	if req.idempotent() {
+		attempts = c.retry.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := c.sendOnce(ctx, req, body)
+		if err != nil {
+			if ctx.Err() != nil || attempt == attempts {
+				return nil, err
+			}
+			if err := c.wait(ctx, attempt, 0); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode < 300 && resp.StatusCode != http.StatusAccepted {
+			return resp, nil
+		}
+
+		apiErr := decodeError(resp)
+		resp.Body.Close()
+		if !retryable(resp.StatusCode) || attempt == attempts {
+			return nil, apiErr
+		}
+		if err := c.wait(ctx, attempt, apiErr.RetryAfter); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sendOnce makes one attempt at sending req with body.
+func (c *Client) sendOnce(ctx context.Context, req request, body []byte) (*http.Response, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var r io.Reader
+	switch {
+	case req.bodyReader != nil:
+		r = req.bodyReader
+	case body != nil:
+		r = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, r)
+	if err != nil {
+		return nil, fmt.Errorf("building %s %s request: %w", req.method, req.path, err)
+	}
+
+	switch {
+	case req.contentType != "":
+		httpReq.Header.Set("Content-Type", req.contentType)
+	case body != nil:
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	accept := req.accept
+	if accept == "" {
+		accept = "application/json"
+	}
+	httpReq.Header.Set("Accept", accept)
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if c.tokens != nil {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getting token: %w", err)
+		}
+		if token != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", req.method, req.path, err)
+	}
+	return resp, nil
+}
+
+// retryable reports whether a response with status may succeed if the
+// request is sent again.
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// wait sleeps before the retry following attempt, for retryAfter if the
+// server asked for it and with jittered exponential backoff otherwise.
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	delay := retryAfter
+	if delay <= 0 {
+		backoff := min(c.retry.MinBackoff<<(attempt-1), c.retry.MaxBackoff)
+		if backoff > 0 {
+			delay = rand.N(backoff) + 1
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// pathf formats a path, escaping each of args as a path segment.
+func pathf(format string, args ...any) string {
+	escaped := make([]any, len(args))
+	for i, arg := range args {
+		escaped[i] = url.PathEscape(fmt.Sprint(arg))
+	}
+	return fmt.Sprintf(format, escaped...)
+}
+
+// setInt sets key to n in q, unless n is zero.
+func setInt(q url.Values, key string, n int) {
+	if n != 0 {
+		q.Set(key, strconv.Itoa(n))
+	}
+}
+
+// setString sets key to s in q, unless s is empty.
+func setString(q url.Values, key, s string) {
+	if s != "" {
+		q.Set(key, s)
+	}
+}
@@ -0,0 +1,549 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy retries without keeping the tests waiting.
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+// newTestClient starts a server answering with h and returns a client for
+// it.
+func newTestClient(t *testing.T, h http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, append([]Option{WithRetryPolicy(testRetryPolicy)}, opts...)...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return c
+}
+
+// reply writes body with status, as JSON unless it is a plain text error.
+func reply(w http.ResponseWriter, status int, body string) {
+	if strings.HasPrefix(body, "{") || strings.HasPrefix(body, "[") {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, body)
+}
+
+func TestClientRequests(t *testing.T) {
+	authorID := 3
+	tests := map[string]struct {
+		call      func(ctx context.Context, c *Client) error
+		wantQuery string
+		wantBody  string
+		want      string // "METHOD path"
+	}{
+		"get user": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetUser(ctx, 7)
+				return err
+			},
+			want: "GET /api/user/7",
+		},
+		"list blogs": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.ListBlogs(ctx, ListBlogsOptions{
+					Tag:    "go",
+					Sort:   SortNewest,
+					Expand: Expand{Include: []string{IncludeAuthor}, Fields: []string{"title", "author.name"}},
+				})
+				return err
+			},
+			want:      "GET /api/blog",
+			wantQuery: "fields=title%2Cauthor.name&include=author&sort=newest&tag=go",
+		},
+		"update comment": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.UpdateComment(ctx, CommentInput{UserID: 3, BlogID: 4, Message: "edited"})
+				return err
+			},
+			want:      "PUT /api/comments",
+			wantQuery: "author_id=3&blog_id=4",
+			wantBody:  `{"user_id":3,"blog_id":4,"message":"edited"}`,
+		},
+		"list comments": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.ListComments(ctx, ListCommentsOptions{AuthorID: &authorID})
+				return err
+			},
+			want:      "GET /api/comments",
+			wantQuery: "author_id=3",
+		},
+		"react to comment": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.ReactToComment(ctx, 3, 4, "like")
+				return err
+			},
+			want:      "PUT /api/comments/reactions/like",
+			wantQuery: "author_id=3&blog_id=4",
+		},
+		"unfollow": {
+			call: func(ctx context.Context, c *Client) error {
+				return c.Unfollow(ctx, 2)
+			},
+			want: "DELETE /api/user/2/follow",
+		},
+		"path segments are escaped": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.ReactToBlog(ctx, 1, "thumbs/up")
+				return err
+			},
+			want: "PUT /api/blog/1/reactions/thumbs%2Fup",
+		},
+		"clearing tags": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.UpdateBlog(ctx, 1, BlogInput{Title: "Hello", AuthorID: 2, Tags: []string{}})
+				return err
+			},
+			want:     "PUT /api/blog/1",
+			wantBody: `{"title":"Hello","author_id":2,"tags":[]}`,
+		},
+		"save reading list item": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.SaveReadingListItem(ctx, 1, 9, ReadingListItemInput{Note: "later"})
+				return err
+			},
+			want:     "PUT /api/me/lists/1/items/9",
+			wantBody: `{"note":"later"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got, gotQuery, gotBody string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				got = r.Method + " " + r.URL.EscapedPath()
+				gotQuery = r.URL.RawQuery
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+				if r.Method == http.MethodGet && r.URL.Path != "/api/user/7" {
+					reply(w, http.StatusOK, `[]`)
+					return
+				}
+				reply(w, http.StatusOK, `{}`)
+			})
+
+			if err := tc.call(context.Background(), c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+			if gotQuery != tc.wantQuery {
+				t.Errorf("want query %q, got %q", tc.wantQuery, gotQuery)
+			}
+			if gotBody != tc.wantBody {
+				t.Errorf("want body %s, got %s", tc.wantBody, gotBody)
+			}
+		})
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := map[string]struct {
+		status    int
+		header    http.Header
+		body      string
+		wantIs    error
+		wantCheck func(*Error) error
+	}{
+		"not found": {
+			status: http.StatusNotFound,
+			body:   "Blog not found\n",
+			wantIs: ErrNotFound,
+			wantCheck: func(e *Error) error {
+				if e.Message != "Blog not found" {
+					return fmt.Errorf("want the message, got %q", e.Message)
+				}
+				return nil
+			},
+		},
+		"problems": {
+			status: http.StatusBadRequest,
+			body:   `{"title": "title is required"}`,
+			wantIs: ErrInvalid,
+			wantCheck: func(e *Error) error {
+				if e.Problems["title"] != "title is required" {
+					return fmt.Errorf("want the title problem, got %v", e.Problems)
+				}
+				return nil
+			},
+		},
+		"unauthorized": {
+			status: http.StatusUnauthorized,
+			header: http.Header{"Www-Authenticate": {"Bearer"}},
+			body:   "Authentication required",
+			wantIs: ErrUnauthorized,
+		},
+		"held for moderation": {
+			status: http.StatusAccepted,
+			body:   `{"moderation_id": 4, "status": "pending", "filter": "links", "reason": "too many links"}`,
+			wantIs: ErrPendingModeration,
+			wantCheck: func(e *Error) error {
+				if e.Moderation == nil || e.Moderation.ID != 4 || e.Moderation.Filter != "links" {
+					return fmt.Errorf("want moderation 4 by links, got %+v", e.Moderation)
+				}
+				return nil
+			},
+		},
+		"rejected": {
+			status: http.StatusUnprocessableEntity,
+			body:   `{"moderation_id": 5, "status": "rejected", "filter": "blocklist", "reason": "blocked word"}`,
+			wantIs: ErrRejected,
+		},
+		"rate limited": {
+			status: http.StatusTooManyRequests,
+			header: http.Header{"Retry-After": {"0"}},
+			body:   "Too Many Requests",
+			wantIs: ErrRateLimited,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tc.header {
+					w.Header()[k] = v
+				}
+				reply(w, tc.status, tc.body)
+			})
+
+			_, err := c.CreateBlog(context.Background(), BlogInput{Title: "Hello", AuthorID: 1})
+			if !errors.Is(err, tc.wantIs) {
+				t.Fatalf("want %v, got %v", tc.wantIs, err)
+			}
+			var apiErr *Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tc.status {
+				t.Fatalf("want an *Error with status %d, got %#v", tc.status, err)
+			}
+			if tc.wantCheck != nil {
+				if err := tc.wantCheck(apiErr); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := map[string]struct {
+		call         func(ctx context.Context, c *Client) error
+		failures     int
+		status       int
+		wantAttempts int32
+		wantErr      bool
+	}{
+		"idempotent calls are retried": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.UpdateUser(ctx, 1, UserInput{Name: "Ada"})
+				return err
+			},
+			failures:     2,
+			status:       http.StatusServiceUnavailable,
+			wantAttempts: 3,
+		},
+		"until attempts run out": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetUser(ctx, 1)
+				return err
+			},
+			failures:     5,
+			status:       http.StatusBadGateway,
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		"others are not": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.CreateUser(ctx, UserInput{Name: "Ada"})
+				return err
+			},
+			failures:     1,
+			status:       http.StatusServiceUnavailable,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		"nor are client errors": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetUser(ctx, 1)
+				return err
+			},
+			failures:     1,
+			status:       http.StatusNotFound,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		"nor readiness checks": {
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Ready(ctx)
+				return err
+			},
+			failures:     1,
+			status:       http.StatusServiceUnavailable,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var attempts atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPut && !strings.Contains(string(body), "Ada") {
+					t.Errorf("want the body on every attempt, got %q", body)
+				}
+				if int(attempts.Add(1)) <= tc.failures {
+					w.Header().Set("Retry-After", "0")
+					reply(w, tc.status, "unavailable")
+					return
+				}
+				reply(w, http.StatusOK, `{"id": 1, "name": "Ada"}`)
+			})
+
+			err := tc.call(context.Background(), c)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error %t, got %v", tc.wantErr, err)
+			}
+			if got := attempts.Load(); got != tc.wantAttempts {
+				t.Errorf("want %d attempts, got %d", tc.wantAttempts, got)
+			}
+		})
+	}
+}
+
+func TestClientRetriesStopWithContext(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Retry-After", "60")
+		reply(w, http.StatusTooManyRequests, "Too Many Requests")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.GetUser(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want the deadline to end the wait, got %v", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("want 1 attempt, got %d", got)
+	}
+}
+
+func TestClientAuthentication(t *testing.T) {
+	var calls atomic.Int32
+	tokens := TokenSourceFunc(func(ctx context.Context) (string, error) {
+		return fmt.Sprintf("token-%d", calls.Add(1)), nil
+	})
+
+	var got []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		if len(got) == 1 {
+			reply(w, http.StatusServiceUnavailable, "unavailable")
+			return
+		}
+		reply(w, http.StatusOK, `[]`)
+	}, WithTokenSource(tokens))
+
+	if _, err := c.ListBookmarks(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != "Bearer token-1" || got[1] != "Bearer token-2" {
+		t.Errorf("want a fresh token on each attempt, got %q", got)
+	}
+
+	anonymous := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if h := r.Header.Get("Authorization"); h != "" {
+			t.Errorf("want no Authorization header, got %q", h)
+		}
+		reply(w, http.StatusOK, `{"status": "ok"}`)
+	})
+	if _, err := anonymous.Health(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientFeedAll(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"blogs": [{"id": 5}, {"id": 4}], "next_cursor": "c1"}`,
+		"c1": `{"blogs": [{"id": 3}, {"id": 2}], "next_cursor": "c2"}`,
+		"c2": `{"blogs": [{"id": 1}]}`,
+	}
+	var requests int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("limit") != "2" {
+			t.Errorf("want limit=2, got %s", r.URL.RawQuery)
+		}
+		reply(w, http.StatusOK, pages[r.URL.Query().Get("cursor")])
+	})
+
+	var ids []uint
+	for blog, err := range c.FeedAll(context.Background(), 2) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, blog.ID)
+	}
+	if fmt.Sprint(ids) != "[5 4 3 2 1]" {
+		t.Errorf("want every blog in order, got %v", ids)
+	}
+
+	requests = 0
+	for blog := range c.FeedAll(context.Background(), 2) {
+		if blog.ID == 4 {
+			break
+		}
+	}
+	if requests != 1 {
+		t.Errorf("want breaking off to stop reading pages, got %d requests", requests)
+	}
+}
+
+func TestClientStream(t *testing.T) {
+	tests := map[string]struct {
+		body    string
+		wantIDs string
+		wantErr bool
+	}{
+		"complete": {
+			body:    `[{"id": 1, "title": "One"}, {"id": 2, "title": "Two"}]`,
+			wantIDs: "[1 2]",
+		},
+		"empty": {
+			body:    `[]`,
+			wantIDs: "[]",
+		},
+		"cut short": {
+			body:    `[{"id": 1, "title": "One"}, {"id": 2, "title": "Two"}`,
+			wantIDs: "[1 2]",
+			wantErr: true,
+		},
+		"not a list": {
+			body:    `{"id": 1}`,
+			wantIDs: "[]",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				reply(w, http.StatusOK, tc.body)
+			})
+
+			ids := []uint{}
+			var gotErr error
+			for blog, err := range c.Blogs(context.Background(), ListBlogsOptions{}) {
+				if err != nil {
+					gotErr = err
+					continue
+				}
+				ids = append(ids, blog.ID)
+			}
+			if fmt.Sprint(ids) != tc.wantIDs {
+				t.Errorf("want %s, got %v", tc.wantIDs, ids)
+			}
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, gotErr)
+			}
+		})
+	}
+}
+
+func TestClientImport(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "text/csv" || r.URL.Query().Get("kind") != ImportUsers {
+			t.Errorf("want a CSV import of users, got %s %s", r.Header.Get("Content-Type"), r.URL.RawQuery)
+		}
+		reply(w, http.StatusUnprocessableEntity, `{"kind": "users", "mode": "atomic", "rows": 2, "failed": 1, "errors": [{"row": 2, "problems": {"email": "email is invalid"}}]}`)
+	})
+
+	result, err := c.Import(context.Background(), ImportUsers, "text/csv", strings.NewReader("name,email,password\n"), ImportOptions{})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("want a 422 error, got %v", err)
+	}
+	if result.Failed != 1 || len(result.Errors) != 1 || result.Errors[0].Problems["email"] != "email is invalid" {
+		t.Errorf("want the failed row reported, got %+v", result)
+	}
+}
+
+func TestClientGraphQL(t *testing.T) {
+	tests := map[string]struct {
+		status     int
+		body       string
+		wantTitle  string
+		wantStatus int
+		wantCode   string
+	}{
+		"data": {
+			status:    http.StatusOK,
+			body:      `{"data": {"blog": {"title": "Hello"}}}`,
+			wantTitle: "Hello",
+		},
+		"field errors": {
+			status:     http.StatusOK,
+			body:       `{"data": {"blog": null}, "errors": [{"message": "blog not found", "path": ["blog"], "extensions": {"code": "NOT_FOUND"}}]}`,
+			wantStatus: http.StatusOK,
+			wantCode:   "NOT_FOUND",
+		},
+		"invalid request": {
+			status:     http.StatusBadRequest,
+			body:       `{"errors": [{"message": "Cannot query field \"nope\" on type \"Query\"."}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				reply(w, tc.status, tc.body)
+			})
+
+			var data struct {
+				Blog *struct {
+					Title string `json:"title"`
+				} `json:"blog"`
+			}
+			err := c.GraphQL(context.Background(), GraphQLRequest{Query: `{ blog(id: 1) { title } }`}, &data)
+
+			var gqlErr *GraphQLError
+			if tc.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if data.Blog == nil || data.Blog.Title != tc.wantTitle {
+					t.Errorf("want title %q, got %+v", tc.wantTitle, data.Blog)
+				}
+				return
+			}
+			if !errors.As(err, &gqlErr) || gqlErr.StatusCode != tc.wantStatus {
+				t.Fatalf("want a *GraphQLError with status %d, got %v", tc.wantStatus, err)
+			}
+			if tc.wantCode != "" && gqlErr.Errors[0].Code() != tc.wantCode {
+				t.Errorf("want code %s, got %s", tc.wantCode, gqlErr.Errors[0].Code())
+			}
+			if errors.Is(err, ErrInvalid) != (tc.wantStatus == http.StatusBadRequest) {
+				t.Errorf("want only requests that couldn't run to match ErrInvalid")
+			}
+		})
+	}
+}
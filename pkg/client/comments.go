@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListCommentsOptions filters the comments ListComments lists. Nil IDs
+// don't filter.
+type ListCommentsOptions struct {
+	AuthorID *int
+	BlogID   *int
+	Expand
+}
+
+// ListComments lists the comments matching opts.
+func (c *Client) ListComments(ctx context.Context, opts ListCommentsOptions) ([]Comment, error) {
+	var comments []Comment
+	err := c.do(ctx, listCommentsRequest(opts), &comments)
+	return comments, err
+}
+
+// Comments streams the comments ListComments lists, decoding each as it
+// arrives.
+func (c *Client) Comments(ctx context.Context, opts ListCommentsOptions) iter.Seq2[Comment, error] {
+	return stream[Comment](ctx, c, listCommentsRequest(opts))
+}
+
+func listCommentsRequest(opts ListCommentsOptions) request {
+	q := url.Values{}
+	if opts.AuthorID != nil {
+		q.Set("author_id", strconv.Itoa(*opts.AuthorID))
+	}
+	if opts.BlogID != nil {
+		q.Set("blog_id", strconv.Itoa(*opts.BlogID))
+	}
+	opts.Expand.set(q)
+	return request{method: http.MethodGet, path: "/api/comments", query: q}
+}
+
+// CreateComment creates a comment. Comments the content filters hold back
+// return an error matching ErrPendingModeration, and those they refuse one
+// matching ErrRejected, with the outcome in its Moderation.
+func (c *Client) CreateComment(ctx context.Context, comment CommentInput) (Comment, error) {
+	var created Comment
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/comments", body: comment}, &created)
+	return created, err
+}
+
+// UpdateComment replaces the message of the user's comment on the blog.
+func (c *Client) UpdateComment(ctx context.Context, comment CommentInput) (Comment, error) {
+	var updated Comment
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   "/api/comments",
+		query:  commentQuery(comment.UserID, comment.BlogID),
+		body:   comment,
+	}, &updated)
+	return updated, err
+}
+
+// DeleteComment deletes the comment userID wrote on the blog.
+func (c *Client) DeleteComment(ctx context.Context, userID, blogID int) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/comments", query: commentQuery(userID, blogID)}, nil)
+}
+
+// commentQuery returns the query parameters identifying the comment userID
+// wrote on the blog.
+func commentQuery(userID, blogID int) url.Values {
+	return url.Values{
+		"author_id": {strconv.Itoa(userID)},
+		"blog_id":   {strconv.Itoa(blogID)},
+	}
+}
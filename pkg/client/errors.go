@@ -0,0 +1,132 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sentinel errors an *Error matches with errors.Is, by the status of the
+// response it was decoded from.
+var (
+	ErrInvalid           = errors.New("invalid request")             // 400
+	ErrUnauthorized      = errors.New("authentication required")     // 401
+	ErrForbidden         = errors.New("forbidden")                   // 403
+	ErrNotFound          = errors.New("not found")                   // 404
+	ErrConflict          = errors.New("conflict")                    // 409
+	ErrRateLimited       = errors.New("rate limited")                // 429
+	ErrPendingModeration = errors.New("held for moderation")         // 202 with a moderation result
+	ErrRejected          = errors.New("rejected by content filters") // 422 with a moderation result
+)
+
+// Moderation is the outcome of screening content the API didn't accept
+// straight away.
+type Moderation struct {
+	ID     uint   `json:"moderation_id"`
+	Status string `json:"status"` // pending or rejected
+	Filter string `json:"filter"`
+	Reason string `json:"reason"`
+}
+
+// Error is an error response from the API.
+type Error struct {
+	StatusCode int
+	// Message is the body of plain text error responses.
+	Message string
+	// Problems maps the fields of an invalid request body, or query
+	// parameter names, to what is wrong with them.
+	Problems map[string]string
+	// Moderation is set when content was held for moderation or rejected by
+	// the content filters.
+	Moderation *Moderation
+	// RetryAfter is how long the API asked to wait before trying again.
+	RetryAfter time.Duration
+	// Body is the raw body of responses that are none of the above, such as
+	// a failed import's result.
+	Body []byte
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "blog api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	switch {
+	case e.Moderation != nil:
+		fmt.Fprintf(&b, ": content %s by %s filter (moderation %d)", e.Moderation.Status, e.Moderation.Filter, e.Moderation.ID)
+	case len(e.Problems) > 0:
+		for i, field := range slices.Sorted(maps.Keys(e.Problems)) {
+			sep := ", "
+			if i == 0 {
+				sep = ": "
+			}
+			fmt.Fprintf(&b, "%s%s: %s", sep, field, e.Problems[field])
+		}
+	case e.Message != "":
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	return b.String()
+}
+
+// Is reports whether e is the kind of error target is.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrPendingModeration:
+		return e.StatusCode == http.StatusAccepted && e.Moderation != nil
+	case ErrRejected:
+		return e.StatusCode == http.StatusUnprocessableEntity && e.Moderation != nil
+	case ErrInvalid:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// maxErrorBody is how much of an error response is read.
+const maxErrorBody = 1 << 20
+
+// decodeError reads the error response resp into an *Error. The API answers
+// with plain text, a JSON object of problems, or a moderation result.
+func decodeError(resp *http.Response) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if seconds, err := strconv.Atoi(s); err == nil && seconds >= 0 {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		} else if t, err := http.ParseTime(s); err == nil {
+			apiErr.RetryAfter = max(time.Until(t), 0)
+		}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		apiErr.Message = strings.TrimSpace(string(body))
+		return apiErr
+	}
+
+	var moderation Moderation
+	if err := json.Unmarshal(body, &moderation); err == nil && moderation.ID != 0 {
+		apiErr.Moderation = &moderation
+		return apiErr
+	}
+	var problems map[string]string
+	if err := json.Unmarshal(body, &problems); err == nil && len(problems) > 0 {
+		apiErr.Problems = problems
+		return apiErr
+	}
+	apiErr.Body = body
+	return apiErr
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLRequest is a GraphQL query or mutation.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLMessage is one of the errors of a GraphQL response.
+type GraphQLMessage struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Code returns the error's extensions.code, such as NOT_FOUND or
+// BAD_USER_INPUT, or "" if it has none.
+func (m GraphQLMessage) Code() string {
+	code, _ := m.Extensions["code"].(string)
+	return code
+}
+
+// GraphQLError is a GraphQL response listing errors. StatusCode is 400 for
+// requests that couldn't be run at all, and 200 for those some fields of
+// failed to resolve, whose data holds the rest.
+type GraphQLError struct {
+	StatusCode int
+	Errors     []GraphQLMessage
+}
+
+func (e *GraphQLError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, m := range e.Errors {
+		messages[i] = m.Message
+	}
+	return "blog api: graphql: " + strings.Join(messages, "; ")
+}
+
+// Is reports whether e is the kind of error target is: ErrInvalid for
+// requests that couldn't be run.
+func (e *GraphQLError) Is(target error) bool {
+	return target == ErrInvalid && e.StatusCode == http.StatusBadRequest
+}
+
+// GraphQL runs req and decodes the data of its response into data, unless
+// data is nil. Responses listing errors return a *GraphQLError, after data
+// has been decoded. Requests aren't retried, as they may be mutations.
+func (c *Client) GraphQL(ctx context.Context, req GraphQLRequest, data any) error {
+	var resp struct {
+		Data   json.RawMessage  `json:"data"`
+		Errors []GraphQLMessage `json:"errors"`
+	}
+	err := c.do(ctx, request{method: http.MethodPost, path: "/graphql", body: req}, &resp)
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest && apiErr.Body != nil {
+		if jsonErr := json.Unmarshal(apiErr.Body, &resp); jsonErr == nil && len(resp.Errors) > 0 {
+			return &GraphQLError{StatusCode: apiErr.StatusCode, Errors: resp.Errors}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if data != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := json.Unmarshal(resp.Data, data); err != nil {
+			return fmt.Errorf("decoding graphql data: %w", err)
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return &GraphQLError{StatusCode: http.StatusOK, Errors: resp.Errors}
+	}
+	return nil
+}
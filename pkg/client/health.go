@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Health reports whether the API is up.
+func (c *Client) Health(ctx context.Context) (HealthReport, error) {
+	var report HealthReport
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/health"}, &report)
+	return report, err
+}
+
+// Live reports whether the API process is serving requests, without
+// checking its dependencies.
+func (c *Client) Live(ctx context.Context) (HealthReport, error) {
+	var report HealthReport
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/health/live"}, &report)
+	return report, err
+}
+
+// Ready reports whether the API and each component it depends on are ready
+// to serve traffic. It isn't retried. An API that isn't ready returns its
+// report alongside an *Error for the 503, so the failing components can be
+// told apart.
+func (c *Client) Ready(ctx context.Context) (HealthReport, error) {
+	var report HealthReport
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/health/ready", once: true}, &report)
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusServiceUnavailable && apiErr.Body != nil {
+		if jsonErr := json.Unmarshal(apiErr.Body, &report); jsonErr != nil {
+			return HealthReport{}, err
+		}
+	}
+	return report, err
+}
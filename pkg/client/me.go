@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// The calls below act for the authenticated user, and fail with an error
+// matching ErrUnauthorized when the client has no token.
+
+// ListBookmarks lists the user's bookmarks, most recent first.
+func (c *Client) ListBookmarks(ctx context.Context) ([]Bookmark, error) {
+	var bookmarks []Bookmark
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/me/bookmarks"}, &bookmarks)
+	return bookmarks, err
+}
+
+// SaveBookmark bookmarks the blog, or updates the note of its bookmark.
+func (c *Client) SaveBookmark(ctx context.Context, blogID int, note string) (Bookmark, error) {
+	var bookmark Bookmark
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   pathf("/api/me/bookmarks/%s", blogID),
+		body:   map[string]string{"note": note},
+	}, &bookmark)
+	return bookmark, err
+}
+
+// DeleteBookmark removes the bookmark of the blog.
+func (c *Client) DeleteBookmark(ctx context.Context, blogID int) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: pathf("/api/me/bookmarks/%s", blogID)}, nil)
+}
+
+// ListReadingLists lists the user's reading lists, without their items.
+func (c *Client) ListReadingLists(ctx context.Context) ([]ReadingList, error) {
+	var lists []ReadingList
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/me/lists"}, &lists)
+	return lists, err
+}
+
+// CreateReadingList creates a reading list.
+func (c *Client) CreateReadingList(ctx context.Context, list ReadingListInput) (ReadingList, error) {
+	var created ReadingList
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/me/lists", body: list}, &created)
+	return created, err
+}
+
+// GetReadingList reads one of the user's reading lists with its items.
+func (c *Client) GetReadingList(ctx context.Context, id uint) (ReadingList, error) {
+	var list ReadingList
+	err := c.do(ctx, request{method: http.MethodGet, path: pathf("/api/me/lists/%s", id)}, &list)
+	return list, err
+}
+
+// GetPublicReadingList reads anyone's public reading list with its items.
+// It doesn't need a token.
+func (c *Client) GetPublicReadingList(ctx context.Context, id uint) (ReadingList, error) {
+	var list ReadingList
+	err := c.do(ctx, request{method: http.MethodGet, path: pathf("/api/lists/%s", id)}, &list)
+	return list, err
+}
+
+// UpdateReadingList renames the reading list or changes whether it is
+// public.
+func (c *Client) UpdateReadingList(ctx context.Context, id uint, list ReadingListInput) (ReadingList, error) {
+	var updated ReadingList
+	err := c.do(ctx, request{method: http.MethodPut, path: pathf("/api/me/lists/%s", id), body: list}, &updated)
+	return updated, err
+}
+
+// DeleteReadingList deletes the reading list.
+func (c *Client) DeleteReadingList(ctx context.Context, id uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: pathf("/api/me/lists/%s", id)}, nil)
+}
+
+// SaveReadingListItem adds the blog to the reading list, or moves it and
+// updates its note if it is already there.
+func (c *Client) SaveReadingListItem(ctx context.Context, id uint, blogID int, item ReadingListItemInput) (ReadingListItem, error) {
+	var saved ReadingListItem
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   pathf("/api/me/lists/%s/items/%s", id, blogID),
+		body:   item,
+	}, &saved)
+	return saved, err
+}
+
+// RemoveReadingListItem takes the blog off the reading list.
+func (c *Client) RemoveReadingListItem(ctx context.Context, id uint, blogID int) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: pathf("/api/me/lists/%s/items/%s", id, blogID)}, nil)
+}
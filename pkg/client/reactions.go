@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ReactToBlog records the authenticated user's reaction of type typ, an
+// emoji such as "👍", to the blog with id. Reacting again is not an error.
+func (c *Client) ReactToBlog(ctx context.Context, id uint, typ string) (Reaction, error) {
+	var reaction Reaction
+	err := c.do(ctx, request{method: http.MethodPut, path: pathf("/api/blog/%s/reactions/%s", id, typ)}, &reaction)
+	return reaction, err
+}
+
+// UnreactToBlog removes the authenticated user's reaction of type typ to the
+// blog with id.
+func (c *Client) UnreactToBlog(ctx context.Context, id uint, typ string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: pathf("/api/blog/%s/reactions/%s", id, typ)}, nil)
+}
+
+// ListBlogReactions lists the reactions to the blog with id, only those of
+// type typ if it isn't empty.
+func (c *Client) ListBlogReactions(ctx context.Context, id uint, typ string) ([]Reaction, error) {
+	q := url.Values{}
+	setString(q, "type", typ)
+	var reactions []Reaction
+	err := c.do(ctx, request{method: http.MethodGet, path: pathf("/api/blog/%s/reactions", id), query: q}, &reactions)
+	return reactions, err
+}
+
+// ReactToComment records the authenticated user's reaction of type typ to
+// the comment authorID wrote on the blog. Reacting again is not an error.
+func (c *Client) ReactToComment(ctx context.Context, authorID, blogID int, typ string) (Reaction, error) {
+	var reaction Reaction
+	err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   pathf("/api/comments/reactions/%s", typ),
+		query:  commentQuery(authorID, blogID),
+	}, &reaction)
+	return reaction, err
+}
+
+// UnreactToComment removes the authenticated user's reaction of type typ to
+// the comment authorID wrote on the blog.
+func (c *Client) UnreactToComment(ctx context.Context, authorID, blogID int, typ string) error {
+	return c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   pathf("/api/comments/reactions/%s", typ),
+		query:  commentQuery(authorID, blogID),
+	}, nil)
+}
+
+// ListCommentReactions lists the reactions to the comment authorID wrote on
+// the blog, only those of type typ if it isn't empty.
+func (c *Client) ListCommentReactions(ctx context.Context, authorID, blogID int, typ string) ([]Reaction, error) {
+	q := commentQuery(authorID, blogID)
+	setString(q, "type", typ)
+	var reactions []Reaction
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/comments/reactions", query: q}, &reactions)
+	return reactions, err
+}
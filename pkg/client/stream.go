@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+)
+
+// stream sends req, which returns a JSON array, and yields each element as
+// it is decoded rather than once the whole list has arrived. It stops after
+// the first error. The API leaves lists it fails to finish unterminated, so
+// those end with an error too.
+func stream[T any](ctx context.Context, c *Client, req request) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		resp, err := c.send(ctx, req)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		fail := func(err error) {
+			yield(zero, fmt.Errorf("decoding %s %s response: %w", req.method, req.path, err))
+		}
+		dec := json.NewDecoder(resp.Body)
+		if tok, err := dec.Token(); err != nil {
+			fail(err)
+			return
+		} else if tok != json.Delim('[') {
+			fail(fmt.Errorf("want a list, got %v", tok))
+			return
+		}
+		for dec.More() {
+			var v T
+			if err := dec.Decode(&v); err != nil {
+				fail(err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			fail(err)
+		}
+	}
+}
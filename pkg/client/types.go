@@ -0,0 +1,228 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// User is a user of the blog.
+type User struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// UserInput is the body of requests creating or replacing a user.
+type UserInput struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Author is the author of a blog or comment, as included in reads that ask
+// for it.
+type Author struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// Token is a bearer token issued for a user's credentials.
+type Token struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UserID    uint      `json:"user_id"`
+}
+
+// Blog is a blog post. Author and Comments are only set when asked for with
+// Include.
+type Blog struct {
+	ID            uint           `json:"id"`
+	Title         string         `json:"title"`
+	AuthorID      int            `json:"author_id"`
+	CreatedDate   time.Time      `json:"created_date"`
+	Score         float64        `json:"score"`
+	RatingAverage float64        `json:"rating_average"`
+	RatingCount   int            `json:"rating_count"`
+	Reactions     map[string]int `json:"reactions,omitempty"`
+	Tags          []string       `json:"tags,omitempty"`
+	Author        *Author        `json:"author,omitempty"`
+	Comments      []Comment      `json:"comments,omitempty"`
+}
+
+// BlogInput is the body of requests creating or replacing a blog. On
+// update, nil Tags keep the blog's tags and an empty slice clears them.
+type BlogInput struct {
+	Title    string   `json:"title"`
+	AuthorID int      `json:"author_id"`
+	Tags     []string `json:"tags"`
+}
+
+// Comment is a user's comment on a blog. A user comments on a blog at most
+// once, so the two identify it. User is only set when asked for with
+// Include.
+type Comment struct {
+	UserID      int            `json:"user_id"`
+	BlogID      int            `json:"blog_id"`
+	Message     string         `json:"message"`
+	CreatedDate time.Time      `json:"created_date"`
+	Reactions   map[string]int `json:"reactions,omitempty"`
+	User        *Author        `json:"user,omitempty"`
+}
+
+// CommentInput is the body of requests creating or replacing a comment.
+type CommentInput struct {
+	UserID  int    `json:"user_id"`
+	BlogID  int    `json:"blog_id"`
+	Message string `json:"message"`
+}
+
+// Follow is one user following another.
+type Follow struct {
+	FollowerID  int       `json:"follower_id"`
+	FolloweeID  int       `json:"followee_id"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+// FollowUser is a user in a list of followers or followed users.
+type FollowUser struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	FollowedDate time.Time `json:"followed_date"`
+}
+
+// FeedPage is a page of a user's feed.
+type FeedPage struct {
+	Blogs []Blog `json:"blogs"`
+	// NextCursor fetches the next page; it is empty on the last one.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Reaction is a user's reaction to a blog or comment.
+type Reaction struct {
+	UserID        int       `json:"user_id"`
+	UserName      string    `json:"user_name,omitempty"`
+	BlogID        int       `json:"blog_id"`
+	CommentUserID int       `json:"comment_user_id,omitempty"`
+	Type          string    `json:"type"`
+	CreatedDate   time.Time `json:"created_date"`
+}
+
+// Bookmark is a blog saved by a user.
+type Bookmark struct {
+	UserID      int       `json:"user_id"`
+	BlogID      int       `json:"blog_id"`
+	BlogTitle   string    `json:"blog_title,omitempty"`
+	Note        string    `json:"note"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+// ReadingList is a named, ordered list of blogs.
+type ReadingList struct {
+	ID          uint              `json:"id"`
+	UserID      int               `json:"user_id"`
+	Name        string            `json:"name"`
+	Public      bool              `json:"public"`
+	CreatedDate time.Time         `json:"created_date"`
+	Items       []ReadingListItem `json:"items,omitempty"`
+}
+
+// ReadingListInput is the body of requests creating or replacing a reading
+// list.
+type ReadingListInput struct {
+	Name   string `json:"name"`
+	Public bool   `json:"public"`
+}
+
+// ReadingListItem is a blog on a reading list.
+type ReadingListItem struct {
+	BlogID    int       `json:"blog_id"`
+	BlogTitle string    `json:"blog_title,omitempty"`
+	Position  int       `json:"position"`
+	Note      string    `json:"note"`
+	AddedDate time.Time `json:"added_date"`
+}
+
+// ReadingListItemInput is the body of requests adding or moving a blog on a
+// reading list. A zero Position appends it.
+type ReadingListItemInput struct {
+	Position int    `json:"position,omitempty"`
+	Note     string `json:"note"`
+}
+
+// ModerationItem is content the content filters flagged or rejected.
+type ModerationItem struct {
+	ID          uint            `json:"id"`
+	Kind        string          `json:"kind"`
+	UserID      int             `json:"user_id"`
+	Text        string          `json:"text"`
+	Payload     json.RawMessage `json:"payload"`
+	Verdict     string          `json:"verdict"`
+	Filter      string          `json:"filter"`
+	Reason      string          `json:"reason"`
+	Status      string          `json:"status"`
+	CreatedDate time.Time       `json:"created_date"`
+	DecidedDate *time.Time      `json:"decided_date,omitempty"`
+}
+
+// BatchRequest is a list of API calls run in one request.
+type BatchRequest struct {
+	// Atomic runs the operations in one transaction, committed only if all
+	// of them succeed.
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is one call in a BatchRequest. Path is relative to the
+// API root, as in "/api/blog/1". Batches and imports can't be batched.
+type BatchOperation struct {
+	ID     string          `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchResponse holds the result of each operation of a BatchRequest.
+type BatchResponse struct {
+	Committed *bool         `json:"committed,omitempty"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchResult is the response to one BatchOperation.
+type BatchResult struct {
+	ID     string          `json:"id,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// ImportResult reports on a bulk import.
+type ImportResult struct {
+	Kind      string        `json:"kind"`
+	Mode      string        `json:"mode"`
+	DryRun    bool          `json:"dry_run"`
+	Rows      int           `json:"rows"`
+	Imported  int           `json:"imported"`
+	Failed    int           `json:"failed"`
+	Committed bool          `json:"committed"`
+	Errors    []ImportError `json:"errors"`
+}
+
+// ImportError lists the problems with one row of an import.
+type ImportError struct {
+	Row      int               `json:"row"`
+	Problems map[string]string `json:"problems"`
+}
+
+// HealthReport is the status of the API and the components it depends on.
+type HealthReport struct {
+	Status     string                     `json:"status"`
+	Components map[string]HealthComponent `json:"components,omitempty"`
+}
+
+// HealthComponent is the status of one dependency of the API.
+type HealthComponent struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Detail    any     `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
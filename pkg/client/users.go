@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// CreateToken exchanges a user's credentials for a bearer token. Pass it to
+// WithToken to authenticate the calls of a new client.
+func (c *Client) CreateToken(ctx context.Context, email, password string) (Token, error) {
+	var token Token
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/token",
+		body:   map[string]string{"email": email, "password": password},
+	}, &token)
+	return token, err
+}
+
+// CreateUser creates a user.
+func (c *Client) CreateUser(ctx context.Context, user UserInput) (User, error) {
+	var created User
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/user", body: user}, &created)
+	return created, err
+}
+
+// GetUser reads the user with id.
+func (c *Client) GetUser(ctx context.Context, id uint) (User, error) {
+	var user User
+	err := c.do(ctx, request{method: http.MethodGet, path: pathf("/api/user/%s", id)}, &user)
+	return user, err
+}
+
+// ListUsers lists the users, only those whose name contains name if it isn't
+// empty.
+func (c *Client) ListUsers(ctx context.Context, name string) ([]User, error) {
+	var users []User
+	err := c.do(ctx, listUsersRequest(name), &users)
+	return users, err
+}
+
+// Users streams the users ListUsers lists, decoding each as it arrives.
+func (c *Client) Users(ctx context.Context, name string) iter.Seq2[User, error] {
+	return stream[User](ctx, c, listUsersRequest(name))
+}
+
+func listUsersRequest(name string) request {
+	q := url.Values{}
+	setString(q, "name", name)
+	return request{method: http.MethodGet, path: "/api/user", query: q}
+}
+
+// UpdateUser replaces the user with id.
+func (c *Client) UpdateUser(ctx context.Context, id uint, user UserInput) (User, error) {
+	var updated User
+	err := c.do(ctx, request{method: http.MethodPut, path: pathf("/api/user/%s", id), body: user}, &updated)
+	return updated, err
+}
+
+// DeleteUser deletes the user with id.
+func (c *Client) DeleteUser(ctx context.Context, id uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: pathf("/api/user/%s", id)}, nil)
+}
+
+// Follow makes the authenticated user follow the user followeeID. Following
+// a user again is not an error.
+func (c *Client) Follow(ctx context.Context, followeeID int) (Follow, error) {
+	var follow Follow
+	err := c.do(ctx, request{method: http.MethodPut, path: pathf("/api/user/%s/follow", followeeID)}, &follow)
+	return follow, err
+}
+
+// Unfollow stops the authenticated user following the user followeeID.
+func (c *Client) Unfollow(ctx context.Context, followeeID int) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: pathf("/api/user/%s/follow", followeeID)}, nil)
+}
+
+// ListFollowers lists the users following the user with id.
+func (c *Client) ListFollowers(ctx context.Context, id int) ([]FollowUser, error) {
+	var users []FollowUser
+	err := c.do(ctx, request{method: http.MethodGet, path: pathf("/api/user/%s/followers", id)}, &users)
+	return users, err
+}
+
+// ListFollowing lists the users the user with id follows.
+func (c *Client) ListFollowing(ctx context.Context, id int) ([]FollowUser, error) {
+	var users []FollowUser
+	err := c.do(ctx, request{method: http.MethodGet, path: pathf("/api/user/%s/following", id)}, &users)
+	return users, err
+}
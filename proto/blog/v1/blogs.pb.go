@@ -0,0 +1,726 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: blog/v1/blogs.proto
+
+package blogv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// BlogSort is how blogs are ordered.
+type BlogSort int32
+
+const (
+	// In storage order.
+	BlogSort_BLOG_SORT_UNSPECIFIED BlogSort = 0
+	// Best weighted rating first.
+	BlogSort_BLOG_SORT_SCORE BlogSort = 1
+	// Most recently created first.
+	BlogSort_BLOG_SORT_NEWEST BlogSort = 2
+)
+
+// Enum value maps for BlogSort.
+var (
+	BlogSort_name = map[int32]string{
+		0: "BLOG_SORT_UNSPECIFIED",
+		1: "BLOG_SORT_SCORE",
+		2: "BLOG_SORT_NEWEST",
+	}
+	BlogSort_value = map[string]int32{
+		"BLOG_SORT_UNSPECIFIED": 0,
+		"BLOG_SORT_SCORE":       1,
+		"BLOG_SORT_NEWEST":      2,
+	}
+)
+
+func (x BlogSort) Enum() *BlogSort {
+	p := new(BlogSort)
+	*p = x
+	return p
+}
+
+func (x BlogSort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BlogSort) Descriptor() protoreflect.EnumDescriptor {
+	return file_blog_v1_blogs_proto_enumTypes[0].Descriptor()
+}
+
+func (BlogSort) Type() protoreflect.EnumType {
+	return &file_blog_v1_blogs_proto_enumTypes[0]
+}
+
+func (x BlogSort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BlogSort.Descriptor instead.
+func (BlogSort) EnumDescriptor() ([]byte, []int) {
+	return file_blog_v1_blogs_proto_rawDescGZIP(), []int{0}
+}
+
+// Blog is a blog post.
+type Blog struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	AuthorId    int64                  `protobuf:"varint,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	CreatedDate *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_date,json=createdDate,proto3" json:"created_date,omitempty"`
+	// The Bayesian-weighted rating used for ranking.
+	Score         float64 `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`
+	RatingAverage float64 `protobuf:"fixed64,6,opt,name=rating_average,json=ratingAverage,proto3" json:"rating_average,omitempty"`
+	RatingCount   int64   `protobuf:"varint,7,opt,name=rating_count,json=ratingCount,proto3" json:"rating_count,omitempty"`
+	// The number of reactions of each type.
+	Reactions     map[string]int64 `protobuf:"bytes,8,rep,name=reactions,proto3" json:"reactions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Tags          []string         `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Blog) Reset() {
+	*x = Blog{}
+	mi := &file_blog_v1_blogs_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Blog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Blog) ProtoMessage() {}
+
+func (x *Blog) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_blogs_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Blog.ProtoReflect.Descriptor instead.
+func (*Blog) Descriptor() ([]byte, []int) {
+	return file_blog_v1_blogs_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Blog) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Blog) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Blog) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *Blog) GetCreatedDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedDate
+	}
+	return nil
+}
+
+func (x *Blog) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Blog) GetRatingAverage() float64 {
+	if x != nil {
+		return x.RatingAverage
+	}
+	return 0
+}
+
+func (x *Blog) GetRatingCount() int64 {
+	if x != nil {
+		return x.RatingCount
+	}
+	return 0
+}
+
+func (x *Blog) GetReactions() map[string]int64 {
+	if x != nil {
+		return x.Reactions
+	}
+	return nil
+}
+
+func (x *Blog) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type CreateBlogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	AuthorId      int64                  `protobuf:"varint,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBlogRequest) Reset() {
+	*x = CreateBlogRequest{}
+	mi := &file_blog_v1_blogs_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBlogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBlogRequest) ProtoMessage() {}
+
+func (x *CreateBlogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_blogs_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBlogRequest.ProtoReflect.Descriptor instead.
+func (*CreateBlogRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_blogs_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateBlogRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateBlogRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *CreateBlogRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type GetBlogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlogRequest) Reset() {
+	*x = GetBlogRequest{}
+	mi := &file_blog_v1_blogs_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlogRequest) ProtoMessage() {}
+
+func (x *GetBlogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_blogs_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlogRequest.ProtoReflect.Descriptor instead.
+func (*GetBlogRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_blogs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetBlogRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListBlogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only blogs whose title contains the value, case-insensitively.
+	Title string `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	// Only blogs written by the user, when non-zero.
+	AuthorId int64 `protobuf:"varint,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	// Only blogs carrying the tag, when non-empty.
+	Tag           string   `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	Sort          BlogSort `protobuf:"varint,4,opt,name=sort,proto3,enum=blog.v1.BlogSort" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBlogsRequest) Reset() {
+	*x = ListBlogsRequest{}
+	mi := &file_blog_v1_blogs_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBlogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlogsRequest) ProtoMessage() {}
+
+func (x *ListBlogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_blogs_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlogsRequest.ProtoReflect.Descriptor instead.
+func (*ListBlogsRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_blogs_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListBlogsRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ListBlogsRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *ListBlogsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ListBlogsRequest) GetSort() BlogSort {
+	if x != nil {
+		return x.Sort
+	}
+	return BlogSort_BLOG_SORT_UNSPECIFIED
+}
+
+type ListBlogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Blogs         []*Blog                `protobuf:"bytes,1,rep,name=blogs,proto3" json:"blogs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBlogsResponse) Reset() {
+	*x = ListBlogsResponse{}
+	mi := &file_blog_v1_blogs_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBlogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlogsResponse) ProtoMessage() {}
+
+func (x *ListBlogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_blogs_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlogsResponse.ProtoReflect.Descriptor instead.
+func (*ListBlogsResponse) Descriptor() ([]byte, []int) {
+	return file_blog_v1_blogs_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListBlogsResponse) GetBlogs() []*Blog {
+	if x != nil {
+		return x.Blogs
+	}
+	return nil
+}
+
+type UpdateBlogRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title    string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	AuthorId int64                  `protobuf:"varint,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	// The blog's tags. Leaving them out keeps the current tags, unless
+	// clear_tags is set.
+	Tags          []string `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	ClearTags     bool     `protobuf:"varint,5,opt,name=clear_tags,json=clearTags,proto3" json:"clear_tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBlogRequest) Reset() {
+	*x = UpdateBlogRequest{}
+	mi := &file_blog_v1_blogs_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBlogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBlogRequest) ProtoMessage() {}
+
+func (x *UpdateBlogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_blogs_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBlogRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBlogRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_blogs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateBlogRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateBlogRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UpdateBlogRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *UpdateBlogRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UpdateBlogRequest) GetClearTags() bool {
+	if x != nil {
+		return x.ClearTags
+	}
+	return false
+}
+
+type DeleteBlogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBlogRequest) Reset() {
+	*x = DeleteBlogRequest{}
+	mi := &file_blog_v1_blogs_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBlogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBlogRequest) ProtoMessage() {}
+
+func (x *DeleteBlogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_blogs_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBlogRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBlogRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_blogs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteBlogRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type RateBlogRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	BlogId uint64                 `protobuf:"varint,1,opt,name=blog_id,json=blogId,proto3" json:"blog_id,omitempty"`
+	UserId int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// From 1 to 5.
+	Rating        int32 `protobuf:"varint,3,opt,name=rating,proto3" json:"rating,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateBlogRequest) Reset() {
+	*x = RateBlogRequest{}
+	mi := &file_blog_v1_blogs_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateBlogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateBlogRequest) ProtoMessage() {}
+
+func (x *RateBlogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_blogs_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateBlogRequest.ProtoReflect.Descriptor instead.
+func (*RateBlogRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_blogs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RateBlogRequest) GetBlogId() uint64 {
+	if x != nil {
+		return x.BlogId
+	}
+	return 0
+}
+
+func (x *RateBlogRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RateBlogRequest) GetRating() int32 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+var File_blog_v1_blogs_proto protoreflect.FileDescriptor
+
+const file_blog_v1_blogs_proto_rawDesc = "" +
+	"\n" +
+	"\x13blog/v1/blogs.proto\x12\ablog.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf6\x02\n" +
+	"\x04Blog\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\x03R\bauthorId\x12=\n" +
+	"\fcreated_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedDate\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x01R\x05score\x12%\n" +
+	"\x0erating_average\x18\x06 \x01(\x01R\rratingAverage\x12!\n" +
+	"\frating_count\x18\a \x01(\x03R\vratingCount\x12:\n" +
+	"\treactions\x18\b \x03(\v2\x1c.blog.v1.Blog.ReactionsEntryR\treactions\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x1a<\n" +
+	"\x0eReactionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"Z\n" +
+	"\x11CreateBlogRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x1b\n" +
+	"\tauthor_id\x18\x02 \x01(\x03R\bauthorId\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\" \n" +
+	"\x0eGetBlogRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"~\n" +
+	"\x10ListBlogsRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x1b\n" +
+	"\tauthor_id\x18\x02 \x01(\x03R\bauthorId\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\x12%\n" +
+	"\x04sort\x18\x04 \x01(\x0e2\x11.blog.v1.BlogSortR\x04sort\"8\n" +
+	"\x11ListBlogsResponse\x12#\n" +
+	"\x05blogs\x18\x01 \x03(\v2\r.blog.v1.BlogR\x05blogs\"\x89\x01\n" +
+	"\x11UpdateBlogRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\x03R\bauthorId\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"clear_tags\x18\x05 \x01(\bR\tclearTags\"#\n" +
+	"\x11DeleteBlogRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"[\n" +
+	"\x0fRateBlogRequest\x12\x17\n" +
+	"\ablog_id\x18\x01 \x01(\x04R\x06blogId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06rating\x18\x03 \x01(\x05R\x06rating*P\n" +
+	"\bBlogSort\x12\x19\n" +
+	"\x15BLOG_SORT_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fBLOG_SORT_SCORE\x10\x01\x12\x14\n" +
+	"\x10BLOG_SORT_NEWEST\x10\x022\xee\x02\n" +
+	"\fBlogsService\x127\n" +
+	"\n" +
+	"CreateBlog\x12\x1a.blog.v1.CreateBlogRequest\x1a\r.blog.v1.Blog\x121\n" +
+	"\aGetBlog\x12\x17.blog.v1.GetBlogRequest\x1a\r.blog.v1.Blog\x12B\n" +
+	"\tListBlogs\x12\x19.blog.v1.ListBlogsRequest\x1a\x1a.blog.v1.ListBlogsResponse\x127\n" +
+	"\n" +
+	"UpdateBlog\x12\x1a.blog.v1.UpdateBlogRequest\x1a\r.blog.v1.Blog\x12@\n" +
+	"\n" +
+	"DeleteBlog\x12\x1a.blog.v1.DeleteBlogRequest\x1a\x16.google.protobuf.Empty\x123\n" +
+	"\bRateBlog\x12\x18.blog.v1.RateBlogRequest\x1a\r.blog.v1.BlogB,Z*github.com/navid/blog/proto/blog/v1;blogv1b\x06proto3"
+
+var (
+	file_blog_v1_blogs_proto_rawDescOnce sync.Once
+	file_blog_v1_blogs_proto_rawDescData []byte
+)
+
+func file_blog_v1_blogs_proto_rawDescGZIP() []byte {
+	file_blog_v1_blogs_proto_rawDescOnce.Do(func() {
+		file_blog_v1_blogs_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_blog_v1_blogs_proto_rawDesc), len(file_blog_v1_blogs_proto_rawDesc)))
+	})
+	return file_blog_v1_blogs_proto_rawDescData
+}
+
+var file_blog_v1_blogs_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_blog_v1_blogs_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_blog_v1_blogs_proto_goTypes = []any{
+	(BlogSort)(0),                 // 0: blog.v1.BlogSort
+	(*Blog)(nil),                  // 1: blog.v1.Blog
+	(*CreateBlogRequest)(nil),     // 2: blog.v1.CreateBlogRequest
+	(*GetBlogRequest)(nil),        // 3: blog.v1.GetBlogRequest
+	(*ListBlogsRequest)(nil),      // 4: blog.v1.ListBlogsRequest
+	(*ListBlogsResponse)(nil),     // 5: blog.v1.ListBlogsResponse
+	(*UpdateBlogRequest)(nil),     // 6: blog.v1.UpdateBlogRequest
+	(*DeleteBlogRequest)(nil),     // 7: blog.v1.DeleteBlogRequest
+	(*RateBlogRequest)(nil),       // 8: blog.v1.RateBlogRequest
+	nil,                           // 9: blog.v1.Blog.ReactionsEntry
+	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 11: google.protobuf.Empty
+}
+var file_blog_v1_blogs_proto_depIdxs = []int32{
+	10, // 0: blog.v1.Blog.created_date:type_name -> google.protobuf.Timestamp
+	9,  // 1: blog.v1.Blog.reactions:type_name -> blog.v1.Blog.ReactionsEntry
+	0,  // 2: blog.v1.ListBlogsRequest.sort:type_name -> blog.v1.BlogSort
+	1,  // 3: blog.v1.ListBlogsResponse.blogs:type_name -> blog.v1.Blog
+	2,  // 4: blog.v1.BlogsService.CreateBlog:input_type -> blog.v1.CreateBlogRequest
+	3,  // 5: blog.v1.BlogsService.GetBlog:input_type -> blog.v1.GetBlogRequest
+	4,  // 6: blog.v1.BlogsService.ListBlogs:input_type -> blog.v1.ListBlogsRequest
+	6,  // 7: blog.v1.BlogsService.UpdateBlog:input_type -> blog.v1.UpdateBlogRequest
+	7,  // 8: blog.v1.BlogsService.DeleteBlog:input_type -> blog.v1.DeleteBlogRequest
+	8,  // 9: blog.v1.BlogsService.RateBlog:input_type -> blog.v1.RateBlogRequest
+	1,  // 10: blog.v1.BlogsService.CreateBlog:output_type -> blog.v1.Blog
+	1,  // 11: blog.v1.BlogsService.GetBlog:output_type -> blog.v1.Blog
+	5,  // 12: blog.v1.BlogsService.ListBlogs:output_type -> blog.v1.ListBlogsResponse
+	1,  // 13: blog.v1.BlogsService.UpdateBlog:output_type -> blog.v1.Blog
+	11, // 14: blog.v1.BlogsService.DeleteBlog:output_type -> google.protobuf.Empty
+	1,  // 15: blog.v1.BlogsService.RateBlog:output_type -> blog.v1.Blog
+	10, // [10:16] is the sub-list for method output_type
+	4,  // [4:10] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_blog_v1_blogs_proto_init() }
+func file_blog_v1_blogs_proto_init() {
+	if File_blog_v1_blogs_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_blog_v1_blogs_proto_rawDesc), len(file_blog_v1_blogs_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_blog_v1_blogs_proto_goTypes,
+		DependencyIndexes: file_blog_v1_blogs_proto_depIdxs,
+		EnumInfos:         file_blog_v1_blogs_proto_enumTypes,
+		MessageInfos:      file_blog_v1_blogs_proto_msgTypes,
+	}.Build()
+	File_blog_v1_blogs_proto = out.File
+	file_blog_v1_blogs_proto_goTypes = nil
+	file_blog_v1_blogs_proto_depIdxs = nil
+}
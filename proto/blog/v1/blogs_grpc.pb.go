@@ -0,0 +1,338 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: blog/v1/blogs.proto
+
+package blogv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BlogsService_CreateBlog_FullMethodName = "/blog.v1.BlogsService/CreateBlog"
+	BlogsService_GetBlog_FullMethodName    = "/blog.v1.BlogsService/GetBlog"
+	BlogsService_ListBlogs_FullMethodName  = "/blog.v1.BlogsService/ListBlogs"
+	BlogsService_UpdateBlog_FullMethodName = "/blog.v1.BlogsService/UpdateBlog"
+	BlogsService_DeleteBlog_FullMethodName = "/blog.v1.BlogsService/DeleteBlog"
+	BlogsService_RateBlog_FullMethodName   = "/blog.v1.BlogsService/RateBlog"
+)
+
+// BlogsServiceClient is the client API for BlogsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BlogsService reads and writes blogs, like the /api/blog endpoints.
+type BlogsServiceClient interface {
+	// CreateBlog creates a blog, after running it through the content
+	// filters. Fails with PERMISSION_DENIED if the filters reject it and
+	// FAILED_PRECONDITION if they hold it for moderation.
+	CreateBlog(ctx context.Context, in *CreateBlogRequest, opts ...grpc.CallOption) (*Blog, error)
+	// GetBlog reads a blog. Fails with NOT_FOUND if there is none.
+	GetBlog(ctx context.Context, in *GetBlogRequest, opts ...grpc.CallOption) (*Blog, error)
+	// ListBlogs lists blogs, optionally filtered and sorted.
+	ListBlogs(ctx context.Context, in *ListBlogsRequest, opts ...grpc.CallOption) (*ListBlogsResponse, error)
+	// UpdateBlog replaces the title and author of a blog, and its tags if
+	// any are given.
+	UpdateBlog(ctx context.Context, in *UpdateBlogRequest, opts ...grpc.CallOption) (*Blog, error)
+	// DeleteBlog deletes a blog.
+	DeleteBlog(ctx context.Context, in *DeleteBlogRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// RateBlog records the authenticated user's rating of a blog, replacing
+	// any earlier rating, and returns the blog with its updated score. Fails
+	// with PERMISSION_DENIED if user_id isn't the authenticated user.
+	RateBlog(ctx context.Context, in *RateBlogRequest, opts ...grpc.CallOption) (*Blog, error)
+}
+
+type blogsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBlogsServiceClient(cc grpc.ClientConnInterface) BlogsServiceClient {
+	return &blogsServiceClient{cc}
+}
+
+func (c *blogsServiceClient) CreateBlog(ctx context.Context, in *CreateBlogRequest, opts ...grpc.CallOption) (*Blog, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Blog)
+	err := c.cc.Invoke(ctx, BlogsService_CreateBlog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogsServiceClient) GetBlog(ctx context.Context, in *GetBlogRequest, opts ...grpc.CallOption) (*Blog, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Blog)
+	err := c.cc.Invoke(ctx, BlogsService_GetBlog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogsServiceClient) ListBlogs(ctx context.Context, in *ListBlogsRequest, opts ...grpc.CallOption) (*ListBlogsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBlogsResponse)
+	err := c.cc.Invoke(ctx, BlogsService_ListBlogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogsServiceClient) UpdateBlog(ctx context.Context, in *UpdateBlogRequest, opts ...grpc.CallOption) (*Blog, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Blog)
+	err := c.cc.Invoke(ctx, BlogsService_UpdateBlog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogsServiceClient) DeleteBlog(ctx context.Context, in *DeleteBlogRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, BlogsService_DeleteBlog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogsServiceClient) RateBlog(ctx context.Context, in *RateBlogRequest, opts ...grpc.CallOption) (*Blog, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Blog)
+	err := c.cc.Invoke(ctx, BlogsService_RateBlog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BlogsServiceServer is the server API for BlogsService service.
+// All implementations must embed UnimplementedBlogsServiceServer
+// for forward compatibility.
+//
+// BlogsService reads and writes blogs, like the /api/blog endpoints.
+type BlogsServiceServer interface {
+	// CreateBlog creates a blog, after running it through the content
+	// filters. Fails with PERMISSION_DENIED if the filters reject it and
+	// FAILED_PRECONDITION if they hold it for moderation.
+	CreateBlog(context.Context, *CreateBlogRequest) (*Blog, error)
+	// GetBlog reads a blog. Fails with NOT_FOUND if there is none.
+	GetBlog(context.Context, *GetBlogRequest) (*Blog, error)
+	// ListBlogs lists blogs, optionally filtered and sorted.
+	ListBlogs(context.Context, *ListBlogsRequest) (*ListBlogsResponse, error)
+	// UpdateBlog replaces the title and author of a blog, and its tags if
+	// any are given.
+	UpdateBlog(context.Context, *UpdateBlogRequest) (*Blog, error)
+	// DeleteBlog deletes a blog.
+	DeleteBlog(context.Context, *DeleteBlogRequest) (*emptypb.Empty, error)
+	// RateBlog records the authenticated user's rating of a blog, replacing
+	// any earlier rating, and returns the blog with its updated score. Fails
+	// with PERMISSION_DENIED if user_id isn't the authenticated user.
+	RateBlog(context.Context, *RateBlogRequest) (*Blog, error)
+	mustEmbedUnimplementedBlogsServiceServer()
+}
+
+// UnimplementedBlogsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBlogsServiceServer struct{}
+
+func (UnimplementedBlogsServiceServer) CreateBlog(context.Context, *CreateBlogRequest) (*Blog, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBlog not implemented")
+}
+func (UnimplementedBlogsServiceServer) GetBlog(context.Context, *GetBlogRequest) (*Blog, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlog not implemented")
+}
+func (UnimplementedBlogsServiceServer) ListBlogs(context.Context, *ListBlogsRequest) (*ListBlogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlogs not implemented")
+}
+func (UnimplementedBlogsServiceServer) UpdateBlog(context.Context, *UpdateBlogRequest) (*Blog, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBlog not implemented")
+}
+func (UnimplementedBlogsServiceServer) DeleteBlog(context.Context, *DeleteBlogRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBlog not implemented")
+}
+func (UnimplementedBlogsServiceServer) RateBlog(context.Context, *RateBlogRequest) (*Blog, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RateBlog not implemented")
+}
+func (UnimplementedBlogsServiceServer) mustEmbedUnimplementedBlogsServiceServer() {}
+func (UnimplementedBlogsServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeBlogsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BlogsServiceServer will
+// result in compilation errors.
+type UnsafeBlogsServiceServer interface {
+	mustEmbedUnimplementedBlogsServiceServer()
+}
+
+func RegisterBlogsServiceServer(s grpc.ServiceRegistrar, srv BlogsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBlogsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BlogsService_ServiceDesc, srv)
+}
+
+func _BlogsService_CreateBlog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBlogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogsServiceServer).CreateBlog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogsService_CreateBlog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogsServiceServer).CreateBlog(ctx, req.(*CreateBlogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogsService_GetBlog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogsServiceServer).GetBlog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogsService_GetBlog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogsServiceServer).GetBlog(ctx, req.(*GetBlogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogsService_ListBlogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBlogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogsServiceServer).ListBlogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogsService_ListBlogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogsServiceServer).ListBlogs(ctx, req.(*ListBlogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogsService_UpdateBlog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBlogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogsServiceServer).UpdateBlog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogsService_UpdateBlog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogsServiceServer).UpdateBlog(ctx, req.(*UpdateBlogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogsService_DeleteBlog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBlogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogsServiceServer).DeleteBlog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogsService_DeleteBlog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogsServiceServer).DeleteBlog(ctx, req.(*DeleteBlogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogsService_RateBlog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RateBlogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogsServiceServer).RateBlog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogsService_RateBlog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogsServiceServer).RateBlog(ctx, req.(*RateBlogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BlogsService_ServiceDesc is the grpc.ServiceDesc for BlogsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BlogsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "blog.v1.BlogsService",
+	HandlerType: (*BlogsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateBlog",
+			Handler:    _BlogsService_CreateBlog_Handler,
+		},
+		{
+			MethodName: "GetBlog",
+			Handler:    _BlogsService_GetBlog_Handler,
+		},
+		{
+			MethodName: "ListBlogs",
+			Handler:    _BlogsService_ListBlogs_Handler,
+		},
+		{
+			MethodName: "UpdateBlog",
+			Handler:    _BlogsService_UpdateBlog_Handler,
+		},
+		{
+			MethodName: "DeleteBlog",
+			Handler:    _BlogsService_DeleteBlog_Handler,
+		},
+		{
+			MethodName: "RateBlog",
+			Handler:    _BlogsService_RateBlog_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "blog/v1/blogs.proto",
+}